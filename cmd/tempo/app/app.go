@@ -191,6 +191,7 @@ func (t *App) setupAuthMiddleware() {
 		noGRPCAuthOn := []string{
 			"/frontend.Frontend/Process",
 			"/frontend.Frontend/NotifyClientShutdown",
+			"/tempopb.IngesterTransfer/Transfer",
 		}
 		ignoredMethods := map[string]bool{}
 		for _, m := range noGRPCAuthOn {
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/dskit/kv/memberlist"
 	"github.com/grafana/dskit/modules"
 	"github.com/grafana/dskit/services"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/thanos-io/thanos/pkg/discovery/dns"
 	"github.com/weaveworks/common/middleware"
@@ -49,10 +50,13 @@ const (
 
 const (
 	apiPathTraces          string = "/api/traces/{traceID}"
+	apiPathTracesBatch     string = "/api/traces"
 	apiPathSearch          string = "/api/search"
 	apiPathSearchTags      string = "/api/search/tags"
 	apiPathSearchTagValues string = "/api/search/tag/{tagName}/values"
+	apiPathTail            string = "/api/tail"
 	apiPathEcho            string = "/api/echo"
+	apiPathSampling        string = "/api/sampling"
 )
 
 func (t *App) initServer() (services.Service, error) {
@@ -119,12 +123,17 @@ func (t *App) initDistributor() (services.Service, error) {
 		t.Server.HTTP.Handle("/distributor/ring", distributor.DistributorRing)
 	}
 
+	if distributor.SamplingStore != nil {
+		api_v2.RegisterSamplingManagerServer(t.Server.GRPC, distributor.SamplingStore)
+		t.Server.HTTP.Handle(addHTTPAPIPrefix(&t.cfg, apiPathSampling), t.HTTPAuthMiddleware.Wrap(distributor.SamplingStore))
+	}
+
 	return t.distributor, nil
 }
 
 func (t *App) initIngester() (services.Service, error) {
 	t.cfg.Ingester.LifecyclerConfig.ListenPort = t.cfg.Server.GRPCListenPort
-	ingester, err := ingester.New(t.cfg.Ingester, t.store, t.overrides)
+	ingester, err := ingester.New(t.cfg.Ingester, t.cfg.IngesterClient, t.ring, t.store, t.overrides)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ingester %w", err)
 	}
@@ -132,7 +141,10 @@ func (t *App) initIngester() (services.Service, error) {
 
 	tempopb.RegisterPusherServer(t.Server.GRPC, t.ingester)
 	tempopb.RegisterQuerierServer(t.Server.GRPC, t.ingester)
+	tempopb.RegisterIngesterTransferServer(t.Server.GRPC, t.ingester)
 	t.Server.HTTP.Path("/flush").Handler(http.HandlerFunc(t.ingester.FlushHandler))
+	t.Server.HTTP.Path("/flush/ops").Methods(http.MethodGet).Handler(http.HandlerFunc(t.ingester.FlushOpsHandler))
+	t.Server.HTTP.Path("/flush/ops/{tenant}/{block}").Methods(http.MethodDelete).Handler(http.HandlerFunc(t.ingester.DropFlushOpsHandler))
 	t.Server.HTTP.Path("/shutdown").Handler(http.HandlerFunc(t.ingester.ShutdownHandler))
 	return t.ingester, nil
 }
@@ -167,6 +179,9 @@ func (t *App) initQuerier() (services.Service, error) {
 	tracesHandler := middleware.Wrap(http.HandlerFunc(t.querier.TraceByIDHandler))
 	t.Server.HTTP.Handle(path.Join("/querier", addHTTPAPIPrefix(&t.cfg, apiPathTraces)), tracesHandler)
 
+	tracesBatchHandler := middleware.Wrap(http.HandlerFunc(t.querier.TracesByIDHandler))
+	t.Server.HTTP.Path(path.Join("/querier", addHTTPAPIPrefix(&t.cfg, apiPathTracesBatch))).Methods(http.MethodPost).Handler(tracesBatchHandler)
+
 	if t.cfg.SearchEnabled {
 		searchHandler := middleware.Wrap(http.HandlerFunc(t.querier.SearchHandler))
 		t.Server.HTTP.Handle(path.Join("/querier", addHTTPAPIPrefix(&t.cfg, apiPathSearch)), searchHandler)
@@ -176,6 +191,10 @@ func (t *App) initQuerier() (services.Service, error) {
 
 		searchTagValuesHandler := middleware.Wrap(http.HandlerFunc(t.querier.SearchTagValuesHandler))
 		t.Server.HTTP.Handle(path.Join("/querier", addHTTPAPIPrefix(&t.cfg, apiPathSearchTagValues)), searchTagValuesHandler)
+
+		// websockets can't be proxied by the query frontend, so tails are served by the querier directly
+		tailHandler := middleware.Wrap(http.HandlerFunc(t.querier.TailHandler))
+		t.Server.HTTP.Handle(addHTTPAPIPrefix(&t.cfg, apiPathTail), tailHandler)
 	}
 
 	return t.querier, t.querier.CreateAndRegisterWorker(t.Server.HTTPServer.Handler)
@@ -192,7 +211,7 @@ func (t *App) initQueryFrontend() (services.Service, error) {
 	}
 	t.frontend = v1
 
-	tripperware, err := frontend.NewTripperware(t.cfg.Frontend, t.cfg.HTTPAPIPrefix, log.Logger, prometheus.DefaultRegisterer)
+	tripperware, err := frontend.NewTripperware(t.cfg.Frontend, t.cfg.HTTPAPIPrefix, t.overrides, log.Logger, prometheus.DefaultRegisterer)
 	if err != nil {
 		return nil, err
 	}
@@ -207,8 +226,12 @@ func (t *App) initQueryFrontend() (services.Service, error) {
 	// register grpc server for queriers to connect to
 	cortex_frontend_v1pb.RegisterFrontendServer(t.Server.GRPC, t.frontend)
 
+	// grpc streaming query api. requests are authenticated by the grpc server interceptors
+	tempopb.RegisterStreamingQuerierServer(t.Server.GRPC, frontend.NewStreamingQuerier(t.cfg.Frontend, t.cfg.HTTPAPIPrefix, roundTripper, log.Logger))
+
 	// http query endpoint
 	t.Server.HTTP.Handle(addHTTPAPIPrefix(&t.cfg, apiPathTraces), frontendHandler)
+	t.Server.HTTP.Path(addHTTPAPIPrefix(&t.cfg, apiPathTracesBatch)).Methods(http.MethodPost).Handler(frontendHandler)
 
 	// http search endpoints
 	if t.cfg.SearchEnabled {
@@ -296,11 +319,11 @@ func (t *App) setupModuleManager() error {
 		// Store:        nil,
 		Overrides:     {Server},
 		MemberlistKV:  {Server},
-		QueryFrontend: {Server},
+		QueryFrontend: {Server, Overrides},
 		Ring:          {Server, MemberlistKV},
 		Distributor:   {Ring, Server, Overrides},
-		Ingester:      {Store, Server, Overrides, MemberlistKV},
-		Querier:       {Store, Ring},
+		Ingester:      {Store, Server, Overrides, MemberlistKV, Ring},
+		Querier:       {Store, Ring, Overrides},
 		Compactor:     {Store, Server, Overrides, MemberlistKV},
 		All:           {Compactor, QueryFrontend, Querier, Ingester, Distributor},
 	}
@@ -166,6 +166,21 @@ func (c *Compactor) BlockRetentionForTenant(tenantID string) time.Duration {
 	return c.overrides.BlockRetention(tenantID)
 }
 
+// BlockEncodingForTenant implements BlockOverrides
+func (c *Compactor) BlockEncodingForTenant(tenantID string) string {
+	return c.overrides.BlockSettings(tenantID).Encoding
+}
+
+// BlockBloomFPForTenant implements BlockOverrides
+func (c *Compactor) BlockBloomFPForTenant(tenantID string) float64 {
+	return c.overrides.BlockSettings(tenantID).BloomFP
+}
+
+// BlockIndexPageSizeBytesForTenant implements BlockOverrides
+func (c *Compactor) BlockIndexPageSizeBytesForTenant(tenantID string) int {
+	return c.overrides.BlockSettings(tenantID).IndexPageSizeBytes
+}
+
 func (c *Compactor) waitRingActive(ctx context.Context) error {
 	for {
 		// Check if the ingester is ACTIVE in the ring and our ring client
@@ -8,6 +8,9 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring"
 	ring_client "github.com/cortexproject/cortex/pkg/ring/client"
 	"github.com/grafana/dskit/flagext"
+
+	"github.com/grafana/tempo/modules/distributor/forwarder"
+	"github.com/grafana/tempo/modules/distributor/sampling"
 )
 
 var defaultReceivers = map[string]interface{}{
@@ -39,6 +42,12 @@ type Config struct {
 	//  note that setting these two config values reduces tolerance to failures on rollout b/c there is always one guaranteed to be failing replica
 	ExtendWrites bool `yaml:"extend_writes"`
 
+	// named destinations that tenants can forward a copy of their spans to. see the forwarders override
+	Forwarders forwarder.ConfigList `yaml:"forwarders"`
+
+	// Jaeger remote sampling strategies endpoint
+	Sampling sampling.Config `yaml:"sampling"`
+
 	// For testing.
 	factory func(addr string) (ring_client.PoolClient, error) `yaml:"-"`
 }
@@ -52,5 +61,7 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 	cfg.OverrideRingKey = ring.DistributorRingKey
 	cfg.ExtendWrites = true
 
+	cfg.Sampling.RegisterFlagsAndApplyDefaults(prefix+".sampling", f)
+
 	f.BoolVar(&cfg.LogReceivedTraces, prefix+".log-received-traces", false, "Enable to log every received trace id to help debug ingestion.")
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cortexproject/cortex/pkg/ring"
@@ -24,9 +25,12 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/grafana/tempo/modules/distributor/forwarder"
 	"github.com/grafana/tempo/modules/distributor/receiver"
+	"github.com/grafana/tempo/modules/distributor/sampling"
 	ingester_client "github.com/grafana/tempo/modules/ingester/client"
 	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/model"
 	"github.com/grafana/tempo/pkg/tempopb"
 	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
 	"github.com/grafana/tempo/pkg/util"
@@ -36,12 +40,20 @@ import (
 const (
 	discardReasonLabel = "reason"
 
+	serviceNameAttribute = "service.name"
+
 	// reasonRateLimited indicates that the tenants spans/second exceeded their limits
 	reasonRateLimited = "rate_limited"
+	// reasonRateLimitedSpans indicates that the tenants spans/second exceeded their span limits
+	reasonRateLimitedSpans = "rate_limited_spans"
+	// reasonServiceRateLimited indicates that a single service exceeded its spans/second limit within the tenant
+	reasonServiceRateLimited = "service_rate_limited"
 	// reasonTraceTooLarge indicates that a single trace has too many spans
 	reasonTraceTooLarge = "trace_too_large"
 	// reasonLiveTracesExceeded indicates that tempo is already tracking too many live traces in the ingesters for this user
 	reasonLiveTracesExceeded = "live_traces_exceeded"
+	// reasonInvalidTraceID indicates that the spans had a trace id that was not 128 bit
+	reasonInvalidTraceID = "invalid_trace_id"
 	// reasonInternalError indicates an unexpected error occurred processing these spans. analogous to a 500
 	reasonInternalError = "internal_error"
 )
@@ -96,8 +108,19 @@ type Distributor struct {
 	DistributorRing *ring.Ring
 	searchEnabled   bool
 
-	// Per-user rate limiter.
+	// Serves Jaeger remote sampling strategies. nil if disabled.
+	SamplingStore *sampling.Store
+
+	// Per-user rate limiters.
+	overrides            *overrides.Overrides
 	ingestionRateLimiter *limiter.RateLimiter
+	spanRateLimiter      *limiter.RateLimiter
+	// Per-service rate limiter keyed by serviceKey()
+	serviceSpanRateLimiter *limiter.RateLimiter
+	serviceTracker         *serviceTracker
+
+	// Sends a copy of accepted spans to the forwarders configured per tenant.
+	forwarders *forwarder.Manager
 
 	// Manager for subservices
 	subservices        *services.Manager
@@ -115,8 +138,8 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 
 	subservices := []services.Service(nil)
 
-	// Create the configured ingestion rate limit strategy (local or global).
-	var ingestionRateStrategy limiter.RateLimiterStrategy
+	// Create the configured ingestion rate limit strategies (local or global).
+	var ingestionRateStrategy, spanRateStrategy, serviceSpanRateStrategy limiter.RateLimiterStrategy
 	var distributorRing *ring.Ring
 
 	if o.IngestionRateStrategy() == overrides.GlobalIngestionRateStrategy {
@@ -127,6 +150,8 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 		}
 		subservices = append(subservices, lifecycler)
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(o, lifecycler)
+		spanRateStrategy = newGlobalStrategy(spansRateLimits(o), lifecycler)
+		serviceSpanRateStrategy = newGlobalStrategy(serviceSpansRateLimits(o), lifecycler)
 
 		ring, err := ring.New(lifecyclerCfg.RingConfig, "distributor", cfg.OverrideRingKey, prometheus.DefaultRegisterer)
 		if err != nil {
@@ -136,6 +161,8 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 		subservices = append(subservices, distributorRing)
 	} else {
 		ingestionRateStrategy = newLocalIngestionRateStrategy(o)
+		spanRateStrategy = newLocalStrategy(spansRateLimits(o))
+		serviceSpanRateStrategy = newLocalStrategy(serviceSpansRateLimits(o))
 	}
 
 	pool := ring_client.NewPool("distributor_pool",
@@ -147,14 +174,35 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 
 	subservices = append(subservices, pool)
 
+	forwarders, err := forwarder.NewManager(cfg.Forwarders, o, cortex_util.Logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to initialize forwarders")
+	}
+	subservices = append(subservices, forwarders)
+
+	var samplingStore *sampling.Store
+	if cfg.Sampling.Enabled {
+		samplingStore, err = sampling.NewStore(cfg.Sampling, o)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to initialize sampling strategy store")
+		}
+		subservices = append(subservices, samplingStore)
+	}
+
 	d := &Distributor{
-		cfg:                  cfg,
-		clientCfg:            clientCfg,
-		ingestersRing:        ingestersRing,
-		pool:                 pool,
-		DistributorRing:      distributorRing,
-		ingestionRateLimiter: limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second),
-		searchEnabled:        searchEnabled,
+		cfg:                    cfg,
+		clientCfg:              clientCfg,
+		ingestersRing:          ingestersRing,
+		pool:                   pool,
+		DistributorRing:        distributorRing,
+		SamplingStore:          samplingStore,
+		overrides:              o,
+		ingestionRateLimiter:   limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second),
+		spanRateLimiter:        limiter.NewRateLimiter(spanRateStrategy, 10*time.Second),
+		serviceSpanRateLimiter: limiter.NewRateLimiter(serviceSpanRateStrategy, 10*time.Second),
+		serviceTracker:         newServiceTracker(),
+		forwarders:             forwarders,
+		searchEnabled:          searchEnabled,
 	}
 
 	cfgReceivers := cfg.Receivers
@@ -231,9 +279,36 @@ func (d *Distributor) Push(ctx context.Context, req *tempopb.PushRequest) (*temp
 		return &tempopb.PushResponse{}, nil
 	}
 	metricSpansIngested.WithLabelValues(userID).Add(float64(spanCount))
+	if d.SamplingStore != nil {
+		d.SamplingStore.Observe(userID, req.Batch)
+	}
 
 	// check limits
 	now := time.Now()
+	// span limits are checked first so spans rejected by them don't consume the byte limit. services beyond the
+	// tracked ones are only subject to the tenant limits
+	service := serviceName(req.Batch)
+	if d.overrides.ServiceIngestionRateLimitSpans(userID, service) > 0 &&
+		d.serviceTracker.track(userID, service, d.overrides.MaxServicesPerTenant(userID)) &&
+		!d.serviceSpanRateLimiter.AllowN(now, serviceKey(userID, service), spanCount) {
+		metricDiscardedSpans.WithLabelValues(reasonServiceRateLimited, userID).Add(float64(spanCount))
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"%s ingestion rate limit (%d spans) for service %s exceeded while adding %d spans",
+			overrides.ErrorPrefixRateLimited,
+			int(d.serviceSpanRateLimiter.Limit(now, serviceKey(userID, service))),
+			service,
+			spanCount)
+	}
+
+	if d.overrides.IngestionRateLimitSpans(userID) > 0 && !d.spanRateLimiter.AllowN(now, userID, spanCount) {
+		metricDiscardedSpans.WithLabelValues(reasonRateLimitedSpans, userID).Add(float64(spanCount))
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"%s ingestion rate limit (%d spans) exceeded while adding %d spans",
+			overrides.ErrorPrefixRateLimited,
+			int(d.spanRateLimiter.Limit(now, userID)),
+			spanCount)
+	}
+
 	if !d.ingestionRateLimiter.AllowN(now, userID, req.Size()) {
 		metricDiscardedSpans.WithLabelValues(reasonRateLimited, userID).Add(float64(spanCount))
 		return nil, status.Errorf(codes.ResourceExhausted,
@@ -243,37 +318,88 @@ func (d *Distributor) Push(ctx context.Context, req *tempopb.PushRequest) (*temp
 			req.Size())
 	}
 
-	keys, traces, ids, err := requestsByTraceID(req, userID, spanCount)
-	if err != nil {
-		metricDiscardedSpans.WithLabelValues(reasonInternalError, userID).Add(float64(spanCount))
-		return nil, err
+	rejectedSpans := map[string]int{}
+	acceptedSpans := spanCount
+
+	for reason, n := range validateSpans(req.Batch, d.overrides.SpanValidation(userID), userID, now) {
+		rejectedSpans[reason] = n
+		acceptedSpans -= n
 	}
 
-	//var
-	var searchData [][]byte
-	if d.searchEnabled {
-		searchData = extractSearchDataAll(traces, ids)
+	keys, traces, ids, invalidSpans := requestsByTraceID(req, userID, spanCount)
+	if invalidSpans > 0 {
+		rejectedSpans[reasonInvalidTraceID] = invalidSpans
+		acceptedSpans -= invalidSpans
 	}
 
-	err = d.sendToIngestersViaBytes(ctx, userID, traces, searchData, keys, ids)
-	if err != nil {
-		recordDiscaredSpans(err, userID, spanCount)
+	var rejectedTraces []tempopb.PushErrorReason
+	if len(traces) > 0 {
+		var searchData [][]byte
+		if d.searchEnabled {
+			searchData = extractSearchDataAll(traces, ids)
+		}
+
+		rejectedTraces, err = d.sendToIngestersViaBytes(ctx, userID, traces, searchData, keys, ids)
+		if err != nil {
+			recordDiscaredSpans(err, userID, spanCount)
+			return nil, err
+		}
+
+		for j, reason := range rejectedTraces {
+			if reason == tempopb.PushErrorReason_NO_ERROR {
+				continue
+			}
+			n := countSpans(traces[j])
+			rejectedSpans[discardReason(reason)] += n
+			acceptedSpans -= n
+		}
 	}
 
-	return nil, err // PushRequest is ignored, so no reason to create one
+	for reason, n := range rejectedSpans {
+		metricDiscardedSpans.WithLabelValues(reason, userID).Add(float64(n))
+	}
+
+	partialSuccess := tempopb.NewPushPartialSuccess(acceptedSpans, rejectedSpans)
+	if partialSuccess == nil {
+		// forwarding is asynchronous and never fails or slows down the push
+		d.forwarders.ForwardTraces(userID, &tempopb.Trace{Batches: []*v1.ResourceSpans{req.Batch}}, spanCount)
+		return nil, nil // PushRequest is ignored, so no reason to create one
+	}
+
+	if acceptedSpans == 0 {
+		return nil, rejectedError(partialSuccess)
+	}
+
+	accepted := &tempopb.Trace{}
+	for j, trace := range traces {
+		if rejectedTraces[j] == tempopb.PushErrorReason_NO_ERROR {
+			accepted.Batches = append(accepted.Batches, trace.Batches...)
+		}
+	}
+	d.forwarders.ForwardTraces(userID, accepted, acceptedSpans)
+
+	return &tempopb.PushResponse{PartialSuccess: partialSuccess}, nil
 }
 
-func (d *Distributor) sendToIngestersViaBytes(ctx context.Context, userID string, traces []*tempopb.Trace, searchData [][]byte, keys []uint32, ids [][]byte) error {
-	// Marshal to bytes once
+// sendToIngestersViaBytes returns the reason each trace was rejected by a quorum of its ingesters,
+// or NO_ERROR if it was accepted.
+func (d *Distributor) sendToIngestersViaBytes(ctx context.Context, userID string, traces []*tempopb.Trace, searchData [][]byte, keys []uint32, ids [][]byte) ([]tempopb.PushErrorReason, error) {
+	// Marshal to bytes once. The root span flag saves the ingesters from unmarshalling to look for it.
 	marshalledTraces := make([][]byte, len(traces))
+	rootSpans := make([]bool, len(traces))
 	for i, t := range traces {
 		b, err := t.Marshal()
 		if err != nil {
-			return errors.Wrap(err, "failed to marshal PushRequest")
+			return nil, errors.Wrap(err, "failed to marshal PushRequest")
 		}
 		marshalledTraces[i] = b
+		rootSpans[i] = model.ContainsRootSpan(t)
 	}
 
+	var rejectionsMtx sync.Mutex
+	rejections := make([]int, len(traces))
+	reasons := make([]tempopb.PushErrorReason, len(traces))
+
 	op := ring.WriteNoExtend
 	if d.cfg.ExtendWrites {
 		op = ring.Write
@@ -288,11 +414,13 @@ func (d *Distributor) sendToIngestersViaBytes(ctx context.Context, userID string
 			Traces:     make([]tempopb.PreallocBytes, len(indexes)),
 			Ids:        make([]tempopb.PreallocBytes, len(indexes)),
 			SearchData: make([]tempopb.PreallocBytes, len(indexes)),
+			RootSpans:  make([]bool, len(indexes)),
 		}
 
 		for i, j := range indexes {
 			req.Traces[i].Slice = marshalledTraces[j][0:]
 			req.Ids[i].Slice = ids[j]
+			req.RootSpans[i] = rootSpans[j]
 
 			// Search data optional
 			if len(searchData) > j {
@@ -305,15 +433,44 @@ func (d *Distributor) sendToIngestersViaBytes(ctx context.Context, userID string
 			return err
 		}
 
-		_, err = c.(tempopb.PusherClient).PushBytes(localCtx, &req)
+		resp, err := c.(tempopb.PusherClient).PushBytes(localCtx, &req)
 		metricIngesterAppends.WithLabelValues(ingester.Addr).Inc()
 		if err != nil {
 			metricIngesterAppendFailures.WithLabelValues(ingester.Addr).Inc()
+			return err
+		}
+
+		if resp != nil && len(resp.TraceErrors) > 0 {
+			rejectionsMtx.Lock()
+			for _, traceErr := range resp.TraceErrors {
+				if int(traceErr.Index) >= len(indexes) {
+					continue
+				}
+				j := indexes[traceErr.Index]
+				rejections[j]++
+				reasons[j] = traceErr.Reason
+			}
+			rejectionsMtx.Unlock()
 		}
-		return err
+		return nil
 	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	quorum := d.ingestersRing.ReplicationFactor()/2 + 1
+
+	rejectionsMtx.Lock()
+	defer rejectionsMtx.Unlock()
+
+	rejected := make([]tempopb.PushErrorReason, len(traces))
+	for j := range rejections {
+		if rejections[j] >= quorum {
+			rejected[j] = reasons[j]
+		}
+	}
 
-	return err
+	return rejected, nil
 }
 
 // PushBytes Not used by the distributor
@@ -328,7 +485,9 @@ func (*Distributor) Check(_ context.Context, _ *grpc_health_v1.HealthCheckReques
 
 // requestsByTraceID takes an incoming tempodb.PushRequest and creates a set of keys for the hash ring
 // and traces to pass onto the ingesters.
-func requestsByTraceID(req *tempopb.PushRequest, userID string, spanCount int) ([]uint32, []*tempopb.Trace, [][]byte, error) {
+// requestsByTraceID splits the request into one trace per trace id. Spans with invalid trace ids are dropped
+// and their count is returned.
+func requestsByTraceID(req *tempopb.PushRequest, userID string, spanCount int) ([]uint32, []*tempopb.Trace, [][]byte, int) {
 	type traceAndID struct {
 		id    []byte
 		trace *tempopb.Trace
@@ -337,12 +496,14 @@ func requestsByTraceID(req *tempopb.PushRequest, userID string, spanCount int) (
 	const tracesPerBatch = 20 // p50 of internal env
 	tracesByID := make(map[uint32]*traceAndID, tracesPerBatch)
 	spansByILS := make(map[uint32]*v1.InstrumentationLibrarySpans)
+	invalidSpans := 0
 
 	for _, ils := range req.Batch.InstrumentationLibrarySpans {
 		for _, span := range ils.Spans {
 			traceID := span.TraceId
 			if !validation.ValidTraceID(traceID) {
-				return nil, nil, nil, status.Errorf(codes.InvalidArgument, "trace ids must be 128 bit")
+				invalidSpans++
+				continue
 			}
 
 			traceKey := util.TokenFor(userID, traceID)
@@ -398,7 +559,46 @@ func requestsByTraceID(req *tempopb.PushRequest, userID string, spanCount int) (
 		ids = append(ids, r.id)
 	}
 
-	return keys, traces, ids, nil
+	return keys, traces, ids, invalidSpans
+}
+
+// rejectedError is returned when every span of a push was rejected. The partial success is attached to the status details.
+func rejectedError(ps *tempopb.PushPartialSuccess) error {
+	code, prefix := codes.InvalidArgument, ""
+	for _, r := range ps.RejectedByReason {
+		switch r.Reason {
+		case reasonLiveTracesExceeded:
+			code, prefix = codes.FailedPrecondition, overrides.ErrorPrefixLiveTracesExceeded
+		case reasonTraceTooLarge:
+			if prefix == "" {
+				code, prefix = codes.FailedPrecondition, overrides.ErrorPrefixTraceTooLarge
+			}
+		}
+	}
+	return tempopb.PushPartialSuccessError(code, prefix, ps)
+}
+
+func discardReason(reason tempopb.PushErrorReason) string {
+	switch reason {
+	case tempopb.PushErrorReason_MAX_LIVE_TRACES:
+		return reasonLiveTracesExceeded
+	case tempopb.PushErrorReason_TRACE_TOO_LARGE:
+		return reasonTraceTooLarge
+	case tempopb.PushErrorReason_INVALID_TRACE_ID:
+		return reasonInvalidTraceID
+	default:
+		return reasonInternalError
+	}
+}
+
+func countSpans(trace *tempopb.Trace) int {
+	count := 0
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			count += len(ils.Spans)
+		}
+	}
+	return count
 }
 
 func recordDiscaredSpans(err error, userID string, spanCount int) {
@@ -417,6 +617,19 @@ func recordDiscaredSpans(err error, userID string, spanCount int) {
 	}
 }
 
+// serviceName returns the service.name resource attribute of the batch or "" if it is not set
+func serviceName(batch *v1.ResourceSpans) string {
+	if batch.Resource == nil {
+		return ""
+	}
+	for _, attr := range batch.Resource.Attributes {
+		if attr.Key == serviceNameAttribute {
+			return attr.Value.GetStringValue()
+		}
+	}
+	return ""
+}
+
 func logTraces(batch *v1.ResourceSpans) {
 	for _, ils := range batch.InstrumentationLibrarySpans {
 		for _, s := range ils.Spans {
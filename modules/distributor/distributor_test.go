@@ -1,10 +1,12 @@
 package distributor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/rand"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,6 +21,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/logging"
@@ -47,12 +50,12 @@ func TestRequestsByTraceID(t *testing.T) {
 	traceIDB := []byte{0x0B, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
 
 	tests := []struct {
-		name           string
-		request        *tempopb.PushRequest
-		expectedKeys   []uint32
-		expectedTraces []*tempopb.Trace
-		expectedIDs    [][]byte
-		expectedErr    error
+		name            string
+		request         *tempopb.PushRequest
+		expectedKeys    []uint32
+		expectedTraces  []*tempopb.Trace
+		expectedIDs     [][]byte
+		expectedInvalid int
 	}{
 		{
 			name: "empty",
@@ -78,7 +81,46 @@ func TestRequestsByTraceID(t *testing.T) {
 					},
 				},
 			},
-			expectedErr: status.Errorf(codes.InvalidArgument, "trace ids must be 128 bit"),
+			expectedKeys:    []uint32{},
+			expectedTraces:  []*tempopb.Trace{},
+			expectedIDs:     [][]byte{},
+			expectedInvalid: 1,
+		},
+		{
+			name: "bad trace id mixed with good",
+			request: &tempopb.PushRequest{
+				Batch: &v1.ResourceSpans{
+					InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+						{
+							Spans: []*v1.Span{
+								{
+									TraceId: []byte{0x01},
+								},
+								{
+									TraceId: traceIDA,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedKeys: []uint32{util.TokenFor(util.FakeTenantID, traceIDA)},
+			expectedTraces: []*tempopb.Trace{
+				{
+					Batches: []*v1.ResourceSpans{
+						{
+							InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+								{
+									Spans: []*v1.Span{
+										{
+											TraceId: traceIDA,
+										}}}}}},
+				},
+			},
+			expectedIDs: [][]byte{
+				traceIDA,
+			},
+			expectedInvalid: 1,
 		},
 		{
 			name: "one span",
@@ -306,7 +348,7 @@ func TestRequestsByTraceID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			keys, reqs, ids, err := requestsByTraceID(tt.request, util.FakeTenantID, 1)
+			keys, reqs, ids, invalid := requestsByTraceID(tt.request, util.FakeTenantID, 1)
 			require.Equal(t, len(keys), len(reqs))
 
 			for i, expectedKey := range tt.expectedKeys {
@@ -326,7 +368,8 @@ func TestRequestsByTraceID(t *testing.T) {
 				assert.Equal(t, tt.expectedIDs[i], ids[foundIndex])
 			}
 
-			assert.Equal(t, tt.expectedErr, err)
+			assert.Len(t, keys, len(tt.expectedKeys))
+			assert.Equal(t, tt.expectedInvalid, invalid)
 		})
 	}
 }
@@ -352,12 +395,12 @@ func BenchmarkTestsByRequestID(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		for _, blerg := range ils {
-			_, _, _, err := requestsByTraceID(&tempopb.PushRequest{
+			_, _, _, invalid := requestsByTraceID(&tempopb.PushRequest{
 				Batch: &v1.ResourceSpans{
 					InstrumentationLibrarySpans: blerg,
 				},
 			}, "test", spansPer*len(traces))
-			require.NoError(b, err)
+			require.Zero(b, invalid)
 		}
 	}
 }
@@ -393,7 +436,204 @@ func TestDistributor(t *testing.T) {
 	}
 }
 
+func TestDistributorSpanRateLimits(t *testing.T) {
+	limits := &overrides.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRateLimitSpans = 10
+	limits.ServiceIngestionLimits = map[string]overrides.ServiceIngestionLimits{
+		"noisy": {IngestionRateLimitSpans: 5},
+	}
+	d := prepare(t, limits, nil)
+
+	withService := func(req *tempopb.PushRequest, service string) *tempopb.PushRequest {
+		req.Batch.Resource = &v1_resource.Resource{
+			Attributes: []*v1_common.KeyValue{
+				{Key: "service.name", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: service}}},
+			},
+		}
+		return req
+	}
+
+	tenant := "span-limits"
+	ctx := user.InjectOrgID(context.Background(), tenant)
+
+	_, err := d.Push(ctx, withService(test.MakeRequest(5, nil), "noisy"))
+	require.NoError(t, err)
+
+	// the noisy service is over its own limit. this does not consume the tenant limit
+	_, err = d.Push(ctx, withService(test.MakeRequest(5, nil), "noisy"))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = d.Push(ctx, withService(test.MakeRequest(5, nil), "other"))
+	require.NoError(t, err)
+
+	// the tenant is over its limit
+	_, err = d.Push(ctx, withService(test.MakeRequest(5, nil), "other"))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	assert.Equal(t, 5.0, testutil.ToFloat64(metricDiscardedSpans.WithLabelValues(reasonServiceRateLimited, tenant)))
+	assert.Equal(t, 5.0, testutil.ToFloat64(metricDiscardedSpans.WithLabelValues(reasonRateLimitedSpans, tenant)))
+}
+
+func TestDistributorMaxServicesPerTenant(t *testing.T) {
+	limits := &overrides.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRateLimitSpansPerService = 5
+	limits.MaxServicesPerTenant = 1
+	d := prepare(t, limits, nil)
+
+	withService := func(req *tempopb.PushRequest, service string) *tempopb.PushRequest {
+		req.Batch.Resource = &v1_resource.Resource{
+			Attributes: []*v1_common.KeyValue{
+				{Key: "service.name", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: service}}},
+			},
+		}
+		return req
+	}
+
+	tenant := "max-services"
+	ctx := user.InjectOrgID(context.Background(), tenant)
+
+	_, err := d.Push(ctx, withService(test.MakeRequest(5, nil), "first"))
+	require.NoError(t, err)
+	_, err = d.Push(ctx, withService(test.MakeRequest(5, nil), "first"))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the second service is beyond the cap and only subject to the tenant limits, which are unset
+	_, err = d.Push(ctx, withService(test.MakeRequest(5, nil), "second"))
+	require.NoError(t, err)
+	_, err = d.Push(ctx, withService(test.MakeRequest(5, nil), "second"))
+	require.NoError(t, err)
+}
+
+func TestDistributorSpanLimitsCheckedBeforeBytes(t *testing.T) {
+	req := test.MakeRequest(5, nil)
+
+	// the byte limit allows a single request and the span limit rejects the second one
+	limits := &overrides.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRateLimitSpans = 5
+	limits.IngestionRateLimitBytes = 1
+	limits.IngestionBurstSizeBytes = req.Size() * 3 / 2
+	d := prepare(t, limits, nil)
+
+	tenant := "span-before-bytes"
+	ctx := user.InjectOrgID(context.Background(), tenant)
+
+	_, err := d.Push(ctx, req)
+	require.NoError(t, err)
+	_, err = d.Push(ctx, test.MakeRequest(5, nil))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	assert.Equal(t, 5.0, testutil.ToFloat64(metricDiscardedSpans.WithLabelValues(reasonRateLimitedSpans, tenant)))
+	assert.Zero(t, testutil.ToFloat64(metricDiscardedSpans.WithLabelValues(reasonRateLimited, tenant)))
+}
+
+func TestDistributorSendsRootSpans(t *testing.T) {
+	traceIDRoot := []byte{0x0A, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+	traceIDChild := []byte{0x0B, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+
+	var mtx sync.Mutex
+	rootSpans := map[string]bool{}
+	ingester := &mockIngester{
+		traceErrors: func(in *tempopb.PushBytesRequest) []tempopb.PushTraceError {
+			mtx.Lock()
+			defer mtx.Unlock()
+			require.Len(t, in.RootSpans, len(in.Traces))
+			for i, id := range in.Ids {
+				rootSpans[string(id.Slice)] = in.RootSpans[i]
+			}
+			return nil
+		},
+	}
+
+	limits := &overrides.Limits{}
+	flagext.DefaultValues(limits)
+	d := prepareWithIngester(t, limits, nil, ingester)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err := d.Push(ctx, &tempopb.PushRequest{
+		Batch: &v1.ResourceSpans{
+			InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{
+				Spans: []*v1.Span{
+					{TraceId: traceIDRoot, SpanId: []byte{0x01}},
+					{TraceId: traceIDRoot, SpanId: []byte{0x02}, ParentSpanId: []byte{0x01}},
+					{TraceId: traceIDChild, SpanId: []byte{0x03}, ParentSpanId: []byte{0x04}},
+				},
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	assert.Equal(t, map[string]bool{string(traceIDRoot): true, string(traceIDChild): false}, rootSpans)
+}
+
+func TestDistributorPartialSuccess(t *testing.T) {
+	traceIDTooLarge := []byte{0xFF, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+	traceIDValid := []byte{0x0A, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+
+	// every ingester rejects the first trace as too large
+	ingester := &mockIngester{
+		traceErrors: func(in *tempopb.PushBytesRequest) []tempopb.PushTraceError {
+			var errs []tempopb.PushTraceError
+			for i, id := range in.Ids {
+				if bytes.Equal(id.Slice, traceIDTooLarge) {
+					errs = append(errs, tempopb.PushTraceError{Index: uint32(i), Reason: tempopb.PushErrorReason_TRACE_TOO_LARGE})
+				}
+			}
+			return errs
+		},
+	}
+
+	limits := &overrides.Limits{}
+	flagext.DefaultValues(limits)
+	d := prepareWithIngester(t, limits, nil, ingester)
+
+	makeRequest := func(ids ...[]byte) *tempopb.PushRequest {
+		spans := make([]*v1.Span, 0, len(ids))
+		for _, id := range ids {
+			spans = append(spans, &v1.Span{TraceId: id, SpanId: []byte{0x01}})
+		}
+		return &tempopb.PushRequest{
+			Batch: &v1.ResourceSpans{
+				InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: spans}},
+			},
+		}
+	}
+
+	// one trace too large, one invalid trace id and two accepted spans
+	resp, err := d.Push(ctx, makeRequest(traceIDTooLarge, []byte{0x01}, traceIDValid, traceIDValid))
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, &tempopb.PushPartialSuccess{
+		RejectedSpans: 2,
+		AcceptedSpans: 2,
+		ErrorMessage:  "2 of 4 spans rejected (invalid_trace_id: 1, trace_too_large: 1)",
+		RejectedByReason: []tempopb.RejectedSpans{
+			{Reason: reasonInvalidTraceID, Spans: 1},
+			{Reason: reasonTraceTooLarge, Spans: 1},
+		},
+	}, resp.PartialSuccess)
+
+	// everything rejected returns an error with the partial success in its details
+	_, err = d.Push(ctx, makeRequest(traceIDTooLarge, traceIDTooLarge))
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, err.Error(), overrides.ErrorPrefixTraceTooLarge)
+
+	ps, ok := tempopb.PushPartialSuccessFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int64(2), ps.RejectedSpans)
+	assert.Equal(t, int64(0), ps.AcceptedSpans)
+}
+
 func prepare(t *testing.T, limits *overrides.Limits, kvStore kv.Client) *Distributor {
+	return prepareWithIngester(t, limits, kvStore, &mockIngester{})
+}
+
+func prepareWithIngester(t *testing.T, limits *overrides.Limits, kvStore kv.Client, ingester *mockIngester) *Distributor {
 	var (
 		distributorConfig Config
 		clientConfig      ingester_client.Config
@@ -406,7 +646,7 @@ func prepare(t *testing.T, limits *overrides.Limits, kvStore kv.Client) *Distrib
 	// Mock the ingesters ring
 	ingesters := map[string]*mockIngester{}
 	for i := 0; i < numIngesters; i++ {
-		ingesters[fmt.Sprintf("ingester%d", i)] = &mockIngester{}
+		ingesters[fmt.Sprintf("ingester%d", i)] = ingester
 	}
 
 	ingestersRing := &mockRing{
@@ -436,6 +676,9 @@ func prepare(t *testing.T, limits *overrides.Limits, kvStore kv.Client) *Distrib
 
 type mockIngester struct {
 	grpc_health_v1.HealthClient
+
+	// optional. returns the traces to reject
+	traceErrors func(in *tempopb.PushBytesRequest) []tempopb.PushTraceError
 }
 
 var _ tempopb.PusherClient = (*mockIngester)(nil)
@@ -445,7 +688,10 @@ func (i *mockIngester) Push(ctx context.Context, in *tempopb.PushRequest, opts .
 }
 
 func (i *mockIngester) PushBytes(ctx context.Context, in *tempopb.PushBytesRequest, opts ...grpc.CallOption) (*tempopb.PushResponse, error) {
-	return nil, nil
+	if i.traceErrors == nil {
+		return nil, nil
+	}
+	return &tempopb.PushResponse{TraceErrors: i.traceErrors(in)}, nil
 }
 
 func (i *mockIngester) Close() error {
@@ -0,0 +1,119 @@
+package forwarder
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/util/grpcclient"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+)
+
+const (
+	BackendOTLPGRPC = "otlpgrpc"
+	BackendKafka    = "kafka"
+
+	defaultQueueSize   = 100
+	defaultWorkers     = 2
+	defaultSendTimeout = 5 * time.Second
+)
+
+// Config for a single named forwarder. Tenants opt into forwarders by name through the
+// `forwarders` override.
+type Config struct {
+	Name    string `yaml:"name"`
+	Backend string `yaml:"backend"`
+
+	OTLPGRPC OTLPGRPCConfig `yaml:"otlpgrpc"`
+	Kafka    KafkaConfig    `yaml:"kafka"`
+
+	// QueueSize is the number of batches buffered before new batches are dropped.
+	QueueSize   int            `yaml:"queue_size"`
+	Workers     int            `yaml:"workers"`
+	SendTimeout time.Duration  `yaml:"send_timeout"`
+	Retry       backoff.Config `yaml:"retry"`
+}
+
+// OTLPGRPCConfig configures forwarding to an OTLP gRPC endpoint.
+type OTLPGRPCConfig struct {
+	Endpoint         string            `yaml:"endpoint"`
+	Headers          map[string]string `yaml:"headers"`
+	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config"`
+}
+
+// KafkaConfig configures forwarding to a Kafka topic. Batches are written as OTLP protobuf.
+type KafkaConfig struct {
+	Brokers         []string `yaml:"brokers"`
+	Topic           string   `yaml:"topic"`
+	ClientID        string   `yaml:"client_id"`
+	ProtocolVersion string   `yaml:"protocol_version"`
+}
+
+// UnmarshalYAML applies defaults before unmarshalling so that forwarders declared in a list
+// pick up sane values for any field left out.
+func (cfg *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	flagext.DefaultValues(&cfg.OTLPGRPC.GRPCClientConfig)
+	cfg.Kafka.ClientID = "tempo"
+	cfg.Kafka.ProtocolVersion = "2.0.0"
+	cfg.QueueSize = defaultQueueSize
+	cfg.Workers = defaultWorkers
+	cfg.SendTimeout = defaultSendTimeout
+	cfg.Retry = backoff.Config{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		MaxRetries: 5,
+	}
+
+	type plain Config
+	return unmarshal((*plain)(cfg))
+}
+
+// Validate checks the forwarder config.
+func (cfg *Config) Validate() error {
+	if cfg.Name == "" {
+		return errors.New("forwarder name must be set")
+	}
+	if cfg.QueueSize <= 0 {
+		return fmt.Errorf("forwarder %s: queue_size must be positive", cfg.Name)
+	}
+	if cfg.Workers <= 0 {
+		return fmt.Errorf("forwarder %s: workers must be positive", cfg.Name)
+	}
+	if cfg.Retry.MaxRetries <= 0 {
+		return fmt.Errorf("forwarder %s: retry max_retries must be positive", cfg.Name)
+	}
+
+	switch cfg.Backend {
+	case BackendOTLPGRPC:
+		if cfg.OTLPGRPC.Endpoint == "" {
+			return fmt.Errorf("forwarder %s: otlpgrpc endpoint must be set", cfg.Name)
+		}
+	case BackendKafka:
+		if len(cfg.Kafka.Brokers) == 0 || cfg.Kafka.Topic == "" {
+			return fmt.Errorf("forwarder %s: kafka brokers and topic must be set", cfg.Name)
+		}
+	default:
+		return fmt.Errorf("forwarder %s: unknown backend %q", cfg.Name, cfg.Backend)
+	}
+
+	return nil
+}
+
+// ConfigList is the list of configured forwarders.
+type ConfigList []Config
+
+// Validate checks each forwarder and makes sure names are unique.
+func (l ConfigList) Validate() error {
+	names := make(map[string]struct{}, len(l))
+	for i := range l {
+		if err := l[i].Validate(); err != nil {
+			return err
+		}
+		if _, ok := names[l[i].Name]; ok {
+			return fmt.Errorf("duplicate forwarder name %s", l[i].Name)
+		}
+		names[l[i].Name] = struct{}{}
+	}
+	return nil
+}
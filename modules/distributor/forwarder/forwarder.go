@@ -0,0 +1,25 @@
+package forwarder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+// Forwarder sends a batch of spans to a secondary destination.
+type Forwarder interface {
+	ForwardTraces(ctx context.Context, tenantID string, trace *tempopb.Trace) error
+	Shutdown(ctx context.Context) error
+}
+
+func newForwarder(cfg Config) (Forwarder, error) {
+	switch cfg.Backend {
+	case BackendOTLPGRPC:
+		return newOTLPGRPCForwarder(cfg.OTLPGRPC)
+	case BackendKafka:
+		return newKafkaForwarder(cfg.Kafka, cfg.SendTimeout)
+	default:
+		return nil, fmt.Errorf("unknown forwarder backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,92 @@
+package forwarder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+// kafkaForwarder connects to the brokers on the first batch it sends rather than when it is created, since
+// sarama dials the brokers when the producer is built. A failed connection is retried with the batch by the queue.
+type kafkaForwarder struct {
+	topic   string
+	brokers []string
+	cfg     *sarama.Config
+
+	mtx      sync.Mutex
+	producer sarama.SyncProducer
+}
+
+func newKafkaForwarder(cfg KafkaConfig, sendTimeout time.Duration) (*kafkaForwarder, error) {
+	c := sarama.NewConfig()
+	c.ClientID = cfg.ClientID
+	c.Producer.Return.Successes = true
+	c.Producer.Return.Errors = true
+	c.Producer.RequiredAcks = sarama.WaitForLocal
+	// retries are handled by the forwarder queue
+	c.Producer.Retry.Max = 0
+	c.Producer.Timeout = sendTimeout
+	c.Net.WriteTimeout = sendTimeout
+
+	version, err := sarama.ParseKafkaVersion(cfg.ProtocolVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka protocol version %s: %w", cfg.ProtocolVersion, err)
+	}
+	c.Version = version
+
+	return &kafkaForwarder{
+		topic:   cfg.Topic,
+		brokers: cfg.Brokers,
+		cfg:     c,
+	}, nil
+}
+
+func (f *kafkaForwarder) getProducer() (sarama.SyncProducer, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.producer != nil {
+		return f.producer, nil
+	}
+
+	producer, err := sarama.NewSyncProducer(f.brokers, f.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+	}
+	f.producer = producer
+	return producer, nil
+}
+
+func (f *kafkaForwarder) ForwardTraces(_ context.Context, tenantID string, trace *tempopb.Trace) error {
+	producer, err := f.getProducer()
+	if err != nil {
+		return err
+	}
+
+	bytes, err := trace.Marshal()
+	if err != nil {
+		return err
+	}
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: f.topic,
+		Key:   sarama.StringEncoder(tenantID),
+		Value: sarama.ByteEncoder(bytes),
+	})
+	return err
+}
+
+func (f *kafkaForwarder) Shutdown(_ context.Context) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.producer == nil {
+		return nil
+	}
+	return f.producer.Close()
+}
@@ -0,0 +1,90 @@
+package forwarder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/grafana/dskit/services"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+// Overrides returns the names of the forwarders enabled for a tenant.
+type Overrides interface {
+	Forwarders(userID string) []string
+}
+
+// Manager owns the configured forwarders and dispatches each tenant's batches to the
+// forwarders enabled for it in the overrides.
+type Manager struct {
+	services.Service
+
+	queues    map[string]*queue
+	overrides Overrides
+	logger    *util.RateLimitedLogger
+}
+
+// NewManager builds a forwarder for every config. Connections are established lazily by the
+// backends, so a destination being unavailable does not prevent startup.
+func NewManager(cfgs ConfigList, o Overrides, logger log.Logger) (*Manager, error) {
+	if err := cfgs.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		queues:    make(map[string]*queue, len(cfgs)),
+		overrides: o,
+		logger:    util.NewRateLimitedLogger(1, level.Warn(logger)),
+	}
+
+	for _, cfg := range cfgs {
+		fwd, err := newForwarder(cfg)
+		if err != nil {
+			m.shutdownForwarders()
+			return nil, fmt.Errorf("failed to create forwarder %s: %w", cfg.Name, err)
+		}
+		m.queues[cfg.Name] = newQueue(cfg, fwd, logger)
+	}
+
+	m.Service = services.NewIdleService(m.starting, m.stopping)
+	return m, nil
+}
+
+func (m *Manager) starting(_ context.Context) error {
+	for _, q := range m.queues {
+		q.start()
+	}
+	return nil
+}
+
+func (m *Manager) stopping(_ error) error {
+	for _, q := range m.queues {
+		q.stop()
+	}
+	m.shutdownForwarders()
+	return nil
+}
+
+func (m *Manager) shutdownForwarders() {
+	for name, q := range m.queues {
+		if err := q.fwd.Shutdown(context.Background()); err != nil {
+			m.logger.Log("msg", "failed to shutdown forwarder", "forwarder", name, "err", err)
+		}
+	}
+}
+
+// ForwardTraces queues the batch for every forwarder enabled for the tenant. It never blocks;
+// batches that do not fit in a forwarder's queue are dropped and counted.
+func (m *Manager) ForwardTraces(tenantID string, trace *tempopb.Trace, spanCount int) {
+	for _, name := range m.overrides.Forwarders(tenantID) {
+		q, ok := m.queues[name]
+		if !ok {
+			m.logger.Log("msg", "tenant configured with unknown forwarder", "tenant", tenantID, "forwarder", name)
+			continue
+		}
+		q.enqueue(tenantID, trace, spanCount)
+	}
+}
@@ -0,0 +1,177 @@
+package forwarder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+type mockForwarder struct {
+	mtx      sync.Mutex
+	received []*tempopb.Trace
+	failures int
+	block    chan struct{}
+}
+
+func (m *mockForwarder) ForwardTraces(_ context.Context, _ string, trace *tempopb.Trace) error {
+	if m.block != nil {
+		<-m.block
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("failed")
+	}
+	m.received = append(m.received, trace)
+	return nil
+}
+
+func (m *mockForwarder) Shutdown(context.Context) error {
+	return nil
+}
+
+func (m *mockForwarder) count() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return len(m.received)
+}
+
+type mockOverrides map[string][]string
+
+func (m mockOverrides) Forwarders(userID string) []string {
+	return m[userID]
+}
+
+func testConfig(name string) Config {
+	return Config{
+		Name:        name,
+		QueueSize:   2,
+		Workers:     1,
+		SendTimeout: time.Second,
+		Retry: backoff.Config{
+			MinBackoff: time.Millisecond,
+			MaxBackoff: time.Millisecond,
+			MaxRetries: 3,
+		},
+	}
+}
+
+func TestQueueRetries(t *testing.T) {
+	fwd := &mockForwarder{failures: 2}
+	q := newQueue(testConfig("retries"), fwd, log.NewNopLogger())
+	q.start()
+
+	require.True(t, q.enqueue("test", &tempopb.Trace{}, 1))
+	q.stop()
+
+	assert.Equal(t, 1, fwd.count())
+	assert.Equal(t, 2.0, testutil.ToFloat64(metricForwarderPushFailures.WithLabelValues("retries", "test")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metricForwarderDroppedSpans.WithLabelValues("retries", "test", reasonRetriesExhausted)))
+}
+
+func TestQueueRetriesExhausted(t *testing.T) {
+	fwd := &mockForwarder{failures: 10}
+	q := newQueue(testConfig("exhausted"), fwd, log.NewNopLogger())
+	q.start()
+
+	require.True(t, q.enqueue("test", &tempopb.Trace{}, 5))
+	q.stop()
+
+	assert.Equal(t, 0, fwd.count())
+	assert.Equal(t, 5.0, testutil.ToFloat64(metricForwarderDroppedSpans.WithLabelValues("exhausted", "test", reasonRetriesExhausted)))
+}
+
+func TestQueueFullDrops(t *testing.T) {
+	fwd := &mockForwarder{block: make(chan struct{})}
+	q := newQueue(testConfig("full"), fwd, log.NewNopLogger())
+
+	// workers not started so nothing is dequeued
+	assert.True(t, q.enqueue("test", &tempopb.Trace{}, 1))
+	assert.True(t, q.enqueue("test", &tempopb.Trace{}, 1))
+	assert.False(t, q.enqueue("test", &tempopb.Trace{}, 3))
+	assert.Equal(t, 3.0, testutil.ToFloat64(metricForwarderDroppedSpans.WithLabelValues("full", "test", reasonQueueFull)))
+
+	close(fwd.block)
+	q.start()
+	q.stop()
+
+	assert.Equal(t, 2, fwd.count())
+	// enqueueing after stop must not panic
+	assert.False(t, q.enqueue("test", &tempopb.Trace{}, 1))
+}
+
+func TestManagerForwardsPerTenant(t *testing.T) {
+	a := &mockForwarder{}
+	b := &mockForwarder{}
+
+	m := &Manager{
+		queues: map[string]*queue{
+			"a": newQueue(testConfig("a"), a, log.NewNopLogger()),
+			"b": newQueue(testConfig("b"), b, log.NewNopLogger()),
+		},
+		overrides: mockOverrides{
+			"tenant-1": {"a"},
+			"tenant-2": {"a", "b", "unknown"},
+		},
+		logger: util.NewRateLimitedLogger(1, log.NewNopLogger()),
+	}
+	require.NoError(t, m.starting(context.Background()))
+
+	m.ForwardTraces("tenant-1", &tempopb.Trace{}, 1)
+	m.ForwardTraces("tenant-2", &tempopb.Trace{}, 1)
+	m.ForwardTraces("tenant-3", &tempopb.Trace{}, 1)
+
+	require.NoError(t, m.stopping(nil))
+
+	assert.Equal(t, 2, a.count())
+	assert.Equal(t, 1, b.count())
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := testConfig("valid")
+	valid.Backend = BackendOTLPGRPC
+	valid.OTLPGRPC.Endpoint = "localhost:4317"
+
+	noEndpoint := valid
+	noEndpoint.OTLPGRPC.Endpoint = ""
+
+	badBackend := valid
+	badBackend.Backend = "foo"
+
+	noTopic := valid
+	noTopic.Backend = BackendKafka
+	noTopic.Kafka.Brokers = []string{"localhost:9092"}
+
+	assert.NoError(t, ConfigList{valid}.Validate())
+	assert.Error(t, ConfigList{valid, valid}.Validate())
+	assert.Error(t, ConfigList{noEndpoint}.Validate())
+	assert.Error(t, ConfigList{badBackend}.Validate())
+	assert.Error(t, ConfigList{noTopic}.Validate())
+}
+
+func TestNewManagerWithUnavailableKafka(t *testing.T) {
+	cfg := testConfig("kafka")
+	cfg.Backend = BackendKafka
+	cfg.Kafka.Brokers = []string{"localhost:1"}
+	cfg.Kafka.Topic = "traces"
+	cfg.Kafka.ProtocolVersion = "2.0.0"
+
+	m, err := NewManager(ConfigList{cfg}, mockOverrides{}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, m.starting(context.Background()))
+	require.NoError(t, m.stopping(nil))
+}
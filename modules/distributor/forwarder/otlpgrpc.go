@@ -0,0 +1,49 @@
+package forwarder
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+// tempopb.Trace is wire compatible with the OTLP ExportTraceServiceRequest, so it can be sent
+// directly to any OTLP trace service.
+const otlpExportMethod = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+
+type otlpGRPCForwarder struct {
+	cfg  OTLPGRPCConfig
+	conn *grpc.ClientConn
+}
+
+func newOTLPGRPCForwarder(cfg OTLPGRPCConfig) (*otlpGRPCForwarder, error) {
+	opts, err := cfg.GRPCClientConfig.DialOption(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.Dial(cfg.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", cfg.Endpoint, err)
+	}
+
+	return &otlpGRPCForwarder{
+		cfg:  cfg,
+		conn: conn,
+	}, nil
+}
+
+func (f *otlpGRPCForwarder) ForwardTraces(ctx context.Context, _ string, trace *tempopb.Trace) error {
+	if len(f.cfg.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(f.cfg.Headers))
+	}
+
+	return f.conn.Invoke(ctx, otlpExportMethod, trace, &tempopb.PushResponse{})
+}
+
+func (f *otlpGRPCForwarder) Shutdown(_ context.Context) error {
+	return f.conn.Close()
+}
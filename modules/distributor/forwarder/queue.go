@@ -0,0 +1,151 @@
+package forwarder
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+const (
+	reasonQueueFull        = "queue_full"
+	reasonRetriesExhausted = "retries_exhausted"
+)
+
+var (
+	metricForwarderPushes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "distributor_forwarder_pushes_total",
+		Help:      "The total number of batches successfully forwarded.",
+	}, []string{"forwarder", "tenant"})
+	metricForwarderPushFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "distributor_forwarder_push_failures_total",
+		Help:      "The total number of failed attempts to forward a batch.",
+	}, []string{"forwarder", "tenant"})
+	metricForwarderDroppedSpans = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "distributor_forwarder_dropped_spans_total",
+		Help:      "The total number of spans dropped by a forwarder.",
+	}, []string{"forwarder", "tenant", "reason"})
+	metricForwarderQueueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "tempo",
+		Name:      "distributor_forwarder_queue_length",
+		Help:      "The number of batches waiting to be forwarded.",
+	}, []string{"forwarder"})
+)
+
+type request struct {
+	tenantID  string
+	trace     *tempopb.Trace
+	spanCount int
+}
+
+// queue buffers batches for a single forwarder and sends them from a fixed pool of workers.
+// When the queue is full new batches are dropped rather than blocking the caller.
+type queue struct {
+	cfg    Config
+	fwd    Forwarder
+	logger *util.RateLimitedLogger
+
+	reqs chan *request
+	wg   sync.WaitGroup
+
+	// mtx guards reqs against being closed while a batch is enqueued
+	mtx     sync.RWMutex
+	stopped bool
+}
+
+func newQueue(cfg Config, fwd Forwarder, logger log.Logger) *queue {
+	return &queue{
+		cfg:    cfg,
+		fwd:    fwd,
+		logger: util.NewRateLimitedLogger(10, level.Warn(log.With(logger, "forwarder", cfg.Name))),
+		reqs:   make(chan *request, cfg.QueueSize),
+	}
+}
+
+func (q *queue) start() {
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+}
+
+// enqueue adds the batch to the queue without blocking. It returns false if the batch was dropped.
+func (q *queue) enqueue(tenantID string, trace *tempopb.Trace, spanCount int) bool {
+	q.mtx.RLock()
+	defer q.mtx.RUnlock()
+
+	if q.stopped {
+		return false
+	}
+
+	select {
+	case q.reqs <- &request{tenantID: tenantID, trace: trace, spanCount: spanCount}:
+		metricForwarderQueueLength.WithLabelValues(q.cfg.Name).Inc()
+		return true
+	default:
+		metricForwarderDroppedSpans.WithLabelValues(q.cfg.Name, tenantID, reasonQueueFull).Add(float64(spanCount))
+		return false
+	}
+}
+
+// stop closes the queue and waits for the workers to drain it. Retries are bounded, so this
+// returns after at most max_retries attempts per queued batch.
+func (q *queue) stop() {
+	q.mtx.Lock()
+	if q.stopped {
+		q.mtx.Unlock()
+		return
+	}
+	q.stopped = true
+	close(q.reqs)
+	q.mtx.Unlock()
+
+	q.wg.Wait()
+}
+
+func (q *queue) worker() {
+	defer q.wg.Done()
+
+	for req := range q.reqs {
+		metricForwarderQueueLength.WithLabelValues(q.cfg.Name).Dec()
+		q.send(req)
+	}
+}
+
+func (q *queue) send(req *request) {
+	b := backoff.New(context.Background(), q.cfg.Retry)
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), q.cfg.SendTimeout)
+		err := q.fwd.ForwardTraces(ctx, req.tenantID, req.trace)
+		cancel()
+
+		if err == nil {
+			metricForwarderPushes.WithLabelValues(q.cfg.Name, req.tenantID).Inc()
+			return
+		}
+
+		metricForwarderPushFailures.WithLabelValues(q.cfg.Name, req.tenantID).Inc()
+		q.logger.Log("msg", "failed to forward batch", "tenant", req.tenantID, "attempt", b.NumRetries()+1, "err", err)
+
+		if !b.Ongoing() {
+			break
+		}
+		b.Wait()
+		if !b.Ongoing() {
+			break
+		}
+	}
+
+	metricForwarderDroppedSpans.WithLabelValues(q.cfg.Name, req.tenantID, reasonRetriesExhausted).Add(float64(req.spanCount))
+}
@@ -1,57 +1,149 @@
 package distributor
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/cortexproject/cortex/pkg/util/limiter"
 	"github.com/grafana/tempo/modules/overrides"
 )
 
+// serviceKeySeparator joins tenant and service into the key of the per service rate limiter
+const serviceKeySeparator = "\x00"
+
 // ReadLifecycler represents the read interface to the lifecycler.
 type ReadLifecycler interface {
 	HealthyInstancesCount() int
 }
 
+// rateLimits are the configured limit and burst for a rate limiter key
+type rateLimits struct {
+	limit func(key string) float64
+	burst func(key string) int
+}
+
+func bytesRateLimits(limits *overrides.Overrides) rateLimits {
+	return rateLimits{
+		limit: limits.IngestionRateLimitBytes,
+		burst: limits.IngestionBurstSizeBytes,
+	}
+}
+
+func spansRateLimits(limits *overrides.Overrides) rateLimits {
+	return rateLimits{
+		limit: limits.IngestionRateLimitSpans,
+		burst: limits.IngestionBurstSizeSpans,
+	}
+}
+
+func serviceSpansRateLimits(limits *overrides.Overrides) rateLimits {
+	return rateLimits{
+		limit: func(key string) float64 {
+			return limits.ServiceIngestionRateLimitSpans(splitServiceKey(key))
+		},
+		burst: func(key string) int {
+			return limits.ServiceIngestionBurstSizeSpans(splitServiceKey(key))
+		},
+	}
+}
+
+func serviceKey(userID, service string) string {
+	return userID + serviceKeySeparator + service
+}
+
+func splitServiceKey(key string) (string, string) {
+	parts := strings.SplitN(key, serviceKeySeparator, 2)
+	if len(parts) != 2 {
+		return key, ""
+	}
+	return parts[0], parts[1]
+}
+
+// serviceTracker records the services of each tenant that have their own span rate limiter. The limiter never
+// forgets a key, so the services are capped to bound its memory when service names have a high cardinality.
+type serviceTracker struct {
+	mtx      sync.Mutex
+	services map[string]map[string]struct{}
+}
+
+func newServiceTracker() *serviceTracker {
+	return &serviceTracker{
+		services: map[string]map[string]struct{}{},
+	}
+}
+
+// track returns true if the service has its own rate limiter, adding it if the tenant has less than max services.
+// A max of 0 means unlimited.
+func (t *serviceTracker) track(userID, service string, max int) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	services, ok := t.services[userID]
+	if !ok {
+		services = map[string]struct{}{}
+		t.services[userID] = services
+	}
+
+	if _, ok := services[service]; ok {
+		return true
+	}
+	if max > 0 && len(services) >= max {
+		return false
+	}
+	services[service] = struct{}{}
+	return true
+}
+
 type localStrategy struct {
-	limits *overrides.Overrides
+	limits rateLimits
 }
 
 func newLocalIngestionRateStrategy(limits *overrides.Overrides) limiter.RateLimiterStrategy {
+	return newLocalStrategy(bytesRateLimits(limits))
+}
+
+func newLocalStrategy(limits rateLimits) limiter.RateLimiterStrategy {
 	return &localStrategy{
 		limits: limits,
 	}
 }
 
-func (s *localStrategy) Limit(userID string) float64 {
-	return s.limits.IngestionRateLimitBytes(userID)
+func (s *localStrategy) Limit(key string) float64 {
+	return s.limits.limit(key)
 }
 
-func (s *localStrategy) Burst(userID string) int {
-	return s.limits.IngestionBurstSizeBytes(userID)
+func (s *localStrategy) Burst(key string) int {
+	return s.limits.burst(key)
 }
 
 type globalStrategy struct {
-	limits *overrides.Overrides
+	limits rateLimits
 	ring   ReadLifecycler
 }
 
 func newGlobalIngestionRateStrategy(limits *overrides.Overrides, ring ReadLifecycler) limiter.RateLimiterStrategy {
+	return newGlobalStrategy(bytesRateLimits(limits), ring)
+}
+
+func newGlobalStrategy(limits rateLimits, ring ReadLifecycler) limiter.RateLimiterStrategy {
 	return &globalStrategy{
 		limits: limits,
 		ring:   ring,
 	}
 }
 
-func (s *globalStrategy) Limit(userID string) float64 {
+func (s *globalStrategy) Limit(key string) float64 {
 	numDistributors := s.ring.HealthyInstancesCount()
 
 	if numDistributors == 0 {
-		return s.limits.IngestionRateLimitBytes(userID)
+		return s.limits.limit(key)
 	}
 
-	return s.limits.IngestionRateLimitBytes(userID) / float64(numDistributors)
+	return s.limits.limit(key) / float64(numDistributors)
 }
 
-func (s *globalStrategy) Burst(userID string) int {
+func (s *globalStrategy) Burst(key string) int {
 	// The meaning of burst doesn't change for the global strategy, in order
 	// to keep it easier to understand for users / operators.
-	return s.limits.IngestionBurstSizeBytes(userID)
+	return s.limits.burst(key)
 }
@@ -70,6 +70,46 @@ func TestIngestionRateStrategy(t *testing.T) {
 	}
 }
 
+func TestServiceSpanRateStrategy(t *testing.T) {
+	limits := overrides.Limits{
+		IngestionRateLimitSpansPerService: 10,
+		ServiceIngestionLimits: map[string]overrides.ServiceIngestionLimits{
+			"noisy": {IngestionRateLimitSpans: 4, IngestionBurstSizeSpans: 8},
+		},
+	}
+	o, err := overrides.NewOverrides(limits)
+	require.NoError(t, err)
+
+	ring := newReadLifecyclerMock()
+	ring.On("HealthyInstancesCount").Return(2)
+
+	local := newLocalStrategy(serviceSpansRateLimits(o))
+	global := newGlobalStrategy(serviceSpansRateLimits(o), ring)
+
+	assert.Equal(t, 4.0, local.Limit(serviceKey("test", "noisy")))
+	assert.Equal(t, 8, local.Burst(serviceKey("test", "noisy")))
+	assert.Equal(t, 10.0, local.Limit(serviceKey("test", "other")))
+	assert.Equal(t, 10, local.Burst(serviceKey("test", "other")))
+
+	assert.Equal(t, 2.0, global.Limit(serviceKey("test", "noisy")))
+	assert.Equal(t, 8, global.Burst(serviceKey("test", "noisy")))
+	assert.Equal(t, 5.0, global.Limit(serviceKey("test", "other")))
+}
+
+func TestServiceTracker(t *testing.T) {
+	tracker := newServiceTracker()
+
+	assert.True(t, tracker.track("test", "a", 2))
+	assert.True(t, tracker.track("test", "b", 2))
+	assert.False(t, tracker.track("test", "c", 2))
+	// tracked services are still tracked after the cap is reached
+	assert.True(t, tracker.track("test", "a", 2))
+	// the cap is per tenant
+	assert.True(t, tracker.track("other", "c", 2))
+	// 0 is unlimited
+	assert.True(t, tracker.track("test", "c", 0))
+}
+
 type readLifecyclerMock struct {
 	mock.Mock
 }
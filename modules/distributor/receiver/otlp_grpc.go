@@ -0,0 +1,152 @@
+package receiver
+
+import (
+	"context"
+	"net"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/obsreport"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+const (
+	otlpGRPCTransport  = "grpc"
+	otlpProtobufFormat = "protobuf"
+)
+
+// otlpTraceServiceDesc describes the OTLP TraceService. tempopb.Trace is wire-compatible with
+// ExportTraceServiceRequest and tempopb.PushResponse with ExportTraceServiceResponse, including its partial_success
+// field which the collector's version of the message doesn't have.
+var otlpTraceServiceDesc = grpc.ServiceDesc{
+	ServiceName: "opentelemetry.proto.collector.trace.v1.TraceService",
+	HandlerType: (*otlpTraceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler:    otlpExportHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "opentelemetry/proto/collector/trace/v1/trace_service.proto",
+}
+
+type otlpTraceServer interface {
+	Export(context.Context, *tempopb.Trace) (*tempopb.PushResponse, error)
+}
+
+func otlpExportHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(tempopb.Trace)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(otlpTraceServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opentelemetry.proto.collector.trace.v1.TraceService/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(otlpTraceServer).Export(ctx, req.(*tempopb.Trace))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// otlpGRPCReceiver serves the OTLP gRPC protocol of an otlp receiver in place of the collector, so that pushes with
+// rejected spans succeed with the partial success in the response.
+type otlpGRPCReceiver struct {
+	name   string
+	cfg    *configgrpc.GRPCServerSettings
+	shim   *receiversShim
+	server *grpc.Server
+}
+
+func newOTLPGRPCReceiver(name string, cfg *configgrpc.GRPCServerSettings, shim *receiversShim) *otlpGRPCReceiver {
+	return &otlpGRPCReceiver{
+		name: name,
+		cfg:  cfg,
+		shim: shim,
+	}
+}
+
+// implements component.Component
+func (r *otlpGRPCReceiver) Start(_ context.Context, host component.Host) error {
+	opts, err := r.cfg.ToServerOption()
+	if err != nil {
+		return err
+	}
+	var ln net.Listener
+	ln, err = r.cfg.ToListener()
+	if err != nil {
+		return err
+	}
+
+	r.server = grpc.NewServer(opts...)
+	r.server.RegisterService(&otlpTraceServiceDesc, r)
+	go func() {
+		if err := r.server.Serve(ln); err != nil {
+			host.ReportFatalError(err)
+		}
+	}()
+
+	return nil
+}
+
+// implements component.Component
+func (r *otlpGRPCReceiver) Shutdown(context.Context) error {
+	if r.server != nil {
+		r.server.Stop()
+	}
+	return nil
+}
+
+// Export implements otlpTraceServer
+func (r *otlpGRPCReceiver) Export(ctx context.Context, req *tempopb.Trace) (*tempopb.PushResponse, error) {
+	spanCount := countSpans(req.Batches)
+	if spanCount == 0 {
+		return &tempopb.PushResponse{}, nil
+	}
+	convertDeprecatedStatusCodes(req)
+
+	ctx = obsreport.ReceiverContext(ctx, r.name, otlpGRPCTransport)
+	ctx = obsreport.StartTraceDataReceiveOp(ctx, r.name, otlpGRPCTransport)
+	partialSuccess, err := r.shim.push(ctx, req)
+	accepted := spanCount
+	if partialSuccess != nil {
+		accepted = int(partialSuccess.AcceptedSpans)
+	}
+	obsreport.EndTraceDataReceiveOp(ctx, otlpProtobufFormat, accepted, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tempopb.PushResponse{PartialSuccess: partialSuccess}, nil
+}
+
+// convertDeprecatedStatusCodes sets the span status codes of older OTLP clients from their deprecated codes, as the
+// collector's OTLP receiver does.
+func convertDeprecatedStatusCodes(trace *tempopb.Trace) {
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				if s.Status == nil {
+					continue
+				}
+				switch s.Status.Code {
+				case v1.Status_STATUS_CODE_UNSET:
+					if s.Status.DeprecatedCode != v1.Status_DEPRECATED_STATUS_CODE_OK {
+						s.Status.Code = v1.Status_STATUS_CODE_ERROR
+					}
+				case v1.Status_STATUS_CODE_OK:
+					s.Status.DeprecatedCode = v1.Status_DEPRECATED_STATUS_CODE_OK
+				case v1.Status_STATUS_CODE_ERROR:
+					s.Status.DeprecatedCode = v1.Status_DEPRECATED_STATUS_CODE_UNKNOWN_ERROR
+				}
+			}
+		}
+	}
+}
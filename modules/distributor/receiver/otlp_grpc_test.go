@@ -0,0 +1,88 @@
+package receiver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gogo/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/confignet"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	tempo_util "github.com/grafana/tempo/pkg/util"
+)
+
+// rejectingPusher rejects every span of the batches whose spans are named reject
+type rejectingPusher struct {
+	tempopb.UnimplementedPusherServer
+}
+
+func (*rejectingPusher) Push(_ context.Context, req *tempopb.PushRequest) (*tempopb.PushResponse, error) {
+	spans := countSpans([]*v1.ResourceSpans{req.Batch})
+	if req.Batch.InstrumentationLibrarySpans[0].Spans[0].Name == "reject" {
+		return nil, tempopb.PushPartialSuccessError(codes.FailedPrecondition, "", tempopb.NewPushPartialSuccess(0, map[string]int{"trace_too_large": spans}))
+	}
+	return nil, nil
+}
+
+func TestOTLPGRPCReceiverPartialSuccess(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	endpoint := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	shim := &receiversShim{
+		pusher: &rejectingPusher{},
+		logger: tempo_util.NewRateLimitedLogger(logsPerSecond, log.NewNopLogger()),
+	}
+	r := newOTLPGRPCReceiver("otlp", &configgrpc.GRPCServerSettings{
+		NetAddr: confignet.NetAddr{Endpoint: endpoint, Transport: "tcp"},
+	}, shim)
+	require.NoError(t, r.Start(context.Background(), shim))
+	defer r.Shutdown(context.Background()) //nolint:errcheck
+
+	conn, err := grpc.Dial(endpoint, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	batch := func(name string, spans int) *v1.ResourceSpans {
+		ils := &v1.InstrumentationLibrarySpans{}
+		for i := 0; i < spans; i++ {
+			ils.Spans = append(ils.Spans, &v1.Span{TraceId: []byte{1}, SpanId: []byte{byte(i)}, Name: name})
+		}
+		return &v1.ResourceSpans{InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{ils}}
+	}
+	export := func(batches ...*v1.ResourceSpans) (*tempopb.PushResponse, error) {
+		resp := &tempopb.PushResponse{}
+		err := conn.Invoke(context.Background(), "/opentelemetry.proto.collector.trace.v1.TraceService/Export", &tempopb.Trace{Batches: batches}, resp)
+		return resp, err
+	}
+
+	// everything accepted
+	resp, err := export(batch("accept", 2))
+	require.NoError(t, err)
+	assert.Nil(t, resp.PartialSuccess)
+
+	// some spans rejected is a success with the partial success in the response
+	resp, err = export(batch("accept", 2), batch("reject", 3))
+	require.NoError(t, err)
+	require.NotNil(t, resp.PartialSuccess)
+	assert.Equal(t, int64(3), resp.PartialSuccess.RejectedSpans)
+	assert.Equal(t, int64(2), resp.PartialSuccess.AcceptedSpans)
+	assert.Equal(t, "3 of 5 spans rejected (trace_too_large: 3)", resp.PartialSuccess.ErrorMessage)
+
+	// nothing accepted is an error
+	_, err = export(batch("reject", 3))
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	ps, ok := tempopb.PushPartialSuccessFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), ps.RejectedSpans)
+}
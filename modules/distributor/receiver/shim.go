@@ -9,6 +9,7 @@ import (
 	"contrib.go.opencensus.io/exporter/prometheus"
 	"github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/status"
 	"github.com/grafana/dskit/services"
 	zaplogfmt "github.com/jsternberg/zap-logfmt"
 	prom_client "github.com/prometheus/client_golang/prometheus"
@@ -31,13 +32,18 @@ import (
 	"go.opentelemetry.io/collector/receiver/zipkinreceiver"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
 
 	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
 	tempo_util "github.com/grafana/tempo/pkg/util"
 )
 
 const (
 	logsPerSecond = 10
+
+	// spans of batches that weren't pushed after an earlier batch was accepted. same as the distributor's reason
+	reasonInternalError = "internal_error"
 )
 
 type receiversShim struct {
@@ -96,6 +102,15 @@ func New(receiverCfg map[string]interface{}, pusher tempopb.PusherServer, multit
 	params := component.ReceiverCreateParams{Logger: zapLogger}
 
 	for _, cfg := range cfgs.Receivers {
+		// OTLP gRPC is served by Tempo, the collector's receiver can't return a partial success
+		if otlpCfg, ok := cfg.(*otlpreceiver.Config); ok && otlpCfg.GRPC != nil {
+			shim.receivers = append(shim.receivers, newOTLPGRPCReceiver(otlpCfg.Name(), otlpCfg.GRPC, shim))
+			otlpCfg.GRPC = nil
+			if otlpCfg.HTTP == nil {
+				continue
+			}
+		}
+
 		factoryBase := receiverFactories[cfg.Type()]
 		if factoryBase == nil {
 			return nil, fmt.Errorf("receiver factory not found for type: %s", cfg.Type())
@@ -154,19 +169,6 @@ func (r *receiversShim) stopping(_ error) error {
 
 // implements consumer.TraceConsumer
 func (r *receiversShim) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
-	if !r.multitenancyEnabled {
-		ctx = user.InjectOrgID(ctx, tempo_util.FakeTenantID)
-	} else {
-		var err error
-		_, ctx, err = user.ExtractFromGRPCRequest(ctx)
-		if err != nil {
-			r.logger.Log("msg", "failed to extract org id", "err", err)
-			return err
-		}
-	}
-
-	var err error
-
 	// Convert to bytes and back. This is unfortunate for efficiency but it works
 	// around the otel-collector internalization of otel-proto which Tempo also uses.
 	convert, err := td.ToOtlpProtoBytes()
@@ -182,17 +184,88 @@ func (r *receiversShim) ConsumeTraces(ctx context.Context, td pdata.Traces) erro
 		return err
 	}
 
-	for _, batch := range trace.Batches {
-		_, err = r.pusher.Push(ctx, &tempopb.PushRequest{
+	// these receivers have no way to return a partial success, the rejected spans are only logged
+	_, err = r.push(ctx, &trace)
+	return err
+}
+
+// push pushes the batches of the trace to the distributor. Spans rejected individually don't stop the remaining
+// batches. The combined result is returned as a partial success if some spans were accepted, and as an error with the
+// partial success in the status details if none were. Clients retry errors, so they are only returned when nothing
+// was stored.
+func (r *receiversShim) push(ctx context.Context, trace *tempopb.Trace) (*tempopb.PushPartialSuccess, error) {
+	if !r.multitenancyEnabled {
+		ctx = user.InjectOrgID(ctx, tempo_util.FakeTenantID)
+	} else {
+		var err error
+		_, ctx, err = user.ExtractFromGRPCRequest(ctx)
+		if err != nil {
+			r.logger.Log("msg", "failed to extract org id", "err", err)
+			return nil, err
+		}
+	}
+
+	partialSuccess := &tempopb.PushPartialSuccess{}
+	code := codes.InvalidArgument
+	for i, batch := range trace.Batches {
+		resp, err := r.pusher.Push(ctx, &tempopb.PushRequest{
 			Batch: batch,
 		})
 		if err != nil {
-			r.logger.Log("msg", "pusher failed to consume trace data", "err", err)
-			break
+			ps, ok := tempopb.PushPartialSuccessFromError(err)
+			if !ok {
+				r.logger.Log("msg", "pusher failed to consume trace data", "err", err)
+				if partialSuccess.AcceptedSpans == 0 {
+					// keep the spans rejected by the previous batches in the error returned to the client
+					if partialSuccess.RejectedSpans > 0 {
+						s := status.Convert(err)
+						return nil, tempopb.PushPartialSuccessError(s.Code(), s.Message(), partialSuccess)
+					}
+					return nil, err
+				}
+
+				// the accepted spans must not be sent again, reject the ones that weren't pushed instead
+				partialSuccess.Merge(tempopb.NewPushPartialSuccess(0, map[string]int{
+					reasonInternalError: countSpans(trace.Batches[i:]),
+				}))
+				break
+			}
+			partialSuccess.Merge(ps)
+			if c := status.Code(err); c != codes.InvalidArgument {
+				code = c
+			}
+			continue
+		}
+
+		if resp != nil && resp.PartialSuccess != nil {
+			partialSuccess.Merge(resp.PartialSuccess)
+		} else {
+			partialSuccess.Merge(&tempopb.PushPartialSuccess{AcceptedSpans: int64(countSpans(trace.Batches[i : i+1]))})
 		}
 	}
 
-	return err
+	if partialSuccess.RejectedSpans == 0 {
+		return nil, nil
+	}
+
+	if partialSuccess.AcceptedSpans == 0 {
+		err := tempopb.PushPartialSuccessError(code, "", partialSuccess)
+		r.logger.Log("msg", "pusher rejected spans", "err", err)
+		return nil, err
+	}
+
+	r.logger.Log("msg", "pusher rejected spans", "err", partialSuccess.ErrorMessage)
+	return partialSuccess, nil
+}
+
+func countSpans(batches []*v1.ResourceSpans) int {
+	count := 0
+	for _, b := range batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			count += len(ils.Spans)
+		}
+	}
+	return count
 }
 
 // implements component.Host
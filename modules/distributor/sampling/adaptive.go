@@ -0,0 +1,180 @@
+package sampling
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+const (
+	serviceNameKey = "service.name"
+
+	// operations that have not been seen for this many calculation intervals are forgotten
+	maxIdleIntervals = 10
+	// probabilities change by at most this factor per interval to avoid oscillation
+	maxChangeFactor = 2.0
+)
+
+type serviceKey struct {
+	tenantID string
+	service  string
+}
+
+type operationState struct {
+	count       int
+	probability float64 // 0 until the first calculation
+	idle        int
+}
+
+// adaptive adjusts per-operation probabilities so each operation is sampled at roughly the
+// target rate. Only root spans are counted since that is where the sampling decision is made.
+type adaptive struct {
+	cfg             AdaptiveConfig
+	baseProbability func(tenantID, service, operation string) float64
+
+	mtx        sync.Mutex
+	services   map[serviceKey]map[string]*operationState
+	lastUpdate time.Time
+}
+
+func newAdaptive(cfg AdaptiveConfig, baseProbability func(tenantID, service, operation string) float64) *adaptive {
+	return &adaptive{
+		cfg:             cfg,
+		baseProbability: baseProbability,
+		services:        map[serviceKey]map[string]*operationState{},
+		lastUpdate:      time.Now(),
+	}
+}
+
+func (a *adaptive) observe(tenantID string, batch *v1.ResourceSpans) {
+	if batch == nil {
+		return
+	}
+
+	service := ""
+	if batch.Resource != nil {
+		for _, attr := range batch.Resource.Attributes {
+			if attr.Key == serviceNameKey {
+				service = attr.Value.GetStringValue()
+				break
+			}
+		}
+	}
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	key := serviceKey{tenantID: tenantID, service: service}
+	ops := a.services[key]
+
+	for _, ils := range batch.InstrumentationLibrarySpans {
+		for _, span := range ils.Spans {
+			if len(span.ParentSpanId) != 0 {
+				continue
+			}
+
+			if ops == nil {
+				ops = map[string]*operationState{}
+				a.services[key] = ops
+			}
+
+			op, ok := ops[span.Name]
+			if !ok {
+				if len(ops) >= a.cfg.MaxOperationsPerService {
+					continue
+				}
+				op = &operationState{}
+				ops[span.Name] = op
+			}
+			op.count++
+		}
+	}
+}
+
+func (a *adaptive) iteration(_ context.Context) error {
+	a.calculate(time.Now())
+	return nil
+}
+
+func (a *adaptive) calculate(now time.Time) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	elapsed := now.Sub(a.lastUpdate).Seconds()
+	a.lastUpdate = now
+	if elapsed <= 0 {
+		return
+	}
+
+	for key, ops := range a.services {
+		for name, op := range ops {
+			if op.count == 0 {
+				op.idle++
+				if op.idle >= maxIdleIntervals {
+					delete(ops, name)
+				}
+				continue
+			}
+
+			if op.probability == 0 {
+				op.probability = a.baseProbability(key.tenantID, key.service, name)
+			}
+
+			rate := float64(op.count) / elapsed
+			op.probability = a.nextProbability(op.probability, rate)
+			op.count = 0
+			op.idle = 0
+		}
+
+		if len(ops) == 0 {
+			delete(a.services, key)
+		}
+	}
+}
+
+func (a *adaptive) nextProbability(current, observedRate float64) float64 {
+	next := current * a.cfg.TargetTracesPerSecond / observedRate
+
+	if next > current*maxChangeFactor {
+		next = current * maxChangeFactor
+	}
+	if next < current/maxChangeFactor {
+		next = current / maxChangeFactor
+	}
+
+	if next < a.cfg.MinProbability {
+		next = a.cfg.MinProbability
+	}
+	if next > a.cfg.MaxProbability {
+		next = a.cfg.MaxProbability
+	}
+	return next
+}
+
+// operations returns the calculated probabilities for a service sorted by operation name.
+func (a *adaptive) operations(tenantID, service string) []*api_v2.OperationSamplingStrategy {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	ops := a.services[serviceKey{tenantID: tenantID, service: service}]
+	strategies := make([]*api_v2.OperationSamplingStrategy, 0, len(ops))
+	for name, op := range ops {
+		if op.probability == 0 {
+			continue
+		}
+		strategies = append(strategies, &api_v2.OperationSamplingStrategy{
+			Operation:             name,
+			ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: op.probability},
+		})
+	}
+
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Operation < strategies[j].Operation
+	})
+	return strategies
+}
@@ -0,0 +1,47 @@
+package sampling
+
+import (
+	"flag"
+	"time"
+)
+
+// Config for the Jaeger remote sampling endpoint.
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+	// StrategiesFile is a Jaeger static sampling strategies file used for all tenants. Per tenant
+	// strategies can be set with the sampling_strategies override.
+	StrategiesFile string `yaml:"strategies_file"`
+	// DefaultProbability is used when neither the overrides nor the strategies file define a default strategy.
+	DefaultProbability float64 `yaml:"default_probability"`
+
+	Adaptive AdaptiveConfig `yaml:"adaptive"`
+}
+
+// AdaptiveConfig configures per-operation probabilities calculated from observed root span rates.
+type AdaptiveConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TargetTracesPerSecond is the desired rate of sampled traces per operation.
+	TargetTracesPerSecond float64       `yaml:"target_traces_per_second"`
+	MinProbability        float64       `yaml:"min_probability"`
+	MaxProbability        float64       `yaml:"max_probability"`
+	CalculationInterval   time.Duration `yaml:"calculation_interval"`
+	// LowerBoundTracesPerSecond guarantees some traces for rarely seen operations.
+	LowerBoundTracesPerSecond float64 `yaml:"lower_bound_traces_per_second"`
+	// MaxOperationsPerService bounds memory used to track operations of a single service.
+	MaxOperationsPerService int `yaml:"max_operations_per_service"`
+}
+
+// RegisterFlagsAndApplyDefaults registers flags and applies defaults
+func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Enable the Jaeger remote sampling endpoint.")
+	f.StringVar(&cfg.StrategiesFile, prefix+".strategies-file", "", "Jaeger static sampling strategies file.")
+	f.Float64Var(&cfg.DefaultProbability, prefix+".default-probability", 0.001, "Sampling probability used when no default strategy is configured.")
+
+	f.BoolVar(&cfg.Adaptive.Enabled, prefix+".adaptive.enabled", false, "Adjust per-operation probabilities based on observed root span rates.")
+	f.Float64Var(&cfg.Adaptive.TargetTracesPerSecond, prefix+".adaptive.target-traces-per-second", 1, "Desired rate of sampled traces per operation.")
+	f.Float64Var(&cfg.Adaptive.MinProbability, prefix+".adaptive.min-probability", 1e-5, "Lowest probability adaptive sampling will assign.")
+	f.Float64Var(&cfg.Adaptive.MaxProbability, prefix+".adaptive.max-probability", 1, "Highest probability adaptive sampling will assign.")
+	f.DurationVar(&cfg.Adaptive.CalculationInterval, prefix+".adaptive.calculation-interval", time.Minute, "How often probabilities are recalculated.")
+	f.Float64Var(&cfg.Adaptive.LowerBoundTracesPerSecond, prefix+".adaptive.lower-bound-traces-per-second", 1.0/60, "Minimum rate of traces sampled for every operation.")
+	f.IntVar(&cfg.Adaptive.MaxOperationsPerService, prefix+".adaptive.max-operations-per-service", 500, "Maximum number of operations tracked per service.")
+}
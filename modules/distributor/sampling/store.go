@@ -0,0 +1,266 @@
+package sampling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/status"
+	"github.com/grafana/dskit/services"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/tempo/modules/overrides"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+const (
+	strategyTypeProbabilistic = "probabilistic"
+	strategyTypeRateLimiting  = "ratelimiting"
+
+	urlParamService = "service"
+)
+
+var metricStrategyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tempo",
+	Name:      "distributor_sampling_strategy_requests_total",
+	Help:      "The total number of sampling strategy requests per tenant.",
+}, []string{"tenant"})
+
+// Overrides provides the per tenant sampling strategies.
+type Overrides interface {
+	SamplingStrategies(userID string) overrides.SamplingStrategies
+}
+
+// Store serves Jaeger remote sampling strategies per tenant. Strategies configured in the overrides
+// take precedence over the static strategies file.
+type Store struct {
+	services.Service
+
+	cfg       Config
+	static    overrides.SamplingStrategies
+	overrides Overrides
+	adaptive  *adaptive
+}
+
+var _ api_v2.SamplingManagerServer = (*Store)(nil)
+
+// NewStore creates a sampling strategy store.
+func NewStore(cfg Config, o Overrides) (*Store, error) {
+	s := &Store{
+		cfg:       cfg,
+		overrides: o,
+	}
+
+	if cfg.StrategiesFile != "" {
+		buff, err := ioutil.ReadFile(cfg.StrategiesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sampling strategies file: %w", err)
+		}
+		if err := json.Unmarshal(buff, &s.static); err != nil {
+			return nil, fmt.Errorf("failed to parse sampling strategies file: %w", err)
+		}
+		if err := validateStrategies(s.static); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Adaptive.Enabled {
+		s.adaptive = newAdaptive(cfg.Adaptive, s.baseProbability)
+		s.Service = services.NewTimerService(cfg.Adaptive.CalculationInterval, nil, s.adaptive.iteration, nil)
+	} else {
+		s.Service = services.NewIdleService(nil, nil)
+	}
+
+	return s, nil
+}
+
+// Observe records the root spans of a batch for adaptive sampling. It is a no-op if adaptive sampling is disabled.
+func (s *Store) Observe(tenantID string, batch *v1.ResourceSpans) {
+	if s.adaptive == nil {
+		return
+	}
+	s.adaptive.observe(tenantID, batch)
+}
+
+// GetSamplingStrategy implements api_v2.SamplingManagerServer.
+func (s *Store) GetSamplingStrategy(ctx context.Context, params *api_v2.SamplingStrategyParameters) (*api_v2.SamplingStrategyResponse, error) {
+	tenantID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.strategy(tenantID, params.ServiceName)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return resp, nil
+}
+
+// ServeHTTP serves the strategy in the same JSON format as the Jaeger agent and collector.
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := user.ExtractOrgID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	services := r.URL.Query()[urlParamService]
+	if len(services) != 1 {
+		http.Error(w, "'service' parameter must be provided once", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.strategy(tenantID, services[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	marshaller := &jsonpb.Marshaler{EmitDefaults: true}
+	w.Header().Set("Content-Type", "application/json")
+	err = marshaller.Marshal(w, resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *Store) strategy(tenantID, service string) (*api_v2.SamplingStrategyResponse, error) {
+	metricStrategyRequests.WithLabelValues(tenantID).Inc()
+
+	strategy := s.findStrategy(tenantID, service)
+	resp, err := s.toResponse(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.adaptive != nil {
+		if ops := s.adaptive.operations(tenantID, service); len(ops) > 0 {
+			// keep configured operations that have not been observed yet
+			if resp.OperationSampling != nil {
+				calculated := make(map[string]struct{}, len(ops))
+				for _, op := range ops {
+					calculated[op.Operation] = struct{}{}
+				}
+				for _, op := range resp.OperationSampling.PerOperationStrategies {
+					if _, ok := calculated[op.Operation]; !ok {
+						ops = append(ops, op)
+					}
+				}
+			}
+
+			resp.OperationSampling = &api_v2.PerOperationSamplingStrategies{
+				DefaultSamplingProbability:       s.defaultProbability(strategy),
+				DefaultLowerBoundTracesPerSecond: s.cfg.Adaptive.LowerBoundTracesPerSecond,
+				PerOperationStrategies:           ops,
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// findStrategy returns the most specific strategy: tenant service, tenant default, static service,
+// static default. Returns nil if none are configured.
+func (s *Store) findStrategy(tenantID, service string) *overrides.SamplingStrategy {
+	tenant := s.overrides.SamplingStrategies(tenantID)
+
+	for _, strategies := range []overrides.SamplingStrategies{tenant, s.static} {
+		for i := range strategies.ServiceStrategies {
+			if strategies.ServiceStrategies[i].Service == service {
+				return &strategies.ServiceStrategies[i]
+			}
+		}
+		if strategies.DefaultStrategy != nil {
+			return strategies.DefaultStrategy
+		}
+	}
+
+	return nil
+}
+
+// baseProbability is the configured probability of an operation before any adaptive adjustments.
+func (s *Store) baseProbability(tenantID, service, operation string) float64 {
+	strategy := s.findStrategy(tenantID, service)
+	if strategy != nil {
+		for _, op := range strategy.OperationStrategies {
+			if op.Operation == operation && op.Type == strategyTypeProbabilistic {
+				return op.Param
+			}
+		}
+	}
+	return s.defaultProbability(strategy)
+}
+
+func (s *Store) defaultProbability(strategy *overrides.SamplingStrategy) float64 {
+	if strategy != nil && strategy.Type == strategyTypeProbabilistic {
+		return strategy.Param
+	}
+	return s.cfg.DefaultProbability
+}
+
+func (s *Store) toResponse(strategy *overrides.SamplingStrategy) (*api_v2.SamplingStrategyResponse, error) {
+	if strategy == nil {
+		return &api_v2.SamplingStrategyResponse{
+			StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+			ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: s.cfg.DefaultProbability},
+		}, nil
+	}
+
+	resp := &api_v2.SamplingStrategyResponse{}
+	switch strategy.Type {
+	case strategyTypeProbabilistic:
+		resp.StrategyType = api_v2.SamplingStrategyType_PROBABILISTIC
+		resp.ProbabilisticSampling = &api_v2.ProbabilisticSamplingStrategy{SamplingRate: strategy.Param}
+	case strategyTypeRateLimiting:
+		resp.StrategyType = api_v2.SamplingStrategyType_RATE_LIMITING
+		resp.RateLimitingSampling = &api_v2.RateLimitingSamplingStrategy{MaxTracesPerSecond: int32(strategy.Param)}
+	default:
+		return nil, fmt.Errorf("unknown sampling strategy type %q", strategy.Type)
+	}
+
+	if len(strategy.OperationStrategies) > 0 {
+		ops := make([]*api_v2.OperationSamplingStrategy, 0, len(strategy.OperationStrategies))
+		for _, op := range strategy.OperationStrategies {
+			if op.Type != strategyTypeProbabilistic {
+				continue
+			}
+			ops = append(ops, &api_v2.OperationSamplingStrategy{
+				Operation:             op.Operation,
+				ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: op.Param},
+			})
+		}
+		resp.OperationSampling = &api_v2.PerOperationSamplingStrategies{
+			DefaultSamplingProbability: s.defaultProbability(strategy),
+			PerOperationStrategies:     ops,
+		}
+	}
+
+	return resp, nil
+}
+
+func validateStrategies(strategies overrides.SamplingStrategies) error {
+	all := strategies.ServiceStrategies
+	if strategies.DefaultStrategy != nil {
+		all = append([]overrides.SamplingStrategy{*strategies.DefaultStrategy}, all...)
+	}
+
+	for _, s := range all {
+		if s.Type != strategyTypeProbabilistic && s.Type != strategyTypeRateLimiting {
+			return fmt.Errorf("unknown sampling strategy type %q for service %q", s.Type, s.Service)
+		}
+		for _, op := range s.OperationStrategies {
+			if op.Type != strategyTypeProbabilistic {
+				return fmt.Errorf("only probabilistic operation strategies are supported, got %q for operation %q", op.Type, op.Operation)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package sampling
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/modules/overrides"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+type mockOverrides map[string]overrides.SamplingStrategies
+
+func (m mockOverrides) SamplingStrategies(userID string) overrides.SamplingStrategies {
+	return m[userID]
+}
+
+const staticStrategies = `{
+	"default_strategy": {"type": "probabilistic", "param": 0.5},
+	"service_strategies": [
+		{
+			"service": "foo",
+			"type": "ratelimiting",
+			"param": 10,
+			"operation_strategies": [{"operation": "op", "type": "probabilistic", "param": 0.2}]
+		}
+	]
+}`
+
+func testConfig(t *testing.T) Config {
+	file := filepath.Join(t.TempDir(), "strategies.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(staticStrategies), os.ModePerm))
+
+	return Config{
+		Enabled:            true,
+		StrategiesFile:     file,
+		DefaultProbability: 0.001,
+		Adaptive: AdaptiveConfig{
+			TargetTracesPerSecond:     1,
+			MinProbability:            0.0001,
+			MaxProbability:            1,
+			CalculationInterval:       time.Minute,
+			LowerBoundTracesPerSecond: 0.1,
+			MaxOperationsPerService:   2,
+		},
+	}
+}
+
+func TestStoreStrategyPrecedence(t *testing.T) {
+	o := mockOverrides{
+		"tenant-service": {
+			ServiceStrategies: []overrides.SamplingStrategy{{Service: "foo", Type: "probabilistic", Param: 0.9}},
+		},
+		"tenant-default": {
+			DefaultStrategy: &overrides.SamplingStrategy{Type: "probabilistic", Param: 0.7},
+		},
+	}
+
+	s, err := NewStore(testConfig(t), o)
+	require.NoError(t, err)
+
+	tests := []struct {
+		tenant   string
+		service  string
+		expected *api_v2.SamplingStrategyResponse
+	}{
+		{
+			tenant:  "tenant-service",
+			service: "foo",
+			expected: &api_v2.SamplingStrategyResponse{
+				StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+				ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.9},
+			},
+		},
+		{
+			tenant:  "tenant-default",
+			service: "foo",
+			expected: &api_v2.SamplingStrategyResponse{
+				StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+				ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.7},
+			},
+		},
+		{
+			tenant:  "other",
+			service: "foo",
+			expected: &api_v2.SamplingStrategyResponse{
+				StrategyType:         api_v2.SamplingStrategyType_RATE_LIMITING,
+				RateLimitingSampling: &api_v2.RateLimitingSamplingStrategy{MaxTracesPerSecond: 10},
+				OperationSampling: &api_v2.PerOperationSamplingStrategies{
+					DefaultSamplingProbability: 0.001,
+					PerOperationStrategies: []*api_v2.OperationSamplingStrategy{
+						{Operation: "op", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.2}},
+					},
+				},
+			},
+		},
+		{
+			tenant:  "other",
+			service: "bar",
+			expected: &api_v2.SamplingStrategyResponse{
+				StrategyType:          api_v2.SamplingStrategyType_PROBABILISTIC,
+				ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.5},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		ctx := user.InjectOrgID(context.Background(), tc.tenant)
+		actual, err := s.GetSamplingStrategy(ctx, &api_v2.SamplingStrategyParameters{ServiceName: tc.service})
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, actual, "%s/%s", tc.tenant, tc.service)
+	}
+}
+
+func TestStoreServeHTTP(t *testing.T) {
+	s, err := NewStore(testConfig(t), mockOverrides{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sampling?service=bar", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"strategyType": "PROBABILISTIC",
+		"probabilisticSampling": {"samplingRate": 0.5},
+		"rateLimitingSampling": null,
+		"operationSampling": null
+	}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/sampling", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestStoreInvalidStrategiesFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "strategies.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"default_strategy": {"type": "foo", "param": 1}}`), os.ModePerm))
+
+	_, err := NewStore(Config{StrategiesFile: file}, mockOverrides{})
+	assert.Error(t, err)
+}
+
+func rootSpans(service string, names ...string) *v1.ResourceSpans {
+	spans := make([]*v1.Span, 0, len(names))
+	for _, n := range names {
+		spans = append(spans, &v1.Span{Name: n})
+	}
+	// child spans are ignored
+	spans = append(spans, &v1.Span{Name: "child", ParentSpanId: []byte{0x01}})
+
+	return &v1.ResourceSpans{
+		Resource: &v1_resource.Resource{
+			Attributes: []*v1_common.KeyValue{
+				{Key: "service.name", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: service}}},
+			},
+		},
+		InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: spans}},
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Adaptive.Enabled = true
+
+	s, err := NewStore(cfg, mockOverrides{})
+	require.NoError(t, err)
+
+	// 240 root spans of "busy" and 15 of "quiet" in a minute. a third operation is over the limit
+	for i := 0; i < 30; i++ {
+		s.Observe("test", rootSpans("foo", "busy", "busy", "busy", "busy", "busy", "busy", "busy", "busy"))
+		if i%2 == 0 {
+			s.Observe("test", rootSpans("foo", "quiet", "dropped"))
+		}
+	}
+	s.adaptive.calculate(s.adaptive.lastUpdate.Add(time.Minute))
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	resp, err := s.GetSamplingStrategy(ctx, &api_v2.SamplingStrategyParameters{ServiceName: "foo"})
+	require.NoError(t, err)
+
+	// busy: 4 traces/s against a target of 1 and quiet: 0.25 traces/s. both changes are dampened to a factor of 2.
+	// "op" is configured but not observed yet so it keeps its configured probability
+	assert.Equal(t, &api_v2.PerOperationSamplingStrategies{
+		DefaultSamplingProbability:       0.001,
+		DefaultLowerBoundTracesPerSecond: 0.1,
+		PerOperationStrategies: []*api_v2.OperationSamplingStrategy{
+			{Operation: "busy", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.001 / 2}},
+			{Operation: "quiet", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.001 * 2}},
+			{Operation: "op", ProbabilisticSampling: &api_v2.ProbabilisticSamplingStrategy{SamplingRate: 0.2}},
+		},
+	}, resp.OperationSampling)
+}
+
+func TestAdaptiveForgetsIdleOperations(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Adaptive.Enabled = true
+
+	s, err := NewStore(cfg, mockOverrides{})
+	require.NoError(t, err)
+
+	s.Observe("test", rootSpans("bar", "op"))
+	now := s.adaptive.lastUpdate
+	for i := 0; i <= maxIdleIntervals; i++ {
+		now = now.Add(time.Minute)
+		s.adaptive.calculate(now)
+	}
+
+	assert.Empty(t, s.adaptive.operations("test", "bar"))
+	assert.Empty(t, s.adaptive.services)
+}
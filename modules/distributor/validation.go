@@ -0,0 +1,180 @@
+package distributor
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/modules/overrides"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/validation"
+)
+
+const (
+	// reasonInvalidSpanID indicates that the span id was not 64 bits or was all zeros
+	reasonInvalidSpanID = "invalid_span_id"
+	// reasonEndBeforeStart indicates that the span ended before it started
+	reasonEndBeforeStart = "end_before_start"
+	// reasonFutureTimestamp indicates that the span started or ended too far in the future
+	reasonFutureTimestamp = "future_timestamp"
+	// reasonAttributeValueTooLong indicates that a span or event attribute value exceeded the configured size
+	reasonAttributeValueTooLong = "attribute_value_too_long"
+
+	// validationFailuresAttribute is added to tagged spans with a comma separated list of the failed checks
+	validationFailuresAttribute = "tempo.validation_failures"
+)
+
+var metricSpanValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tempo",
+	Name:      "distributor_span_validation_failures_total",
+	Help:      "The total number of spans that failed validation per tenant, reason and the action taken.",
+}, []string{"tenant", discardReasonLabel, "action"})
+
+// validateSpans applies the tenant policy to every span of the batch. Spans are fixed and tagged in place and
+// rejected spans are removed from the batch. Returns the number of rejected spans per reason.
+func validateSpans(batch *v1.ResourceSpans, policy overrides.SpanValidation, userID string, now time.Time) map[string]int {
+	if policy == (overrides.SpanValidation{}) {
+		return nil
+	}
+
+	var rejected map[string]int
+	for _, ils := range batch.InstrumentationLibrarySpans {
+		kept := ils.Spans[:0]
+		for _, span := range ils.Spans {
+			reason := validateSpan(span, policy, userID, now)
+			if reason != "" {
+				if rejected == nil {
+					rejected = map[string]int{}
+				}
+				rejected[reason]++
+				continue
+			}
+			kept = append(kept, span)
+		}
+		// clear the tail so rejected spans can be collected
+		for i := len(kept); i < len(ils.Spans); i++ {
+			ils.Spans[i] = nil
+		}
+		ils.Spans = kept
+	}
+
+	return rejected
+}
+
+// validateSpan runs the checks in order and returns the reason of the first check that rejects the span, or
+// "" if the span is kept.
+func validateSpan(span *v1.Span, policy overrides.SpanValidation, userID string, now time.Time) string {
+	var tags []string
+
+	// apply returns true if the span should be rejected
+	apply := func(reason, action string, fix func()) bool {
+		metricSpanValidationFailures.WithLabelValues(userID, reason, action).Inc()
+		switch action {
+		case overrides.SpanValidationActionReject:
+			return true
+		case overrides.SpanValidationActionFix:
+			fix()
+		case overrides.SpanValidationActionTag:
+			tags = append(tags, reason)
+		}
+		return false
+	}
+
+	if policy.InvalidSpanID != "" && !validation.ValidSpanID(span.SpanId) {
+		if apply(reasonInvalidSpanID, policy.InvalidSpanID, func() { span.SpanId = randomSpanID() }) {
+			return reasonInvalidSpanID
+		}
+	}
+
+	// checked before end_before_start so clamping a timestamp can't leave the span inverted
+	if policy.FutureTimestamp != "" {
+		limit := uint64(now.Add(time.Duration(policy.MaxFutureSkew)).UnixNano())
+		if span.StartTimeUnixNano > limit || span.EndTimeUnixNano > limit {
+			fix := func() {
+				nowNanos := uint64(now.UnixNano())
+				if span.EndTimeUnixNano > nowNanos {
+					span.EndTimeUnixNano = nowNanos
+				}
+				if span.StartTimeUnixNano > span.EndTimeUnixNano {
+					span.StartTimeUnixNano = span.EndTimeUnixNano
+				}
+			}
+			if apply(reasonFutureTimestamp, policy.FutureTimestamp, fix) {
+				return reasonFutureTimestamp
+			}
+		}
+	}
+
+	if policy.EndBeforeStart != "" && span.EndTimeUnixNano < span.StartTimeUnixNano {
+		if apply(reasonEndBeforeStart, policy.EndBeforeStart, func() { span.EndTimeUnixNano = span.StartTimeUnixNano }) {
+			return reasonEndBeforeStart
+		}
+	}
+
+	if policy.AttributeValueTooLong != "" && hasLongAttributeValue(span, policy.MaxAttributeValueBytes) {
+		if apply(reasonAttributeValueTooLong, policy.AttributeValueTooLong, func() { truncateAttributeValues(span, policy.MaxAttributeValueBytes) }) {
+			return reasonAttributeValueTooLong
+		}
+	}
+
+	if len(tags) > 0 {
+		span.Attributes = append(span.Attributes, &v1_common.KeyValue{
+			Key:   validationFailuresAttribute,
+			Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: strings.Join(tags, ",")}},
+		})
+	}
+
+	return ""
+}
+
+func hasLongAttributeValue(span *v1.Span, maxBytes int) bool {
+	long := false
+	forEachStringValue(span, func(v *v1_common.AnyValue_StringValue) {
+		if len(v.StringValue) > maxBytes {
+			long = true
+		}
+	})
+	return long
+}
+
+func truncateAttributeValues(span *v1.Span, maxBytes int) {
+	forEachStringValue(span, func(v *v1_common.AnyValue_StringValue) {
+		if len(v.StringValue) > maxBytes {
+			// drop a rune that was cut in half
+			v.StringValue = strings.ToValidUTF8(v.StringValue[:maxBytes], "")
+		}
+	})
+}
+
+// forEachStringValue calls fn for the string values of the span and event attributes
+func forEachStringValue(span *v1.Span, fn func(v *v1_common.AnyValue_StringValue)) {
+	visit := func(attrs []*v1_common.KeyValue) {
+		for _, attr := range attrs {
+			if attr == nil || attr.Value == nil {
+				continue
+			}
+			if v, ok := attr.Value.Value.(*v1_common.AnyValue_StringValue); ok {
+				fn(v)
+			}
+		}
+	}
+
+	visit(span.Attributes)
+	for _, e := range span.Events {
+		if e != nil {
+			visit(e.Attributes)
+		}
+	}
+}
+
+func randomSpanID() []byte {
+	id := make([]byte, 8)
+	for !validation.ValidSpanID(id) {
+		_, _ = rand.Read(id)
+	}
+	return id
+}
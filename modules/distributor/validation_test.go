@@ -0,0 +1,146 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/modules/overrides"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/validation"
+)
+
+func stringAttr(key, value string) *v1_common.KeyValue {
+	return &v1_common.KeyValue{Key: key, Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: value}}}
+}
+
+func TestValidateSpans(t *testing.T) {
+	now := time.Unix(1000, 0)
+	nowNanos := uint64(now.UnixNano())
+	validID := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	future := uint64(now.Add(2 * time.Hour).UnixNano())
+
+	policy := func(action string) overrides.SpanValidation {
+		return overrides.SpanValidation{
+			InvalidSpanID:          action,
+			EndBeforeStart:         action,
+			FutureTimestamp:        action,
+			MaxFutureSkew:          model.Duration(time.Hour),
+			AttributeValueTooLong:  action,
+			MaxAttributeValueBytes: 4,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		policy           overrides.SpanValidation
+		span             *v1.Span
+		expectedRejected map[string]int
+		expectedSpan     *v1.Span
+	}{
+		{
+			name:         "valid span",
+			policy:       policy(overrides.SpanValidationActionReject),
+			span:         &v1.Span{SpanId: validID, StartTimeUnixNano: 1, EndTimeUnixNano: 2, Attributes: []*v1_common.KeyValue{stringAttr("foo", "bar")}},
+			expectedSpan: &v1.Span{SpanId: validID, StartTimeUnixNano: 1, EndTimeUnixNano: 2, Attributes: []*v1_common.KeyValue{stringAttr("foo", "bar")}},
+		},
+		{
+			name:         "disabled",
+			policy:       overrides.SpanValidation{},
+			span:         &v1.Span{StartTimeUnixNano: 2, EndTimeUnixNano: 1},
+			expectedSpan: &v1.Span{StartTimeUnixNano: 2, EndTimeUnixNano: 1},
+		},
+		{
+			name:             "reject invalid span id",
+			policy:           policy(overrides.SpanValidationActionReject),
+			span:             &v1.Span{SpanId: make([]byte, 8)},
+			expectedRejected: map[string]int{reasonInvalidSpanID: 1},
+		},
+		{
+			name:             "reject end before start",
+			policy:           policy(overrides.SpanValidationActionReject),
+			span:             &v1.Span{SpanId: validID, StartTimeUnixNano: 2, EndTimeUnixNano: 1},
+			expectedRejected: map[string]int{reasonEndBeforeStart: 1},
+		},
+		{
+			name:             "reject future timestamp",
+			policy:           policy(overrides.SpanValidationActionReject),
+			span:             &v1.Span{SpanId: validID, StartTimeUnixNano: 1, EndTimeUnixNano: future},
+			expectedRejected: map[string]int{reasonFutureTimestamp: 1},
+		},
+		{
+			name:             "reject long event attribute",
+			policy:           policy(overrides.SpanValidationActionReject),
+			span:             &v1.Span{SpanId: validID, Events: []*v1.Span_Event{{Attributes: []*v1_common.KeyValue{stringAttr("foo", "barbaz")}}}},
+			expectedRejected: map[string]int{reasonAttributeValueTooLong: 1},
+		},
+		{
+			name:         "fix end before start",
+			policy:       policy(overrides.SpanValidationActionFix),
+			span:         &v1.Span{SpanId: validID, StartTimeUnixNano: 2, EndTimeUnixNano: 1},
+			expectedSpan: &v1.Span{SpanId: validID, StartTimeUnixNano: 2, EndTimeUnixNano: 2},
+		},
+		{
+			name:         "fix future timestamps",
+			policy:       policy(overrides.SpanValidationActionFix),
+			span:         &v1.Span{SpanId: validID, StartTimeUnixNano: future, EndTimeUnixNano: future},
+			expectedSpan: &v1.Span{SpanId: validID, StartTimeUnixNano: nowNanos, EndTimeUnixNano: nowNanos},
+		},
+		{
+			name:         "fix long attribute",
+			policy:       policy(overrides.SpanValidationActionFix),
+			span:         &v1.Span{SpanId: validID, Attributes: []*v1_common.KeyValue{stringAttr("foo", "barbaz"), stringAttr("short", "ok")}},
+			expectedSpan: &v1.Span{SpanId: validID, Attributes: []*v1_common.KeyValue{stringAttr("foo", "barb"), stringAttr("short", "ok")}},
+		},
+		{
+			name:         "fix long attribute on rune boundary",
+			policy:       policy(overrides.SpanValidationActionFix),
+			span:         &v1.Span{SpanId: validID, Attributes: []*v1_common.KeyValue{stringAttr("foo", "ab€")}},
+			expectedSpan: &v1.Span{SpanId: validID, Attributes: []*v1_common.KeyValue{stringAttr("foo", "ab")}},
+		},
+		{
+			name:   "tag",
+			policy: policy(overrides.SpanValidationActionTag),
+			span:   &v1.Span{SpanId: validID, StartTimeUnixNano: 2, EndTimeUnixNano: 1, Attributes: []*v1_common.KeyValue{stringAttr("foo", "barbaz")}},
+			expectedSpan: &v1.Span{SpanId: validID, StartTimeUnixNano: 2, EndTimeUnixNano: 1, Attributes: []*v1_common.KeyValue{
+				stringAttr("foo", "barbaz"),
+				stringAttr(validationFailuresAttribute, "end_before_start,attribute_value_too_long"),
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			batch := &v1.ResourceSpans{
+				InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: []*v1.Span{tc.span}}},
+			}
+
+			rejected := validateSpans(batch, tc.policy, "test", now)
+			assert.Equal(t, tc.expectedRejected, rejected)
+
+			if tc.expectedSpan == nil {
+				assert.Empty(t, batch.InstrumentationLibrarySpans[0].Spans)
+				return
+			}
+			require.Len(t, batch.InstrumentationLibrarySpans[0].Spans, 1)
+			assert.Equal(t, tc.expectedSpan, batch.InstrumentationLibrarySpans[0].Spans[0])
+		})
+	}
+}
+
+func TestValidateSpansFixesSpanID(t *testing.T) {
+	batch := &v1.ResourceSpans{
+		InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: []*v1.Span{{}, {SpanId: []byte{0x01}}}}},
+	}
+
+	rejected := validateSpans(batch, overrides.SpanValidation{InvalidSpanID: overrides.SpanValidationActionFix}, "test", time.Now())
+	assert.Empty(t, rejected)
+
+	for _, span := range batch.InstrumentationLibrarySpans[0].Spans {
+		assert.True(t, validation.ValidSpanID(span.SpanId))
+	}
+}
@@ -0,0 +1,163 @@
+package frontend
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	cortex_cache "github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/protobuf/proto"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/tempodb/backend/cache/memcached"
+	"github.com/grafana/tempo/tempodb/backend/cache/redis"
+)
+
+const traceByIDCacheName = "frontend-trace-by-id"
+
+// newTraceByIDCache creates the configured cache of trace by id responses. Returns nil if caching is disabled.
+func newTraceByIDCache(cfg TraceByIDCacheConfig, logger log.Logger, registerer prometheus.Registerer) (cortex_cache.Cache, error) {
+	switch cfg.Cache {
+	case "":
+		return nil, nil
+	case "redis":
+		if cfg.Redis == nil {
+			return nil, fmt.Errorf("trace by id cache redis requires redis config")
+		}
+		return redis.NewClient(traceByIDCacheName, cfg.Redis, cfg.BackgroundCache, logger), nil
+	case "memcached":
+		if cfg.Memcached == nil {
+			return nil, fmt.Errorf("trace by id cache memcached requires memcached config")
+		}
+		return memcached.NewClient(traceByIDCacheName, cfg.Memcached, cfg.BackgroundCache, logger), nil
+	case "inmemory":
+		if cfg.InMemory == nil {
+			return nil, fmt.Errorf("trace by id cache inmemory requires inmemory config")
+		}
+		return cortex_cache.NewFifoCache(traceByIDCacheName, *cfg.InMemory, registerer, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown trace by id cache %s", cfg.Cache)
+	}
+}
+
+// CacheWare serves trace by id requests from the cache and caches the responses of settled traces. Responses are
+// expected to be protobuf encoded traces.
+func CacheWare(cache cortex_cache.Cache, settledAge time.Duration, registerer prometheus.Registerer) Middleware {
+	lookups := promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "query_frontend_trace_by_id_cache_lookups_total",
+		Help:      "Total trace by id cache lookups by result.",
+	}, []string{"result"})
+
+	return MiddlewareFunc(func(next Handler) Handler {
+		return cacheWare{
+			next:       next,
+			cache:      cache,
+			settledAge: settledAge,
+			hits:       lookups.WithLabelValues("hit"),
+			misses:     lookups.WithLabelValues("miss"),
+		}
+	})
+}
+
+type cacheWare struct {
+	next         Handler
+	cache        cortex_cache.Cache
+	settledAge   time.Duration
+	hits, misses prometheus.Counter
+}
+
+// Do implements Handler
+func (c cacheWare) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	span, ctx := opentracing.StartSpanFromContext(ctx, "frontend.Cache")
+	defer span.Finish()
+
+	// context propagation
+	req = req.WithContext(ctx)
+
+	key, err := traceByIDCacheKey(req)
+	if err != nil {
+		return c.next.Do(req)
+	}
+
+	found, bufs, _ := c.cache.Fetch(ctx, []string{key})
+	if len(found) > 0 {
+		c.hits.Inc()
+		span.SetTag("cache", "hit")
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Body:          ioutil.NopCloser(bytes.NewReader(bufs[0])),
+			ContentLength: int64(len(bufs[0])),
+			Header:        http.Header{},
+		}, nil
+	}
+	c.misses.Inc()
+	span.SetTag("cache", "miss")
+
+	resp, err := c.next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	trace := &tempopb.Trace{}
+	if err := proto.Unmarshal(body, trace); err != nil {
+		return resp, nil
+	}
+	if traceSettled(trace, c.settledAge, time.Now()) {
+		c.cache.Store(ctx, []string{key}, [][]byte{body})
+	}
+
+	return resp, nil
+}
+
+// traceByIDCacheKey is the key of the request's trace. The query parameters are part of the key as they can
+// change the response.
+func traceByIDCacheKey(req *http.Request) (string, error) {
+	userID, err := user.ExtractOrgID(req.Context())
+	if err != nil {
+		return "", err
+	}
+
+	id, err := util.ParseTraceID(req)
+	if err != nil {
+		return "", err
+	}
+
+	return cortex_cache.HashKey(userID + ":" + util.TraceIDToHexString(id) + "?" + req.URL.Query().Encode()), nil
+}
+
+// traceSettled returns true if the newest span of the trace ended at least settledAge ago
+func traceSettled(trace *tempopb.Trace, settledAge time.Duration, now time.Time) bool {
+	var newest uint64
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				if s.EndTimeUnixNano > newest {
+					newest = s.EndTimeUnixNano
+				}
+			}
+		}
+	}
+
+	if newest == 0 {
+		return false
+	}
+	return now.Sub(time.Unix(0, int64(newest))) >= settledAge
+}
@@ -0,0 +1,127 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cortex_cache "github.com/cortexproject/cortex/pkg/chunk/cache"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+func setTraceEnd(trace *tempopb.Trace, end time.Time) {
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				s.StartTimeUnixNano = uint64(end.Add(-time.Second).UnixNano())
+				s.EndTimeUnixNano = uint64(end.UnixNano())
+			}
+		}
+	}
+}
+
+func TestCacheWare(t *testing.T) {
+	cache := cortex_cache.NewFifoCache("test", cortex_cache.FifoCacheConfig{MaxSizeItems: 10}, prometheus.NewRegistry(), log.NewNopLogger())
+
+	settledID := make([]byte, 16)
+	rand.Read(settledID)
+	settled := test.MakeTrace(2, settledID)
+	setTraceEnd(settled, time.Now().Add(-time.Hour))
+
+	recentID := make([]byte, 16)
+	rand.Read(recentID)
+	recent := test.MakeTrace(2, recentID)
+	setTraceEnd(recent, time.Now())
+
+	traces := map[string]*tempopb.Trace{
+		util.TraceIDToHexString(settledID): settled,
+		util.TraceIDToHexString(recentID):  recent,
+	}
+
+	var calls atomic.Int32
+	next := HandlerFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Inc()
+		trace, ok := traces[mux.Vars(req)[util.TraceIDVar]]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+		}
+		b, err := proto.Marshal(trace)
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(b))}, nil
+	})
+
+	handler := CacheWare(cache, 10*time.Minute, prometheus.NewRegistry()).Wrap(next)
+
+	get := func(tenant string, id []byte, query string) *tempopb.Trace {
+		req := httptest.NewRequest(http.MethodGet, "/api/traces/"+util.TraceIDToHexString(id)+query, nil)
+		req = req.WithContext(user.InjectOrgID(context.Background(), tenant))
+		req = mux.SetURLVars(req, map[string]string{util.TraceIDVar: util.TraceIDToHexString(id)})
+
+		resp, err := handler.Do(req)
+		require.NoError(t, err)
+		if resp.StatusCode != http.StatusOK {
+			return nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		trace := &tempopb.Trace{}
+		require.NoError(t, proto.Unmarshal(body, trace))
+		return trace
+	}
+
+	// settled traces are served from the cache after the first request
+	assert.True(t, proto.Equal(settled, get("a", settledID, "")))
+	assert.True(t, proto.Equal(settled, get("a", settledID, "")))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// tenants and query parameters are part of the key
+	get("b", settledID, "")
+	get("a", settledID, "?foo=bar")
+	assert.Equal(t, int32(3), calls.Load())
+
+	// recent traces and misses are not cached
+	assert.True(t, proto.Equal(recent, get("a", recentID, "")))
+	assert.True(t, proto.Equal(recent, get("a", recentID, "")))
+	missingID := make([]byte, 16)
+	rand.Read(missingID)
+	assert.Nil(t, get("a", missingID, ""))
+	assert.Nil(t, get("a", missingID, ""))
+	assert.Equal(t, int32(7), calls.Load())
+}
+
+func TestNewTraceByIDCache(t *testing.T) {
+	cache, err := newTraceByIDCache(TraceByIDCacheConfig{}, log.NewNopLogger(), prometheus.NewRegistry())
+	assert.NoError(t, err)
+	assert.Nil(t, cache)
+
+	cache, err = newTraceByIDCache(TraceByIDCacheConfig{
+		Cache:    "inmemory",
+		InMemory: &cortex_cache.FifoCacheConfig{MaxSizeItems: 10},
+	}, log.NewNopLogger(), prometheus.NewRegistry())
+	assert.NoError(t, err)
+	assert.NotNil(t, cache)
+
+	_, err = newTraceByIDCache(TraceByIDCacheConfig{Cache: "memcached"}, log.NewNopLogger(), prometheus.NewRegistry())
+	assert.Error(t, err)
+
+	_, err = newTraceByIDCache(TraceByIDCacheConfig{Cache: "disk"}, log.NewNopLogger(), prometheus.NewRegistry())
+	assert.Error(t, err)
+}
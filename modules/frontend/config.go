@@ -2,15 +2,37 @@ package frontend
 
 import (
 	"flag"
+	"time"
 
+	cortex_cache "github.com/cortexproject/cortex/pkg/chunk/cache"
 	"github.com/cortexproject/cortex/pkg/frontend"
 	v1 "github.com/cortexproject/cortex/pkg/frontend/v1"
+
+	"github.com/grafana/tempo/tempodb/backend/cache/memcached"
+	"github.com/grafana/tempo/tempodb/backend/cache/redis"
 )
 
 type Config struct {
-	Config      frontend.CombinedFrontendConfig `yaml:",inline"`
-	MaxRetries  int                             `yaml:"max_retries,omitempty"`
-	QueryShards int                             `yaml:"query_shards,omitempty"`
+	Config         frontend.CombinedFrontendConfig `yaml:",inline"`
+	MaxRetries     int                             `yaml:"max_retries,omitempty"`
+	QueryShards    int                             `yaml:"query_shards,omitempty"`
+	TraceByIDCache TraceByIDCacheConfig            `yaml:"trace_by_id_cache"`
+	// StreamingSearchShards is the number of time ranges searched in parallel by streaming searches
+	StreamingSearchShards int `yaml:"streaming_search_shards,omitempty"`
+}
+
+// TraceByIDCacheConfig configures the cache of trace by id responses
+type TraceByIDCacheConfig struct {
+	// Cache is one of redis, memcached or inmemory. Caching is disabled if empty.
+	Cache string `yaml:"cache"`
+	// Only traces whose newest span ended at least this long ago are cached, as more spans can still arrive
+	// for newer traces.
+	SettledAge time.Duration `yaml:"settled_age"`
+
+	BackgroundCache *cortex_cache.BackgroundConfig `yaml:"background_cache"`
+	Memcached       *memcached.Config              `yaml:"memcached"`
+	Redis           *redis.Config                  `yaml:"redis"`
+	InMemory        *cortex_cache.FifoCacheConfig  `yaml:"inmemory"`
 }
 
 func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet) {
@@ -19,6 +41,17 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 	cfg.Config.FrontendV1.MaxOutstandingPerTenant = 100
 	cfg.MaxRetries = 2
 	cfg.QueryShards = 20
+	cfg.StreamingSearchShards = 4
+
+	cfg.TraceByIDCache.SettledAge = 10 * time.Minute
+	cfg.TraceByIDCache.BackgroundCache = &cortex_cache.BackgroundConfig{
+		WriteBackBuffer:     10000,
+		WriteBackGoroutines: 10,
+	}
+	cfg.TraceByIDCache.InMemory = &cortex_cache.FifoCacheConfig{
+		MaxSizeItems: 1000,
+		Validity:     time.Hour,
+	}
 }
 
 type CortexNoQuerierLimits struct{}
@@ -78,11 +78,12 @@ func (s spanIDDeduper) Do(req *http.Request) (*http.Response, error) {
 			return nil, err
 		}
 
+		// keep the headers of the shards, like the cost of the query
 		return &http.Response{
 			StatusCode:    http.StatusOK,
 			Body:          ioutil.NopCloser(bytes.NewReader(traceBytes)),
-			Header:        http.Header{},
-			ContentLength: resp.ContentLength,
+			Header:        resp.Header,
+			ContentLength: int64(len(traceBytes)),
 		}, nil
 	}
 
@@ -2,16 +2,17 @@ package frontend
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
 
+	cortex_cache "github.com/cortexproject/cortex/pkg/chunk/cache"
 	"github.com/cortexproject/cortex/pkg/querier/queryrange"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
 	"github.com/opentracing/opentracing-go"
 	ot_log "github.com/opentracing/opentracing-go/log"
@@ -22,7 +23,10 @@ import (
 	"github.com/weaveworks/common/tracing"
 	"github.com/weaveworks/common/user"
 
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/modules/querier"
 	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/traceformat"
 	"github.com/grafana/tempo/pkg/util"
 )
 
@@ -32,28 +36,36 @@ const (
 )
 
 // NewTripperware returns a Tripperware configured with a middleware to route, split and dedupe requests.
-func NewTripperware(cfg Config, apiPrefix string, logger log.Logger, registerer prometheus.Registerer) (queryrange.Tripperware, error) {
+func NewTripperware(cfg Config, apiPrefix string, limits *overrides.Overrides, logger log.Logger, registerer prometheus.Registerer) (queryrange.Tripperware, error) {
 	level.Info(logger).Log("msg", "creating tripperware in query frontend")
 
-	tracesTripperware := NewTracesTripperware(cfg, logger, registerer)
-	searchTripperware := NewSearchTripperware()
+	cache, err := newTraceByIDCache(cfg.TraceByIDCache, logger, registerer)
+	if err != nil {
+		return nil, err
+	}
+
+	// traces and searches share the per tenant limit of concurrent jobs
+	jobs := NewJobLimiter(limits)
+	tracesTripperware := NewTracesTripperware(cfg, cache, jobs, logger, registerer)
+	searchTripperware := NewSearchTripperware(limits, jobs)
 
 	return func(next http.RoundTripper) http.RoundTripper {
 		traces := tracesTripperware(next)
 		search := searchTripperware(next)
 
-		return newFrontendRoundTripper(apiPrefix, next, traces, search, logger, registerer)
+		return newFrontendRoundTripper(apiPrefix, next, traces, search, limits, logger, registerer)
 	}, nil
 }
 
 type frontendRoundTripper struct {
 	apiPrefix            string
 	next, traces, search http.RoundTripper
+	limits               *overrides.Overrides
 	logger               log.Logger
 	queriesPerTenant     *prometheus.CounterVec
 }
 
-func newFrontendRoundTripper(apiPrefix string, next, traces, search http.RoundTripper, logger log.Logger, registerer prometheus.Registerer) frontendRoundTripper {
+func newFrontendRoundTripper(apiPrefix string, next, traces, search http.RoundTripper, limits *overrides.Overrides, logger log.Logger, registerer prometheus.Registerer) frontendRoundTripper {
 	queriesPerTenant := promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
 		Namespace: "tempo",
 		Name:      "query_frontend_queries_total",
@@ -65,6 +77,7 @@ func newFrontendRoundTripper(apiPrefix string, next, traces, search http.RoundTr
 		next:             next,
 		traces:           traces,
 		search:           search,
+		limits:           limits,
 		logger:           logger,
 		queriesPerTenant: queriesPerTenant,
 	}
@@ -87,7 +100,8 @@ func (r frontendRoundTripper) RoundTrip(req *http.Request) (resp *http.Response,
 	switch op := getOperation(r.apiPrefix, req.URL.Path); op {
 	case TracesOp:
 		resp, err = r.traces.RoundTrip(req)
-	case SearchOp:
+	case SearchOp, TracesBatchOp:
+		// batch trace by id requests are passed through to the queriers like searches
 		resp, err = r.search.RoundTrip(req)
 	default:
 		// should never be called
@@ -95,12 +109,18 @@ func (r frontendRoundTripper) RoundTrip(req *http.Request) (resp *http.Response,
 		resp, err = r.next.RoundTrip(req)
 	}
 
+	if err == nil && resp != nil && resp.StatusCode == http.StatusOK {
+		resp = r.limitResponseSize(orgID, resp)
+	}
+
 	traceID, _ := tracing.ExtractTraceID(ctx)
 	statusCode := 500
 	var contentLength int64 = 0
+	cost := &tempopb.SearchMetrics{}
 	if resp != nil {
 		statusCode = resp.StatusCode
 		contentLength = resp.ContentLength
+		cost = querier.CostFromHeaders(resp.Header)
 	} else if httpResp, ok := httpgrpc.HTTPResponseFromError(err); ok {
 		statusCode = int(httpResp.Code)
 		contentLength = int64(len(httpResp.Body))
@@ -114,16 +134,39 @@ func (r frontendRoundTripper) RoundTrip(req *http.Request) (resp *http.Response,
 		"duration", time.Since(start).String(),
 		"response_size", contentLength,
 		"status", statusCode,
+		"inspected_traces", cost.InspectedTraces,
+		"inspected_bytes", cost.InspectedBytes,
+		"inspected_blocks", cost.InspectedBlocks,
+		"skipped_blocks", cost.SkippedBlocks,
 	)
 
 	return
 }
 
+// limitResponseSize replaces responses larger than the max response size of the tenant with an error
+func (r frontendRoundTripper) limitResponseSize(orgID string, resp *http.Response) *http.Response {
+	max := r.limits.MaxResponseSizeBytes(orgID)
+	if max <= 0 || resp.ContentLength <= int64(max) {
+		return resp
+	}
+	resp.Body.Close()
+
+	// keep the cost headers, the query was still run
+	header := http.Header{}
+	querier.SetCostHeaders(header, querier.CostFromHeaders(resp.Header))
+	return &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       ioutil.NopCloser(strings.NewReader(fmt.Sprintf("response of %d bytes is larger than max_response_size_bytes %d", resp.ContentLength, max))),
+		Header:     header,
+	}
+}
+
 type RequestOp string
 
 const (
-	TracesOp RequestOp = "traces"
-	SearchOp RequestOp = "search"
+	TracesOp      RequestOp = "traces"
+	TracesBatchOp RequestOp = "traces_batch"
+	SearchOp      RequestOp = "search"
 )
 
 func getOperation(prefix, path string) RequestOp {
@@ -135,6 +178,8 @@ func getOperation(prefix, path string) RequestOp {
 	path = path[len(prefix):]
 
 	switch {
+	case path == apiPathTraces:
+		return TracesBatchOp
 	case strings.HasPrefix(path, apiPathTraces):
 		return TracesOp
 	case strings.HasPrefix(path, apiPathSearch):
@@ -144,14 +189,24 @@ func getOperation(prefix, path string) RequestOp {
 	}
 }
 
-// NewTracesTripperware creates a new frontend tripperware responsible for handling get traces requests.
-func NewTracesTripperware(cfg Config, logger log.Logger, registerer prometheus.Registerer) func(next http.RoundTripper) http.RoundTripper {
+// NewTracesTripperware creates a new frontend tripperware responsible for handling get traces requests. Responses
+// are cached if cache is not nil.
+func NewTracesTripperware(cfg Config, cache cortex_cache.Cache, jobs *JobLimiter, logger log.Logger, registerer prometheus.Registerer) func(next http.RoundTripper) http.RoundTripper {
+	// We're constructing middleware in this statement, each middleware wraps the next one from left-to-right
+	// - the TrimWare cuts the requested spans from the trace
+	// - the CacheWare serves settled traces from the cache
+	// - the Deduper dedupes Span IDs for Zipkin support
+	// - the ShardingWare shards queries by splitting the block ID space
+	// - the JobLimitWare limits the number of shards of a tenant that run at once
+	// - the RetryWare retries requests that have failed (error or http status 500)
+	middlewares := []Middleware{TrimWare()}
+	if cache != nil {
+		middlewares = append(middlewares, CacheWare(cache, cfg.TraceByIDCache.SettledAge, registerer))
+	}
+	middlewares = append(middlewares, Deduper(logger), ShardingWare(cfg.QueryShards, logger), JobLimitWare(jobs), RetryWare(cfg.MaxRetries, registerer))
+
 	return func(next http.RoundTripper) http.RoundTripper {
-		// We're constructing middleware in this statement, each middleware wraps the next one from left-to-right
-		// - the Deduper dedupes Span IDs for Zipkin support
-		// - the ShardingWare shards queries by splitting the block ID space
-		// - the RetryWare retries requests that have failed (error or http status 500)
-		rt := NewRoundTripper(next, Deduper(logger), ShardingWare(cfg.QueryShards, logger), RetryWare(cfg.MaxRetries, registerer))
+		rt := NewRoundTripper(next, middlewares...)
 
 		return queryrange.RoundTripFunc(func(r *http.Request) (*http.Response, error) {
 			// don't start a new span, this is already handled by frontendRoundTripper
@@ -168,19 +223,26 @@ func NewTracesTripperware(cfg Config, logger log.Logger, registerer prometheus.R
 			}
 			span.LogFields(ot_log.String("msg", "validated traceID"))
 
-			// check marshalling format
-			marshallingFormat := util.JSONTypeHeaderValue
-			if r.Header.Get(util.AcceptHeaderKey) == util.ProtobufTypeHeaderValue {
-				marshallingFormat = util.ProtobufTypeHeaderValue
+			// validate the time range hint. it is passed on to every shard
+			_, _, err = querier.ParseTimeRange(r)
+			if err != nil {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       ioutil.NopCloser(strings.NewReader(err.Error())),
+					Header:     http.Header{},
+				}, nil
 			}
 
+			// check marshalling format
+			marshallingFormat := traceformat.Negotiate(r.Header.Get(util.AcceptHeaderKey))
+
 			// Enforce all communication internal to Tempo to be in protobuf bytes
 			r.Header.Set(util.AcceptHeaderKey, util.ProtobufTypeHeaderValue)
 
 			resp, err := rt.RoundTrip(r)
 
-			if resp != nil && resp.StatusCode == http.StatusOK && marshallingFormat == util.JSONTypeHeaderValue {
-				// if request is for application/json, unmarshal into proto object and re-marshal into json bytes
+			if resp != nil && resp.StatusCode == http.StatusOK && marshallingFormat != util.ProtobufTypeHeaderValue {
+				// if request is for another format, unmarshal into proto object and re-marshal into the format
 				body, err := io.ReadAll(resp.Body)
 				resp.Body.Close()
 				if err != nil {
@@ -192,13 +254,16 @@ func NewTracesTripperware(cfg Config, logger log.Logger, registerer prometheus.R
 					return nil, err
 				}
 
-				var jsonTrace bytes.Buffer
-				marshaller := &jsonpb.Marshaler{}
-				err = marshaller.Marshal(&jsonTrace, traceObject)
+				formattedTrace, err := traceformat.Marshal(traceObject, marshallingFormat)
 				if err != nil {
 					return nil, err
 				}
-				resp.Body = ioutil.NopCloser(bytes.NewReader(jsonTrace.Bytes()))
+				resp.Body = ioutil.NopCloser(bytes.NewReader(formattedTrace))
+				resp.ContentLength = int64(len(formattedTrace))
+				if resp.Header == nil {
+					resp.Header = http.Header{}
+				}
+				resp.Header.Set("Content-Type", marshallingFormat)
 			}
 			span.SetTag("response marshalling format", marshallingFormat)
 
@@ -207,18 +272,30 @@ func NewTracesTripperware(cfg Config, logger log.Logger, registerer prometheus.R
 	}
 }
 
-// NewSearchTripperware creates a new frontend tripperware to handle search and search tags requests.
-func NewSearchTripperware() queryrange.Tripperware {
+// NewSearchTripperware creates a new frontend tripperware to handle search, search tags and batch trace by id
+// requests.
+func NewSearchTripperware(limits *overrides.Overrides, jobs *JobLimiter) queryrange.Tripperware {
 	return func(rt http.RoundTripper) http.RoundTripper {
 		return queryrange.RoundTripFunc(func(r *http.Request) (*http.Response, error) {
 			orgID, _ := user.ExtractOrgID(r.Context())
 
+			// the queriers limit searches without a time range, only reject the ranges that are too long here
+			start, end, err := querier.ParseTimeRange(r)
+			if err == nil {
+				err = querier.LimitSearchTimeRange(&tempopb.SearchRequest{Start: start, End: end}, limits.MaxSearchDuration(orgID), time.Now())
+			}
+			if err != nil {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       ioutil.NopCloser(strings.NewReader(err.Error())),
+					Header:     http.Header{},
+				}, nil
+			}
+
 			r.Header.Set(user.OrgIDHeaderName, orgID)
 			r.RequestURI = querierPrefix + r.RequestURI
 
-			resp, err := rt.RoundTrip(r)
-
-			return resp, err
+			return jobs.do(r, rt.RoundTrip)
 		})
 	}
 }
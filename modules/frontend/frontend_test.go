@@ -2,15 +2,29 @@ package frontend
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/cortexproject/cortex/pkg/querier/queryrange"
 	"github.com/go-kit/kit/log"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/mux"
+	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/modules/querier"
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/util"
 )
 
 type mockNextTripperware struct{}
@@ -60,6 +74,12 @@ func TestFrontendRoundTripper(t *testing.T) {
 			endpoint:  apiPathTraces + "/X",
 			response:  "traces",
 		},
+		{
+			name:      "batch traces tripper",
+			apiPrefix: "",
+			endpoint:  apiPathTraces,
+			response:  "search",
+		},
 		{
 			name:      "search tripper",
 			apiPrefix: "",
@@ -82,7 +102,9 @@ func TestFrontendRoundTripper(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			frontendTripper := newFrontendRoundTripper(tt.apiPrefix, next, traces, search, log.NewNopLogger(), prometheus.NewRegistry())
+			limits, err := overrides.NewOverrides(overrides.Limits{})
+			require.NoError(t, err)
+			frontendTripper := newFrontendRoundTripper(tt.apiPrefix, next, traces, search, limits, log.NewNopLogger(), prometheus.NewRegistry())
 
 			req := &http.Request{
 				URL: &url.URL{
@@ -99,3 +121,69 @@ func TestFrontendRoundTripper(t *testing.T) {
 		})
 	}
 }
+
+func TestFrontendRoundTripperMaxResponseSize(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxResponseSizeBytes: 5})
+	require.NoError(t, err)
+
+	respond := func(body string) http.RoundTripper {
+		return queryrange.RoundTripFunc(func(_ *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set(querier.InspectedBlocksHeader, "3")
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Body:          ioutil.NopCloser(strings.NewReader(body)),
+				ContentLength: int64(len(body)),
+				Header:        header,
+			}, nil
+		})
+	}
+
+	for body, expectedStatus := range map[string]int{"small": http.StatusOK, "too large": http.StatusBadRequest} {
+		search := respond(body)
+		frontendTripper := newFrontendRoundTripper("", search, search, search, limits, log.NewNopLogger(), prometheus.NewRegistry())
+
+		resp, err := frontendTripper.RoundTrip(httptest.NewRequest(http.MethodGet, apiPathSearch, nil))
+		require.NoError(t, err)
+		assert.Equal(t, expectedStatus, resp.StatusCode, body)
+		assert.Equal(t, "3", resp.Header.Get(querier.InspectedBlocksHeader))
+	}
+}
+
+func TestTracesTripperwareKeepsCostHeaders(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{})
+	require.NoError(t, err)
+
+	traceBytes, err := proto.Marshal(&tempopb.Trace{
+		Batches: []*v1.ResourceSpans{{InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: []*v1.Span{
+			{SpanId: []byte{0, 0, 0, 0, 0, 0, 0, 1}, Name: "a"},
+			{SpanId: []byte{0, 0, 0, 0, 0, 0, 0, 2}, ParentSpanId: []byte{0, 0, 0, 0, 0, 0, 0, 1}, Name: "b"},
+		}}}}},
+	})
+	require.NoError(t, err)
+
+	// every shard inspects a block
+	next := queryrange.RoundTripFunc(func(_ *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		querier.SetCostHeaders(header, &tempopb.SearchMetrics{InspectedBlocks: 1, InspectedBytes: 10})
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(traceBytes)),
+			Header:     header,
+		}, nil
+	})
+
+	cfg := Config{QueryShards: 2}
+	traces := NewTracesTripperware(cfg, nil, NewJobLimiter(limits), log.NewNopLogger(), prometheus.NewRegistry())(next)
+
+	_, ctx := opentracing.StartSpanFromContext(user.InjectOrgID(context.Background(), "test"), "test")
+	req := httptest.NewRequest(http.MethodGet, "/api/traces/1234?maxSpans=1", nil).WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{util.TraceIDVar: "1234"})
+	resp, err := traces.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, "2", resp.Header.Get(querier.InspectedBlocksHeader))
+	assert.Equal(t, "20", resp.Header.Get(querier.InspectedBytesHeader))
+	assert.NotEmpty(t, resp.Header.Get(NextCursorHeader))
+}
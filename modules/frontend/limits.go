@@ -0,0 +1,96 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/weaveworks/common/user"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/grafana/tempo/modules/overrides"
+)
+
+// JobLimiter limits the number of jobs of each tenant that are in the queue or processed by queriers at once to
+// the max_concurrent_query_jobs override. Jobs over the limit wait for others to finish.
+type JobLimiter struct {
+	limits *overrides.Overrides
+
+	mtx     sync.Mutex
+	tenants map[string]*tenantJobs
+}
+
+type tenantJobs struct {
+	sem *semaphore.Weighted
+	max int
+}
+
+// NewJobLimiter returns a JobLimiter enforcing the limits
+func NewJobLimiter(limits *overrides.Overrides) *JobLimiter {
+	return &JobLimiter{
+		limits:  limits,
+		tenants: map[string]*tenantJobs{},
+	}
+}
+
+// acquire waits until the tenant can run another job. release must be called once the job is done.
+func (l *JobLimiter) acquire(ctx context.Context, tenantID string) (release func(), err error) {
+	max := l.limits.MaxConcurrentQueryJobs(tenantID)
+	if max <= 0 {
+		return func() {}, nil
+	}
+
+	l.mtx.Lock()
+	jobs, ok := l.tenants[tenantID]
+	// the limit can change with the runtime overrides. jobs holding the old semaphore release it as usual
+	if !ok || jobs.max != max {
+		jobs = &tenantJobs{
+			sem: semaphore.NewWeighted(int64(max)),
+			max: max,
+		}
+		l.tenants[tenantID] = jobs
+	}
+	l.mtx.Unlock()
+
+	err = jobs.sem.Acquire(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	return func() { jobs.sem.Release(1) }, nil
+}
+
+// do runs the job once the tenant of the request can run another one
+func (l *JobLimiter) do(req *http.Request, job func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	tenantID, _ := user.ExtractOrgID(req.Context())
+
+	span, _ := opentracing.StartSpanFromContext(req.Context(), "frontend.JobLimiter")
+	release, err := l.acquire(req.Context(), tenantID)
+	span.Finish()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return job(req)
+}
+
+// JobLimitWare limits the number of concurrent jobs per tenant with the JobLimiter
+func JobLimitWare(jobs *JobLimiter) Middleware {
+	return MiddlewareFunc(func(next Handler) Handler {
+		return jobLimitWare{
+			next: next,
+			jobs: jobs,
+		}
+	})
+}
+
+type jobLimitWare struct {
+	next Handler
+	jobs *JobLimiter
+}
+
+// Do implements Handler
+func (j jobLimitWare) Do(req *http.Request) (*http.Response, error) {
+	return j.jobs.do(req, j.next.Do)
+}
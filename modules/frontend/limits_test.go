@@ -0,0 +1,79 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/tempo/modules/overrides"
+)
+
+func TestJobLimitWare(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxConcurrentQueryJobs: 2})
+	require.NoError(t, err)
+
+	var running, maxRunning atomic.Int32
+	unblock := make(chan struct{})
+	next := HandlerFunc(func(req *http.Request) (*http.Response, error) {
+		n := running.Inc()
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CAS(m, n) {
+				break
+			}
+		}
+		<-unblock
+		running.Dec()
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	handler := JobLimitWare(NewJobLimiter(limits)).Wrap(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traces/1234", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+
+	done := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		go func() {
+			_, err := handler.Do(req)
+			assert.NoError(t, err)
+			done <- struct{}{}
+		}()
+	}
+
+	// only 2 jobs run until one finishes
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), running.Load())
+
+	close(unblock)
+	for i := 0; i < 5; i++ {
+		<-done
+	}
+	assert.Equal(t, int32(2), maxRunning.Load())
+}
+
+func TestJobLimiterCancel(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxConcurrentQueryJobs: 1})
+	require.NoError(t, err)
+	jobs := NewJobLimiter(limits)
+
+	release, err := jobs.acquire(context.Background(), "test")
+	require.NoError(t, err)
+	defer release()
+
+	// other tenants are not limited by this one
+	releaseOther, err := jobs.acquire(context.Background(), "other")
+	require.NoError(t, err)
+	releaseOther()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = jobs.acquire(ctx, "test")
+	assert.Error(t, err)
+}
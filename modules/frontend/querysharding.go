@@ -17,6 +17,7 @@ import (
 
 	"github.com/grafana/tempo/modules/querier"
 	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempopb"
 )
 
 const (
@@ -77,10 +78,10 @@ func (s shardQuery) Do(r *http.Request) (*http.Response, error) {
 		// adding to RequestURI only because weaveworks/common uses the RequestURI field to
 		// translate from http.Request to httpgrpc.Request
 		// https://github.com/weaveworks/common/blob/47e357f4e1badb7da17ad74bae63e228bdd76e8f/httpgrpc/server/server.go#L48
-		reqs[i].RequestURI = querierPrefix + reqs[i].URL.RequestURI() + queryDelimiter + q.Encode()
+		reqs[i].RequestURI = querierPrefix + reqs[i].URL.EscapedPath() + queryDelimiter + q.Encode()
 	}
 
-	rrs, err := doRequests(reqs, s.next)
+	rrs, err := doRequests(reqs, s.next, shardResponseFuncFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -111,14 +112,31 @@ func createBlockBoundaries(queryShards int) [][]byte {
 	return blockBoundaries
 }
 
+type shardResponseFuncKey struct{}
+
+// ShardResponseFunc is called with the status, headers and body of the response of each shard of a trace by id query
+// as soon as it completes, before the responses are merged
+type ShardResponseFunc func(statusCode int, header http.Header, body []byte)
+
+// ContextWithShardResponseFunc returns a context whose trace by id queries call f with the response of each shard
+func ContextWithShardResponseFunc(ctx context.Context, f ShardResponseFunc) context.Context {
+	return context.WithValue(ctx, shardResponseFuncKey{}, f)
+}
+
+func shardResponseFuncFromContext(ctx context.Context) ShardResponseFunc {
+	f, _ := ctx.Value(shardResponseFuncKey{}).(ShardResponseFunc)
+	return f
+}
+
 // RequestResponse contains a request response and the respective request that was used.
 type RequestResponse struct {
 	Request  *http.Request
 	Response *http.Response
 }
 
-// doRequests executes a list of requests in parallel.
-func doRequests(reqs []*http.Request, downstream Handler) ([]RequestResponse, error) {
+// doRequests executes a list of requests in parallel. onResponse is called with every response as it arrives if it
+// is not nil.
+func doRequests(reqs []*http.Request, downstream Handler, onResponse ShardResponseFunc) ([]RequestResponse, error) {
 	respChan, errChan := make(chan RequestResponse), make(chan error)
 	for _, req := range reqs {
 		go func(req *http.Request) {
@@ -136,6 +154,15 @@ func doRequests(reqs []*http.Request, downstream Handler) ([]RequestResponse, er
 	for range reqs {
 		select {
 		case resp := <-respChan:
+			if onResponse != nil {
+				body, err := io.ReadAll(resp.Response.Body)
+				resp.Response.Body.Close()
+				if err != nil && firstErr == nil {
+					firstErr = errors.Wrap(err, "error reading response body at query frontend")
+				}
+				onResponse(resp.Response.StatusCode, resp.Response.Header, body)
+				resp.Response.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
 			resps = append(resps, resp)
 		case err := <-errChan:
 			if firstErr == nil {
@@ -156,7 +183,11 @@ func mergeResponses(ctx context.Context, rrs []RequestResponse) (*http.Response,
 	var errBody io.ReadCloser
 	var combinedTrace []byte
 	var shardMissCount = 0
+	// the cost of every shard, including the ones that failed or didn't find the trace
+	cost := &tempopb.SearchMetrics{}
 	for _, rr := range rrs {
+		querier.AddMetrics(cost, querier.CostFromHeaders(rr.Response.Header))
+
 		if rr.Response.StatusCode == http.StatusOK {
 			body, err := io.ReadAll(rr.Response.Body)
 			rr.Response.Body.Close()
@@ -181,11 +212,14 @@ func mergeResponses(ctx context.Context, rrs []RequestResponse) (*http.Response,
 		}
 	}
 
+	header := http.Header{}
+	querier.SetCostHeaders(header, cost)
+
 	if shardMissCount == len(rrs) {
 		return &http.Response{
 			StatusCode: http.StatusNotFound,
 			Body:       ioutil.NopCloser(strings.NewReader("trace not found in Tempo")),
-			Header:     http.Header{},
+			Header:     header,
 		}, nil
 	}
 
@@ -196,7 +230,7 @@ func mergeResponses(ctx context.Context, rrs []RequestResponse) (*http.Response,
 			// ContentLength header is added to log the size of response in the Tripperware in frontend.go
 			// This could be overwritten if the query client and Tempo negotiate compression
 			ContentLength: int64(len(combinedTrace)),
-			Header:        http.Header{},
+			Header:        header,
 		}, nil
 	}
 
@@ -204,6 +238,6 @@ func mergeResponses(ctx context.Context, rrs []RequestResponse) (*http.Response,
 	return &http.Response{
 		StatusCode: http.StatusInternalServerError,
 		Body:       errBody,
-		Header:     http.Header{},
+		Header:     header,
 	}, nil
 }
@@ -6,11 +6,19 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/go-kit/kit/log"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 
+	"github.com/grafana/tempo/modules/querier"
 	"github.com/grafana/tempo/pkg/model"
 	"github.com/grafana/tempo/pkg/util/test"
 )
@@ -179,3 +187,78 @@ func TestMergeResponses(t *testing.T) {
 	}
 
 }
+
+func TestMergeResponsesCost(t *testing.T) {
+	shard := func(statusCode int, blocks string) RequestResponse {
+		header := http.Header{}
+		header.Set(querier.InspectedBlocksHeader, blocks)
+		header.Set(querier.InspectedBytesHeader, "10")
+		return RequestResponse{
+			Response: &http.Response{
+				StatusCode: statusCode,
+				Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+				Header:     header,
+			},
+		}
+	}
+
+	merged, err := mergeResponses(context.Background(), []RequestResponse{
+		shard(http.StatusNotFound, "2"),
+		shard(http.StatusNotFound, "3"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, merged.StatusCode)
+	assert.Equal(t, "5", merged.Header.Get(querier.InspectedBlocksHeader))
+	assert.Equal(t, "20", merged.Header.Get(querier.InspectedBytesHeader))
+}
+
+func TestShardingWarePropagatesQuery(t *testing.T) {
+	var mtx sync.Mutex
+	var uris []string
+	next := HandlerFunc(func(req *http.Request) (*http.Response, error) {
+		mtx.Lock()
+		uris = append(uris, req.RequestURI)
+		mtx.Unlock()
+		return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+	})
+
+	handler := ShardingWare(2, log.NewNopLogger()).Wrap(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traces/1234?start=10&end=20", nil)
+	req = req.WithContext(user.InjectOrgID(context.Background(), "test"))
+	_, err := handler.Do(req)
+	require.NoError(t, err)
+
+	require.Len(t, uris, 2)
+	for _, uri := range uris {
+		u, err := url.ParseRequestURI(uri)
+		require.NoError(t, err)
+		assert.Equal(t, querierPrefix+"/api/traces/1234", u.Path)
+		assert.Equal(t, "10", u.Query().Get(querier.TimeStartKey))
+		assert.Equal(t, "20", u.Query().Get(querier.TimeEndKey))
+		assert.NotEmpty(t, u.Query().Get(querier.QueryModeKey))
+	}
+}
+
+func TestShardingWareShardResponseFunc(t *testing.T) {
+	next := HandlerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader("not found"))}, nil
+	})
+
+	handler := ShardingWare(3, log.NewNopLogger()).Wrap(next)
+
+	var mtx sync.Mutex
+	var bodies []string
+	ctx := ContextWithShardResponseFunc(user.InjectOrgID(context.Background(), "test"), func(statusCode int, _ http.Header, body []byte) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		assert.Equal(t, http.StatusNotFound, statusCode)
+		bodies = append(bodies, string(body))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traces/1234", nil)
+	resp, err := handler.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, []string{"not found", "not found", "not found"}, bodies)
+}
@@ -0,0 +1,270 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/status"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/opentracing/opentracing-go"
+	ot_log "github.com/opentracing/opentracing-go/log"
+	"github.com/weaveworks/common/httpgrpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/tempo/modules/querier"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/tempodb/search"
+)
+
+// StreamingQuerier serves the public gRPC query API. Queries are sent through the same round tripper as the http
+// endpoints, so they are split in the same jobs and subject to the same limits, but the result of every job is sent
+// as soon as it completes rather than once they are all merged.
+type StreamingQuerier struct {
+	apiPrefix    string
+	rt           http.RoundTripper
+	searchShards int
+	logger       log.Logger
+}
+
+var _ tempopb.StreamingQuerierServer = (*StreamingQuerier)(nil)
+
+// NewStreamingQuerier returns a StreamingQuerier sending queries to rt, the round tripper of the http endpoints
+func NewStreamingQuerier(cfg Config, apiPrefix string, rt http.RoundTripper, logger log.Logger) *StreamingQuerier {
+	return &StreamingQuerier{
+		apiPrefix:    apiPrefix,
+		rt:           rt,
+		searchShards: cfg.StreamingSearchShards,
+		logger:       logger,
+	}
+}
+
+// FindTraceByID implements tempopb.StreamingQuerierServer. It sends the part of the trace found by every shard of the
+// query. If the trace is cached, it is sent at once.
+func (s *StreamingQuerier) FindTraceByID(req *tempopb.TraceByIDRequest, srv tempopb.StreamingQuerier_FindTraceByIDServer) error {
+	span, ctx := opentracing.StartSpanFromContext(srv.Context(), "frontend.StreamingFindTraceByID")
+	defer span.Finish()
+
+	query := url.Values{}
+	if req.Start != 0 {
+		query.Set(querier.TimeStartKey, strconv.FormatUint(uint64(req.Start), 10))
+	}
+	if req.End != 0 {
+		query.Set(querier.TimeEndKey, strconv.FormatUint(uint64(req.End), 10))
+	}
+
+	sent := 0
+	var sendErr error
+	send := func(statusCode int, header http.Header, body []byte) {
+		if statusCode != http.StatusOK || sendErr != nil {
+			return
+		}
+
+		trace := &tempopb.Trace{}
+		sendErr = proto.Unmarshal(body, trace)
+		if sendErr != nil {
+			return
+		}
+		sendErr = srv.Send(&tempopb.TraceByIDResponse{
+			Trace:   trace,
+			Metrics: querier.CostFromHeaders(header),
+		})
+		sent++
+	}
+
+	ctx = ContextWithShardResponseFunc(ctx, send)
+	resp, err := s.roundTrip(ctx, path.Join(apiPathTraces, hex.EncodeToString(req.TraceID)), query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if sendErr != nil {
+		return sendErr
+	}
+	span.LogFields(ot_log.Int("sent", sent))
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, body)
+	}
+
+	// the shards didn't run, e.g. because the trace was cached
+	if sent == 0 {
+		send(resp.StatusCode, resp.Header, body)
+	}
+	return sendErr
+}
+
+// Search implements tempopb.StreamingQuerierServer. Unsorted searches with a start are split in time ranges that are
+// searched in parallel, and the results of each range are sent as soon as they are found. Each trace is sent once, and
+// the search stops once the limit of the request is reached.
+func (s *StreamingQuerier) Search(req *tempopb.SearchRequest, srv tempopb.StreamingQuerier_SearchServer) error {
+	span, ctx := opentracing.StartSpanFromContext(srv.Context(), "frontend.StreamingSearch")
+	defer span.Finish()
+
+	shards := s.searchShards
+	// the continuation token of a sorted search is only valid for the whole time range
+	if req.Sort != tempopb.SearchSort_FIRST_FOUND || req.ContinuationToken != "" {
+		shards = 1
+	}
+	ranges := splitTimeRange(req.Start, req.End, shards, time.Now())
+	span.LogFields(ot_log.Int("jobs", len(ranges)))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		resp *tempopb.SearchResponse
+		err  error
+	}
+	results := make(chan result, len(ranges))
+	for _, r := range ranges {
+		job := *req
+		job.Start, job.End = r[0], r[1]
+
+		go func() {
+			resp, err := s.search(ctx, &job)
+			results <- result{resp: resp, err: err}
+		}()
+	}
+
+	// every range is searched with the whole limit, so only send up to limit distinct traces across all of them
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = search.DefaultLimit
+	}
+	sent := make(map[string]struct{}, limit)
+	for range ranges {
+		r := <-results
+		if r.err != nil {
+			return r.err
+		}
+
+		traces := r.resp.Traces[:0]
+		for _, t := range r.resp.Traces {
+			if _, ok := sent[t.TraceID]; ok || len(sent) >= limit {
+				continue
+			}
+			sent[t.TraceID] = struct{}{}
+			traces = append(traces, t)
+		}
+		r.resp.Traces = traces
+
+		if err := srv.Send(r.resp); err != nil {
+			return err
+		}
+		if len(sent) >= limit {
+			span.LogFields(ot_log.String("msg", "limit reached"))
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (s *StreamingQuerier) search(ctx context.Context, req *tempopb.SearchRequest) (*tempopb.SearchResponse, error) {
+	resp, err := s.roundTrip(ctx, apiPathSearch, querier.SearchRequestQuery(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, body)
+	}
+
+	searchResp := &tempopb.SearchResponse{}
+	err = jsonpb.Unmarshal(bytes.NewReader(body), searchResp)
+	if err != nil {
+		return nil, err
+	}
+	return searchResp, nil
+}
+
+// roundTrip sends a GET request of the http api to the round tripper
+func (s *StreamingQuerier) roundTrip(ctx context.Context, apiPath string, query url.Values) (*http.Response, error) {
+	uri := s.apiPrefix + apiPath
+	if len(query) > 0 {
+		uri += queryDelimiter + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.RequestURI = uri
+	req.Header.Set(util.AcceptHeaderKey, util.ProtobufTypeHeaderValue)
+
+	resp, err := s.rt.RoundTrip(req)
+	if err != nil {
+		if httpResp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+			return nil, statusError(int(httpResp.Code), httpResp.Body)
+		}
+		level.Error(s.logger).Log("msg", "streaming query failed", "uri", uri, "err", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// splitTimeRange splits the range from start to end, or now if end is 0, in up to shards ranges, the latest first.
+// Ranges without a start can't be split.
+func splitTimeRange(start, end uint32, shards int, now time.Time) [][2]uint32 {
+	if end == 0 {
+		end = uint32(now.Unix())
+	}
+	if start == 0 || start >= end || shards <= 1 {
+		return [][2]uint32{{start, end}}
+	}
+
+	step := (end - start) / uint32(shards)
+	if step == 0 {
+		step = 1
+	}
+
+	var ranges [][2]uint32
+	for rangeEnd := end; rangeEnd > start; rangeEnd -= step {
+		rangeStart := start
+		if rangeEnd-start > step && len(ranges) < shards-1 {
+			rangeStart = rangeEnd - step
+		}
+		ranges = append(ranges, [2]uint32{rangeStart, rangeEnd})
+		if rangeStart == start {
+			break
+		}
+	}
+	return ranges
+}
+
+// statusError translates the status code of an http response to a grpc error
+func statusError(statusCode int, body []byte) error {
+	code := codes.Internal
+	switch statusCode {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	}
+	return status.Error(code, fmt.Sprintf("%d: %s", statusCode, body))
+}
@@ -0,0 +1,239 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gogo/status"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/tempo/modules/querier"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type mockSearchServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*tempopb.SearchResponse
+}
+
+func (m *mockSearchServer) Context() context.Context { return m.ctx }
+
+func (m *mockSearchServer) Send(resp *tempopb.SearchResponse) error {
+	m.responses = append(m.responses, resp)
+	return nil
+}
+
+type mockTraceByIDServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*tempopb.TraceByIDResponse
+}
+
+func (m *mockTraceByIDServer) Context() context.Context { return m.ctx }
+
+func (m *mockTraceByIDServer) Send(resp *tempopb.TraceByIDResponse) error {
+	m.responses = append(m.responses, resp)
+	return nil
+}
+
+func TestStreamingSearch(t *testing.T) {
+	var mtx sync.Mutex
+	var uris []string
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		orgID, err := user.ExtractOrgID(req.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "test", orgID)
+
+		mtx.Lock()
+		uris = append(uris, req.RequestURI)
+		mtx.Unlock()
+
+		resp := &tempopb.SearchResponse{
+			Traces:  []*tempopb.TraceSearchMetadata{{TraceID: req.URL.Query().Get(querier.TimeStartKey)}},
+			Metrics: &tempopb.SearchMetrics{InspectedTraces: 1},
+		}
+		body, err := (&jsonpb.Marshaler{}).MarshalToString(resp)
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+
+	s := NewStreamingQuerier(Config{StreamingSearchShards: 2}, "/tempo", rt, log.NewNopLogger())
+	srv := &mockSearchServer{ctx: user.InjectOrgID(context.Background(), "test")}
+
+	err := s.Search(&tempopb.SearchRequest{Tags: map[string]string{"foo": "bar"}, Start: 100, End: 200}, srv)
+	require.NoError(t, err)
+
+	require.Len(t, srv.responses, 2)
+	var traceIDs []string
+	for _, resp := range srv.responses {
+		require.Len(t, resp.Traces, 1)
+		traceIDs = append(traceIDs, resp.Traces[0].TraceID)
+	}
+	sort.Strings(traceIDs)
+	assert.Equal(t, []string{"100", "150"}, traceIDs)
+
+	sort.Strings(uris)
+	assert.Equal(t, []string{
+		"/tempo/api/search?end=150&foo=bar&start=100",
+		"/tempo/api/search?end=200&foo=bar&start=150",
+	}, uris)
+}
+
+func TestStreamingSearchLimit(t *testing.T) {
+	// every range finds the same trace and one of its own
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp := &tempopb.SearchResponse{
+			Traces: []*tempopb.TraceSearchMetadata{
+				{TraceID: "shared"},
+				{TraceID: req.URL.Query().Get(querier.TimeStartKey)},
+			},
+		}
+		body, err := (&jsonpb.Marshaler{}).MarshalToString(resp)
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+
+	s := NewStreamingQuerier(Config{StreamingSearchShards: 4}, "", rt, log.NewNopLogger())
+
+	for _, limit := range []uint32{2, 3, 5, 10} {
+		srv := &mockSearchServer{ctx: user.InjectOrgID(context.Background(), "test")}
+		err := s.Search(&tempopb.SearchRequest{Start: 100, End: 200, Limit: limit}, srv)
+		require.NoError(t, err)
+
+		seen := map[string]struct{}{}
+		for _, resp := range srv.responses {
+			for _, tr := range resp.Traces {
+				_, ok := seen[tr.TraceID]
+				assert.False(t, ok, "trace %s sent twice", tr.TraceID)
+				seen[tr.TraceID] = struct{}{}
+			}
+		}
+
+		// 4 ranges find 5 distinct traces
+		expected := int(limit)
+		if expected > 5 {
+			expected = 5
+		}
+		assert.Len(t, seen, expected)
+	}
+}
+
+func TestStreamingSearchError(t *testing.T) {
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(strings.NewReader("invalid"))}, nil
+	})
+
+	s := NewStreamingQuerier(Config{StreamingSearchShards: 2}, "", rt, log.NewNopLogger())
+	srv := &mockSearchServer{ctx: user.InjectOrgID(context.Background(), "test")}
+
+	err := s.Search(&tempopb.SearchRequest{}, srv)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Empty(t, srv.responses)
+}
+
+func TestStreamingFindTraceByID(t *testing.T) {
+	trace := test.MakeTrace(2, []byte{0x01})
+	traceBytes, err := proto.Marshal(trace)
+	require.NoError(t, err)
+
+	// shards send the trace to the shard response func, the cache doesn't
+	tests := []struct {
+		name   string
+		shards int
+	}{
+		{name: "shards", shards: 2},
+		{name: "cached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "/api/traces/01", req.URL.Path)
+				assert.Equal(t, "10", req.URL.Query().Get(querier.TimeStartKey))
+
+				if onResponse := shardResponseFuncFromContext(req.Context()); onResponse != nil {
+					for i := 0; i < tt.shards; i++ {
+						header := http.Header{}
+						querier.SetCostHeaders(header, &tempopb.SearchMetrics{InspectedBlocks: 1})
+						onResponse(http.StatusOK, header, traceBytes)
+					}
+					onResponse(http.StatusNotFound, http.Header{}, nil)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(traceBytes))}, nil
+			})
+
+			s := NewStreamingQuerier(Config{}, "", rt, log.NewNopLogger())
+			srv := &mockTraceByIDServer{ctx: user.InjectOrgID(context.Background(), "test")}
+
+			err := s.FindTraceByID(&tempopb.TraceByIDRequest{TraceID: []byte{0x01}, Start: 10}, srv)
+			require.NoError(t, err)
+
+			expected := tt.shards
+			if expected == 0 {
+				expected = 1
+			}
+			require.Len(t, srv.responses, expected)
+			for _, resp := range srv.responses {
+				assert.True(t, proto.Equal(trace, resp.Trace))
+			}
+		})
+	}
+}
+
+func TestStreamingFindTraceByIDNotFound(t *testing.T) {
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader("trace not found"))}, nil
+	})
+
+	s := NewStreamingQuerier(Config{}, "", rt, log.NewNopLogger())
+	srv := &mockTraceByIDServer{ctx: user.InjectOrgID(context.Background(), "test")}
+
+	err := s.FindTraceByID(&tempopb.TraceByIDRequest{TraceID: []byte{0x01}}, srv)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		name       string
+		start, end uint32
+		shards     int
+		expected   [][2]uint32
+	}{
+		{name: "no start", end: 500, shards: 4, expected: [][2]uint32{{0, 500}}},
+		{name: "one shard", start: 100, end: 500, shards: 1, expected: [][2]uint32{{100, 500}}},
+		{name: "even", start: 100, end: 500, shards: 4, expected: [][2]uint32{{400, 500}, {300, 400}, {200, 300}, {100, 200}}},
+		{name: "uneven", start: 100, end: 510, shards: 4, expected: [][2]uint32{{408, 510}, {306, 408}, {204, 306}, {100, 204}}},
+		{name: "no end", start: 800, shards: 2, expected: [][2]uint32{{900, 1000}, {800, 900}}},
+		{name: "short", start: 100, end: 102, shards: 4, expected: [][2]uint32{{101, 102}, {100, 101}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, splitTimeRange(tt.start, tt.end, tt.shards, now))
+		})
+	}
+}
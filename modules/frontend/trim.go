@@ -0,0 +1,99 @@
+package frontend
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/opentracing/opentracing-go"
+	ot_log "github.com/opentracing/opentracing-go/log"
+
+	"github.com/grafana/tempo/modules/querier"
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+// NextCursorHeader is set on trace by id responses cut to maxSpans if there are more spans. Its value is passed as
+// the cursor parameter to get the next page.
+const NextCursorHeader = "X-Tempo-Next-Cursor"
+
+// TrimWare cuts the spans selected by the maxSpans, maxDepth, attribute and cursor parameters from the combined trace.
+// Span depths and the ranking are only known once the shards are combined, the queriers only cut the spans of their
+// part that can't be selected down to what is needed to rank the others.
+func TrimWare() Middleware {
+	return MiddlewareFunc(func(next Handler) Handler {
+		return trimWare{
+			next: next,
+		}
+	})
+}
+
+type trimWare struct {
+	next Handler
+}
+
+// Do implements Handler
+func (t trimWare) Do(req *http.Request) (*http.Response, error) {
+	opts, err := querier.ParseTrimOptions(req)
+	if err != nil {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       ioutil.NopCloser(strings.NewReader(err.Error())),
+			Header:     http.Header{},
+		}, nil
+	}
+	if !opts.Enabled() {
+		return t.next.Do(req)
+	}
+
+	ctx := req.Context()
+	span, ctx := opentracing.StartSpanFromContext(ctx, "frontend.Trim")
+	defer span.Finish()
+
+	// context propagation
+	req = req.WithContext(ctx)
+
+	resp, err := t.next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &tempopb.Trace{}
+	err = proto.Unmarshal(body, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	trace, next := model.TrimTrace(trace, opts)
+	span.LogFields(ot_log.Int("next", next))
+
+	traceBytes, err := proto.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the headers of the shards, like the cost of the query
+	header := resp.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	if next > 0 {
+		header.Set(NextCursorHeader, model.EncodeSpanCursor(next))
+	}
+
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Body:          ioutil.NopCloser(bytes.NewReader(traceBytes)),
+		Header:        header,
+		ContentLength: int64(len(traceBytes)),
+	}, nil
+}
@@ -0,0 +1,217 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/modules/querier"
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+func TestTrimWare(t *testing.T) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	trace := test.MakeTrace(3, id)
+	totalSpans := 0
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			totalSpans += len(ils.Spans)
+		}
+	}
+	require.Greater(t, totalSpans, 2)
+
+	traceBytes, err := proto.Marshal(trace)
+	require.NoError(t, err)
+	next := HandlerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(traceBytes)), Header: http.Header{}}, nil
+	})
+
+	handler := TrimWare().Wrap(next)
+
+	get := func(query string) (*http.Response, *tempopb.Trace) {
+		resp, err := handler.Do(httptest.NewRequest(http.MethodGet, "/api/traces/1234"+query, nil))
+		require.NoError(t, err)
+		if resp.StatusCode != http.StatusOK {
+			return resp, nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		trace := &tempopb.Trace{}
+		require.NoError(t, proto.Unmarshal(body, trace))
+		return resp, trace
+	}
+
+	spanCount := func(trace *tempopb.Trace) int {
+		count := 0
+		for _, b := range trace.Batches {
+			for _, ils := range b.InstrumentationLibrarySpans {
+				count += len(ils.Spans)
+			}
+		}
+		return count
+	}
+
+	// no options passes the trace through
+	resp, found := get("")
+	assert.Equal(t, totalSpans, spanCount(found))
+	assert.Empty(t, resp.Header.Get(NextCursorHeader))
+
+	// page through all of the spans
+	seen := 0
+	cursor := ""
+	for {
+		query := "?maxSpans=2"
+		if cursor != "" {
+			query += "&cursor=" + cursor
+		}
+		resp, found = get(query)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.LessOrEqual(t, spanCount(found), 2)
+		seen += spanCount(found)
+
+		cursor = resp.Header.Get(NextCursorHeader)
+		if cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, totalSpans, seen)
+
+	// invalid options are rejected
+	resp, _ = get("?maxSpans=foo")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = get("?cursor=" + model.EncodeSpanCursor(2))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTrimWareAfterShardsAreCombined(t *testing.T) {
+	span := func(id, parent byte) *v1.Span {
+		s := &v1.Span{SpanId: []byte{id}, Name: string(rune('a' + id - 1))}
+		if parent != 0 {
+			s.ParentSpanId = []byte{parent}
+		}
+		return s
+	}
+	part := func(spans ...*v1.Span) []byte {
+		b, err := proto.Marshal(&tempopb.Trace{
+			Batches: []*v1.ResourceSpans{{InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: spans}}}},
+		})
+		require.NoError(t, err)
+		return b
+	}
+
+	// a -> b -> c, with b found by another shard than its parent and its child
+	var mtx sync.Mutex
+	parts := [][]byte{part(span(1, 0), span(3, 2)), part(span(2, 1))}
+	next := HandlerFunc(func(req *http.Request) (*http.Response, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		b := parts[0]
+		parts = parts[1:]
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(b)), Header: http.Header{}}, nil
+	})
+
+	handler := TrimWare().Wrap(ShardingWare(2, log.NewNopLogger()).Wrap(next))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traces/1234?maxDepth=2", nil)
+	resp, err := handler.Do(req.WithContext(user.InjectOrgID(context.Background(), "test")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	trace := &tempopb.Trace{}
+	require.NoError(t, proto.Unmarshal(body, trace))
+
+	var names []string
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				names = append(names, s.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestTrimWareAfterShardsAreTrimmed(t *testing.T) {
+	span := func(id, parent byte, kind v1.Span_SpanKind, start, end uint64) *v1.Span {
+		s := &v1.Span{
+			SpanId:            []byte{0, 0, 0, 0, 0, 0, 0, id},
+			Name:              string(rune('a' + id - 1)),
+			Kind:              kind,
+			StartTimeUnixNano: start,
+			EndTimeUnixNano:   end,
+		}
+		if parent != 0 {
+			s.ParentSpanId = []byte{0, 0, 0, 0, 0, 0, 0, parent}
+		}
+		return s
+	}
+
+	// a zipkin trace where the server span b shares its id with the client span. the deduper makes the server span
+	// the child of the client span, which puts it on the critical path
+	server := span(2, 1, v1.Span_SPAN_KIND_SERVER, 20, 80)
+	server.Name = "server"
+	var mtx sync.Mutex
+	found := false
+	next := HandlerFunc(func(req *http.Request) (*http.Response, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if found {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(bytes.NewReader(nil)), Header: http.Header{}}, nil
+		}
+		found = true
+
+		// like the queriers, trim the part found by the shard
+		opts, err := querier.ParseTrimOptions(req)
+		require.NoError(t, err)
+		part := model.TrimTracePart(&tempopb.Trace{
+			Batches: []*v1.ResourceSpans{{InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: []*v1.Span{
+				span(1, 0, v1.Span_SPAN_KIND_INTERNAL, 0, 100),
+				span(2, 1, v1.Span_SPAN_KIND_CLIENT, 10, 90),
+				server,
+				span(3, 1, v1.Span_SPAN_KIND_INTERNAL, 1, 5),
+				span(4, 1, v1.Span_SPAN_KIND_INTERNAL, 2, 5),
+			}}}}},
+		}, opts)
+		b, err := proto.Marshal(part)
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(b)), Header: http.Header{}}, nil
+	})
+
+	handler := TrimWare().Wrap(Deduper(log.NewNopLogger()).Wrap(ShardingWare(2, log.NewNopLogger()).Wrap(next)))
+
+	// the third span of the ranking: the root, the client span and the server span
+	req := httptest.NewRequest(http.MethodGet, "/api/traces/1234?maxSpans=1&cursor="+model.EncodeSpanCursor(2), nil)
+	resp, err := handler.Do(req.WithContext(user.InjectOrgID(context.Background(), "test")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, model.EncodeSpanCursor(3), resp.Header.Get(NextCursorHeader))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	trace := &tempopb.Trace{}
+	require.NoError(t, proto.Unmarshal(body, trace))
+	require.Len(t, trace.Batches, 1)
+	require.Len(t, trace.Batches[0].InstrumentationLibrarySpans[0].Spans, 1)
+	assert.Equal(t, "server", trace.Batches[0].InstrumentationLibrarySpans[0].Spans[0].Name)
+}
@@ -26,6 +26,7 @@ type Config struct {
 type Client struct {
 	tempopb.PusherClient
 	tempopb.QuerierClient
+	tempopb.IngesterTransferClient
 	grpc_health_v1.HealthClient
 	io.Closer
 }
@@ -57,19 +58,20 @@ func New(addr string, cfg Config) (*Client, error) {
 		return nil, err
 	}
 	return &Client{
-		PusherClient:  tempopb.NewPusherClient(conn),
-		QuerierClient: tempopb.NewQuerierClient(conn),
-		HealthClient:  grpc_health_v1.NewHealthClient(conn),
-		Closer:        conn,
+		PusherClient:           tempopb.NewPusherClient(conn),
+		QuerierClient:          tempopb.NewQuerierClient(conn),
+		IngesterTransferClient: tempopb.NewIngesterTransferClient(conn),
+		HealthClient:           grpc_health_v1.NewHealthClient(conn),
+		Closer:                 conn,
 	}, nil
 }
 
 func instrumentation() ([]grpc.UnaryClientInterceptor, []grpc.StreamClientInterceptor) {
 	return []grpc.UnaryClientInterceptor{
-			otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()),
-			middleware.ClientUserHeaderInterceptor,
-		}, []grpc.StreamClientInterceptor{
-			otgrpc.OpenTracingStreamClientInterceptor(opentracing.GlobalTracer()),
-			middleware.StreamClientUserHeaderInterceptor,
-		}
+		otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()),
+		middleware.ClientUserHeaderInterceptor,
+	}, []grpc.StreamClientInterceptor{
+		otgrpc.OpenTracingStreamClientInterceptor(opentracing.GlobalTracer()),
+		middleware.StreamClientUserHeaderInterceptor,
+	}
 }
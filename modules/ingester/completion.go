@@ -0,0 +1,87 @@
+package ingester
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+const (
+	// completionReasonIdle indicates that no spans were received for the idle period
+	completionReasonIdle = "idle"
+	// completionReasonRootEnded indicates that the root span was received and no spans were received for the grace period
+	completionReasonRootEnded = "root_ended"
+	// completionReasonMaxDuration indicates that the trace was live for the max trace duration
+	completionReasonMaxDuration = "max_duration"
+	// completionReasonImmediate indicates that all traces were cut, e.g. on shutdown or by the flush handler
+	completionReasonImmediate = "immediate"
+)
+
+var (
+	metricTracesCompletedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_traces_completed_total",
+		Help:      "The total number of live traces cut as complete per tenant and reason.",
+	}, []string{"tenant", "reason"})
+	metricLateTracesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_late_traces_total",
+		Help:      "The total number of traces that received spans within the late span window after they were cut as complete per tenant and the reason they were cut.",
+	}, []string{"tenant", "reason"})
+)
+
+// cutTrace is a trace that was recently cut as complete
+type cutTrace struct {
+	at     time.Time
+	reason string
+}
+
+// completionReason returns why the trace is complete according to the policy, or "" if it should be kept open.
+// defaultIdle is used if the policy has no idle period.
+func completionReason(t *trace, policy overrides.TraceCompletion, defaultIdle time.Duration, now time.Time) string {
+	maxDuration := time.Duration(policy.MaxTraceDuration)
+	if maxDuration > 0 && now.Sub(t.firstAppend) >= maxDuration {
+		return completionReasonMaxDuration
+	}
+
+	idle := now.Sub(t.lastAppend)
+	if t.rootEnded && policy.RootSpanGracePeriod > 0 && idle >= time.Duration(policy.RootSpanGracePeriod) {
+		return completionReasonRootEnded
+	}
+
+	// long running traces stay open until their root span ends or they reach the max duration
+	if maxDuration > 0 && !t.rootEnded {
+		return ""
+	}
+
+	idlePeriod := defaultIdle
+	if policy.IdlePeriod > 0 {
+		idlePeriod = time.Duration(policy.IdlePeriod)
+	}
+	if idle >= idlePeriod {
+		return completionReasonIdle
+	}
+
+	return ""
+}
+
+// needsRootSpan returns true if the policy depends on whether the root span was received
+func needsRootSpan(policy overrides.TraceCompletion) bool {
+	return policy.RootSpanGracePeriod > 0 || policy.MaxTraceDuration > 0
+}
+
+// containsRootSpan returns true if the marshalled tempopb.Trace contains a span without a parent. Pushes from
+// the distributor carry this flag, so it's only needed for pushes that don't.
+func containsRootSpan(traceBytes []byte) bool {
+	t := &tempopb.Trace{}
+	if err := t.Unmarshal(traceBytes); err != nil {
+		return false
+	}
+
+	return model.ContainsRootSpan(t)
+}
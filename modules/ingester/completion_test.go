@@ -0,0 +1,162 @@
+package ingester
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	prom_dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+func TestCompletionReason(t *testing.T) {
+	now := time.Now()
+	idle := 10 * time.Second
+
+	tests := []struct {
+		name     string
+		policy   overrides.TraceCompletion
+		trace    *trace
+		expected string
+	}{
+		{
+			name:     "idle",
+			trace:    &trace{firstAppend: now.Add(-time.Minute), lastAppend: now.Add(-idle)},
+			expected: completionReasonIdle,
+		},
+		{
+			name:  "not idle",
+			trace: &trace{firstAppend: now.Add(-time.Minute), lastAppend: now.Add(-time.Second)},
+		},
+		{
+			name:     "tenant idle period",
+			policy:   overrides.TraceCompletion{IdlePeriod: model.Duration(time.Second)},
+			trace:    &trace{firstAppend: now.Add(-time.Minute), lastAppend: now.Add(-time.Second)},
+			expected: completionReasonIdle,
+		},
+		{
+			name:     "root ended",
+			policy:   overrides.TraceCompletion{RootSpanGracePeriod: model.Duration(time.Second)},
+			trace:    &trace{firstAppend: now.Add(-time.Minute), lastAppend: now.Add(-time.Second), rootEnded: true},
+			expected: completionReasonRootEnded,
+		},
+		{
+			name:   "root ended within grace period",
+			policy: overrides.TraceCompletion{RootSpanGracePeriod: model.Duration(5 * time.Second)},
+			trace:  &trace{firstAppend: now.Add(-time.Minute), lastAppend: now.Add(-time.Second), rootEnded: true},
+		},
+		{
+			name:   "long running trace stays open",
+			policy: overrides.TraceCompletion{MaxTraceDuration: model.Duration(time.Hour)},
+			trace:  &trace{firstAppend: now.Add(-time.Minute), lastAppend: now.Add(-time.Minute)},
+		},
+		{
+			name:     "long running trace with root ended is idle",
+			policy:   overrides.TraceCompletion{MaxTraceDuration: model.Duration(time.Hour)},
+			trace:    &trace{firstAppend: now.Add(-time.Minute), lastAppend: now.Add(-time.Minute), rootEnded: true},
+			expected: completionReasonIdle,
+		},
+		{
+			name:     "max duration",
+			policy:   overrides.TraceCompletion{MaxTraceDuration: model.Duration(time.Hour)},
+			trace:    &trace{firstAppend: now.Add(-time.Hour), lastAppend: now},
+			expected: completionReasonMaxDuration,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, completionReason(tc.trace, tc.policy, idle, now))
+		})
+	}
+}
+
+func TestContainsRootSpan(t *testing.T) {
+	trace := test.MakeTrace(1, []byte{0x01})
+	b, err := trace.Marshal()
+	require.NoError(t, err)
+	assert.True(t, containsRootSpan(b))
+
+	for _, batch := range trace.Batches {
+		for _, ils := range batch.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				s.ParentSpanId = []byte{0x01}
+			}
+		}
+	}
+	b, err = trace.Marshal()
+	require.NoError(t, err)
+	assert.False(t, containsRootSpan(b))
+}
+
+func TestInstanceLateTraces(t *testing.T) {
+	instance := defaultInstance(t, t.TempDir())
+
+	limits, err := overrides.NewOverrides(overrides.Limits{
+		TraceCompletion: overrides.TraceCompletion{
+			RootSpanGracePeriod: model.Duration(time.Nanosecond),
+			LateSpanWindow:      model.Duration(time.Hour),
+		},
+	})
+	require.NoError(t, err)
+	instance.limiter = NewLimiter(limits, &ringCountMock{count: 1}, 1)
+
+	getMetric := func() float64 {
+		m := &prom_dto.Metric{}
+		err := metricLateTracesTotal.WithLabelValues(instance.instanceID, completionReasonRootEnded).Write(m)
+		require.NoError(t, err)
+		return m.Counter.GetValue()
+	}
+	before := getMetric()
+
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	require.NoError(t, err)
+	push := func() {
+		trace := test.MakeTrace(1, id)
+		require.NoError(t, instance.PushBytes(context.Background(), id, marshalTrace(t, trace), nil))
+	}
+
+	push()
+	time.Sleep(time.Millisecond)
+
+	// the root span ended, so the trace is cut long before the idle period
+	require.NoError(t, instance.CutCompleteTraces(time.Hour, false))
+	assert.Empty(t, instance.traces)
+	assert.Equal(t, before, getMetric())
+
+	push()
+	assert.Equal(t, before+1, getMetric())
+}
+
+func TestInstancePushBytesRootSpanFlag(t *testing.T) {
+	instance := defaultInstance(t, t.TempDir())
+
+	limits, err := overrides.NewOverrides(overrides.Limits{
+		TraceCompletion: overrides.TraceCompletion{
+			RootSpanGracePeriod: model.Duration(time.Second),
+		},
+	})
+	require.NoError(t, err)
+	instance.limiter = NewLimiter(limits, &ringCountMock{count: 1}, 1)
+
+	// the flag sent by the distributor is trusted, the trace isn't looked at
+	flagged := make([]byte, 16)
+	_, err = rand.Read(flagged)
+	require.NoError(t, err)
+	require.NoError(t, instance.pushBytes(context.Background(), flagged, []byte{0xFF}, nil, true))
+
+	unflagged := make([]byte, 16)
+	_, err = rand.Read(unflagged)
+	require.NoError(t, err)
+	require.NoError(t, instance.pushBytes(context.Background(), unflagged, marshalTrace(t, test.MakeTrace(1, unflagged)), nil, false))
+
+	assert.True(t, instance.traces[instance.tokenForTraceID(flagged)].rootEnded)
+	assert.False(t, instance.traces[instance.tokenForTraceID(unflagged)].rootEnded)
+}
@@ -2,6 +2,7 @@ package ingester
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"time"
 
@@ -24,6 +25,71 @@ type Config struct {
 	MaxBlockBytes        uint64        `yaml:"max_block_bytes"`
 	CompleteBlockTimeout time.Duration `yaml:"complete_block_timeout"`
 	OverrideRingKey      string        `yaml:"override_ring_key"`
+	MaxTransferRetries   int           `yaml:"max_transfer_retries"`
+	FlushOwnedTracesOnly bool          `yaml:"flush_owned_traces_only"`
+	TailBufferSize       int           `yaml:"tail_buffer_size"`
+
+	LiveTracesWAL    LiveTracesWALConfig    `yaml:"live_traces_wal"`
+	LiveTracesMemory LiveTracesMemoryConfig `yaml:"live_traces_memory"`
+}
+
+const (
+	// FsyncPolicyAlways syncs the live traces wal before every push is acknowledged
+	FsyncPolicyAlways = "always"
+	// FsyncPolicyInterval syncs the live traces wal periodically
+	FsyncPolicyInterval = "interval"
+	// FsyncPolicyNever leaves syncing the live traces wal to the OS
+	FsyncPolicyNever = "never"
+)
+
+// LiveTracesWALConfig configures the per tenant log of pushed traces that have not been cut into the head block yet.
+type LiveTracesWALConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	FsyncPolicy     string        `yaml:"fsync_policy"`
+	FsyncInterval   time.Duration `yaml:"fsync_interval"`
+	MaxSegmentBytes int64         `yaml:"max_segment_bytes"`
+}
+
+// Validate checks the live traces wal config
+func (cfg *LiveTracesWALConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	switch cfg.FsyncPolicy {
+	case FsyncPolicyAlways, FsyncPolicyNever:
+	case FsyncPolicyInterval:
+		if cfg.FsyncInterval <= 0 {
+			return fmt.Errorf("live traces wal fsync_interval must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown live traces wal fsync_policy %q", cfg.FsyncPolicy)
+	}
+	return nil
+}
+
+const (
+	// SpillOrderLargest cuts the largest live traces first when a memory budget is exceeded
+	SpillOrderLargest = "largest"
+	// SpillOrderOldest cuts the live traces with the oldest last push first when a memory budget is exceeded
+	SpillOrderOldest = "oldest"
+)
+
+// LiveTracesMemoryConfig bounds the memory used by the live traces of all tenants. Per tenant budgets are set
+// with the max_live_traces_bytes override.
+type LiveTracesMemoryConfig struct {
+	MaxBytes   uint64 `yaml:"max_bytes"`
+	SpillOrder string `yaml:"spill_order"`
+}
+
+// Validate checks the live traces memory config
+func (cfg *LiveTracesMemoryConfig) Validate() error {
+	switch cfg.SpillOrder {
+	case "", SpillOrderLargest, SpillOrderOldest:
+	default:
+		return fmt.Errorf("unknown live traces memory spill_order %q", cfg.SpillOrder)
+	}
+	return nil
 }
 
 // RegisterFlagsAndApplyDefaults registers the flags.
@@ -41,8 +107,19 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 	f.DurationVar(&cfg.MaxTraceIdle, prefix+".trace-idle-period", 10*time.Second, "Duration after which to consider a trace complete if no spans have been received")
 	f.DurationVar(&cfg.MaxBlockDuration, prefix+".max-block-duration", time.Hour, "Maximum duration which the head block can be appended to before cutting it.")
 	f.Uint64Var(&cfg.MaxBlockBytes, prefix+".max-block-bytes", 1024*1024*1024, "Maximum size of the head block before cutting it.")
+	f.IntVar(&cfg.MaxTransferRetries, prefix+".max-transfer-retries", 0, "Number of times to try to transfer live traces and blocks to a joining or peer ingester on shutdown before flushing instead. 0 to disable transfers.")
+	f.BoolVar(&cfg.FlushOwnedTracesOnly, prefix+".flush-owned-traces-only", false, "Only flush the traces this ingester owns in the ring to the backend. Every other replica keeps its copy for durability and reads until the block is completed.")
+	f.IntVar(&cfg.TailBufferSize, prefix+".tail-buffer-size", 100, "Number of matching pushes buffered per live tail subscriber. Further matches are dropped until the subscriber catches up.")
 	f.DurationVar(&cfg.CompleteBlockTimeout, prefix+".complete-block-timeout", 3*tempodb.DefaultBlocklistPoll, "Duration to keep head blocks in the ingester after they have been cut.")
 
+	f.BoolVar(&cfg.LiveTracesWAL.Enabled, prefix+".live-traces-wal.enabled", false, "Write pushed traces to a per tenant log before acknowledging them so they survive a crash.")
+	f.StringVar(&cfg.LiveTracesWAL.FsyncPolicy, prefix+".live-traces-wal.fsync-policy", FsyncPolicyInterval, "When to fsync the live traces log: always, interval or never.")
+	f.DurationVar(&cfg.LiveTracesWAL.FsyncInterval, prefix+".live-traces-wal.fsync-interval", time.Second, "How often to fsync the live traces log with the interval fsync policy.")
+	f.Int64Var(&cfg.LiveTracesWAL.MaxSegmentBytes, prefix+".live-traces-wal.max-segment-bytes", 64*1024*1024, "Size at which a new live traces log segment is started.")
+
+	f.Uint64Var(&cfg.LiveTracesMemory.MaxBytes, prefix+".live-traces-memory.max-bytes", 0, "Maximum size of the live traces of all tenants in bytes. Traces are cut into the head block early once exceeded. 0 to disable.")
+	f.StringVar(&cfg.LiveTracesMemory.SpillOrder, prefix+".live-traces-memory.spill-order", SpillOrderLargest, "Which live traces to cut first when a memory budget is exceeded: largest or oldest.")
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		level.Error(cortex_util.Logger).Log("msg", "failed to get hostname", "err", err)
@@ -0,0 +1,84 @@
+package ingester
+
+import (
+	"context"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/tempodb/encoding/common"
+	"github.com/grafana/tempo/tempodb/wal"
+)
+
+var (
+	metricTracesNotOwnedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_traces_not_owned_total",
+		Help:      "The total number of traces left out of flushed blocks because another replica flushes them per tenant.",
+	}, []string{"tenant"})
+	metricBlocksNotOwnedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_blocks_not_owned_total",
+		Help:      "The total number of blocks that were not flushed because other replicas flush all of their traces per tenant.",
+	}, []string{"tenant"})
+)
+
+// traceOwnership returns a function that returns true if this ingester flushes the trace of the tenant to the
+// backend. nil if every trace is flushed.
+//
+// The owner of a trace is the first healthy ingester of its replication set, so all replicas agree on it as long
+// as they see the same ring. A trace is flushed whenever its owner can't be determined or this ingester is not in
+// the replication set anymore, e.g. because it is leaving, which favours duplicates that are removed by compaction
+// over lost traces.
+func (i *Ingester) traceOwnership(userID string) func(id []byte) bool {
+	if !i.cfg.FlushOwnedTracesOnly || i.ring == nil {
+		return nil
+	}
+
+	return func(id []byte) bool {
+		if i.lifecycler.GetState() != ring.ACTIVE {
+			return true
+		}
+
+		rs, err := i.ring.Get(util.TokenFor(userID, id), ring.Write, nil, nil, nil)
+		if err != nil || len(rs.Instances) == 0 {
+			return true
+		}
+
+		for j, inst := range rs.Instances {
+			if inst.Addr == i.lifecycler.Addr {
+				return j == 0
+			}
+		}
+		return true
+	}
+}
+
+// writeBlock flushes the block to the backend. If the instance only flushes owned traces the others are left out of
+// the backend block, the local block keeps them so they can still be queried.
+func (i *Ingester) writeBlock(ctx context.Context, instance *instance, block *wal.LocalBlock) error {
+	if instance.ownsTrace == nil {
+		return i.store.WriteBlock(ctx, block)
+	}
+
+	notOwned := 0
+	written, err := i.store.WriteBlockWithFilter(ctx, block, func(id common.ID) bool {
+		owned := instance.ownsTrace(id)
+		if !owned {
+			notOwned++
+		}
+		return owned
+	})
+	if err != nil {
+		return err
+	}
+
+	metricTracesNotOwnedTotal.WithLabelValues(instance.instanceID).Add(float64(notOwned))
+	// other replicas flush every trace of the block
+	if !written {
+		metricBlocksNotOwnedTotal.WithLabelValues(instance.instanceID).Inc()
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package ingester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	prom_dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/backend/local"
+	"github.com/grafana/tempo/tempodb/encoding"
+)
+
+// ownerRingMock makes the ingester the owner of every trace for which owned returns true
+type ownerRingMock struct {
+	ring.ReadRing
+	self  ring.InstanceDesc
+	owned func(token uint32) bool
+}
+
+func (r *ownerRingMock) Get(key uint32, _ ring.Operation, _ []ring.InstanceDesc, _, _ []string) (ring.ReplicationSet, error) {
+	other := ring.InstanceDesc{Addr: "other", State: ring.ACTIVE}
+	if r.owned(key) {
+		return ring.ReplicationSet{Instances: []ring.InstanceDesc{r.self, other}}, nil
+	}
+	return ring.ReplicationSet{Instances: []ring.InstanceDesc{other, r.self}}, nil
+}
+
+func TestFlushOwnedTracesOnly(t *testing.T) {
+	tests := []struct {
+		name  string
+		owned func(token uint32) bool
+	}{
+		{
+			name:  "all",
+			owned: func(uint32) bool { return true },
+		},
+		{
+			name:  "some",
+			owned: func(token uint32) bool { return token%2 == 0 },
+		},
+		{
+			name:  "none",
+			owned: func(uint32) bool { return false },
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := defaultIngesterTestConfig()
+			cfg.FlushOwnedTracesOnly = true
+			tmpDir := t.TempDir()
+			i, traces, traceIDs := defaultIngesterWithConfig(t, tmpDir, cfg)
+
+			require.Eventually(t, func() bool {
+				return i.lifecycler.GetState() == ring.ACTIVE
+			}, 5*time.Second, 10*time.Millisecond)
+
+			i.ring = &ownerRingMock{
+				self:  ring.InstanceDesc{Addr: i.lifecycler.Addr, State: ring.ACTIVE},
+				owned: tc.owned,
+			}
+
+			inst, ok := i.getInstanceByID("test")
+			require.True(t, ok)
+			inst.ownsTrace = i.traceOwnership("test")
+
+			var owned [][]byte
+			var ownedTraces []*tempopb.Trace
+			for j, id := range traceIDs {
+				if tc.owned(util.TokenFor("test", id)) {
+					owned = append(owned, id)
+					ownedTraces = append(ownedTraces, traces[j])
+				}
+			}
+
+			getMetric := func() float64 {
+				m := &prom_dto.Metric{}
+				err := metricTracesNotOwnedTotal.WithLabelValues("test").Write(m)
+				require.NoError(t, err)
+				return m.Counter.GetValue()
+			}
+			before := getMetric()
+
+			require.NoError(t, inst.CutCompleteTraces(0, true))
+			blockID, err := inst.CutBlockIfReady(0, 0, true)
+			require.NoError(t, err)
+			require.NoError(t, inst.CompleteBlock(blockID))
+			require.NoError(t, inst.ClearCompletingBlock(blockID))
+
+			// the local block keeps every trace so they can still be queried
+			block := inst.GetBlockToBeFlushed(blockID)
+			require.NotNil(t, block)
+			assert.Equal(t, len(traceIDs), block.BlockMeta().TotalObjects)
+
+			ctx := user.InjectOrgID(context.Background(), "test")
+			for j, id := range traceIDs {
+				foundTrace, err := i.FindTraceByID(ctx, &tempopb.TraceByIDRequest{TraceID: id})
+				require.NoError(t, err)
+				assert.Equal(t, traces[j], foundTrace.Trace)
+			}
+
+			retry, err := i.handleFlush(context.Background(), "test", blockID)
+			require.NoError(t, err)
+			assert.False(t, retry)
+			assert.False(t, block.FlushedTime().IsZero())
+			assert.Equal(t, float64(len(traceIDs)-len(owned)), getMetric()-before)
+
+			// only the owned traces are flushed to the backend
+			r := backend.NewReader(backendRaw(t, tmpDir))
+			meta, err := r.BlockMeta(ctx, blockID, "test")
+			if len(owned) == 0 {
+				assert.Equal(t, backend.ErrDoesNotExist, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(owned), meta.TotalObjects)
+
+			flushed, err := encoding.NewBackendBlock(meta, r)
+			require.NoError(t, err)
+			for j, id := range owned {
+				foundBytes, err := flushed.Find(ctx, id)
+				require.NoError(t, err)
+				foundTrace, err := model.Unmarshal(foundBytes, meta.DataEncoding)
+				require.NoError(t, err)
+				model.SortTrace(foundTrace)
+				assert.Equal(t, ownedTraces[j], foundTrace)
+			}
+		})
+	}
+}
+
+// backendRaw returns the raw reader of the local backend the test ingesters flush to
+func backendRaw(t *testing.T, tmpDir string) backend.RawReader {
+	r, _, _, err := local.New(&local.Config{Path: tmpDir})
+	require.NoError(t, err)
+	return r
+}
+
+func TestTraceOwnershipWhenNotInReplicationSet(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.FlushOwnedTracesOnly = true
+	i, _, traceIDs := defaultIngesterWithConfig(t, t.TempDir(), cfg)
+
+	require.Eventually(t, func() bool {
+		return i.lifecycler.GetState() == ring.ACTIVE
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the ring doesn't know about this ingester, so it flushes everything
+	i.ring = &ownerRingMock{
+		self:  ring.InstanceDesc{Addr: "unknown", State: ring.ACTIVE},
+		owned: func(uint32) bool { return false },
+	}
+	ownsTrace := i.traceOwnership("test")
+	require.NotNil(t, ownsTrace)
+	for _, id := range traceIDs {
+		assert.True(t, ownsTrace(id))
+	}
+
+	cfg.FlushOwnedTracesOnly = false
+	i.cfg = cfg
+	assert.Nil(t, i.traceOwnership("test"))
+}
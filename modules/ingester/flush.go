@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cortexproject/cortex/pkg/util/log"
@@ -83,6 +84,7 @@ func (i *Ingester) ShutdownHandler(w http.ResponseWriter, _ *http.Request) {
 
 		// lifecycler should exit the ring on shutdown
 		i.lifecycler.SetUnregisterOnShutdown(true)
+		i.flushOnShutdown.Store(true)
 
 		// stop accepting new writes
 		i.markUnavailable()
@@ -103,13 +105,6 @@ func (i *Ingester) ShutdownHandler(w http.ResponseWriter, _ *http.Request) {
 	_, _ = w.Write([]byte("shutdown job acknowledged"))
 }
 
-// FlushHandler calls sweepAllInstances(true) which will force push all traces into the WAL and force
-//  mark all head blocks as ready to flush.
-func (i *Ingester) FlushHandler(w http.ResponseWriter, _ *http.Request) {
-	i.sweepAllInstances(true)
-	w.WriteHeader(http.StatusNoContent)
-}
-
 type flushOp struct {
 	kind     int
 	at       time.Time // When to execute
@@ -117,6 +112,13 @@ type flushOp struct {
 	backoff  time.Duration
 	userID   string
 	blockID  uuid.UUID
+
+	// Fields changed while the op is processed are written under mtx so the flush ops API can read them.
+	mtx     sync.Mutex
+	lastErr error
+	dropped bool
+	// Cuts the jitter or backoff short when the op is forced.
+	wake chan struct{}
 }
 
 func (o *flushOp) Key() string {
@@ -186,7 +188,13 @@ func (i *Ingester) flushLoop(j int) {
 			return
 		}
 		op := o.(*flushOp)
+		if op.isDropped() {
+			continue
+		}
+
+		op.mtx.Lock()
 		op.attempts++
+		op.mtx.Unlock()
 
 		var retry bool
 		var err error
@@ -201,15 +209,19 @@ func (i *Ingester) flushLoop(j int) {
 			handleFailedOp(op, err)
 		}
 
-		if retry {
+		if retry && !op.isDropped() {
 			i.requeue(op)
 		} else {
-			i.flushQueues.Clear(op)
+			i.clearOp(op)
 		}
 	}
 }
 
 func handleFailedOp(op *flushOp, err error) {
+	op.mtx.Lock()
+	op.lastErr = err
+	op.mtx.Unlock()
+
 	level.Error(log.WithUserID(op.userID, log.Logger)).Log("msg", "error performing op in flushQueue",
 		"op", op.kind, "block", op.blockID.String(), "attempts", op.attempts, "err", err)
 	metricFailedFlushes.Inc()
@@ -302,7 +314,7 @@ func (i *Ingester) handleFlush(ctx context.Context, userID string, blockID uuid.
 		defer cancel()
 
 		start := time.Now()
-		err = i.store.WriteBlock(ctx, block)
+		err = i.writeBlock(ctx, instance, block)
 		metricFlushDuration.Observe(time.Since(start).Seconds())
 		metricFlushSize.Observe(float64(block.BlockMeta().Size))
 		if err != nil {
@@ -327,19 +339,31 @@ func (i *Ingester) enqueue(op *flushOp, jitter bool) {
 	}
 
 	op.at = time.Now().Add(delay)
+	op.wake = make(chan struct{}, 1)
+
+	// ops are registered right away so they can be listed and waited for while they wait for their jitter
+	if !i.registerOp(op) {
+		return
+	}
 
 	go func() {
-		time.Sleep(delay)
+		op.sleep(delay)
+
+		if op.isDropped() {
+			return
+		}
 
 		// Check if shutdown initiated
 		if i.flushQueues.IsStopped() {
 			handleAbandonedOp(op)
+			i.unregisterOp(op)
 			return
 		}
 
 		err := i.flushQueues.Enqueue(op)
 		if err != nil {
 			handleFailedOp(op, err)
+			i.unregisterOp(op)
 		}
 	}()
 }
@@ -353,23 +377,31 @@ func (i *Ingester) requeue(op *flushOp) {
 		op.backoff = maxBackoff
 	}
 
+	op.mtx.Lock()
 	op.at = time.Now().Add(op.backoff)
+	op.mtx.Unlock()
 
 	level.Info(log.WithUserID(op.userID, log.Logger)).Log("msg", "retrying op in flushQueue",
 		"op", op.kind, "block", op.blockID.String(), "backoff", op.backoff)
 
 	go func() {
-		time.Sleep(op.backoff)
+		op.sleep(op.backoff)
+
+		if op.isDropped() {
+			return
+		}
 
 		// Check if shutdown initiated
 		if i.flushQueues.IsStopped() {
 			handleAbandonedOp(op)
+			i.clearOp(op)
 			return
 		}
 
 		err := i.flushQueues.Requeue(op)
 		if err != nil {
 			handleFailedOp(op, err)
+			i.clearOp(op)
 		}
 	}()
 }
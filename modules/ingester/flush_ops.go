@@ -0,0 +1,326 @@
+package ingester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	flushParamTenant = "tenant"
+	flushParamBlock  = "block"
+	flushParamWait   = "wait"
+
+	flushWaitInterval = 100 * time.Millisecond
+)
+
+var errFlushTargetNotFound = errors.New("tenant or block not found")
+
+// flushOpStatus is a pending or failed op as listed by the flush ops API
+type flushOpStatus struct {
+	Tenant      string    `json:"tenant"`
+	BlockID     string    `json:"blockID"`
+	Kind        string    `json:"kind"`
+	Attempts    uint      `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	NextAttempt time.Time `json:"nextAttempt"`
+}
+
+func opKindName(kind int) string {
+	if kind == opKindComplete {
+		return "complete"
+	}
+	return "flush"
+}
+
+func (o *flushOp) status() flushOpStatus {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	s := flushOpStatus{
+		Tenant:      o.userID,
+		BlockID:     o.blockID.String(),
+		Kind:        opKindName(o.kind),
+		Attempts:    o.attempts,
+		NextAttempt: o.at,
+	}
+	if o.lastErr != nil {
+		s.LastError = o.lastErr.Error()
+	}
+	return s
+}
+
+func (o *flushOp) isDropped() bool {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return o.dropped
+}
+
+// sleep waits for d unless the op is woken up first
+func (o *flushOp) sleep(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-o.wake:
+	}
+}
+
+// wakeUp cuts the jitter or backoff the op is waiting for short. Does nothing if the op is queued or running.
+func (o *flushOp) wakeUp() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// registerOp tracks the op until it is done. Returns false if an op with the same key is tracked already.
+func (i *Ingester) registerOp(op *flushOp) bool {
+	i.flushOpsMtx.Lock()
+	defer i.flushOpsMtx.Unlock()
+
+	if _, ok := i.flushOps[op.Key()]; ok {
+		return false
+	}
+	i.flushOps[op.Key()] = op
+	return true
+}
+
+func (i *Ingester) unregisterOp(op *flushOp) {
+	i.flushOpsMtx.Lock()
+	defer i.flushOpsMtx.Unlock()
+
+	if i.flushOps[op.Key()] == op {
+		delete(i.flushOps, op.Key())
+	}
+}
+
+// clearOp is called once the op is done and unblocks its key
+func (i *Ingester) clearOp(op *flushOp) {
+	i.flushQueues.Clear(op)
+	i.unregisterOp(op)
+}
+
+// dropOp removes the op from the flush queues. An op that is running finishes, but is not retried.
+func (i *Ingester) dropOp(op *flushOp) {
+	op.mtx.Lock()
+	op.dropped = true
+	attempts := op.attempts
+	op.mtx.Unlock()
+
+	i.flushQueues.Remove(op)
+	i.unregisterOp(op)
+	op.wakeUp()
+
+	level.Warn(log.WithUserID(op.userID, log.Logger)).Log("msg", "dropped op from flush queue",
+		"op", op.kind, "block", op.blockID.String(), "attempts", attempts)
+}
+
+// findOps returns the tracked ops of the tenant and block. An empty tenant or nil block matches all.
+func (i *Ingester) findOps(tenant string, blockID uuid.UUID) []*flushOp {
+	i.flushOpsMtx.Lock()
+	defer i.flushOpsMtx.Unlock()
+
+	var ops []*flushOp
+	for _, op := range i.flushOps {
+		if (tenant == "" || op.userID == tenant) && (blockID == uuid.Nil || op.blockID == blockID) {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+const (
+	blockStageUnknown = iota
+	blockStageHead
+	blockStageCompleting
+	blockStageComplete
+	blockStageFlushed
+)
+
+// blockStage returns where the block of the instance is on its way to the backend
+func (i *instance) blockStage(blockID uuid.UUID) int {
+	i.blocksMtx.RLock()
+	defer i.blocksMtx.RUnlock()
+
+	if i.headBlock != nil && i.headBlock.BlockID() == blockID {
+		return blockStageHead
+	}
+	for _, b := range i.completingBlocks {
+		if b.BlockID() == blockID {
+			return blockStageCompleting
+		}
+	}
+	for _, b := range i.completeBlocks {
+		if b.BlockMeta().BlockID == blockID {
+			if b.FlushedTime().IsZero() {
+				return blockStageComplete
+			}
+			return blockStageFlushed
+		}
+	}
+	return blockStageUnknown
+}
+
+// flushBlock moves a single block of the tenant to the backend as soon as possible. The head block is cut,
+// ops waiting for their jitter or backoff are woken up and complete blocks without ops, e.g. because they were
+// dropped, are queued again.
+func (i *Ingester) flushBlock(tenant string, blockID uuid.UUID) error {
+	inst, ok := i.getInstanceByID(tenant)
+	if !ok {
+		return errFlushTargetNotFound
+	}
+
+	if ops := i.findOps(tenant, blockID); len(ops) > 0 {
+		for _, op := range ops {
+			op.wakeUp()
+		}
+		return nil
+	}
+
+	switch inst.blockStage(blockID) {
+	case blockStageHead:
+		i.sweepInstance(inst, true)
+	case blockStageCompleting:
+		i.enqueue(&flushOp{
+			kind:    opKindComplete,
+			userID:  tenant,
+			blockID: blockID,
+		}, false)
+	case blockStageComplete:
+		i.enqueue(&flushOp{
+			kind:    opKindFlush,
+			userID:  tenant,
+			blockID: blockID,
+		}, false)
+	case blockStageFlushed:
+		// nothing to do
+	default:
+		return errFlushTargetNotFound
+	}
+	return nil
+}
+
+// waitForOps blocks until the ops of the tenant and block are done or ctx is done
+func (i *Ingester) waitForOps(ctx context.Context, tenant string, blockID uuid.UUID) error {
+	ticker := time.NewTicker(flushWaitInterval)
+	defer ticker.Stop()
+
+	for len(i.findOps(tenant, blockID)) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// FlushHandler calls sweepAllInstances(true) which will force push all traces into the WAL and force
+//  mark all head blocks as ready to flush. The tenant parameter restricts it to a single tenant, and the block
+//  parameter additionally to a single block of the tenant. With wait=true it only returns once the resulting
+//  flushes are done.
+func (i *Ingester) FlushHandler(w http.ResponseWriter, r *http.Request) {
+	tenant := r.URL.Query().Get(flushParamTenant)
+
+	blockID := uuid.Nil
+	if s := r.URL.Query().Get(flushParamBlock); s != "" {
+		var err error
+		blockID, err = uuid.Parse(s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid block: %v", err), http.StatusBadRequest)
+			return
+		}
+		if tenant == "" {
+			http.Error(w, "block requires tenant", http.StatusBadRequest)
+			return
+		}
+	}
+
+	wait := false
+	if s := r.URL.Query().Get(flushParamWait); s != "" {
+		var err error
+		wait, err = strconv.ParseBool(s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid wait: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch {
+	case blockID != uuid.Nil:
+		if err := i.flushBlock(tenant, blockID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	case tenant != "":
+		inst, ok := i.getInstanceByID(tenant)
+		if !ok {
+			http.Error(w, errFlushTargetNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		i.sweepInstance(inst, true)
+	default:
+		i.sweepAllInstances(true)
+	}
+
+	if wait {
+		if err := i.waitForOps(r.Context(), tenant, blockID); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FlushOpsHandler lists the pending and failed ops of the flush queues as json, optionally of a single tenant.
+func (i *Ingester) FlushOpsHandler(w http.ResponseWriter, r *http.Request) {
+	ops := i.findOps(r.URL.Query().Get(flushParamTenant), uuid.Nil)
+
+	statuses := make([]flushOpStatus, 0, len(ops))
+	for _, op := range ops {
+		statuses = append(statuses, op.status())
+	}
+	sort.Slice(statuses, func(j, k int) bool {
+		return statuses[j].NextAttempt.Before(statuses[k].NextAttempt)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DropFlushOpsHandler drops the ops of a block that keep failing. The block stays in the ingester until it is
+// flushed again with the flush handler or the ingester restarts.
+func (i *Ingester) DropFlushOpsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	blockID, err := uuid.Parse(vars[flushParamBlock])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid block: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	ops := i.findOps(vars[flushParamTenant], blockID)
+	if len(ops) == 0 {
+		http.Error(w, "no flush ops for block", http.StatusNotFound)
+		return
+	}
+
+	for _, op := range ops {
+		i.dropOp(op)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,108 @@
+package ingester
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushOpsHandlerListAndDrop(t *testing.T) {
+	ingester, _, _ := defaultIngester(t, t.TempDir())
+
+	failing := &flushOp{
+		kind:     opKindFlush,
+		userID:   "test",
+		blockID:  uuid.New(),
+		attempts: 3,
+		wake:     make(chan struct{}, 1),
+	}
+	handleFailedOp(failing, errors.New("backend unavailable"))
+	require.True(t, ingester.registerOp(failing))
+
+	pending := &flushOp{
+		kind:    opKindComplete,
+		userID:  "other",
+		blockID: uuid.New(),
+		wake:    make(chan struct{}, 1),
+	}
+	require.True(t, ingester.registerOp(pending))
+	// ops with the same key are only tracked once
+	require.False(t, ingester.registerOp(&flushOp{kind: opKindComplete, userID: "other", blockID: pending.blockID}))
+
+	list := func(query string) []flushOpStatus {
+		w := httptest.NewRecorder()
+		ingester.FlushOpsHandler(w, httptest.NewRequest(http.MethodGet, "/flush/ops"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var statuses []flushOpStatus
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+		return statuses
+	}
+
+	assert.Len(t, list(""), 2)
+
+	statuses := list("?tenant=test")
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "test", statuses[0].Tenant)
+	assert.Equal(t, failing.blockID.String(), statuses[0].BlockID)
+	assert.Equal(t, "flush", statuses[0].Kind)
+	assert.Equal(t, uint(3), statuses[0].Attempts)
+	assert.Equal(t, "backend unavailable", statuses[0].LastError)
+
+	drop := func(tenant string, block string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/flush/ops/"+tenant+"/"+block, nil)
+		r = mux.SetURLVars(r, map[string]string{flushParamTenant: tenant, flushParamBlock: block})
+		ingester.DropFlushOpsHandler(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, drop("test", "not-a-block"))
+	assert.Equal(t, http.StatusNotFound, drop("other", failing.blockID.String()))
+	assert.Equal(t, http.StatusNoContent, drop("test", failing.blockID.String()))
+	assert.True(t, failing.isDropped())
+
+	statuses = list("")
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "other", statuses[0].Tenant)
+}
+
+func TestFlushHandlerWait(t *testing.T) {
+	ingester, _, _ := defaultIngester(t, t.TempDir())
+
+	inst, ok := ingester.getInstanceByID("test")
+	require.True(t, ok)
+
+	w := httptest.NewRecorder()
+	ingester.FlushHandler(w, httptest.NewRequest(http.MethodPost, "/flush?tenant=unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	ingester.FlushHandler(w, httptest.NewRequest(http.MethodPost, "/flush?block="+uuid.New().String(), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// the head block is cut, completed and flushed before the handler returns
+	headBlockID := inst.headBlock.BlockID()
+	w = httptest.NewRecorder()
+	ingester.FlushHandler(w, httptest.NewRequest(http.MethodPost, "/flush?tenant=test&block="+headBlockID.String()+"&wait=true", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Empty(t, ingester.findOps("", uuid.Nil))
+	assert.Equal(t, blockStageFlushed, inst.blockStage(headBlockID))
+
+	// flushed blocks are left alone
+	w = httptest.NewRecorder()
+	ingester.FlushHandler(w, httptest.NewRequest(http.MethodPost, "/flush?tenant=test&block="+headBlockID.String()+"&wait=true", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	ingester.FlushHandler(w, httptest.NewRequest(http.MethodPost, "/flush?tenant=test&block="+uuid.New().String(), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -3,6 +3,7 @@ package ingester
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,15 +18,19 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
 	"google.golang.org/grpc/codes"
 
+	ingester_client "github.com/grafana/tempo/modules/ingester/client"
 	"github.com/grafana/tempo/modules/overrides"
 	"github.com/grafana/tempo/modules/storage"
 	"github.com/grafana/tempo/pkg/flushqueues"
 	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
 	"github.com/grafana/tempo/pkg/validation"
 	"github.com/grafana/tempo/tempodb/backend"
 	"github.com/grafana/tempo/tempodb/backend/local"
+	"github.com/grafana/tempo/tempodb/wal"
 )
 
 // ErrReadOnly is returned when the ingester is shutting down and a push was
@@ -42,33 +47,61 @@ var metricFlushQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
 type Ingester struct {
 	services.Service
 
-	cfg Config
+	cfg       Config
+	clientCfg ingester_client.Config
 
 	instancesMtx sync.RWMutex
 	instances    map[string]*instance
 	readonly     bool
 
 	lifecycler   *ring.Lifecycler
+	ring         ring.ReadRing
 	store        storage.Store
 	local        *local.Backend
 	replayJitter bool // this var exists so tests can remove jitter
 
 	flushQueues     *flushqueues.ExclusiveQueues
 	flushQueuesDone sync.WaitGroup
+	// Ops from the time they are enqueued until they are done, including while they wait for jitter or backoff.
+	flushOpsMtx sync.Mutex
+	flushOps    map[string]*flushOp
 
 	limiter *Limiter
 
+	// Shared by the live traces of all tenants. nil if unbounded.
+	memoryBudget *memoryBudget
+	spillMtx     sync.Mutex
+
+	// Only one transfer is received at a time. What was committed of the received transfers is kept under the same
+	// lock, by transfer id.
+	transferMtx sync.Mutex
+	transfers   map[string]*transferProgress
+	// Set by the shutdown handler, which flushes everything to the backend instead of transferring it.
+	flushOnShutdown atomic.Bool
+
 	subservicesWatcher *services.FailureWatcher
 }
 
 // New makes a new Ingester.
-func New(cfg Config, store storage.Store, limits *overrides.Overrides) (*Ingester, error) {
+func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRing, store storage.Store, limits *overrides.Overrides) (*Ingester, error) {
+	if err := cfg.LiveTracesWAL.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.LiveTracesMemory.Validate(); err != nil {
+		return nil, err
+	}
+
 	i := &Ingester{
 		cfg:          cfg,
+		clientCfg:    clientCfg,
+		ring:         ingestersRing,
 		instances:    map[string]*instance{},
 		store:        store,
 		flushQueues:  flushqueues.New(cfg.ConcurrentFlushes, metricFlushQueueLength),
+		flushOps:     map[string]*flushOp{},
 		replayJitter: true,
+		memoryBudget: newMemoryBudget(cfg.LiveTracesMemory.MaxBytes),
+		transfers:    map[string]*transferProgress{},
 	}
 
 	i.local = store.WAL().LocalBackend()
@@ -87,6 +120,7 @@ func New(cfg Config, store storage.Store, limits *overrides.Overrides) (*Ingeste
 	// Now that the lifecycler has been created, we can create the limiter
 	// which depends on it.
 	i.limiter = NewLimiter(limits, i.lifecycler, cfg.LifecyclerConfig.RingConfig.ReplicationFactor)
+	store.EnableBlockOverrides(i)
 
 	i.subservicesWatcher = services.NewFailureWatcher()
 	i.subservicesWatcher.WatchService(i.lifecycler)
@@ -96,7 +130,7 @@ func New(cfg Config, store storage.Store, limits *overrides.Overrides) (*Ingeste
 }
 
 func (i *Ingester) starting(ctx context.Context) error {
-	err := i.replayWal()
+	blocks, err := i.replayWal()
 	if err != nil {
 		return fmt.Errorf("failed to replay wal %w", err)
 	}
@@ -106,6 +140,12 @@ func (i *Ingester) starting(ctx context.Context) error {
 		return fmt.Errorf("failed to rediscover local blocks %w", err)
 	}
 
+	// the live traces are replayed last, once all of the blocks they could have been cut into are known
+	err = i.replayLiveTraces(blocks)
+	if err != nil {
+		return fmt.Errorf("failed to replay live traces %w", err)
+	}
+
 	// Search data is considered experimental and removed on every startup.
 	i.clearSearchData()
 
@@ -125,11 +165,22 @@ func (i *Ingester) loop(ctx context.Context) error {
 	flushTicker := time.NewTicker(i.cfg.FlushCheckPeriod)
 	defer flushTicker.Stop()
 
+	// nil channel if the live traces wal is not synced periodically
+	var fsyncC <-chan time.Time
+	if i.cfg.LiveTracesWAL.Enabled && i.cfg.LiveTracesWAL.FsyncPolicy == FsyncPolicyInterval {
+		fsyncTicker := time.NewTicker(i.cfg.LiveTracesWAL.FsyncInterval)
+		defer fsyncTicker.Stop()
+		fsyncC = fsyncTicker.C
+	}
+
 	for {
 		select {
 		case <-flushTicker.C:
 			i.sweepAllInstances(false)
 
+		case <-fsyncC:
+			i.syncLiveTracesLogs()
+
 		case <-ctx.Done():
 			return nil
 
@@ -148,9 +199,25 @@ func (i *Ingester) stopping(_ error) error {
 		i.flushQueuesDone.Wait()
 	}
 
+	for _, instance := range i.getInstances() {
+		err := instance.CloseLiveTracesLog()
+		if err != nil {
+			level.Error(log.Logger).Log("msg", "failed to close live traces wal", "tenant", instance.instanceID, "err", err)
+		}
+	}
+
 	return nil
 }
 
+func (i *Ingester) syncLiveTracesLogs() {
+	for _, instance := range i.getInstances() {
+		err := instance.SyncLiveTracesLog()
+		if err != nil {
+			level.Error(log.Logger).Log("msg", "failed to sync live traces wal", "tenant", instance.instanceID, "err", err)
+		}
+	}
+}
+
 func (i *Ingester) markUnavailable() {
 	// Lifecycler can be nil if the ingester is for a flusher.
 	if i.lifecycler != nil {
@@ -181,6 +248,7 @@ func (i *Ingester) Push(ctx context.Context, req *tempopb.PushRequest) (*tempopb
 	}
 
 	err = instance.Push(ctx, req)
+	i.enforceMemoryBudgets(instance)
 	return &tempopb.PushResponse{}, err
 }
 
@@ -218,7 +286,9 @@ func (i *Ingester) PushBytes(ctx context.Context, req *tempopb.PushBytesRequest)
 		}
 	}
 
-	// Unmarshal and push each trace
+	// Unmarshal and push each trace. Traces rejected due to limits are reported individually
+	// so the rest of the request is still accepted.
+	var traceErrors []tempopb.PushTraceError
 	for i := range req.Traces {
 
 		// Search data is optional.
@@ -227,13 +297,39 @@ func (i *Ingester) PushBytes(ctx context.Context, req *tempopb.PushBytesRequest)
 			searchData = req.SearchData[i].Slice
 		}
 
-		err := instance.PushBytes(ctx, req.Ids[i].Slice, req.Traces[i].Slice, searchData)
+		// Root span flags are sent by the distributor, older ones don't so fall back to looking in the trace.
+		var err error
+		if len(req.RootSpans) == len(req.Traces) {
+			err = instance.pushBytes(ctx, req.Ids[i].Slice, req.Traces[i].Slice, searchData, req.RootSpans[i])
+		} else {
+			err = instance.PushBytes(ctx, req.Ids[i].Slice, req.Traces[i].Slice, searchData)
+		}
 		if err != nil {
-			return nil, err
+			reason, ok := pushErrorReason(err)
+			if !ok {
+				return nil, err
+			}
+			traceErrors = append(traceErrors, tempopb.PushTraceError{Index: uint32(i), Reason: reason})
 		}
 	}
 
-	return &tempopb.PushResponse{}, nil
+	i.enforceMemoryBudgets(instance)
+
+	return &tempopb.PushResponse{TraceErrors: traceErrors}, nil
+}
+
+// pushErrorReason returns why a single trace was rejected. Returns false if the error is not specific to the trace.
+func pushErrorReason(err error) (tempopb.PushErrorReason, bool) {
+	s := status.Convert(err)
+	switch {
+	case strings.HasPrefix(s.Message(), overrides.ErrorPrefixLiveTracesExceeded):
+		return tempopb.PushErrorReason_MAX_LIVE_TRACES, true
+	case strings.HasPrefix(s.Message(), overrides.ErrorPrefixTraceTooLarge):
+		return tempopb.PushErrorReason_TRACE_TOO_LARGE, true
+	case strings.HasPrefix(s.Message(), overrides.ErrorPrefixInvalidTraceID):
+		return tempopb.PushErrorReason_INVALID_TRACE_ID, true
+	}
+	return tempopb.PushErrorReason_NO_ERROR, false
 }
 
 // FindTraceByID implements tempopb.Querier.f
@@ -255,7 +351,7 @@ func (i *Ingester) FindTraceByID(ctx context.Context, req *tempopb.TraceByIDRequ
 		return &tempopb.TraceByIDResponse{}, nil
 	}
 
-	trace, err := inst.FindTraceByID(ctx, req.TraceID)
+	trace, err := inst.FindTraceByIDInRange(ctx, req.TraceID, int64(req.Start), int64(req.End))
 	if err != nil {
 		return nil, err
 	}
@@ -267,7 +363,55 @@ func (i *Ingester) FindTraceByID(ctx context.Context, req *tempopb.TraceByIDRequ
 	}, nil
 }
 
+// FindTracesByID looks up many traces of the tenant at once
+func (i *Ingester) FindTracesByID(ctx context.Context, req *tempopb.TracesByIDRequest) (*tempopb.TracesByIDResponse, error) {
+	for _, id := range req.TraceIDs {
+		if !validation.ValidTraceID(id) {
+			return nil, fmt.Errorf("invalid trace id")
+		}
+	}
+
+	// tracing instrumentation
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ingester.FindTracesByID")
+	defer span.Finish()
+
+	instanceID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inst, ok := i.getInstanceByID(instanceID)
+	if !ok || inst == nil {
+		return &tempopb.TracesByIDResponse{}, nil
+	}
+
+	resp := &tempopb.TracesByIDResponse{}
+	for _, id := range req.TraceIDs {
+		trace, err := inst.FindTraceByIDInRange(ctx, id, int64(req.Start), int64(req.End))
+		if err != nil {
+			return nil, err
+		}
+
+		if trace == nil {
+			resp.NotFound = append(resp.NotFound, util.TraceIDToHexString(id))
+			continue
+		}
+		resp.Traces = append(resp.Traces, &tempopb.TraceByIDResult{
+			TraceID: util.TraceIDToHexString(id),
+			Trace:   trace,
+		})
+	}
+
+	span.LogFields(ot_log.Int("traces found", len(resp.Traces)))
+
+	return resp, nil
+}
+
 func (i *Ingester) CheckReady(ctx context.Context) error {
+	// only exceeded while spilling is behind or failing
+	if b := i.memoryBudget; b != nil && b.used.Load() > b.maxBytes {
+		return fmt.Errorf("live traces use %d bytes, exceeding the memory budget of %d bytes", b.used.Load(), b.maxBytes)
+	}
+
 	if err := i.lifecycler.CheckReady(ctx); err != nil {
 		return fmt.Errorf("ingester check ready failed %w", err)
 	}
@@ -290,6 +434,16 @@ func (i *Ingester) getOrCreateInstance(instanceID string) (*instance, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		if i.cfg.LiveTracesWAL.Enabled {
+			l, err := i.store.WAL().NewSegmentLog(instanceID, i.cfg.LiveTracesWAL.MaxSegmentBytes)
+			if err != nil {
+				return nil, err
+			}
+			inst.enableLiveTracesLog(l, i.cfg.LiveTracesWAL.FsyncPolicy)
+		}
+		inst.memoryBudget = i.memoryBudget
+		inst.ownsTrace = i.traceOwnership(instanceID)
 		i.instances[instanceID] = inst
 	}
 	return inst, nil
@@ -322,17 +476,13 @@ func (i *Ingester) stopIncomingRequests() {
 	i.readonly = true
 }
 
-// TransferOut implements ring.Lifecycler.
-func (i *Ingester) TransferOut(ctx context.Context) error {
-	return ring.ErrTransferDisabled
-}
-
-func (i *Ingester) replayWal() error {
+// replayWal adds the wal blocks to the completing blocks. They are completed by replayLiveTraces.
+func (i *Ingester) replayWal() ([]*wal.AppendBlock, error) {
 	level.Info(log.Logger).Log("msg", "beginning wal replay")
 
 	blocks, err := i.store.WAL().RescanBlocks(log.Logger)
 	if err != nil {
-		return fmt.Errorf("fatal error replaying wal %w", err)
+		return nil, fmt.Errorf("fatal error replaying wal %w", err)
 	}
 
 	for _, b := range blocks {
@@ -340,7 +490,7 @@ func (i *Ingester) replayWal() error {
 
 		instance, err := i.getOrCreateInstance(tenantID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Delete anything remaining for the completed version of this
@@ -351,20 +501,54 @@ func (i *Ingester) replayWal() error {
 		// caution and replay the wal block.
 		err = instance.local.ClearBlock(b.Meta().BlockID, tenantID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		instance.AddCompletingBlock(b)
 
+		err = instance.rebuildWalSearchTags(context.Background(), b)
+		if err != nil {
+			level.Warn(log.Logger).Log("msg", "failed to rebuild search tags for wal block", "tenant", tenantID, "block", b.Meta().BlockID.String(), "err", err)
+		}
+	}
+
+	level.Info(log.Logger).Log("msg", "wal replay complete")
+
+	return blocks, nil
+}
+
+// replayLiveTraces replays the live traces wal and then queues the replayed wal blocks to be completed. It must be
+// called after the wal blocks are replayed and the local blocks are rediscovered.
+func (i *Ingester) replayLiveTraces(blocks []*wal.AppendBlock) error {
+	if i.cfg.LiveTracesWAL.Enabled {
+		tenants, err := i.store.WAL().SegmentLogTenants()
+		if err != nil {
+			return fmt.Errorf("fatal error listing live traces wal %w", err)
+		}
+
+		for _, tenantID := range tenants {
+			instance, err := i.getOrCreateInstance(tenantID)
+			if err != nil {
+				return err
+			}
+
+			err = instance.replayLiveTracesLog()
+			if err != nil {
+				return fmt.Errorf("fatal error replaying live traces wal for tenant %s %w", tenantID, err)
+			}
+			level.Info(log.Logger).Log("msg", "replayed live traces", "tenant", tenantID, "traces", instance.traceCount.Load())
+		}
+	}
+
+	// the wal blocks are only completed once the live traces are replayed, which drops the spans found in them
+	for _, b := range blocks {
 		i.enqueue(&flushOp{
 			kind:    opKindComplete,
-			userID:  tenantID,
+			userID:  b.Meta().TenantID,
 			blockID: b.Meta().BlockID,
 		}, i.replayJitter)
 	}
 
-	level.Info(log.Logger).Log("msg", "wal replay complete")
-
 	return nil
 }
 
@@ -404,3 +588,18 @@ func (i *Ingester) rediscoverLocalBlocks() error {
 
 	return nil
 }
+
+// BlockEncodingForTenant implements tempodb.BlockOverrides
+func (i *Ingester) BlockEncodingForTenant(tenantID string) string {
+	return i.limiter.limits.BlockSettings(tenantID).Encoding
+}
+
+// BlockBloomFPForTenant implements tempodb.BlockOverrides
+func (i *Ingester) BlockBloomFPForTenant(tenantID string) float64 {
+	return i.limiter.limits.BlockSettings(tenantID).BloomFP
+}
+
+// BlockIndexPageSizeBytesForTenant implements tempodb.BlockOverrides
+func (i *Ingester) BlockIndexPageSizeBytesForTenant(tenantID string) int {
+	return i.limiter.limits.BlockSettings(tenantID).IndexPageSizeBytes
+}
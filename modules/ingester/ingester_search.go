@@ -42,7 +42,8 @@ func (i *Ingester) SearchTags(ctx context.Context, req *tempopb.SearchTagsReques
 	tags := inst.GetSearchTags()
 
 	resp := &tempopb.SearchTagsResponse{
-		TagNames: tags,
+		TagNames:                tags,
+		HighCardinalityTagNames: inst.GetHighCardinalitySearchTags(),
 	}
 
 	return resp, nil
@@ -58,10 +59,15 @@ func (i *Ingester) SearchTagValues(ctx context.Context, req *tempopb.SearchTagVa
 		return &tempopb.SearchTagValuesResponse{}, nil
 	}
 
+	if req.Filter != nil {
+		return inst.SearchTagValues(ctx, req.TagName, req.Filter), nil
+	}
+
 	vals := inst.GetSearchTagValues(req.TagName)
 
 	resp := &tempopb.SearchTagValuesResponse{
-		TagValues: vals,
+		TagValues:       vals,
+		HighCardinality: inst.IsHighCardinalitySearchTag(req.TagName),
 	}
 
 	return resp, nil
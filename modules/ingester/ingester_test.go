@@ -5,23 +5,28 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/go-kit/kit/log"
 	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/status"
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/kv/consul"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc/codes"
 
+	ingester_client "github.com/grafana/tempo/modules/ingester/client"
 	"github.com/grafana/tempo/modules/overrides"
 	"github.com/grafana/tempo/modules/storage"
 	"github.com/grafana/tempo/pkg/model"
 	"github.com/grafana/tempo/pkg/tempopb"
 	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/util"
 	"github.com/grafana/tempo/pkg/util/test"
 	"github.com/grafana/tempo/tempodb"
 	"github.com/grafana/tempo/tempodb/backend"
@@ -63,6 +68,76 @@ func TestPushQuery(t *testing.T) {
 	}
 }
 
+func TestFindTracesByID(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "test")
+	ingester, traces, traceIDs := defaultIngester(t, t.TempDir())
+
+	missingID := make([]byte, 16)
+	rand.Read(missingID)
+
+	resp, err := ingester.FindTracesByID(ctx, &tempopb.TracesByIDRequest{
+		TraceIDs: append(traceIDs, missingID),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Traces, len(traceIDs))
+	for i, found := range resp.Traces {
+		assert.Equal(t, util.TraceIDToHexString(traceIDs[i]), found.TraceID)
+		assert.True(t, proto.Equal(traces[i], found.Trace))
+	}
+	assert.Equal(t, []string{util.TraceIDToHexString(missingID)}, resp.NotFound)
+
+	_, err = ingester.FindTracesByID(ctx, &tempopb.TracesByIDRequest{
+		TraceIDs: [][]byte{{0x01}},
+	})
+	assert.Error(t, err)
+}
+
+func TestPushBytesRejectsOnlyOffendingTraces(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	ingester, _, _ := defaultIngester(t, tmpDir)
+
+	validID := make([]byte, 16)
+	_, err := rand.Read(validID)
+	require.NoError(t, err)
+	validTrace := test.MakeTrace(10, validID)
+	model.SortTrace(validTrace)
+
+	marshal := func(trace *tempopb.Trace) tempopb.PreallocBytes {
+		b, err := trace.Marshal()
+		require.NoError(t, err)
+		return tempopb.PreallocBytes{Slice: b}
+	}
+
+	resp, err := ingester.PushBytes(ctx, &tempopb.PushBytesRequest{
+		Traces: []tempopb.PreallocBytes{marshal(test.MakeTrace(1, []byte{0x01})), marshal(validTrace)},
+		Ids:    []tempopb.PreallocBytes{{Slice: []byte{0x01}}, {Slice: validID}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []tempopb.PushTraceError{{Index: 0, Reason: tempopb.PushErrorReason_INVALID_TRACE_ID}}, resp.TraceErrors)
+
+	foundTrace, err := ingester.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
+		TraceID: validID,
+	})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(validTrace, foundTrace.Trace))
+}
+
+func TestPushErrorReason(t *testing.T) {
+	reason, ok := pushErrorReason(status.Errorf(codes.InvalidArgument, "%s 01 is not a valid traceid", overrides.ErrorPrefixInvalidTraceID))
+	assert.True(t, ok)
+	assert.Equal(t, tempopb.PushErrorReason_INVALID_TRACE_ID, reason)
+
+	reason, ok = pushErrorReason(status.Errorf(codes.FailedPrecondition, "%s trace too large", overrides.ErrorPrefixTraceTooLarge))
+	assert.True(t, ok)
+	assert.Equal(t, tempopb.PushErrorReason_TRACE_TOO_LARGE, reason)
+
+	// other invalid arguments are not specific to the trace
+	_, ok = pushErrorReason(status.Errorf(codes.InvalidArgument, "failed to unmarshal trace"))
+	assert.False(t, ok)
+}
+
 func TestFullTraceReturned(t *testing.T) {
 	tmpDir, err := ioutil.TempDir("/tmp", "")
 	assert.NoError(t, err, "unexpected error getting tempdir")
@@ -259,6 +334,229 @@ func TestWalReplayDeletesLocalBlocks(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestLiveTracesWal(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := defaultIngesterTestConfig()
+	cfg.LiveTracesWAL = LiveTracesWALConfig{
+		Enabled:     true,
+		FsyncPolicy: FsyncPolicyAlways,
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, traces, traceIDs := defaultIngesterWithConfig(t, tmpDir, cfg)
+
+	// simulate a crash by creating a new ingester without cutting the live traces. this should replay them
+	ingester, _, _ := defaultIngesterWithConfig(t, tmpDir, cfg)
+	inst, ok := ingester.getInstanceByID("test")
+	require.True(t, ok)
+	require.Len(t, inst.traces, 2*len(traceIDs))
+
+	for i, traceID := range traceIDs {
+		foundTrace, err := ingester.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
+			TraceID: traceID,
+		})
+		require.NoError(t, err, "unexpected error querying")
+		require.True(t, proto.Equal(traces[i], foundTrace.Trace))
+	}
+
+	// cutting every live trace into the head block truncates the log
+	err := inst.CutCompleteTraces(0, true)
+	require.NoError(t, err)
+
+	files, err := ioutil.ReadDir(filepath.Join(tmpDir, "live", "test"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	for i, traceID := range traceIDs {
+		foundTrace, err := ingester.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
+			TraceID: traceID,
+		})
+		require.NoError(t, err, "unexpected error querying")
+		require.True(t, proto.Equal(traces[i], foundTrace.Trace))
+	}
+
+	err = ingester.stopping(nil)
+	require.NoError(t, err)
+}
+
+func TestLiveTracesWalReplayDropsCutSpans(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := defaultIngesterTestConfig()
+	cfg.LiveTracesWAL = LiveTracesWALConfig{
+		Enabled:     true,
+		FsyncPolicy: FsyncPolicyAlways,
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	ingester, traces, traceIDs := defaultIngesterWithConfig(t, tmpDir, cfg)
+	inst, ok := ingester.getInstanceByID("test")
+	require.True(t, ok)
+
+	// simulate a crash after the traces are cut into the head block but before the log is truncated
+	logDir := filepath.Join(tmpDir, "live", "test")
+	segments := map[string][]byte{}
+	files, err := ioutil.ReadDir(logDir)
+	require.NoError(t, err)
+	for _, f := range files {
+		segments[f.Name()], err = ioutil.ReadFile(filepath.Join(logDir, f.Name()))
+		require.NoError(t, err)
+	}
+
+	err = inst.CutCompleteTraces(0, true)
+	require.NoError(t, err)
+	for name, b := range segments {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, name), b, 0644))
+	}
+
+	// the head block is replayed as a wal block, so only the traces pushed after the restart are live
+	ingester, _, newTraceIDs := defaultIngesterWithConfig(t, tmpDir, cfg)
+	inst, ok = ingester.getInstanceByID("test")
+	require.True(t, ok)
+	require.Len(t, inst.traces, len(newTraceIDs))
+	for _, traceID := range traceIDs {
+		_, ok := inst.traces[inst.tokenForTraceID(traceID)]
+		require.False(t, ok)
+	}
+
+	for i, traceID := range traceIDs {
+		foundTrace, err := ingester.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
+			TraceID: traceID,
+		})
+		require.NoError(t, err, "unexpected error querying")
+		require.True(t, proto.Equal(traces[i], foundTrace.Trace))
+	}
+
+	err = ingester.stopping(nil)
+	require.NoError(t, err)
+}
+
+func TestLiveTracesWalReplayDropsCompletedSpans(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := defaultIngesterTestConfig()
+	cfg.LiveTracesWAL = LiveTracesWALConfig{
+		Enabled:     true,
+		FsyncPolicy: FsyncPolicyAlways,
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	ingester, traces, traceIDs := defaultIngesterWithConfig(t, tmpDir, cfg)
+	inst, ok := ingester.getInstanceByID("test")
+	require.True(t, ok)
+
+	// simulate segments kept alive by another trace after the traces were completed into a local block
+	logDir := filepath.Join(tmpDir, "live", "test")
+	segments := map[string][]byte{}
+	files, err := ioutil.ReadDir(logDir)
+	require.NoError(t, err)
+	for _, f := range files {
+		segments[f.Name()], err = ioutil.ReadFile(filepath.Join(logDir, f.Name()))
+		require.NoError(t, err)
+	}
+
+	err = inst.CutCompleteTraces(0, true)
+	require.NoError(t, err)
+	blockID, err := inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	require.NoError(t, inst.CompleteBlock(blockID))
+	require.NoError(t, inst.ClearCompletingBlock(blockID))
+	for name, b := range segments {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, name), b, 0644))
+	}
+
+	// the traces are only in the rediscovered local block
+	ingester, _, newTraceIDs := defaultIngesterWithConfig(t, tmpDir, cfg)
+	inst, ok = ingester.getInstanceByID("test")
+	require.True(t, ok)
+	require.Len(t, inst.traces, len(newTraceIDs))
+	require.Len(t, inst.completeBlocks, 1)
+	for _, traceID := range traceIDs {
+		_, ok := inst.traces[inst.tokenForTraceID(traceID)]
+		require.False(t, ok)
+	}
+
+	for i, traceID := range traceIDs {
+		foundTrace, err := ingester.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
+			TraceID: traceID,
+		})
+		require.NoError(t, err, "unexpected error querying")
+		require.True(t, proto.Equal(traces[i], foundTrace.Trace))
+	}
+
+	err = ingester.stopping(nil)
+	require.NoError(t, err)
+}
+
+func TestLiveTracesWalAppendFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := defaultIngesterTestConfig()
+	cfg.LiveTracesWAL = LiveTracesWALConfig{
+		Enabled:     true,
+		FsyncPolicy: FsyncPolicyAlways,
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	ingester, _, _ := defaultIngesterWithConfig(t, tmpDir, cfg)
+	inst, ok := ingester.getInstanceByID("test")
+	require.True(t, ok)
+
+	// the next segment can't be created
+	logDir := filepath.Join(tmpDir, "live", "test")
+	require.NoError(t, inst.CloseLiveTracesLog())
+	require.NoError(t, os.RemoveAll(logDir))
+
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	require.NoError(t, err)
+	trace := test.MakeTrace(10, id)
+	model.SortTrace(trace)
+	traceBytes, err := trace.Marshal()
+	require.NoError(t, err)
+
+	err = inst.PushBytes(ctx, id, traceBytes, nil)
+	require.Error(t, err)
+	live := inst.traces[inst.tokenForTraceID(id)]
+	require.NotNil(t, live)
+	assert.Empty(t, live.traceBytes.Traces)
+	assert.Equal(t, 0, live.currentBytes)
+
+	// the retry of the client adds the spans once
+	require.NoError(t, os.MkdirAll(logDir, 0755))
+	require.NoError(t, inst.PushBytes(ctx, id, traceBytes, nil))
+
+	foundTrace, err := ingester.FindTraceByID(ctx, &tempopb.TraceByIDRequest{TraceID: id})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(trace, foundTrace.Trace))
+}
+
+func TestLiveTracesMemoryBudget(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.LiveTracesMemory.MaxBytes = 1
+	ingester, traces, traceIDs := defaultIngesterWithConfig(t, t.TempDir(), cfg)
+
+	// every push exceeds the budget and is spilled into the head block
+	inst, ok := ingester.getInstanceByID("test")
+	require.True(t, ok)
+	assert.Empty(t, inst.traces)
+	assert.Equal(t, int64(0), ingester.memoryBudget.used.Load())
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for i, traceID := range traceIDs {
+		foundTrace, err := ingester.FindTraceByID(ctx, &tempopb.TraceByIDRequest{TraceID: traceID})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(traces[i], foundTrace.Trace))
+	}
+
+	// readiness fails while the budget is exceeded
+	ingester.memoryBudget.used.Store(2)
+	err := ingester.CheckReady(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "memory budget")
+}
+
 func TestFlush(t *testing.T) {
 	tmpDir, err := ioutil.TempDir("/tmp", "")
 	require.NoError(t, err, "unexpected error getting tempdir")
@@ -294,7 +592,10 @@ func TestFlush(t *testing.T) {
 }
 
 func defaultIngester(t *testing.T, tmpDir string) (*Ingester, []*tempopb.Trace, [][]byte) {
-	ingesterConfig := defaultIngesterTestConfig()
+	return defaultIngesterWithConfig(t, tmpDir, defaultIngesterTestConfig())
+}
+
+func defaultIngesterWithConfig(t *testing.T, tmpDir string, ingesterConfig Config) (*Ingester, []*tempopb.Trace, [][]byte) {
 	limits, err := overrides.NewOverrides(defaultLimitsTestConfig())
 	require.NoError(t, err, "unexpected error creating overrides")
 
@@ -318,7 +619,10 @@ func defaultIngester(t *testing.T, tmpDir string) (*Ingester, []*tempopb.Trace,
 	}, log.NewNopLogger())
 	require.NoError(t, err, "unexpected error store")
 
-	ingester, err := New(ingesterConfig, s, limits)
+	clientCfg := ingester_client.Config{}
+	flagext.DefaultValues(&clientCfg)
+
+	ingester, err := New(ingesterConfig, clientCfg, nil, s, limits)
 	require.NoError(t, err, "unexpected error creating ingester")
 	ingester.replayJitter = false
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"sort"
 	"sync"
 	"time"
 
@@ -61,6 +62,13 @@ type instance struct {
 	tracesMtx  sync.Mutex
 	traces     map[uint32]*trace
 	traceCount atomic.Int32
+	// Traces cut as complete within the late span window, to detect spans that arrive after their trace was cut.
+	recentlyCut map[uint32]cutTrace
+
+	// Size of the live traces and the ingester wide budget they count against. nil if the ingester is unbounded.
+	liveTracesBytes      atomic.Int64
+	liveTracesBytesGauge prometheus.Gauge
+	memoryBudget         *memoryBudget
 
 	blocksMtx        sync.RWMutex
 	headBlock        *wal.AppendBlock
@@ -74,6 +82,19 @@ type instance struct {
 
 	lastBlockCut time.Time
 
+	// Returns true if this ingester flushes the trace to the backend. nil if every trace is flushed. Complete blocks
+	// keep every trace either way so they can still be queried.
+	ownsTrace func(id []byte) bool
+
+	// Live tails receiving matching pushes.
+	tails tailSubscribers
+
+	// Log of pushed traces that have not been cut into the head block yet. nil if disabled.
+	liveTracesLog         *wal.SegmentLog
+	liveTracesFsyncPolicy string
+	// Serializes cutting traces, truncating the live traces log and cutting the head block.
+	cutMtx sync.Mutex
+
 	instanceID         string
 	tracesCreatedTotal prometheus.Counter
 	bytesWrittenTotal  prometheus.Counter
@@ -100,15 +121,17 @@ type searchLocalBlockEntry struct {
 func newInstance(instanceID string, limiter *Limiter, writer tempodb.Writer, l *local.Backend) (*instance, error) {
 	i := &instance{
 		traces:               map[uint32]*trace{},
+		recentlyCut:          map[uint32]cutTrace{},
 		searchAppendBlocks:   map[*wal.AppendBlock]*searchStreamingBlockEntry{},
 		searchCompleteBlocks: map[*wal.LocalBlock]*searchLocalBlockEntry{},
 		searchTagCache:       search.NewTagCache(),
 
-		instanceID:         instanceID,
-		tracesCreatedTotal: metricTracesCreatedTotal.WithLabelValues(instanceID),
-		bytesWrittenTotal:  metricBytesWrittenTotal.WithLabelValues(instanceID),
-		limiter:            limiter,
-		writer:             writer,
+		instanceID:           instanceID,
+		tracesCreatedTotal:   metricTracesCreatedTotal.WithLabelValues(instanceID),
+		bytesWrittenTotal:    metricBytesWrittenTotal.WithLabelValues(instanceID),
+		liveTracesBytesGauge: metricLiveTracesBytes.WithLabelValues(instanceID),
+		limiter:              limiter,
+		writer:               writer,
 
 		local:       l,
 		localReader: backend.NewReader(l),
@@ -116,6 +139,8 @@ func newInstance(instanceID string, limiter *Limiter, writer tempodb.Writer, l *
 
 		hash: fnv.New32(),
 	}
+	i.searchTagCache.SetMaxValuesPerTag(limiter.limits.MaxSearchTagValuesPerTag(instanceID))
+
 	err := i.resetHeadBlock()
 	if err != nil {
 		return nil, err
@@ -132,9 +157,6 @@ func (i *instance) Push(ctx context.Context, req *tempopb.PushRequest) error {
 		return status.Errorf(codes.FailedPrecondition, "%s max live traces per tenant exceeded: %v", overrides.ErrorPrefixLiveTracesExceeded, err)
 	}
 
-	i.tracesMtx.Lock()
-	defer i.tracesMtx.Unlock()
-
 	id, err := pushRequestTraceID(req)
 	if err != nil {
 		return err
@@ -153,14 +175,21 @@ func (i *instance) Push(ctx context.Context, req *tempopb.PushRequest) error {
 		return err
 	}
 
-	trace := i.getOrCreateTrace(id)
-	return trace.Push(ctx, i.instanceID, buffer, nil)
+	return i.pushTrace(ctx, id, buffer, nil, model.ContainsRootSpan(t))
 }
 
-// PushBytes is used to push an unmarshalled tempopb.Trace to the instance
+// PushBytes is used to push an unmarshalled tempopb.Trace to the instance. The trace is only unmarshalled to look
+// for its root span if the completion policy needs it.
 func (i *instance) PushBytes(ctx context.Context, id []byte, traceBytes []byte, searchData []byte) error {
+	rootSpan := needsRootSpan(i.limiter.limits.TraceCompletion(i.instanceID)) && containsRootSpan(traceBytes)
+	return i.pushBytes(ctx, id, traceBytes, searchData, rootSpan)
+}
+
+// pushBytes pushes an unmarshalled tempopb.Trace to the instance. rootSpan is whether the trace contains a span
+// without a parent, as sent by the distributor.
+func (i *instance) pushBytes(ctx context.Context, id []byte, traceBytes []byte, searchData []byte, rootSpan bool) error {
 	if !validation.ValidTraceID(id) {
-		return status.Errorf(codes.InvalidArgument, "%s is not a valid traceid", hex.EncodeToString(id))
+		return status.Errorf(codes.InvalidArgument, "%s %s is not a valid traceid", overrides.ErrorPrefixInvalidTraceID, hex.EncodeToString(id))
 	}
 
 	// check for max traces before grabbing the lock to better load shed
@@ -173,17 +202,72 @@ func (i *instance) PushBytes(ctx context.Context, id []byte, traceBytes []byte,
 		i.RecordSearchLookupValues(searchData)
 	}
 
-	i.tracesMtx.Lock()
-	defer i.tracesMtx.Unlock()
+	err = i.pushTrace(ctx, id, traceBytes, searchData, rootSpan)
+	if err != nil {
+		return err
+	}
+
+	i.tailPush(id, traceBytes, searchData)
+	return nil
+}
 
+// pushTrace appends the trace bytes to the live traces log and the live trace. The push is only acknowledged once
+// it is durable according to the fsync policy. It is written to the log before the live trace is changed, so a push
+// that failed to be logged can be retried without its spans being added twice.
+func (i *instance) pushTrace(ctx context.Context, id []byte, traceBytes []byte, searchData []byte, rootSpan bool) error {
+	rootEnded := rootSpan && needsRootSpan(i.limiter.limits.TraceCompletion(i.instanceID))
+
+	i.tracesMtx.Lock()
 	trace := i.getOrCreateTrace(id)
-	return trace.Push(ctx, i.instanceID, traceBytes, searchData)
+	err := trace.checkSize(len(traceBytes))
+	if err == nil && i.liveTracesLog != nil {
+		var seq uint64
+		seq, err = i.liveTracesLog.Append(id, traceBytes, searchData)
+		if err == nil && trace.firstSegment == 0 {
+			trace.firstSegment = seq
+		}
+	}
+	if err == nil {
+		err = trace.Push(ctx, i.instanceID, traceBytes, searchData)
+	}
+	if err == nil {
+		i.addLiveTracesBytes(int64(len(traceBytes)))
+		trace.rootEnded = trace.rootEnded || rootEnded
+	}
+	i.tracesMtx.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	// sync outside of the lock so concurrent pushes can share an fsync
+	if i.liveTracesLog != nil && i.liveTracesFsyncPolicy == FsyncPolicyAlways {
+		return i.liveTracesLog.Sync()
+	}
+	return nil
 }
 
 // Moves any complete traces out of the map to complete traces
 func (i *instance) CutCompleteTraces(cutoff time.Duration, immediate bool) error {
-	tracesToCut := i.tracesToCut(cutoff, immediate)
+	i.cutMtx.Lock()
+	defer i.cutMtx.Unlock()
+
+	return i.cutTraces(i.tracesToCut(cutoff, immediate))
+}
+
+// SpillLiveTraces cuts live traces into the head block before they are complete, in the given order, until at
+// least bytes were cut. Returns the number of traces and bytes cut.
+func (i *instance) SpillLiveTraces(bytes int64, order string) (int, int64, error) {
+	i.cutMtx.Lock()
+	defer i.cutMtx.Unlock()
+
+	tracesToSpill, spilled := i.tracesToSpill(bytes, order)
+	return len(tracesToSpill), spilled, i.cutTraces(tracesToSpill)
+}
 
+// cutTraces writes traces that were removed from the live traces to the head block. It must be called under the
+// i.cutMtx lock.
+func (i *instance) cutTraces(tracesToCut []*trace) error {
 	for _, t := range tracesToCut {
 		model.SortTraceBytes(t.traceBytes)
 
@@ -203,12 +287,16 @@ func (i *instance) CutCompleteTraces(cutoff time.Duration, immediate bool) error
 		tempopb.ReuseTraceBytes(t.traceBytes)
 	}
 
-	return nil
+	return i.truncateLiveTracesLog()
 }
 
 // CutBlockIfReady cuts a completingBlock from the HeadBlock if ready
 // Returns a bool indicating if a block was cut along with the error (if any).
 func (i *instance) CutBlockIfReady(maxBlockLifetime time.Duration, maxBlockBytes uint64, immediate bool) (uuid.UUID, error) {
+	// the head block can't be cut while traces are cut into it and synced
+	i.cutMtx.Lock()
+	defer i.cutMtx.Unlock()
+
 	i.blocksMtx.Lock()
 	defer i.blocksMtx.Unlock()
 
@@ -366,6 +454,12 @@ func (i *instance) ClearFlushedBlocks(completeBlockTimeout time.Duration) error
 }
 
 func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace, error) {
+	return i.FindTraceByIDInRange(ctx, id, 0, 0)
+}
+
+// FindTraceByIDInRange is FindTraceByID but skips blocks that don't overlap the range given in unix epoch seconds.
+// Live traces are always searched. A start or end of 0 leaves that side of the range unbounded.
+func (i *instance) FindTraceByIDInRange(ctx context.Context, id []byte, start, end int64) (*tempopb.Trace, error) {
 	var err error
 	var allBytes []byte
 
@@ -384,18 +478,23 @@ func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace
 	defer i.blocksMtx.Unlock()
 
 	// headBlock
-	foundBytes, err := i.headBlock.Find(id, model.ObjectCombiner)
-	if err != nil {
-		return nil, fmt.Errorf("headBlock.Find failed: %w", err)
-	}
-	allBytes, _, err = model.CombineTraceBytes(allBytes, foundBytes, model.CurrentEncoding, i.headBlock.Meta().DataEncoding)
-	if err != nil {
-		return nil, fmt.Errorf("post headBlock combine failed: %w", err)
+	if i.headBlock.Meta().OverlapsTimeRange(start, end) {
+		foundBytes, err := i.headBlock.Find(id, model.ObjectCombiner)
+		if err != nil {
+			return nil, fmt.Errorf("headBlock.Find failed: %w", err)
+		}
+		allBytes, _, err = model.CombineTraceBytes(allBytes, foundBytes, model.CurrentEncoding, i.headBlock.Meta().DataEncoding)
+		if err != nil {
+			return nil, fmt.Errorf("post headBlock combine failed: %w", err)
+		}
 	}
 
 	// completingBlock
 	for _, c := range i.completingBlocks {
-		foundBytes, err = c.Find(id, model.ObjectCombiner)
+		if !c.Meta().OverlapsTimeRange(start, end) {
+			continue
+		}
+		foundBytes, err := c.Find(id, model.ObjectCombiner)
 		if err != nil {
 			return nil, fmt.Errorf("completingBlock.Find failed: %w", err)
 		}
@@ -407,7 +506,10 @@ func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace
 
 	// completeBlock
 	for _, c := range i.completeBlocks {
-		foundBytes, err = c.Find(ctx, id)
+		if !c.BlockMeta().OverlapsTimeRange(start, end) {
+			continue
+		}
+		foundBytes, err := c.Find(ctx, id)
 		if err != nil {
 			return nil, fmt.Errorf("completeBlock.Find failed: %w", err)
 		}
@@ -430,6 +532,217 @@ func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace
 	return nil, nil
 }
 
+// enableLiveTracesLog writes every push to the log before it is acknowledged. Must be called before the first push.
+func (i *instance) enableLiveTracesLog(l *wal.SegmentLog, fsyncPolicy string) {
+	i.liveTracesLog = l
+	i.liveTracesFsyncPolicy = fsyncPolicy
+}
+
+// replayLiveTracesLog rebuilds the live traces from the log. Limits are not enforced since the pushes were
+// already acknowledged. It must be called after the wal blocks are replayed and the local blocks are rediscovered:
+// segments are only removed once all of their traces are cut, so a long lived trace can keep spans of other traces
+// in the log long after they were cut into a wal block or completed into a local block. These are dropped so they
+// aren't written to a block twice. Spans of blocks that were flushed and already cleared from the local disk can't be
+// told apart and are replayed.
+func (i *instance) replayLiveTracesLog() error {
+	if i.liveTracesLog == nil {
+		return nil
+	}
+
+	// spans of each replayed trace found in the wal blocks
+	cutSpans := map[string]map[string]struct{}{}
+
+	return i.liveTracesLog.Replay(log.Logger, func(seq uint64, id []byte, object []byte, searchData []byte) error {
+		cut, ok := cutSpans[string(id)]
+		if !ok {
+			var err error
+			cut, err = i.blockSpans(id)
+			if err != nil {
+				return err
+			}
+			cutSpans[string(id)] = cut
+		}
+
+		object, err := dropSpans(object, cut)
+		if err != nil {
+			level.Warn(log.Logger).Log("msg", "failed to replay live trace", "tenant", i.instanceID, "traceID", hex.EncodeToString(id), "err", err)
+			return nil
+		}
+		if object == nil {
+			return nil
+		}
+
+		// trace bytes are returned to the bytepool once cut, so they have to come from it
+		traceBytes := tempopb.SliceFromBytePool(len(object))
+		copy(traceBytes, object)
+
+		if searchData != nil {
+			i.RecordSearchLookupValues(searchData)
+		}
+
+		rootEnded := needsRootSpan(i.limiter.limits.TraceCompletion(i.instanceID)) && containsRootSpan(traceBytes)
+
+		i.tracesMtx.Lock()
+		defer i.tracesMtx.Unlock()
+
+		trace := i.getOrCreateTrace(id)
+		err = trace.Push(context.Background(), i.instanceID, traceBytes, searchData)
+		if err != nil {
+			level.Warn(log.Logger).Log("msg", "failed to replay live trace", "tenant", i.instanceID, "traceID", hex.EncodeToString(id), "err", err)
+			return nil
+		}
+		i.addLiveTracesBytes(int64(len(traceBytes)))
+		trace.rootEnded = trace.rootEnded || rootEnded
+		if trace.firstSegment == 0 {
+			trace.firstSegment = seq
+		}
+		return nil
+	})
+}
+
+// blockSpans returns the ids of the spans of the trace in the completing and complete blocks
+func (i *instance) blockSpans(id []byte) (map[string]struct{}, error) {
+	i.blocksMtx.RLock()
+	defer i.blocksMtx.RUnlock()
+
+	var spans map[string]struct{}
+	addSpans := func(obj []byte, dataEncoding string) error {
+		t, err := model.Unmarshal(obj, dataEncoding)
+		if err != nil {
+			return err
+		}
+		if spans == nil {
+			spans = map[string]struct{}{}
+		}
+		for _, batch := range t.Batches {
+			for _, ils := range batch.InstrumentationLibrarySpans {
+				for _, span := range ils.Spans {
+					spans[string(span.SpanId)] = struct{}{}
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, b := range i.completingBlocks {
+		obj, err := b.Find(id, model.ObjectCombiner)
+		if err != nil {
+			return nil, fmt.Errorf("completingBlock.Find failed: %w", err)
+		}
+		if obj == nil {
+			continue
+		}
+		err = addSpans(obj, b.Meta().DataEncoding)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range i.completeBlocks {
+		obj, err := c.Find(context.Background(), id)
+		if err != nil {
+			return nil, fmt.Errorf("completeBlock.Find failed: %w", err)
+		}
+		if obj == nil {
+			continue
+		}
+		err = addSpans(obj, c.BlockMeta().DataEncoding)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return spans, nil
+}
+
+// dropSpans removes the spans with the given ids from the marshalled trace. It returns nil if no span is left.
+func dropSpans(traceBytes []byte, spanIDs map[string]struct{}) ([]byte, error) {
+	if len(spanIDs) == 0 {
+		return traceBytes, nil
+	}
+
+	t := &tempopb.Trace{}
+	err := t.Unmarshal(traceBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := 0
+	batches := t.Batches[:0]
+	for _, batch := range t.Batches {
+		ilss := batch.InstrumentationLibrarySpans[:0]
+		for _, ils := range batch.InstrumentationLibrarySpans {
+			spans := ils.Spans[:0]
+			for _, span := range ils.Spans {
+				if _, ok := spanIDs[string(span.SpanId)]; !ok {
+					spans = append(spans, span)
+				}
+			}
+			if len(spans) > 0 {
+				ils.Spans = spans
+				ilss = append(ilss, ils)
+				remaining += len(spans)
+			}
+		}
+		if len(ilss) > 0 {
+			batch.InstrumentationLibrarySpans = ilss
+			batches = append(batches, batch)
+		}
+	}
+
+	if remaining == 0 {
+		return nil, nil
+	}
+	t.Batches = batches
+	return t.Marshal()
+}
+
+// SyncLiveTracesLog flushes the live traces log to disk.
+func (i *instance) SyncLiveTracesLog() error {
+	if i.liveTracesLog == nil {
+		return nil
+	}
+	return i.liveTracesLog.Sync()
+}
+
+// CloseLiveTracesLog syncs and closes the live traces log.
+func (i *instance) CloseLiveTracesLog() error {
+	if i.liveTracesLog == nil {
+		return nil
+	}
+	return i.liveTracesLog.Close()
+}
+
+// truncateLiveTracesLog removes the segments that only hold traces that have been cut into the head block.
+// It must be called under the i.cutMtx lock.
+func (i *instance) truncateLiveTracesLog() error {
+	if i.liveTracesLog == nil {
+		return nil
+	}
+
+	// the cut traces must be durable in the head block before they are removed from the log
+	if i.liveTracesFsyncPolicy != FsyncPolicyNever {
+		i.blocksMtx.RLock()
+		err := i.headBlock.Sync()
+		i.blocksMtx.RUnlock()
+		if err != nil {
+			return fmt.Errorf("failed to sync head block: %w", err)
+		}
+	}
+
+	i.tracesMtx.Lock()
+	defer i.tracesMtx.Unlock()
+
+	keepFrom := wal.TruncateAllSegments
+	for _, t := range i.traces {
+		if t.firstSegment != 0 && t.firstSegment < keepFrom {
+			keepFrom = t.firstSegment
+		}
+	}
+
+	return i.liveTracesLog.Truncate(keepFrom)
+}
+
 // AddCompletingBlock adds an AppendBlock directly to the slice of completing blocks.
 // This is used during wal replay. It is expected that calling code will add the appropriate
 // jobs to the queue to eventually flush these.
@@ -453,6 +766,11 @@ func (i *instance) getOrCreateTrace(traceID []byte) *trace {
 	maxSearchBytes := i.limiter.limits.MaxSearchBytesPerTrace(i.instanceID)
 	trace = newTrace(traceID, maxBytes, maxSearchBytes)
 	i.traces[fp] = trace
+
+	if cut, ok := i.recentlyCut[fp]; ok {
+		delete(i.recentlyCut, fp)
+		metricLateTracesTotal.WithLabelValues(i.instanceID, cut.reason).Inc()
+	}
 	i.tracesCreatedTotal.Inc()
 	i.traceCount.Inc()
 
@@ -470,9 +788,21 @@ func (i *instance) tokenForTraceID(id []byte) uint32 {
 func (i *instance) resetHeadBlock() error {
 	oldHeadBlock := i.headBlock
 	var err error
-	newHeadBlock, err := i.writer.WAL().NewBlock(uuid.New(), i.instanceID, model.CurrentEncoding)
-	if err != nil {
-		return err
+	var newHeadBlock *wal.AppendBlock
+	if enc := i.limiter.limits.BlockSettings(i.instanceID).WALEncoding; enc != "" {
+		e, err := backend.ParseEncoding(enc)
+		if err != nil {
+			return errors.Wrap(err, "invalid wal encoding")
+		}
+		newHeadBlock, err = i.writer.WAL().NewBlockWithEncoding(uuid.New(), i.instanceID, model.CurrentEncoding, e)
+		if err != nil {
+			return err
+		}
+	} else {
+		newHeadBlock, err = i.writer.WAL().NewBlock(uuid.New(), i.instanceID, model.CurrentEncoding)
+		if err != nil {
+			return err
+		}
 	}
 
 	i.headBlock = newHeadBlock
@@ -497,24 +827,92 @@ func (i *instance) resetHeadBlock() error {
 	return nil
 }
 
+// tracesToCut removes the traces that are complete according to the completion policy of the tenant. cutoff is
+// the idle period used if the policy has none.
 func (i *instance) tracesToCut(cutoff time.Duration, immediate bool) []*trace {
+	policy := i.limiter.limits.TraceCompletion(i.instanceID)
+	lateSpanWindow := time.Duration(policy.LateSpanWindow)
+	now := time.Now()
+
 	i.tracesMtx.Lock()
 	defer i.tracesMtx.Unlock()
 
-	cutoffTime := time.Now().Add(cutoff)
+	for key, cut := range i.recentlyCut {
+		if now.Sub(cut.at) > lateSpanWindow {
+			delete(i.recentlyCut, key)
+		}
+	}
+
 	tracesToCut := make([]*trace, 0, len(i.traces))
+	reasons := map[string]int{}
 
 	for key, trace := range i.traces {
-		if cutoffTime.After(trace.lastAppend) || immediate {
-			tracesToCut = append(tracesToCut, trace)
-			delete(i.traces, key)
+		reason := completionReasonImmediate
+		if !immediate {
+			reason = completionReason(trace, policy, cutoff, now)
+			if reason == "" {
+				continue
+			}
+			if lateSpanWindow > 0 {
+				i.recentlyCut[key] = cutTrace{at: now, reason: reason}
+			}
 		}
+
+		tracesToCut = append(tracesToCut, trace)
+		delete(i.traces, key)
+		i.addLiveTracesBytes(-int64(trace.currentBytes))
+		reasons[reason]++
 	}
 	i.traceCount.Store(int32(len(i.traces)))
 
+	for reason, count := range reasons {
+		metricTracesCompletedTotal.WithLabelValues(i.instanceID, reason).Add(float64(count))
+	}
+
 	return tracesToCut
 }
 
+// tracesToSpill removes live traces in the given order until at least bytes were removed
+func (i *instance) tracesToSpill(bytes int64, order string) ([]*trace, int64) {
+	i.tracesMtx.Lock()
+	defer i.tracesMtx.Unlock()
+
+	keys := make([]uint32, 0, len(i.traces))
+	for key := range i.traces {
+		keys = append(keys, key)
+	}
+	if order == SpillOrderOldest {
+		sort.Slice(keys, func(a, b int) bool { return i.traces[keys[a]].lastAppend.Before(i.traces[keys[b]].lastAppend) })
+	} else {
+		sort.Slice(keys, func(a, b int) bool { return i.traces[keys[a]].currentBytes > i.traces[keys[b]].currentBytes })
+	}
+
+	var tracesToSpill []*trace
+	var spilled int64
+	for _, key := range keys {
+		if spilled >= bytes {
+			break
+		}
+		trace := i.traces[key]
+		tracesToSpill = append(tracesToSpill, trace)
+		spilled += int64(trace.currentBytes)
+		delete(i.traces, key)
+	}
+	i.traceCount.Store(int32(len(i.traces)))
+	i.addLiveTracesBytes(-spilled)
+
+	return tracesToSpill, spilled
+}
+
+// addLiveTracesBytes accounts for live trace bytes being added or, if negative, removed
+func (i *instance) addLiveTracesBytes(bytes int64) {
+	i.liveTracesBytes.Add(bytes)
+	i.liveTracesBytesGauge.Add(float64(bytes))
+	if i.memoryBudget != nil {
+		i.memoryBudget.used.Add(bytes)
+	}
+}
+
 func (i *instance) writeTraceToHeadBlock(id common.ID, b []byte, searchData [][]byte) error {
 	i.blocksMtx.Lock()
 	defer i.blocksMtx.Unlock()
@@ -595,25 +993,100 @@ func (i *instance) rediscoverLocalBlocks(ctx context.Context) error {
 			return err
 		}
 
-		b, err := encoding.NewBackendBlock(meta, i.localReader)
+		ib, err := i.openLocalBlock(ctx, meta)
 		if err != nil {
 			return err
 		}
 
-		ib, err := wal.NewLocalBlock(ctx, b, i.local)
-		if err != nil {
-			return err
-		}
-
-		//sb := search.OpenBackendSearchBlock(i.local, b.BlockMeta().BlockID, b.BlockMeta().TenantID)
-
 		i.blocksMtx.Lock()
 		i.completeBlocks = append(i.completeBlocks, ib)
-		//i.searchCompleteBlocks[ib] = sb
 		i.blocksMtx.Unlock()
 
+		err = i.rebuildSearchTags(ctx, ib)
+		if err != nil {
+			level.Warn(log.Logger).Log("msg", "failed to reload search data for local block", "tenant", i.instanceID, "block", id.String(), "err", err)
+		}
+
+		err = i.reopenSearchBlock(ctx, ib)
+		if err != nil {
+			level.Warn(log.Logger).Log("msg", "failed to reopen search block for local block", "tenant", i.instanceID, "block", id.String(), "err", err)
+		}
+
 		level.Info(log.Logger).Log("msg", "reloaded local block", "tenantID", i.instanceID, "block", id.String(), "flushed", ib.FlushedTime())
 	}
 
 	return nil
 }
+
+// addLocalBlock adds a block that was written to the local backend by another ingester to the complete blocks.
+// It is expected that calling code will add the appropriate jobs to the queue to eventually flush it.
+func (i *instance) addLocalBlock(ctx context.Context, blockID uuid.UUID) error {
+	meta, err := i.localReader.BlockMeta(ctx, blockID, i.instanceID)
+	if err != nil {
+		return err
+	}
+
+	ib, err := i.openLocalBlock(ctx, meta)
+	if err != nil {
+		return err
+	}
+
+	i.blocksMtx.Lock()
+	i.completeBlocks = append(i.completeBlocks, ib)
+	i.blocksMtx.Unlock()
+
+	return nil
+}
+
+func (i *instance) openLocalBlock(ctx context.Context, meta *backend.BlockMeta) (*wal.LocalBlock, error) {
+	b, err := encoding.NewBackendBlock(meta, i.localReader)
+	if err != nil {
+		return nil, err
+	}
+
+	return wal.NewLocalBlock(ctx, b, i.local)
+}
+
+// hasBlock returns true if the block is a completing or complete block of the instance.
+func (i *instance) hasBlock(blockID uuid.UUID) bool {
+	i.blocksMtx.RLock()
+	defer i.blocksMtx.RUnlock()
+
+	for _, b := range i.completingBlocks {
+		if b.BlockID() == blockID {
+			return true
+		}
+	}
+	for _, b := range i.completeBlocks {
+		if b.BlockMeta().BlockID == blockID {
+			return true
+		}
+	}
+	return false
+}
+
+// clearCompleteBlock removes a complete block from the instance and the local backend.
+func (i *instance) clearCompleteBlock(b *wal.LocalBlock) error {
+	i.blocksMtx.Lock()
+	defer i.blocksMtx.Unlock()
+
+	for idx, c := range i.completeBlocks {
+		if c == b {
+			i.completeBlocks = append(i.completeBlocks[:idx], i.completeBlocks[idx+1:]...)
+			break
+		}
+	}
+
+	searchEntry := i.searchCompleteBlocks[b]
+	if searchEntry != nil {
+		searchEntry.mtx.Lock()
+		defer searchEntry.mtx.Unlock()
+		delete(i.searchCompleteBlocks, b)
+	}
+
+	err := i.local.ClearBlock(b.BlockMeta().BlockID, i.instanceID)
+	if err == nil {
+		metricBlocksClearedTotal.Inc()
+	}
+	return err
+}
@@ -3,26 +3,42 @@ package ingester
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/pkg/model"
 	"github.com/grafana/tempo/pkg/tempofb"
 	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/tempodb/backend"
 	"github.com/grafana/tempo/tempodb/search"
 	"github.com/grafana/tempo/tempodb/wal"
 )
 
+var metricSearchHighCardinalityTags = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "tempo",
+	Name:      "ingester_search_high_cardinality_tags",
+	Help:      "The number of search tags per tenant with more values than are kept for autocomplete.",
+}, []string{"tenant"})
+
 func (i *instance) Search(ctx context.Context, req *tempopb.SearchRequest) (*tempopb.SearchResponse, error) {
 
-	maxResults := 20
+	maxResults := search.DefaultLimit
 	if req.Limit != 0 {
 		maxResults = int(req.Limit)
 	}
+	// sorted searches need every match to find the first ones in the order
+	sorted := req.Sort != tempopb.SearchSort_FIRST_FOUND || req.ContinuationToken != ""
 
 	p := search.NewSearchPipeline(req)
 
 	sr := search.NewResults()
 	defer sr.Close()
+	// the querier rejects searches over the limit, so there is no need to inspect more
+	sr.SetMaxBytesInspected(uint64(i.limiter.limits.MaxBytesPerQuery(i.instanceID)))
 
 	i.searchLiveTraces(ctx, p, sr)
 	i.searchWAL(ctx, p, sr)
@@ -40,7 +56,7 @@ func (i *instance) Search(ctx context.Context, req *tempopb.SearchRequest) (*tem
 			resultsMap[result.TraceID] = result
 		}
 
-		if len(resultsMap) >= maxResults {
+		if !sorted && len(resultsMap) >= maxResults {
 			break
 		}
 	}
@@ -50,13 +66,15 @@ func (i *instance) Search(ctx context.Context, req *tempopb.SearchRequest) (*tem
 		results = append(results, result)
 	}
 
-	// Sort
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].StartTimeUnixNano > results[j].StartTimeUnixNano
-	})
+	// Sort and page
+	results, token, err := search.SortAndPage(req, results, false)
+	if err != nil {
+		return nil, err
+	}
 
 	return &tempopb.SearchResponse{
-		Traces: results,
+		Traces:            results,
+		ContinuationToken: token,
 		Metrics: &tempopb.SearchMetrics{
 			InspectedTraces: sr.TracesInspected(),
 			InspectedBytes:  sr.BytesInspected(),
@@ -90,6 +108,7 @@ func (i *instance) searchLiveTraces(ctx context.Context, p search.Pipeline, sr *
 
 				entry := tempofb.SearchEntryFromBytes(s)
 				if p.Matches(entry) {
+					sr.Match(entry)
 					newResult := search.GetSearchResultFromData(entry)
 					if result != nil {
 						search.CombineSearchResults(result, newResult)
@@ -163,11 +182,133 @@ func (i *instance) GetSearchTagValues(tagName string) []string {
 	return i.searchTagCache.GetValues(tagName)
 }
 
+// SearchTagValues returns the values of the tag in the traces matching the filter, counted by trace. Only the values
+// in the most traces are returned if there are more than are kept for autocomplete, or if the search stopped at
+// max_bytes_per_query, in which case the response is high cardinality.
+func (i *instance) SearchTagValues(ctx context.Context, tagName string, filter *tempopb.SearchRequest) *tempopb.SearchTagValuesResponse {
+	p := search.NewSearchPipeline(filter)
+	values := search.NewTagValues(tagName)
+
+	sr := search.NewResults()
+	defer sr.Close()
+	sr.SetMaxBytesInspected(uint64(i.limiter.limits.MaxBytesPerQuery(i.instanceID)))
+	sr.SetMatchFunc(values.Collect)
+
+	i.searchLiveTraces(ctx, p, sr)
+	i.searchWAL(ctx, p, sr)
+	i.searchLocalBlocks(ctx, p, sr)
+
+	sr.AllWorkersStarted()
+
+	// the values are collected by the workers, the results are only drained
+	for range sr.Results() {
+	}
+
+	counts, dropped := values.Counts(i.limiter.limits.MaxSearchTagValuesPerTag(i.instanceID))
+	resp := &tempopb.SearchTagValuesResponse{
+		TagValues:       make([]string, 0, len(counts)),
+		HighCardinality: dropped || sr.BytesLimitReached(),
+		EstimatedCounts: counts,
+	}
+	for v := range counts {
+		resp.TagValues = append(resp.TagValues, v)
+	}
+	sort.Strings(resp.TagValues)
+
+	return resp
+}
+
+func (i *instance) GetHighCardinalitySearchTags() []string {
+	return i.searchTagCache.GetHighCardinalityNames()
+}
+
+func (i *instance) IsHighCardinalitySearchTag(tagName string) bool {
+	return i.searchTagCache.IsHighCardinality(tagName)
+}
+
 func (i *instance) RecordSearchLookupValues(b []byte) {
 	s := tempofb.SearchEntryFromBytes(b)
 	i.searchTagCache.SetData(time.Now(), s)
 }
 
 func (i *instance) PurgeExpiredSearchTags(before time.Time) {
+	// pick up changes to the overrides
+	i.searchTagCache.SetMaxValuesPerTag(i.limiter.limits.MaxSearchTagValuesPerTag(i.instanceID))
 	i.searchTagCache.PurgeExpired(before)
+
+	metricSearchHighCardinalityTags.WithLabelValues(i.instanceID).Set(float64(len(i.searchTagCache.GetHighCardinalityNames())))
+}
+
+// rebuildSearchTags adds the tags of the search data of a reloaded local block to the tag cache, so autocomplete
+// doesn't start empty after a restart. Blocks without search data are skipped.
+func (i *instance) rebuildSearchTags(ctx context.Context, b *wal.LocalBlock) error {
+	meta := b.BlockMeta()
+
+	_, err := search.ReadSearchBlockMeta(ctx, i.local, meta.BlockID, meta.TenantID)
+	if err == backend.ErrDoesNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sb := search.OpenBackendSearchBlock(i.local, meta.BlockID, meta.TenantID)
+	header, err := sb.Header(ctx)
+	if err != nil {
+		return err
+	}
+	i.searchTagCache.SetHeader(meta.EndTime, header)
+
+	return nil
+}
+
+// reopenSearchBlock makes the search data of a reloaded local block searchable again. Unlike the search data in the
+// wal, which is cleared on startup, it is written next to the local block by CompleteBlock and only removed with the
+// block, so it is still intact. Blocks without search data are skipped.
+func (i *instance) reopenSearchBlock(ctx context.Context, b *wal.LocalBlock) error {
+	meta := b.BlockMeta()
+
+	_, err := search.ReadSearchBlockMeta(ctx, i.local, meta.BlockID, meta.TenantID)
+	if err == backend.ErrDoesNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	i.blocksMtx.Lock()
+	i.searchCompleteBlocks[b] = &searchLocalBlockEntry{
+		b: search.OpenBackendSearchBlock(i.local, meta.BlockID, meta.TenantID),
+	}
+	i.blocksMtx.Unlock()
+
+	return nil
+}
+
+// rebuildWalSearchTags adds the tags of the traces of a replayed wal block to the tag cache. The search data of wal
+// blocks is not replayed, so it is extracted from the traces again.
+func (i *instance) rebuildWalSearchTags(ctx context.Context, b *wal.AppendBlock) error {
+	iter, err := b.GetIterator(model.ObjectCombiner)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for {
+		id, obj, err := iter.Next(ctx)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if id == nil {
+			break
+		}
+
+		t, err := model.Unmarshal(obj, b.Meta().DataEncoding)
+		if err != nil {
+			return err
+		}
+		i.RecordSearchLookupValues(search.ExtractSearchData(t, id))
+	}
+
+	return nil
 }
@@ -329,6 +329,183 @@ func TestWALBlockDeletedDuringSearch(t *testing.T) {
 	time.Sleep(2 * time.Second)
 }
 
+func TestInstanceSearchTagsAfterRestart(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	i, _, _ := defaultIngester(t, tmpDir)
+	inst, ok := i.getInstanceByID("test")
+	require.True(t, ok)
+
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	entry := &tempofb.SearchEntryMutable{}
+	entry.TraceID = id
+	entry.AddTag("foo", "bar")
+	err := inst.PushBytes(context.Background(), id, marshalTrace(t, test.MakeTrace(1, id)), entry.ToBytes())
+	require.NoError(t, err)
+
+	err = inst.CutCompleteTraces(0, true)
+	require.NoError(t, err)
+	blockID, err := inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	err = inst.CompleteBlock(blockID)
+	require.NoError(t, err)
+	err = inst.ClearCompletingBlock(blockID)
+	require.NoError(t, err)
+
+	err = i.stopping(nil)
+	require.NoError(t, err)
+
+	// Simulate a restart by creating a new ingester.
+	i, _, _ = defaultIngester(t, tmpDir)
+	inst, ok = i.getInstanceByID("test")
+	require.True(t, ok)
+
+	assert.Contains(t, inst.GetSearchTags(), "foo")
+	assert.Equal(t, []string{"bar"}, inst.GetSearchTagValues("foo"))
+
+	// the search data of the local block is searchable again
+	sr, err := inst.Search(context.Background(), &tempopb.SearchRequest{
+		Tags: map[string]string{"foo": "bar"},
+	})
+	require.NoError(t, err)
+	require.Len(t, sr.Traces, 1)
+	checkEqual(t, [][]byte{id}, sr)
+
+	err = i.stopping(nil)
+	require.NoError(t, err)
+}
+
+func TestInstanceSearchTagsAfterWalReplay(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	i, _, _ := defaultIngester(t, tmpDir)
+	inst, ok := i.getInstanceByID("test")
+	require.True(t, ok)
+
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	trace := test.MakeTrace(1, id)
+	trace.Batches[0].InstrumentationLibrarySpans[0].Spans[0].Name = "replayed"
+	err := inst.PushBytes(context.Background(), id, marshalTrace(t, trace), nil)
+	require.NoError(t, err)
+
+	// the trace is left in a completing wal block
+	err = inst.CutCompleteTraces(0, true)
+	require.NoError(t, err)
+	_, err = inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+
+	err = i.stopping(nil)
+	require.NoError(t, err)
+
+	// Simulate a restart by creating a new ingester.
+	i, _, _ = defaultIngester(t, tmpDir)
+	inst, ok = i.getInstanceByID("test")
+	require.True(t, ok)
+
+	assert.Contains(t, inst.GetSearchTagValues(search.SpanNameTag), "replayed")
+
+	err = i.stopping(nil)
+	require.NoError(t, err)
+}
+
+func TestInstanceSearchTagsMaxValuesPerTag(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxSearchTagValuesPerTag: 2})
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, &ringCountMock{count: 1}, 1)
+
+	ingester, _, _ := defaultIngester(t, t.TempDir())
+	i, err := newInstance("fake", limiter, ingester.store, ingester.local)
+	require.NoError(t, err)
+
+	for _, v := range []string{"a", "b", "c"} {
+		entry := &tempofb.SearchEntryMutable{}
+		entry.AddTag("foo", v)
+		entry.AddTag("bar", v)
+		i.RecordSearchLookupValues(entry.ToBytes())
+	}
+
+	assert.Len(t, i.GetSearchTagValues("foo"), 2)
+	assert.True(t, i.IsHighCardinalitySearchTag("foo"))
+	assert.Equal(t, []string{"bar", "foo"}, i.GetHighCardinalitySearchTags())
+}
+
+func TestInstanceSearchTagValuesFiltered(t *testing.T) {
+	i := defaultInstance(t, t.TempDir())
+
+	push := func(service, route string, start time.Time) {
+		id := make([]byte, 16)
+		rand.Read(id)
+
+		entry := &tempofb.SearchEntryMutable{}
+		entry.TraceID = id
+		entry.AddTag("service.name", service)
+		entry.AddTag("http.route", route)
+		entry.SetStartTimeUnixNano(uint64(start.UnixNano()))
+		entry.SetEndTimeUnixNano(uint64(start.Add(time.Second).UnixNano()))
+		err := i.PushBytes(context.Background(), id, marshalTrace(t, test.MakeTrace(1, id)), entry.ToBytes())
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	push("checkout", "/cart", now)
+	push("checkout", "/cart", now)
+	push("checkout", "/pay", now.Add(-time.Hour))
+	push("shop", "/home", now)
+
+	check := func() {
+		resp := i.SearchTagValues(context.Background(), "http.route", &tempopb.SearchRequest{
+			Tags: map[string]string{"service.name": "checkout"},
+		})
+		assert.Equal(t, []string{"/cart", "/pay"}, resp.TagValues)
+		assert.Equal(t, map[string]uint32{"/cart": 2, "/pay": 1}, resp.EstimatedCounts)
+		assert.False(t, resp.HighCardinality)
+
+		// recent traces only
+		resp = i.SearchTagValues(context.Background(), "http.route", &tempopb.SearchRequest{
+			Tags:  map[string]string{"service.name": "checkout"},
+			Start: uint32(now.Add(-time.Minute).Unix()),
+		})
+		assert.Equal(t, []string{"/cart"}, resp.TagValues)
+		assert.Equal(t, map[string]uint32{"/cart": 2}, resp.EstimatedCounts)
+	}
+
+	// live traces
+	check()
+
+	// wal
+	err := i.CutCompleteTraces(0, true)
+	require.NoError(t, err)
+	blockID, err := i.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	check()
+
+	// local block
+	err = i.CompleteBlock(blockID)
+	require.NoError(t, err)
+	err = i.ClearCompletingBlock(blockID)
+	require.NoError(t, err)
+	check()
+
+	// the values and counts only cover what the ingester still has, not the blocks flushed to the backend, even if the
+	// time range covers them
+	block := i.GetBlockToBeFlushed(blockID)
+	require.NotNil(t, block)
+	err = i.writer.WriteBlock(context.Background(), block)
+	require.NoError(t, err)
+	err = i.ClearFlushedBlocks(0)
+	require.NoError(t, err)
+	resp := i.SearchTagValues(context.Background(), "http.route", &tempopb.SearchRequest{
+		Tags:  map[string]string{"service.name": "checkout"},
+		Start: uint32(now.Add(-2 * time.Hour).Unix()),
+	})
+	assert.Empty(t, resp.TagValues)
+	assert.Empty(t, resp.EstimatedCounts)
+}
+
 func TestInstanceSearchMetrics(t *testing.T) {
 
 	i := defaultInstance(t, t.TempDir())
@@ -399,6 +576,83 @@ func TestInstanceSearchMetrics(t *testing.T) {
 	require.Equal(t, uint32(2), m.InspectedBlocks) // 1 head block, 1 complete block
 }
 
+func TestInstanceSearchMaxBytesPerQuery(t *testing.T) {
+	i := defaultInstance(t, t.TempDir())
+
+	maxBytes := 1000
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxBytesPerQuery: maxBytes})
+	require.NoError(t, err)
+	i.limiter = NewLimiter(limits, &ringCountMock{count: 1}, 1)
+
+	numTraces := uint32(500)
+	for j := uint32(0); j < numTraces; j++ {
+		id := make([]byte, 16)
+		rand.Read(id)
+
+		data := &tempofb.SearchEntryMutable{}
+		data.TraceID = id
+		data.AddTag("foo", "bar")
+
+		err := i.PushBytes(context.Background(), id, marshalTrace(t, test.MakeTrace(10, id)), data.ToBytes())
+		require.NoError(t, err)
+	}
+
+	sr, err := i.Search(context.Background(), &tempopb.SearchRequest{
+		// Exhaustive search
+		Tags: map[string]string{search.SecretExhaustiveSearchTag: "!"},
+	})
+	require.NoError(t, err)
+
+	// the search stops once over the limit
+	assert.Greater(t, sr.Metrics.InspectedBytes, uint64(maxBytes))
+	assert.Less(t, sr.Metrics.InspectedTraces, numTraces)
+}
+
+func TestInstanceSearchSorted(t *testing.T) {
+	i := defaultInstance(t, t.TempDir())
+
+	// more matches than the default limit, so an unsorted search would stop early
+	numTraces := 50
+	for j := 0; j < numTraces; j++ {
+		id := make([]byte, 16)
+		rand.Read(id)
+
+		data := &tempofb.SearchEntryMutable{}
+		data.TraceID = id
+		data.AddTag("foo", "bar")
+		data.SpanCount = uint32(j)
+
+		err := i.PushBytes(context.Background(), id, marshalTrace(t, test.MakeTrace(10, id)), data.ToBytes())
+		require.NoError(t, err)
+	}
+
+	req := &tempopb.SearchRequest{
+		Tags:  map[string]string{"foo": "bar"},
+		Sort:  tempopb.SearchSort_SPAN_COUNT_DESC,
+		Limit: 20,
+	}
+
+	var spanCounts []uint32
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+
+		sr, err := i.Search(context.Background(), req)
+		require.NoError(t, err)
+		for _, r := range sr.Traces {
+			spanCounts = append(spanCounts, r.SpanCount)
+		}
+		if sr.ContinuationToken == "" {
+			break
+		}
+		req.ContinuationToken = sr.ContinuationToken
+	}
+
+	require.Len(t, spanCounts, numTraces)
+	for j, c := range spanCounts {
+		assert.Equal(t, uint32(numTraces-1-j), c)
+	}
+}
+
 func BenchmarkInstanceSearchUnderLoad(b *testing.B) {
 	ctx := context.TODO()
 	//n := 1_000_000
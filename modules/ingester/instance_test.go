@@ -167,6 +167,62 @@ func queryAll(t *testing.T, i *instance, ids [][]byte, traces []*tempopb.Trace)
 	}
 }
 
+func TestInstanceFindTraceByIDInRange(t *testing.T) {
+	i := defaultInstance(t, t.TempDir())
+
+	pushTrace := func() ([]byte, *tempopb.Trace) {
+		id := make([]byte, 16)
+		rand.Read(id)
+		trace := test.MakeTrace(10, id)
+		model.SortTrace(trace)
+
+		require.NoError(t, i.PushBytes(context.Background(), id, marshalTrace(t, trace), nil))
+		require.NoError(t, i.CutCompleteTraces(0, true))
+		return id, trace
+	}
+
+	// one trace in a complete block and one in the head block
+	completeID, completeTrace := pushTrace()
+	blockID, err := i.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	require.NoError(t, i.CompleteBlock(blockID))
+	require.NoError(t, i.ClearCompletingBlock(blockID))
+	require.Len(t, i.completeBlocks, 1)
+
+	headID, headTrace := pushTrace()
+
+	completeMeta := i.completeBlocks[0].BlockMeta()
+	headMeta := i.headBlock.Meta()
+
+	tests := []struct {
+		name          string
+		id            []byte
+		start, end    int64
+		expectedTrace *tempopb.Trace
+	}{
+		{"complete unbounded", completeID, 0, 0, completeTrace},
+		{"complete in range", completeID, completeMeta.StartTime.Unix(), completeMeta.EndTime.Unix(), completeTrace},
+		{"complete before range", completeID, completeMeta.EndTime.Unix() + 1, 0, nil},
+		{"complete after range", completeID, 0, completeMeta.StartTime.Unix() - 1, nil},
+		{"head unbounded", headID, 0, 0, headTrace},
+		{"head in range", headID, headMeta.StartTime.Unix(), 0, headTrace},
+		{"head after range", headID, 0, headMeta.StartTime.Unix() - 1, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			trace, err := i.FindTraceByIDInRange(context.Background(), tc.id, tc.start, tc.end)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTrace, trace)
+		})
+	}
+
+	// unknown ids are rejected by the bloom filters of the complete block
+	trace, err := i.FindTraceByID(context.Background(), []byte{0x01, 0x02})
+	require.NoError(t, err)
+	assert.Nil(t, trace)
+}
+
 func TestInstanceDoesNotRace(t *testing.T) {
 	limits, err := overrides.NewOverrides(overrides.Limits{})
 	assert.NoError(t, err, "unexpected error creating limits")
@@ -384,11 +440,11 @@ func TestInstanceCutCompleteTraces(t *testing.T) {
 			expectedNotExist: []*trace{pastTrace},
 		},
 		{
-			name:             "cut all time",
-			cutoff:           2 * time.Hour,
-			immediate:        false,
-			input:            []*trace{pastTrace, nowTrace},
-			expectedNotExist: []*trace{pastTrace, nowTrace},
+			name:          "keep traces idle for less than cutoff",
+			cutoff:        2 * time.Hour,
+			immediate:     false,
+			input:         []*trace{pastTrace, nowTrace},
+			expectedExist: []*trace{pastTrace, nowTrace},
 		},
 	}
 
@@ -418,6 +474,61 @@ func TestInstanceCutCompleteTraces(t *testing.T) {
 	}
 }
 
+func TestInstanceSpillLiveTraces(t *testing.T) {
+	tempDir := t.TempDir()
+	instance := defaultInstance(t, tempDir)
+
+	push := func(spans int) []byte {
+		id := make([]byte, 16)
+		_, err := rand.Read(id)
+		require.NoError(t, err)
+
+		trace := test.MakeTrace(spans, id)
+		buffer, err := trace.Marshal()
+		require.NoError(t, err)
+
+		require.NoError(t, instance.PushBytes(context.Background(), id, buffer, nil))
+		return id
+	}
+
+	oldest := push(1)
+	largest := push(10)
+	newest := push(1)
+	instance.traces[instance.tokenForTraceID(oldest)].lastAppend = time.Now().Add(-time.Hour)
+
+	var total int64
+	for _, trace := range instance.traces {
+		total += int64(trace.currentBytes)
+	}
+	assert.Equal(t, total, instance.liveTracesBytes.Load())
+
+	traces, spilled, err := instance.SpillLiveTraces(1, SpillOrderLargest)
+	require.NoError(t, err)
+	assert.Equal(t, 1, traces)
+	assert.NotContains(t, instance.traces, instance.tokenForTraceID(largest))
+	assert.Equal(t, total-spilled, instance.liveTracesBytes.Load())
+
+	traces, _, err = instance.SpillLiveTraces(1, SpillOrderOldest)
+	require.NoError(t, err)
+	assert.Equal(t, 1, traces)
+	assert.NotContains(t, instance.traces, instance.tokenForTraceID(oldest))
+	assert.Contains(t, instance.traces, instance.tokenForTraceID(newest))
+	assert.Equal(t, int32(1), instance.traceCount.Load())
+
+	// spilled traces are in the head block
+	for _, id := range [][]byte{oldest, largest, newest} {
+		trace, err := instance.FindTraceByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.NotNil(t, trace)
+	}
+
+	// everything is spilled if needed
+	_, _, err = instance.SpillLiveTraces(total, SpillOrderLargest)
+	require.NoError(t, err)
+	assert.Empty(t, instance.traces)
+	assert.Equal(t, int64(0), instance.liveTracesBytes.Load())
+}
+
 func TestInstanceCutBlockIfReady(t *testing.T) {
 	tempDir, _ := ioutil.TempDir("/tmp", "")
 	defer os.RemoveAll(tempDir)
@@ -501,6 +612,41 @@ func TestInstanceCutBlockIfReady(t *testing.T) {
 	}
 }
 
+func TestInstanceBlockSettings(t *testing.T) {
+	i := defaultInstance(t, t.TempDir())
+
+	limits, err := overrides.NewOverrides(overrides.Limits{
+		BlockSettings: overrides.BlockSettings{
+			WALEncoding: "snappy",
+			Encoding:    "zstd",
+		},
+	})
+	require.NoError(t, err)
+	i.limiter = NewLimiter(limits, &ringCountMock{count: 1}, 1)
+	i.writer.EnableBlockOverrides(&Ingester{limiter: i.limiter})
+
+	i.blocksMtx.Lock()
+	require.NoError(t, i.resetHeadBlock())
+	i.blocksMtx.Unlock()
+	assert.Equal(t, backend.EncSnappy, i.headBlock.Meta().Encoding)
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	trace := test.MakeTrace(10, id)
+	require.NoError(t, i.PushBytes(context.Background(), id, marshalTrace(t, trace), nil))
+	require.NoError(t, i.CutCompleteTraces(0, true))
+
+	blockID, err := i.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	require.NoError(t, i.CompleteBlock(blockID))
+	require.Len(t, i.completeBlocks, 1)
+	assert.Equal(t, backend.EncZstd, i.completeBlocks[0].BlockMeta().Encoding)
+
+	found, err := i.FindTraceByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.NotNil(t, found)
+}
+
 func defaultInstance(t require.TestingT, tmpDir string) *instance {
 	limits, err := overrides.NewOverrides(overrides.Limits{})
 	assert.NoError(t, err, "unexpected error creating limits")
@@ -587,3 +733,10 @@ func BenchmarkInstanceFindTraceByID(b *testing.B) {
 		assert.NoError(b, err)
 	}
 }
+
+// marshalTrace marshals the trace for a push
+func marshalTrace(t require.TestingT, trace *tempopb.Trace) []byte {
+	b, err := trace.Marshal()
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,113 @@
+package ingester
+
+import (
+	"sort"
+
+	"github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/atomic"
+)
+
+const (
+	budgetTenant = "tenant"
+	budgetGlobal = "global"
+)
+
+var (
+	metricLiveTracesBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "tempo",
+		Name:      "ingester_live_traces_bytes",
+		Help:      "The size of the live traces in bytes per tenant.",
+	}, []string{"tenant"})
+	metricLiveTracesMemoryBudgetBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "tempo",
+		Name:      "ingester_live_traces_memory_budget_bytes",
+		Help:      "The maximum size of the live traces of all tenants in bytes. 0 if unbounded.",
+	})
+	metricTracesSpilledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_traces_spilled_total",
+		Help:      "The total number of live traces cut early because a memory budget was exceeded per tenant and budget.",
+	}, []string{"tenant", "budget"})
+	metricBytesSpilledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_bytes_spilled_total",
+		Help:      "The total bytes of live traces cut early because a memory budget was exceeded per tenant and budget.",
+	}, []string{"tenant", "budget"})
+)
+
+// memoryBudget is the memory shared by the live traces of all tenants
+type memoryBudget struct {
+	maxBytes int64
+	used     atomic.Int64
+}
+
+func newMemoryBudget(maxBytes uint64) *memoryBudget {
+	metricLiveTracesMemoryBudgetBytes.Set(float64(maxBytes))
+	if maxBytes == 0 {
+		return nil
+	}
+	return &memoryBudget{maxBytes: int64(maxBytes)}
+}
+
+// enforceMemoryBudgets spills live traces into the head block until the tenant and the ingester are within
+// their budgets. The tenant budget is enforced first, the ingester budget spills the largest tenants first.
+func (i *Ingester) enforceMemoryBudgets(inst *instance) {
+	tenantMax := int64(i.limiter.limits.MaxLiveTracesBytes(inst.instanceID))
+	overTenant := tenantMax > 0 && inst.liveTracesBytes.Load() > tenantMax
+	overGlobal := i.memoryBudget != nil && i.memoryBudget.used.Load() > i.memoryBudget.maxBytes
+	if !overTenant && !overGlobal {
+		return
+	}
+
+	// concurrent pushes over budget would all spill the same excess
+	i.spillMtx.Lock()
+	defer i.spillMtx.Unlock()
+
+	if tenantMax > 0 {
+		if over := inst.liveTracesBytes.Load() - tenantMax; over > 0 {
+			i.spill(inst, over, budgetTenant)
+		}
+	}
+
+	if i.memoryBudget == nil {
+		return
+	}
+	over := i.memoryBudget.used.Load() - i.memoryBudget.maxBytes
+	if over <= 0 {
+		return
+	}
+
+	type instanceSize struct {
+		inst  *instance
+		bytes int64
+	}
+	instances := i.getInstances()
+	sizes := make([]instanceSize, 0, len(instances))
+	for _, inst := range instances {
+		sizes = append(sizes, instanceSize{inst: inst, bytes: inst.liveTracesBytes.Load()})
+	}
+	sort.Slice(sizes, func(a, b int) bool { return sizes[a].bytes > sizes[b].bytes })
+
+	for _, s := range sizes {
+		if over <= 0 {
+			break
+		}
+		over -= i.spill(s.inst, over, budgetGlobal)
+	}
+}
+
+// spill cuts at least bytes of live traces of the instance into the head block and returns the bytes cut
+func (i *Ingester) spill(inst *instance, bytes int64, budget string) int64 {
+	traces, spilled, err := inst.SpillLiveTraces(bytes, i.cfg.LiveTracesMemory.SpillOrder)
+	if err != nil {
+		level.Error(log.WithUserID(inst.instanceID, log.Logger)).Log("msg", "failed to spill live traces", "budget", budget, "err", err)
+	}
+
+	metricTracesSpilledTotal.WithLabelValues(inst.instanceID, budget).Add(float64(traces))
+	metricBytesSpilledTotal.WithLabelValues(inst.instanceID, budget).Add(float64(spilled))
+
+	return spilled
+}
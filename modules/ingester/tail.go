@@ -0,0 +1,176 @@
+package ingester
+
+import (
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/status"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/tempo/pkg/tempofb"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/tempodb/search"
+)
+
+var (
+	metricTailSubscribers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "tempo",
+		Name:      "ingester_tail_subscribers",
+		Help:      "The number of live tail subscribers per tenant.",
+	}, []string{"tenant"})
+	metricTailDroppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_tail_dropped_total",
+		Help:      "The total number of matching segments dropped because a live tail subscriber fell behind per tenant.",
+	}, []string{"tenant"})
+)
+
+// tailMatch is a pushed segment that matched the filter of a subscriber
+type tailMatch struct {
+	metadata   *tempopb.TraceSearchMetadata
+	traceBytes []byte // copy of the pushed segment, nil if the subscriber doesn't want spans
+}
+
+// tailSubscriber receives the pushed segments matching its pipeline. Matches are dropped and counted instead of
+// blocking pushes when the subscriber falls behind.
+type tailSubscriber struct {
+	pipeline     search.Pipeline
+	includeSpans bool
+	matches      chan tailMatch
+	dropped      atomic.Uint32
+}
+
+// tailSubscribers are the live tails of a tenant
+type tailSubscribers struct {
+	mtx         sync.RWMutex
+	subscribers map[*tailSubscriber]struct{}
+	count       atomic.Int32 // checked on every push before taking the lock
+}
+
+func (i *instance) subscribeTail(req *tempopb.TailRequest, bufferSize int) (*tailSubscriber, error) {
+	searchReq := req.Search
+	if searchReq == nil {
+		searchReq = &tempopb.SearchRequest{}
+	}
+
+	sub := &tailSubscriber{
+		pipeline:     search.NewSearchPipeline(searchReq),
+		includeSpans: req.IncludeSpans,
+		matches:      make(chan tailMatch, bufferSize),
+	}
+
+	i.tails.mtx.Lock()
+	defer i.tails.mtx.Unlock()
+
+	max := i.limiter.limits.MaxTailSubscribers(i.instanceID)
+	if len(i.tails.subscribers) >= max {
+		return nil, status.Errorf(codes.ResourceExhausted, "max tail subscribers per tenant exceeded (%d)", max)
+	}
+
+	if i.tails.subscribers == nil {
+		i.tails.subscribers = map[*tailSubscriber]struct{}{}
+	}
+	i.tails.subscribers[sub] = struct{}{}
+	i.tails.count.Store(int32(len(i.tails.subscribers)))
+	metricTailSubscribers.WithLabelValues(i.instanceID).Set(float64(len(i.tails.subscribers)))
+
+	return sub, nil
+}
+
+func (i *instance) unsubscribeTail(sub *tailSubscriber) {
+	i.tails.mtx.Lock()
+	defer i.tails.mtx.Unlock()
+
+	delete(i.tails.subscribers, sub)
+	i.tails.count.Store(int32(len(i.tails.subscribers)))
+	metricTailSubscribers.WithLabelValues(i.instanceID).Set(float64(len(i.tails.subscribers)))
+}
+
+// tailPush hands a pushed segment to every subscriber whose pipeline matches its search data. Segments without
+// search data can't be matched and are never tailed.
+func (i *instance) tailPush(id []byte, traceBytes []byte, searchData []byte) {
+	if i.tails.count.Load() == 0 || len(searchData) == 0 {
+		return
+	}
+
+	entry := tempofb.SearchEntryFromBytes(searchData)
+
+	i.tails.mtx.RLock()
+	defer i.tails.mtx.RUnlock()
+
+	var metadata *tempopb.TraceSearchMetadata
+	var copied []byte
+	for sub := range i.tails.subscribers {
+		if !sub.pipeline.Matches(entry) {
+			continue
+		}
+
+		if metadata == nil {
+			metadata = search.GetSearchResultFromData(entry)
+			metadata.TraceID = util.TraceIDToHexString(id)
+		}
+
+		match := tailMatch{metadata: metadata}
+		if sub.includeSpans {
+			// pushed bytes are returned to the pool once the trace is cut
+			if copied == nil {
+				copied = append([]byte(nil), traceBytes...)
+			}
+			match.traceBytes = copied
+		}
+
+		select {
+		case sub.matches <- match:
+		default:
+			sub.dropped.Inc()
+			metricTailDroppedTotal.WithLabelValues(i.instanceID).Inc()
+		}
+	}
+}
+
+// Tail implements tempopb.Querier. It streams the segments pushed to this ingester that match the request until
+// the client goes away.
+func (i *Ingester) Tail(req *tempopb.TailRequest, stream tempopb.Querier_TailServer) error {
+	ctx := stream.Context()
+	instanceID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	inst, err := i.getOrCreateInstance(instanceID)
+	if err != nil {
+		return err
+	}
+
+	sub, err := inst.subscribeTail(req, i.cfg.TailBufferSize)
+	if err != nil {
+		return err
+	}
+	defer inst.unsubscribeTail(sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case match := <-sub.matches:
+			resp := &tempopb.TailResponse{
+				Metadata: match.metadata,
+				Dropped:  sub.dropped.Swap(0),
+			}
+			if match.traceBytes != nil {
+				resp.Trace = &tempopb.Trace{}
+				if err := proto.Unmarshal(match.traceBytes, resp.Trace); err != nil {
+					return err
+				}
+			}
+
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package ingester
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gogo/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/tempofb"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+func tailInstance(t *testing.T, maxSubscribers int) *instance {
+	i := defaultInstance(t, t.TempDir())
+
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxTailSubscribers: maxSubscribers})
+	require.NoError(t, err)
+	i.limiter = NewLimiter(limits, &ringCountMock{count: 1}, 1)
+
+	return i
+}
+
+func pushTailed(t *testing.T, i *instance, tagValue string) ([]byte, *tempopb.Trace) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	trace := test.MakeTrace(1, id)
+
+	entry := &tempofb.SearchEntryMutable{}
+	entry.TraceID = id
+	entry.AddTag("foo", tagValue)
+
+	err := i.PushBytes(context.Background(), id, marshalTrace(t, trace), entry.ToBytes())
+	require.NoError(t, err)
+
+	return id, trace
+}
+
+func TestInstanceTail(t *testing.T) {
+	i := tailInstance(t, 2)
+
+	sub, err := i.subscribeTail(&tempopb.TailRequest{
+		Search:       &tempopb.SearchRequest{Tags: map[string]string{"foo": "bar"}},
+		IncludeSpans: true,
+	}, 10)
+	require.NoError(t, err)
+
+	all, err := i.subscribeTail(&tempopb.TailRequest{}, 10)
+	require.NoError(t, err)
+
+	matchingID, _ := pushTailed(t, i, "bar")
+	otherID, _ := pushTailed(t, i, "baz")
+
+	// segments are copied, cutting the traces returns the pushed bytes to the pool
+	require.NoError(t, i.CutCompleteTraces(0, true))
+
+	require.Len(t, sub.matches, 1)
+	match := <-sub.matches
+	assert.Equal(t, util.TraceIDToHexString(matchingID), match.metadata.TraceID)
+	assert.NotNil(t, match.traceBytes)
+
+	require.Len(t, all.matches, 2)
+	match = <-all.matches
+	assert.Equal(t, util.TraceIDToHexString(matchingID), match.metadata.TraceID)
+	assert.Nil(t, match.traceBytes)
+	match = <-all.matches
+	assert.Equal(t, util.TraceIDToHexString(otherID), match.metadata.TraceID)
+
+	// pushes without search data can't be matched
+	id := make([]byte, 16)
+	rand.Read(id)
+	require.NoError(t, i.PushBytes(context.Background(), id, marshalTrace(t, test.MakeTrace(1, id)), nil))
+	assert.Len(t, all.matches, 0)
+
+	// subscribers are limited per tenant
+	_, err = i.subscribeTail(&tempopb.TailRequest{}, 10)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	i.unsubscribeTail(sub)
+	_, err = i.subscribeTail(&tempopb.TailRequest{}, 10)
+	assert.NoError(t, err)
+}
+
+func TestInstanceTailDropsWhenFull(t *testing.T) {
+	i := tailInstance(t, 1)
+
+	sub, err := i.subscribeTail(&tempopb.TailRequest{}, 2)
+	require.NoError(t, err)
+
+	for j := 0; j < 5; j++ {
+		pushTailed(t, i, "bar")
+	}
+
+	assert.Len(t, sub.matches, 2)
+	assert.Equal(t, uint32(3), sub.dropped.Load())
+}
+
+func TestInstanceTailDisabled(t *testing.T) {
+	i := tailInstance(t, 0)
+
+	_, err := i.subscribeTail(&tempopb.TailRequest{}, 10)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+type tailServerMock struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses chan *tempopb.TailResponse
+}
+
+func (s *tailServerMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *tailServerMock) Send(resp *tempopb.TailResponse) error {
+	s.responses <- resp
+	return nil
+}
+
+func TestIngesterTail(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.TailBufferSize = 10
+	ingester, _, _ := defaultIngesterWithConfig(t, t.TempDir(), cfg)
+
+	i, err := ingester.getOrCreateInstance("test")
+	require.NoError(t, err)
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxTailSubscribers: 1})
+	require.NoError(t, err)
+	i.limiter = NewLimiter(limits, &ringCountMock{count: 1}, 1)
+
+	ctx, cancel := context.WithCancel(user.InjectOrgID(context.Background(), "test"))
+	stream := &tailServerMock{
+		ctx:       ctx,
+		responses: make(chan *tempopb.TailResponse, 10),
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- ingester.Tail(&tempopb.TailRequest{IncludeSpans: true}, stream)
+	}()
+
+	require.Eventually(t, func() bool {
+		return i.tails.count.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	id, trace := pushTailed(t, i, "bar")
+
+	select {
+	case resp := <-stream.responses:
+		assert.Equal(t, util.TraceIDToHexString(id), resp.Metadata.TraceID)
+		assert.Equal(t, trace, resp.Trace)
+		assert.Equal(t, uint32(0), resp.Dropped)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tail response")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(0), i.tails.count.Load())
+}
@@ -26,11 +26,18 @@ var (
 
 type trace struct {
 	traceBytes   *tempopb.TraceBytes
+	firstAppend  time.Time
 	lastAppend   time.Time
 	traceID      []byte
 	maxBytes     int
 	currentBytes int
 
+	// set once a push contained the root span. only tracked if the completion policy of the tenant needs it
+	rootEnded bool
+
+	// first segment of the live traces log that holds data of this trace. 0 if the log is disabled
+	firstSegment uint64
+
 	// List of flatbuffers
 	searchData         [][]byte
 	maxSearchBytes     int
@@ -38,11 +45,13 @@ type trace struct {
 }
 
 func newTrace(traceID []byte, maxBytes int, maxSearchBytes int) *trace {
+	now := time.Now()
 	return &trace{
 		traceBytes: &tempopb.TraceBytes{
 			Traces: make([][]byte, 0, 10), // 10 for luck
 		},
-		lastAppend:     time.Now(),
+		firstAppend:    now,
+		lastAppend:     now,
 		traceID:        traceID,
 		maxBytes:       maxBytes,
 		maxSearchBytes: maxSearchBytes,
@@ -51,14 +60,11 @@ func newTrace(traceID []byte, maxBytes int, maxSearchBytes int) *trace {
 
 func (t *trace) Push(_ context.Context, instanceID string, trace []byte, searchData []byte) error {
 	t.lastAppend = time.Now()
-	if t.maxBytes != 0 {
-		reqSize := len(trace)
-		if t.currentBytes+reqSize > t.maxBytes {
-			return status.Errorf(codes.FailedPrecondition, "%s max size of trace (%d) exceeded while adding %d bytes to trace %s", overrides.ErrorPrefixTraceTooLarge, t.maxBytes, reqSize, hex.EncodeToString(t.traceID))
-		}
-
-		t.currentBytes += reqSize
+	reqSize := len(trace)
+	if err := t.checkSize(reqSize); err != nil {
+		return err
 	}
+	t.currentBytes += reqSize
 
 	t.traceBytes.Traces = append(t.traceBytes.Traces, trace)
 
@@ -76,3 +82,11 @@ func (t *trace) Push(_ context.Context, instanceID string, trace []byte, searchD
 
 	return nil
 }
+
+// checkSize returns an error if reqSize more bytes would exceed the max size of the trace
+func (t *trace) checkSize(reqSize int) error {
+	if t.maxBytes != 0 && t.currentBytes+reqSize > t.maxBytes {
+		return status.Errorf(codes.FailedPrecondition, "%s max size of trace (%d) exceeded while adding %d bytes to trace %s", overrides.ErrorPrefixTraceTooLarge, t.maxBytes, reqSize, hex.EncodeToString(t.traceID))
+	}
+	return nil
+}
@@ -0,0 +1,749 @@
+package ingester
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/status"
+	"github.com/google/uuid"
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc/codes"
+
+	ingester_client "github.com/grafana/tempo/modules/ingester/client"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/validation"
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/wal"
+)
+
+const (
+	// transferChunkBytes is the approximate size of a single transfer message
+	transferChunkBytes = 1024 * 1024
+
+	transferDirectionOut = "out"
+	transferDirectionIn  = "in"
+)
+
+var (
+	metricTransfersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_transfers_total",
+		Help:      "The total number of ingester hand-offs by direction and result.",
+	}, []string{"direction", "result"})
+	metricTransferBytesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_transfer_bytes_total",
+		Help:      "The total number of trace and block bytes transferred between ingesters by direction.",
+	}, []string{"direction"})
+)
+
+// TransferOut implements ring.FlushTransferer. It is called by the lifecycler on shutdown and hands the live
+// traces and the blocks that have not been flushed yet to a joining ingester, or to an active peer if no ingester
+// is joining. If the transfer fails the lifecycler flushes instead.
+func (i *Ingester) TransferOut(ctx context.Context) error {
+	if i.cfg.MaxTransferRetries <= 0 || i.flushOnShutdown.Load() {
+		return ring.ErrTransferDisabled
+	}
+
+	// the data is stable once pushes are rejected and the flush loops are done
+	i.stopIncomingRequests()
+	i.flushQueues.Stop()
+	i.flushQueuesDone.Wait()
+
+	retries := backoff.New(ctx, backoff.Config{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		MaxRetries: i.cfg.MaxTransferRetries,
+	})
+
+	// retries keep the id so the receiver can tell if it already received the transfer
+	transferID := uuid.New().String()
+
+	var err error
+	for retries.Ongoing() {
+		err = i.transferOut(ctx, transferID)
+		if err == nil {
+			metricTransfersTotal.WithLabelValues(transferDirectionOut, "success").Inc()
+			return nil
+		}
+
+		level.Error(log.Logger).Log("msg", "transfer failed", "attempt", retries.NumRetries()+1, "err", err)
+		retries.Wait()
+	}
+
+	metricTransfersTotal.WithLabelValues(transferDirectionOut, "failure").Inc()
+	return fmt.Errorf("transfer failed after %d attempts: %w", retries.NumRetries(), err)
+}
+
+func (i *Ingester) transferOut(ctx context.Context, transferID string) error {
+	target, targetID, err := i.findTransferTarget(ctx)
+	if err != nil {
+		return err
+	}
+
+	level.Info(log.Logger).Log("msg", "transferring live traces and blocks", "to", targetID, "addr", target.Addr, "state", target.State, "transferID", transferID)
+
+	c, err := ingester_client.New(target.Addr, i.clientCfg)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// the receiver doesn't require a tenant, but the client middleware does
+	ctx = user.InjectOrgID(ctx, "-1")
+	stream, err := c.Transfer(ctx)
+	if err != nil {
+		return err
+	}
+
+	fromID := i.lifecycler.ID
+	send := func(req *tempopb.TransferRequest) error {
+		req.FromIngesterID = fromID
+		req.TransferID = transferID
+		metricTransferBytesTotal.WithLabelValues(transferDirectionOut).Add(float64(req.Size()))
+		return stream.Send(req)
+	}
+
+	instances := i.getInstances()
+	transferred := make([]*transferredData, 0, len(instances))
+	for _, inst := range instances {
+		data, err := inst.transferOut(ctx, send)
+		if err != nil {
+			return fmt.Errorf("failed to transfer tenant %s: %w", inst.instanceID, err)
+		}
+		transferred = append(transferred, data)
+	}
+
+	_, err = stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	for j, inst := range instances {
+		err = inst.clearTransferred(transferred[j])
+		if err != nil {
+			level.Error(log.Logger).Log("msg", "failed to clear transferred data", "tenant", inst.instanceID, "err", err)
+		}
+	}
+
+	level.Info(log.Logger).Log("msg", "transfer complete", "to", targetID)
+	return nil
+}
+
+// findTransferTarget returns a healthy PENDING ingester, which also takes over the tokens, or else a healthy ACTIVE one.
+func (i *Ingester) findTransferTarget(ctx context.Context) (ring.InstanceDesc, string, error) {
+	desc, err := i.lifecycler.KVStore.Get(ctx, i.lifecycler.RingKey)
+	if err != nil {
+		return ring.InstanceDesc{}, "", err
+	}
+
+	ringDesc, ok := desc.(*ring.Desc)
+	if !ok || ringDesc == nil {
+		return ring.InstanceDesc{}, "", fmt.Errorf("no ring found")
+	}
+
+	now := time.Now()
+	heartbeatTimeout := i.cfg.LifecyclerConfig.RingConfig.HeartbeatTimeout
+	for _, state := range []ring.InstanceState{ring.PENDING, ring.ACTIVE} {
+		for id, ing := range ringDesc.Ingesters {
+			if id == i.lifecycler.ID || ing.State != state || !ing.IsHeartbeatHealthy(heartbeatTimeout, now) {
+				continue
+			}
+			return ing, id, nil
+		}
+	}
+
+	return ring.InstanceDesc{}, "", fmt.Errorf("no pending or active ingester found")
+}
+
+// Transfer implements tempopb.IngesterTransferServer. A PENDING ingester takes over the tokens of the sender once
+// everything is received. An ACTIVE ingester only takes over the data.
+func (i *Ingester) Transfer(stream tempopb.IngesterTransfer_TransferServer) error {
+	i.transferMtx.Lock()
+	defer i.transferMtx.Unlock()
+
+	if i.readonly {
+		return ErrReadOnly
+	}
+
+	ctx := stream.Context()
+
+	state := i.lifecycler.GetState()
+	claimTokens := false
+	switch state {
+	case ring.PENDING:
+		claimTokens = true
+		if err := i.lifecycler.ChangeState(ctx, ring.JOINING); err != nil {
+			return err
+		}
+	case ring.ACTIVE:
+	default:
+		return status.Errorf(codes.FailedPrecondition, "ingester in state %v can't receive a transfer", state)
+	}
+
+	i.expireTransfers(time.Now())
+
+	received, err := i.receiveTransfer(stream)
+	if err != nil {
+		i.dropTransfer(received)
+	} else if progress := i.transfers[received.id]; progress != nil && progress.done {
+		// the sender retried a transfer that was received but not acknowledged
+		level.Info(log.Logger).Log("msg", "dropping transfer that was already received", "from", received.fromID, "transferID", received.id)
+		i.dropTransfer(received)
+	} else {
+		if progress == nil {
+			progress = &transferProgress{traceObjects: map[transferTraceKey]int{}}
+			i.transfers[received.id] = progress
+		}
+		err = i.commitTransfer(ctx, received, progress)
+		progress.done = err == nil
+		progress.updated = time.Now()
+	}
+
+	fromID := received.fromID
+	if err == nil && claimTokens {
+		err = i.lifecycler.ClaimTokensFor(ctx, fromID)
+		if err == nil {
+			err = i.lifecycler.ChangeState(ctx, ring.ACTIVE)
+		}
+	}
+	if err != nil {
+		metricTransfersTotal.WithLabelValues(transferDirectionIn, "failure").Inc()
+		level.Error(log.Logger).Log("msg", "failed to receive transfer", "from", fromID, "transferID", received.id, "err", err)
+
+		if claimTokens {
+			if stateErr := i.lifecycler.ChangeState(context.Background(), ring.PENDING); stateErr != nil {
+				level.Error(log.Logger).Log("msg", "failed to revert to PENDING after a failed transfer", "err", stateErr)
+			}
+		}
+		return err
+	}
+
+	metricTransfersTotal.WithLabelValues(transferDirectionIn, "success").Inc()
+	level.Info(log.Logger).Log("msg", "transfer received", "from", fromID, "claimedTokens", claimTokens)
+
+	return stream.SendAndClose(&tempopb.TransferResponse{})
+}
+
+// transferProgressTTL is how long what was committed of a transfer is kept. It is well past the last retry of the
+// sender.
+const transferProgressTTL = time.Hour
+
+// transferProgress is what was committed of a received transfer, so a retry of the sender after a failure or a lost
+// acknowledgement doesn't add it again.
+type transferProgress struct {
+	// number of objects of each live trace that were pushed. the sender sends the objects of a trace in the same
+	// order every time, but not the traces
+	traceObjects map[transferTraceKey]int
+	done         bool
+	updated      time.Time
+}
+
+type transferTraceKey struct {
+	tenantID string
+	traceID  string
+}
+
+// expireTransfers forgets the transfers that were last committed more than transferProgressTTL ago. It must be
+// called under the i.transferMtx lock.
+func (i *Ingester) expireTransfers(now time.Time) {
+	for id, p := range i.transfers {
+		if now.Sub(p.updated) > transferProgressTTL {
+			delete(i.transfers, id)
+		}
+	}
+}
+
+// receivedTransfer is what has been received of a transfer so far. Nothing is added to the instances until the
+// whole transfer is received, and the received files are removed if it fails, so a retry starts from scratch.
+type receivedTransfer struct {
+	id     string
+	fromID string
+
+	traces      []receivedTrace
+	walBlocks   []receivedWalBlock
+	localBlocks []receivedLocalBlock
+	current     *transferFileWriter
+}
+
+type receivedTrace struct {
+	inst  *instance
+	trace *tempopb.TransferTrace
+}
+
+type receivedWalBlock struct {
+	inst  *instance
+	block *wal.AppendBlock
+}
+
+type receivedLocalBlock struct {
+	inst     *instance
+	blockID  uuid.UUID
+	complete bool // meta was received
+}
+
+// transferFileWriter is the file currently being received
+type transferFileWriter struct {
+	inst    *instance
+	kind    tempopb.TransferFileKind
+	blockID uuid.UUID
+	name    string
+	skip    bool // block already present, e.g. sent by a previous transfer
+
+	walFile *os.File
+	tracker backend.AppendTracker
+}
+
+func (i *Ingester) receiveTransfer(stream tempopb.IngesterTransfer_TransferServer) (*receivedTransfer, error) {
+	ctx := stream.Context()
+
+	received := &receivedTransfer{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return received, err
+		}
+
+		if req.FromIngesterID == "" {
+			return received, fmt.Errorf("transfer request without the id of the sending ingester")
+		}
+		if req.TransferID == "" {
+			return received, fmt.Errorf("transfer request without transfer id")
+		}
+		if received.id != "" && req.TransferID != received.id {
+			return received, fmt.Errorf("transfer request of transfer %s while receiving %s", req.TransferID, received.id)
+		}
+		if req.TenantID == "" {
+			return received, fmt.Errorf("transfer request without tenant")
+		}
+		received.id = req.TransferID
+		received.fromID = req.FromIngesterID
+		metricTransferBytesTotal.WithLabelValues(transferDirectionIn).Add(float64(req.Size()))
+
+		inst, err := i.getOrCreateInstance(req.TenantID)
+		if err != nil {
+			return received, err
+		}
+
+		if req.Trace != nil {
+			if !validation.ValidTraceID(req.Trace.TraceID) {
+				return received, fmt.Errorf("invalid trace id %s", hex.EncodeToString(req.Trace.TraceID))
+			}
+			received.traces = append(received.traces, receivedTrace{inst: inst, trace: req.Trace})
+		}
+
+		if req.File != nil {
+			err = i.receiveFile(ctx, inst, received, req.File)
+			if err != nil {
+				return received, err
+			}
+		}
+	}
+
+	if received.current != nil {
+		return received, fmt.Errorf("transfer ended in the middle of file %s", received.current.name)
+	}
+
+	return received, nil
+}
+
+// receiveFile writes a chunk of a block file. received.current is the file that is still being received, or nil if
+// the chunk was the last one of its file.
+func (i *Ingester) receiveFile(ctx context.Context, inst *instance, received *receivedTransfer, f *tempopb.TransferFile) error {
+	current := received.current
+	if current == nil {
+		blockID, err := uuid.Parse(f.BlockID)
+		if err != nil {
+			return fmt.Errorf("invalid block id %q: %w", f.BlockID, err)
+		}
+		if f.Name == "" || f.Name == "." || f.Name == ".." || filepath.Base(f.Name) != f.Name {
+			return fmt.Errorf("invalid file name %q", f.Name)
+		}
+
+		current = &transferFileWriter{
+			inst:    inst,
+			kind:    f.Kind,
+			blockID: blockID,
+			name:    f.Name,
+			skip:    inst.hasBlock(blockID),
+		}
+
+		if !current.skip && f.Kind == tempopb.TransferFileKind_WAL_BLOCK {
+			current.walFile, err = i.store.WAL().CreateBlockFile(f.Name)
+			if err != nil {
+				return err
+			}
+		}
+		if !current.skip && f.Kind == tempopb.TransferFileKind_LOCAL_BLOCK && received.localBlock(inst, blockID) == nil {
+			received.localBlocks = append(received.localBlocks, receivedLocalBlock{inst: inst, blockID: blockID})
+		}
+		received.current = current
+	} else if current.kind != f.Kind || current.name != f.Name || current.blockID.String() != f.BlockID || current.inst != inst {
+		return fmt.Errorf("received a chunk of %s while receiving %s", f.Name, current.name)
+	}
+
+	var err error
+	switch {
+	case current.skip:
+	case current.kind == tempopb.TransferFileKind_WAL_BLOCK:
+		err = i.receiveWalChunk(received, current, f)
+	case current.kind == tempopb.TransferFileKind_LOCAL_BLOCK:
+		err = i.receiveLocalChunk(ctx, received, current, f)
+	default:
+		err = fmt.Errorf("unknown file kind %v", current.kind)
+	}
+	if err == nil && f.Eof {
+		received.current = nil
+	}
+	return err
+}
+
+func (i *Ingester) receiveWalChunk(received *receivedTransfer, current *transferFileWriter, f *tempopb.TransferFile) error {
+	_, err := current.walFile.Write(f.Data)
+	if err != nil {
+		return err
+	}
+	if !f.Eof {
+		return nil
+	}
+
+	err = current.walFile.Close()
+	if err != nil {
+		return err
+	}
+
+	b, warning, err := i.store.WAL().OpenBlockFile(current.name)
+	if warning != nil {
+		level.Warn(log.Logger).Log("msg", "received wal file with a warning", "file", current.name, "warning", warning)
+	}
+	if err != nil {
+		return err
+	}
+	if b.Meta().TenantID != current.inst.instanceID || b.BlockID() != current.blockID {
+		return fmt.Errorf("wal file %s doesn't belong to tenant %s block %s", current.name, current.inst.instanceID, current.blockID)
+	}
+
+	received.walBlocks = append(received.walBlocks, receivedWalBlock{inst: current.inst, block: b})
+	current.walFile = nil
+	return nil
+}
+
+func (i *Ingester) receiveLocalChunk(ctx context.Context, received *receivedTransfer, current *transferFileWriter, f *tempopb.TransferFile) error {
+	var err error
+	current.tracker, err = i.local.Append(ctx, current.name, backend.KeyPathForBlock(current.blockID, current.inst.instanceID), current.tracker, f.Data)
+	if err != nil {
+		return err
+	}
+	if !f.Eof {
+		return nil
+	}
+
+	err = i.local.CloseAppend(ctx, current.tracker)
+	if err != nil {
+		return err
+	}
+
+	// meta is sent last, the block is complete
+	if current.name == backend.MetaName {
+		received.localBlock(current.inst, current.blockID).complete = true
+	}
+	return nil
+}
+
+func (r *receivedTransfer) localBlock(inst *instance, blockID uuid.UUID) *receivedLocalBlock {
+	for j := range r.localBlocks {
+		if r.localBlocks[j].inst == inst && r.localBlocks[j].blockID == blockID {
+			return &r.localBlocks[j]
+		}
+	}
+	return nil
+}
+
+// commitTransfer adds a fully received transfer to the instances and queues its blocks for completion and flushing.
+// Blocks are added first: a transfer retried after a failure here skips the blocks that were already added. The
+// objects of the live traces that were pushed are recorded in progress and skipped by a retry.
+func (i *Ingester) commitTransfer(ctx context.Context, received *receivedTransfer, progress *transferProgress) error {
+	for _, b := range received.walBlocks {
+		b.inst.AddCompletingBlock(b.block)
+		i.enqueue(&flushOp{
+			kind:    opKindComplete,
+			userID:  b.inst.instanceID,
+			blockID: b.block.BlockID(),
+		}, i.replayJitter)
+	}
+
+	for _, b := range received.localBlocks {
+		if !b.complete {
+			return fmt.Errorf("local block %s was received without meta", b.blockID)
+		}
+		err := b.inst.addLocalBlock(ctx, b.blockID)
+		if err != nil {
+			return err
+		}
+		i.enqueue(&flushOp{
+			kind:    opKindFlush,
+			userID:  b.inst.instanceID,
+			blockID: b.blockID,
+		}, i.replayJitter)
+	}
+
+	for _, t := range received.traces {
+		key := transferTraceKey{tenantID: t.inst.instanceID, traceID: string(t.trace.TraceID)}
+		pushed, err := t.inst.receiveTrace(ctx, t.trace, progress.traceObjects[key])
+		progress.traceObjects[key] = pushed
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropTransfer removes the files of a transfer that failed or was already received.
+func (i *Ingester) dropTransfer(received *receivedTransfer) {
+	if c := received.current; c != nil && c.walFile != nil {
+		_ = c.walFile.Close()
+		if err := os.Remove(c.walFile.Name()); err != nil && !os.IsNotExist(err) {
+			level.Error(log.Logger).Log("msg", "failed to remove partially received wal file", "file", c.name, "err", err)
+		}
+	}
+
+	for _, b := range received.walBlocks {
+		if err := b.block.Clear(); err != nil {
+			level.Error(log.Logger).Log("msg", "failed to remove received wal block", "block", b.block.BlockID(), "err", err)
+		}
+	}
+
+	for _, b := range received.localBlocks {
+		if err := i.local.ClearBlock(b.blockID, b.inst.instanceID); err != nil {
+			level.Error(log.Logger).Log("msg", "failed to remove received local block", "block", b.blockID, "err", err)
+		}
+	}
+}
+
+// transferredData is what an instance sent in a successful transfer and can remove afterwards
+type transferredData struct {
+	completingBlocks []*wal.AppendBlock
+	completeBlocks   []*wal.LocalBlock
+}
+
+// transferOut sends the live traces, the completing blocks and the complete blocks that have not been flushed.
+// The head block is cut first so its wal file doesn't change while it is sent.
+func (i *instance) transferOut(ctx context.Context, send func(*tempopb.TransferRequest) error) (*transferredData, error) {
+	err := i.transferLiveTraces(send)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = i.CutBlockIfReady(0, 0, true)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &transferredData{}
+	i.blocksMtx.RLock()
+	data.completingBlocks = append(data.completingBlocks, i.completingBlocks...)
+	for _, b := range i.completeBlocks {
+		if b.FlushedTime().IsZero() {
+			data.completeBlocks = append(data.completeBlocks, b)
+		}
+	}
+	i.blocksMtx.RUnlock()
+
+	for _, b := range data.completingBlocks {
+		r, err := b.Reader()
+		if err != nil {
+			return nil, err
+		}
+		err = i.transferFile(send, tempopb.TransferFileKind_WAL_BLOCK, b.BlockID(), b.Filename(), r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, b := range data.completeBlocks {
+		blockID := b.BlockMeta().BlockID
+		keypath := backend.KeyPathForBlock(blockID, i.instanceID)
+
+		names, err := i.local.Objects(ctx, keypath)
+		if err != nil {
+			return nil, err
+		}
+
+		// the receiver loads the block once meta is received
+		ordered := make([]string, 0, len(names))
+		for _, name := range names {
+			if name != backend.MetaName {
+				ordered = append(ordered, name)
+			}
+		}
+		ordered = append(ordered, backend.MetaName)
+
+		for _, name := range ordered {
+			r, _, err := i.local.Read(ctx, name, keypath, false)
+			if err != nil {
+				return nil, err
+			}
+			err = i.transferFile(send, tempopb.TransferFileKind_LOCAL_BLOCK, blockID, name, r)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// transferLiveTraces sends the live traces. Traces are split across several messages if needed.
+func (i *instance) transferLiveTraces(send func(*tempopb.TransferRequest) error) error {
+	type liveTrace struct {
+		id         []byte
+		traces     [][]byte
+		searchData [][]byte
+	}
+
+	i.tracesMtx.Lock()
+	snapshot := make([]liveTrace, 0, len(i.traces))
+	for _, t := range i.traces {
+		snapshot = append(snapshot, liveTrace{
+			id:         t.traceID,
+			traces:     append([][]byte(nil), t.traceBytes.Traces...),
+			searchData: append([][]byte(nil), t.searchData...),
+		})
+	}
+	i.tracesMtx.Unlock()
+
+	for _, t := range snapshot {
+		// search data is sent with the trace bytes of the same index so a message never carries more search data
+		// than trace bytes
+		for start := 0; start < len(t.traces); {
+			msg := &tempopb.TransferTrace{TraceID: t.id}
+			size := 0
+			end := start
+			for ; end < len(t.traces) && (end == start || size+len(t.traces[end]) <= transferChunkBytes); end++ {
+				msg.Traces = append(msg.Traces, t.traces[end])
+				size += len(t.traces[end])
+				if end < len(t.searchData) {
+					msg.SearchData = append(msg.SearchData, t.searchData[end])
+					size += len(t.searchData[end])
+				}
+			}
+			start = end
+
+			err := send(&tempopb.TransferRequest{
+				TenantID: i.instanceID,
+				Trace:    msg,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// transferFile sends the contents of r in chunks and closes it
+func (i *instance) transferFile(send func(*tempopb.TransferRequest) error, kind tempopb.TransferFileKind, blockID uuid.UUID, name string, r io.ReadCloser) error {
+	defer r.Close()
+
+	buffer := make([]byte, transferChunkBytes)
+	for {
+		n, err := io.ReadFull(r, buffer)
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return err
+		}
+
+		err = send(&tempopb.TransferRequest{
+			TenantID: i.instanceID,
+			File: &tempopb.TransferFile{
+				Kind:    kind,
+				BlockID: blockID.String(),
+				Name:    name,
+				Data:    buffer[:n],
+				Eof:     eof,
+			},
+		})
+		if err != nil || eof {
+			return err
+		}
+	}
+}
+
+// receiveTrace adds a transferred trace to the live traces. Like replay, limits are not enforced since the pushes
+// were already acknowledged by the sender.
+func (i *instance) receiveTrace(ctx context.Context, t *tempopb.TransferTrace, skip int) (int, error) {
+	for j := skip; j < len(t.Traces); j++ {
+		object := t.Traces[j]
+
+		// trace bytes are returned to the bytepool once cut, so they have to come from it
+		traceBytes := tempopb.SliceFromBytePool(len(object))
+		copy(traceBytes, object)
+
+		var searchData []byte
+		if j < len(t.SearchData) && len(t.SearchData[j]) > 0 {
+			searchData = t.SearchData[j]
+			i.RecordSearchLookupValues(searchData)
+		}
+
+		rootSpan := needsRootSpan(i.limiter.limits.TraceCompletion(i.instanceID)) && containsRootSpan(traceBytes)
+		err := i.pushTrace(ctx, t.TraceID, traceBytes, searchData, rootSpan)
+		if status.Code(err) == codes.FailedPrecondition {
+			level.Warn(log.Logger).Log("msg", "failed to receive live trace", "tenant", i.instanceID, "err", err)
+			continue
+		}
+		if err != nil {
+			return j, err
+		}
+	}
+
+	return len(t.Traces), nil
+}
+
+// clearTransferred removes the data that was transferred. Pushes are rejected at this point so every live trace
+// was transferred.
+func (i *instance) clearTransferred(data *transferredData) error {
+	i.tracesMtx.Lock()
+	i.traces = map[uint32]*trace{}
+	i.traceCount.Store(0)
+	i.addLiveTracesBytes(-i.liveTracesBytes.Load())
+	i.tracesMtx.Unlock()
+
+	if i.liveTracesLog != nil {
+		err := i.liveTracesLog.Truncate(wal.TruncateAllSegments)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, b := range data.completingBlocks {
+		err := i.ClearCompletingBlock(b.BlockID())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, b := range data.completeBlocks {
+		err := i.clearCompleteBlock(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package ingester
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/go-kit/kit/log"
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/grafana/dskit/kv/consul"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+func TestTransferOut(t *testing.T) {
+	kvStore, _ := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	// the receiver stays PENDING until it receives a transfer
+	receiverCfg := defaultIngesterTestConfig()
+	receiverCfg.LifecyclerConfig.RingConfig.KVStore.Mock = kvStore
+	receiverCfg.LifecyclerConfig.ID = "receiver"
+	receiverCfg.LifecyclerConfig.Addr = "127.0.0.1"
+	receiverCfg.LifecyclerConfig.ListenPort = lis.Addr().(*net.TCPAddr).Port
+	receiverCfg.LifecyclerConfig.JoinAfter = time.Hour
+	receiver, receiverTraces, receiverIDs := defaultIngesterWithConfig(t, t.TempDir(), receiverCfg)
+
+	srv := grpc.NewServer()
+	tempopb.RegisterIngesterTransferServer(srv, receiver)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	senderCfg := defaultIngesterTestConfig()
+	senderCfg.LifecyclerConfig.RingConfig.KVStore.Mock = kvStore
+	senderCfg.LifecyclerConfig.ID = "sender"
+	senderCfg.MaxTransferRetries = 1
+	sender, traces, traceIDs := defaultIngesterWithConfig(t, t.TempDir(), senderCfg)
+
+	require.Eventually(t, func() bool {
+		return sender.lifecycler.GetState() == ring.ACTIVE
+	}, 5*time.Second, 10*time.Millisecond)
+	senderTokens := ringTokens(t, sender, "sender")
+	require.NotEmpty(t, senderTokens)
+
+	inst, ok := sender.getInstanceByID("test")
+	require.True(t, ok)
+
+	// complete block
+	require.NoError(t, inst.CutCompleteTraces(0, true))
+	blockID, err := inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	require.NoError(t, inst.CompleteBlock(blockID))
+	require.NoError(t, inst.ClearCompletingBlock(blockID))
+
+	// completing block
+	traces, traceIDs = pushRandomTraces(t, sender, traces, traceIDs)
+	require.NoError(t, inst.CutCompleteTraces(0, true))
+	_, err = inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+
+	// head block and live traces
+	traces, traceIDs = pushRandomTraces(t, sender, traces, traceIDs)
+	require.NoError(t, inst.CutCompleteTraces(0, true))
+	traces, traceIDs = pushRandomTraces(t, sender, traces, traceIDs)
+
+	require.NoError(t, sender.TransferOut(context.Background()))
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for i, traceID := range traceIDs {
+		foundTrace, err := receiver.FindTraceByID(ctx, &tempopb.TraceByIDRequest{TraceID: traceID})
+		require.NoError(t, err)
+		require.NotNil(t, foundTrace.Trace)
+		assert.True(t, proto.Equal(traces[i], foundTrace.Trace))
+
+		foundTrace, err = sender.FindTraceByID(ctx, &tempopb.TraceByIDRequest{TraceID: traceID})
+		require.NoError(t, err)
+		assert.Nil(t, foundTrace.Trace)
+	}
+
+	// the receiver's own traces are untouched
+	for i, traceID := range receiverIDs {
+		foundTrace, err := receiver.FindTraceByID(ctx, &tempopb.TraceByIDRequest{TraceID: traceID})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(receiverTraces[i], foundTrace.Trace))
+	}
+
+	assert.Equal(t, ring.ACTIVE, receiver.lifecycler.GetState())
+	assert.Equal(t, senderTokens, ringTokens(t, receiver, "receiver"))
+}
+
+func TestTransferRetries(t *testing.T) {
+	kvStore, _ := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	receiverCfg := defaultIngesterTestConfig()
+	receiverCfg.LifecyclerConfig.RingConfig.KVStore.Mock = kvStore
+	receiverCfg.LifecyclerConfig.ID = "receiver"
+	receiver, _, _ := defaultIngesterWithConfig(t, tmpDir, receiverCfg)
+	require.Eventually(t, func() bool {
+		return receiver.lifecycler.GetState() == ring.ACTIVE
+	}, 5*time.Second, 10*time.Millisecond)
+
+	srv := grpc.NewServer()
+	tempopb.RegisterIngesterTransferServer(srv, receiver)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := tempopb.NewIngesterTransferClient(conn)
+
+	traceID := make([]byte, 16)
+	_, err = rand.Read(traceID)
+	require.NoError(t, err)
+	traceBytes, err := test.MakeTrace(10, traceID).Marshal()
+	require.NoError(t, err)
+
+	walFile := uuid.New().String() + ":test:v2:none"
+	transfer := func(transferID string, eof bool) error {
+		stream, err := client.Transfer(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&tempopb.TransferRequest{
+			FromIngesterID: "sender",
+			TransferID:     transferID,
+			TenantID:       "test",
+			Trace:          &tempopb.TransferTrace{TraceID: traceID, Traces: [][]byte{traceBytes}},
+		}))
+		if !eof {
+			require.NoError(t, stream.Send(&tempopb.TransferRequest{
+				FromIngesterID: "sender",
+				TransferID:     transferID,
+				TenantID:       "test",
+				File:           &tempopb.TransferFile{Kind: tempopb.TransferFileKind_WAL_BLOCK, BlockID: strings.Split(walFile, ":")[0], Name: walFile, Data: []byte{0x01}},
+			}))
+		}
+		_, err = stream.CloseAndRecv()
+		return err
+	}
+
+	inst, err := receiver.getOrCreateInstance("test")
+	require.NoError(t, err)
+	liveTraceBytes := func() int {
+		inst.tracesMtx.Lock()
+		defer inst.tracesMtx.Unlock()
+		if tr, ok := inst.traces[inst.tokenForTraceID(traceID)]; ok {
+			return len(tr.traceBytes.Traces)
+		}
+		return 0
+	}
+
+	// a transfer that ends in the middle of a file is dropped
+	require.Error(t, transfer("failed", false))
+	assert.Equal(t, 0, liveTraceBytes())
+	_, err = os.Stat(filepath.Join(tmpDir, walFile))
+	assert.True(t, os.IsNotExist(err))
+
+	// a retry of a received transfer is acknowledged but not added again
+	require.NoError(t, transfer("retried", true))
+	assert.Equal(t, 1, liveTraceBytes())
+	require.NoError(t, transfer("retried", true))
+	assert.Equal(t, 1, liveTraceBytes())
+}
+
+func TestTransferRetriesAfterPartialCommit(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	receiverCfg := defaultIngesterTestConfig()
+	receiverCfg.LiveTracesWAL = LiveTracesWALConfig{
+		Enabled:     true,
+		FsyncPolicy: FsyncPolicyAlways,
+	}
+	receiver, _, _ := defaultIngesterWithConfig(t, tmpDir, receiverCfg)
+
+	srv := grpc.NewServer()
+	tempopb.RegisterIngesterTransferServer(srv, receiver)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := tempopb.NewIngesterTransferClient(conn)
+
+	traceID := make([]byte, 16)
+	_, err = rand.Read(traceID)
+	require.NoError(t, err)
+	traceBytes, err := test.MakeTrace(10, traceID).Marshal()
+	require.NoError(t, err)
+
+	// the live trace of the second tenant can't be written to its live traces log
+	tenants := []string{"test", "other"}
+	other, err := receiver.getOrCreateInstance("other")
+	require.NoError(t, err)
+	logDir := filepath.Join(tmpDir, "live", "other")
+	require.NoError(t, other.CloseLiveTracesLog())
+	require.NoError(t, os.RemoveAll(logDir))
+
+	transfer := func() error {
+		stream, err := client.Transfer(context.Background())
+		require.NoError(t, err)
+		for _, tenant := range tenants {
+			require.NoError(t, stream.Send(&tempopb.TransferRequest{
+				FromIngesterID: "sender",
+				TransferID:     "partial",
+				TenantID:       tenant,
+				Trace:          &tempopb.TransferTrace{TraceID: traceID, Traces: [][]byte{traceBytes, traceBytes}},
+			}))
+		}
+		_, err = stream.CloseAndRecv()
+		return err
+	}
+	liveTraceBytes := func(tenant string) int {
+		inst, ok := receiver.getInstanceByID(tenant)
+		require.True(t, ok)
+		inst.tracesMtx.Lock()
+		defer inst.tracesMtx.Unlock()
+		if tr, ok := inst.traces[inst.tokenForTraceID(traceID)]; ok {
+			return len(tr.traceBytes.Traces)
+		}
+		return 0
+	}
+
+	require.Error(t, transfer())
+	assert.Equal(t, 2, liveTraceBytes("test"))
+	assert.Equal(t, 0, liveTraceBytes("other"))
+
+	// the retry only pushes what wasn't committed
+	require.NoError(t, os.MkdirAll(logDir, 0755))
+	require.NoError(t, transfer())
+	assert.Equal(t, 2, liveTraceBytes("test"))
+	assert.Equal(t, 2, liveTraceBytes("other"))
+
+	// what was committed is forgotten after a while
+	receiver.transferMtx.Lock()
+	defer receiver.transferMtx.Unlock()
+	receiver.expireTransfers(time.Now().Add(transferProgressTTL / 2))
+	assert.Len(t, receiver.transfers, 1)
+	receiver.expireTransfers(time.Now().Add(2 * transferProgressTTL))
+	assert.Empty(t, receiver.transfers)
+}
+
+func TestTransferOutDisabled(t *testing.T) {
+	i, _, _ := defaultIngester(t, t.TempDir())
+	assert.Equal(t, ring.ErrTransferDisabled, i.TransferOut(context.Background()))
+	assert.False(t, i.readonly)
+}
+
+func pushRandomTraces(t *testing.T, i *Ingester, traces []*tempopb.Trace, traceIDs [][]byte) ([]*tempopb.Trace, [][]byte) {
+	for j := 0; j < 5; j++ {
+		id := make([]byte, 16)
+		_, err := rand.Read(id)
+		require.NoError(t, err)
+
+		trace := test.MakeTrace(10, id)
+		model.SortTrace(trace)
+		for _, batch := range trace.Batches {
+			pushBatch(t, i, batch, id)
+		}
+
+		traces = append(traces, trace)
+		traceIDs = append(traceIDs, id)
+	}
+	return traces, traceIDs
+}
+
+func ringTokens(t *testing.T, i *Ingester, id string) ring.Tokens {
+	desc, err := i.lifecycler.KVStore.Get(context.Background(), i.lifecycler.RingKey)
+	require.NoError(t, err)
+	return desc.(*ring.Desc).Ingesters[id].Tokens
+}
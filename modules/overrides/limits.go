@@ -2,8 +2,11 @@ package overrides
 
 import (
 	"flag"
+	"fmt"
 
 	"github.com/prometheus/common/model"
+
+	"github.com/grafana/tempo/tempodb/backend"
 )
 
 const (
@@ -18,6 +21,15 @@ const (
 	ErrorPrefixTraceTooLarge = "TRACE_TOO_LARGE:"
 	// ErrorPrefixRateLimited is used to flag batches that have exceeded the spans/second of the tenant
 	ErrorPrefixRateLimited = "RATE_LIMITED:"
+	// ErrorPrefixInvalidTraceID is used to flag traces rejected by the ingester b/c their trace id is invalid
+	ErrorPrefixInvalidTraceID = "INVALID_TRACE_ID:"
+
+	// SpanValidationActionReject drops the span
+	SpanValidationActionReject = "reject"
+	// SpanValidationActionFix repairs the span, e.g. by clamping timestamps or truncating attribute values
+	SpanValidationActionFix = "fix"
+	// SpanValidationActionTag keeps the span as is and adds the failed checks to it as an attribute
+	SpanValidationActionTag = "tag"
 )
 
 // Limits describe all the limits for users; can be used to describe global default
@@ -27,33 +39,227 @@ type Limits struct {
 	IngestionRateStrategy   string `yaml:"ingestion_rate_strategy" json:"ingestion_rate_strategy"`
 	IngestionRateLimitBytes int    `yaml:"ingestion_rate_limit_bytes" json:"ingestion_rate_limit_bytes"`
 	IngestionBurstSizeBytes int    `yaml:"ingestion_burst_size_bytes" json:"ingestion_burst_size_bytes"`
+	IngestionRateLimitSpans int    `yaml:"ingestion_rate_limit_spans" json:"ingestion_rate_limit_spans"`
+	IngestionBurstSizeSpans int    `yaml:"ingestion_burst_size_spans" json:"ingestion_burst_size_spans"`
+
+	// Per service limits keyed by the service.name resource attribute. The per service values apply to every
+	// service not listed in ServiceIngestionLimits.
+	IngestionRateLimitSpansPerService int                               `yaml:"ingestion_rate_limit_spans_per_service" json:"ingestion_rate_limit_spans_per_service"`
+	IngestionBurstSizeSpansPerService int                               `yaml:"ingestion_burst_size_spans_per_service" json:"ingestion_burst_size_spans_per_service"`
+	ServiceIngestionLimits            map[string]ServiceIngestionLimits `yaml:"service_ingestion_limits" json:"service_ingestion_limits"`
+	// MaxServicesPerTenant caps the services with their own rate limiter. Spans of other services are only
+	// subject to the tenant limits.
+	MaxServicesPerTenant int `yaml:"max_services_per_tenant" json:"max_services_per_tenant"`
+
+	// Forwarders to send a copy of accepted spans to. Names refer to distributor.forwarders.
+	Forwarders []string `yaml:"forwarders" json:"forwarders"`
+
+	// Jaeger remote sampling strategies served by the distributor. Takes precedence over the static strategies file.
+	SamplingStrategies SamplingStrategies `yaml:"sampling_strategies" json:"sampling_strategies"`
+
+	// Policy for malformed spans, applied by the distributor before spans are sent to the ingesters.
+	SpanValidation SpanValidation `yaml:"span_validation" json:"span_validation"`
 
 	// Ingester enforced limits.
-	MaxLocalTracesPerUser  int `yaml:"max_traces_per_user" json:"max_traces_per_user"`
-	MaxGlobalTracesPerUser int `yaml:"max_global_traces_per_user" json:"max_global_traces_per_user"`
-	MaxBytesPerTrace       int `yaml:"max_bytes_per_trace" json:"max_bytes_per_trace"`
-	MaxSearchBytesPerTrace int `yaml:"max_search_bytes_per_trace" json:"max_search_bytes_per_trace"`
+	MaxLocalTracesPerUser    int `yaml:"max_traces_per_user" json:"max_traces_per_user"`
+	MaxGlobalTracesPerUser   int `yaml:"max_global_traces_per_user" json:"max_global_traces_per_user"`
+	MaxBytesPerTrace         int `yaml:"max_bytes_per_trace" json:"max_bytes_per_trace"`
+	MaxSearchBytesPerTrace   int `yaml:"max_search_bytes_per_trace" json:"max_search_bytes_per_trace"`
+	MaxSearchTagValuesPerTag int `yaml:"max_search_tag_values_per_tag" json:"max_search_tag_values_per_tag"`
+	MaxLiveTracesBytes       int `yaml:"max_live_traces_bytes" json:"max_live_traces_bytes"`
+	MaxTailSubscribers       int `yaml:"max_tail_subscribers" json:"max_tail_subscribers"`
+
+	// Policy for deciding when a live trace is complete and cut into the head block.
+	TraceCompletion TraceCompletion `yaml:"trace_completion" json:"trace_completion"`
+
+	// Querier enforced limits.
+	// Tenants whose traces may be queried together with this tenant's by a federated query, i.e. an org id of
+	// several tenants separated by |. Every tenant of the query must allow all of the others. * allows any tenant.
+	FederatedQueryTenants []string `yaml:"federated_query_tenants" json:"federated_query_tenants"`
+	// Longest time range a search can cover. Searches without a time range are limited to the most recent window.
+	MaxSearchDuration model.Duration `yaml:"max_search_duration" json:"max_search_duration"`
+	// Most blocks and bytes a single querier job can inspect.
+	MaxBlocksPerQuery int `yaml:"max_blocks_per_query" json:"max_blocks_per_query"`
+	MaxBytesPerQuery  int `yaml:"max_bytes_per_query" json:"max_bytes_per_query"`
+	// Largest response a query can return.
+	MaxResponseSizeBytes int `yaml:"max_response_size_bytes" json:"max_response_size_bytes"`
+
+	// Frontend enforced limits.
+	// Most jobs of a tenant that can wait in the frontend queue or be processed by queriers at once.
+	MaxConcurrentQueryJobs int `yaml:"max_concurrent_query_jobs" json:"max_concurrent_query_jobs"`
 
 	// Compactor enforced limits.
 	BlockRetention model.Duration `yaml:"block_retention" json:"block_retention"`
 
+	// Encoding of the wal and backend blocks, applied by the ingester and the compactor.
+	BlockSettings BlockSettings `yaml:"block_settings" json:"block_settings"`
+
 	// Configuration for overrides, convenient if it goes here.
 	PerTenantOverrideConfig string         `yaml:"per_tenant_override_config" json:"per_tenant_override_config"`
 	PerTenantOverridePeriod model.Duration `yaml:"per_tenant_override_period" json:"per_tenant_override_period"`
 }
 
+// ServiceIngestionLimits are the span rate limits of a single service within a tenant.
+type ServiceIngestionLimits struct {
+	IngestionRateLimitSpans int `yaml:"ingestion_rate_limit_spans" json:"ingestion_rate_limit_spans"`
+	IngestionBurstSizeSpans int `yaml:"ingestion_burst_size_spans" json:"ingestion_burst_size_spans"`
+}
+
+// SamplingStrategies mirrors the format of the Jaeger static sampling strategies file.
+type SamplingStrategies struct {
+	DefaultStrategy   *SamplingStrategy  `yaml:"default_strategy,omitempty" json:"default_strategy,omitempty"`
+	ServiceStrategies []SamplingStrategy `yaml:"service_strategies,omitempty" json:"service_strategies,omitempty"`
+}
+
+// SamplingStrategy is the strategy for a service, or the default strategy if Service is empty.
+// Type is either probabilistic or ratelimiting.
+type SamplingStrategy struct {
+	Service             string                      `yaml:"service,omitempty" json:"service,omitempty"`
+	Type                string                      `yaml:"type" json:"type"`
+	Param               float64                     `yaml:"param" json:"param"`
+	OperationStrategies []OperationSamplingStrategy `yaml:"operation_strategies,omitempty" json:"operation_strategies,omitempty"`
+}
+
+// OperationSamplingStrategy is the strategy for a single operation of a service. Only probabilistic is supported.
+type OperationSamplingStrategy struct {
+	Operation string  `yaml:"operation" json:"operation"`
+	Type      string  `yaml:"type" json:"type"`
+	Param     float64 `yaml:"param" json:"param"`
+}
+
+// SpanValidation configures the action taken for each span check. A check is disabled if its action is empty.
+type SpanValidation struct {
+	// Span ids that are not 64 bits or are all zeros. Fixing generates a random span id.
+	InvalidSpanID string `yaml:"invalid_span_id" json:"invalid_span_id"`
+	// End time before start time. Fixing sets the end time to the start time.
+	EndBeforeStart string `yaml:"end_before_start" json:"end_before_start"`
+	// Start or end time more than MaxFutureSkew ahead of the distributor clock. Fixing clamps them to now.
+	FutureTimestamp string         `yaml:"future_timestamp" json:"future_timestamp"`
+	MaxFutureSkew   model.Duration `yaml:"max_future_skew" json:"max_future_skew"`
+	// Span or event attribute string values longer than MaxAttributeValueBytes. Fixing truncates them.
+	AttributeValueTooLong  string `yaml:"attribute_value_too_long" json:"attribute_value_too_long"`
+	MaxAttributeValueBytes int    `yaml:"max_attribute_value_bytes" json:"max_attribute_value_bytes"`
+}
+
+// Validate checks that the actions are known and that the checks that need a threshold have one.
+func (v *SpanValidation) Validate() error {
+	actions := []struct {
+		name, action string
+	}{
+		{"invalid_span_id", v.InvalidSpanID},
+		{"end_before_start", v.EndBeforeStart},
+		{"future_timestamp", v.FutureTimestamp},
+		{"attribute_value_too_long", v.AttributeValueTooLong},
+	}
+	for _, a := range actions {
+		switch a.action {
+		case "", SpanValidationActionReject, SpanValidationActionFix, SpanValidationActionTag:
+		default:
+			return fmt.Errorf("unknown span validation action %q for %s", a.action, a.name)
+		}
+	}
+
+	if v.FutureTimestamp != "" && v.MaxFutureSkew <= 0 {
+		return fmt.Errorf("span validation future_timestamp requires max_future_skew > 0")
+	}
+	if v.AttributeValueTooLong != "" && v.MaxAttributeValueBytes <= 0 {
+		return fmt.Errorf("span validation attribute_value_too_long requires max_attribute_value_bytes > 0")
+	}
+	return nil
+}
+
+// TraceCompletion decides when the ingester considers a live trace complete. Durations of 0 disable the rule.
+type TraceCompletion struct {
+	// Cut after no spans were received for this long. Defaults to the ingester trace_idle_period.
+	IdlePeriod model.Duration `yaml:"idle_period" json:"idle_period"`
+	// Cut once the root span was received and no spans were received for this long.
+	RootSpanGracePeriod model.Duration `yaml:"root_span_grace_period" json:"root_span_grace_period"`
+	// Traces without a root span are kept open regardless of the idle period, but every trace is cut once it
+	// has been live for this long.
+	MaxTraceDuration model.Duration `yaml:"max_trace_duration" json:"max_trace_duration"`
+	// Spans received within this long after their trace was cut are counted as late.
+	LateSpanWindow model.Duration `yaml:"late_span_window" json:"late_span_window"`
+}
+
+// Validate checks that the durations are not negative.
+func (c *TraceCompletion) Validate() error {
+	durations := []struct {
+		name     string
+		duration model.Duration
+	}{
+		{"idle_period", c.IdlePeriod},
+		{"root_span_grace_period", c.RootSpanGracePeriod},
+		{"max_trace_duration", c.MaxTraceDuration},
+		{"late_span_window", c.LateSpanWindow},
+	}
+	for _, d := range durations {
+		if d.duration < 0 {
+			return fmt.Errorf("trace completion %s must not be negative", d.name)
+		}
+	}
+	return nil
+}
+
+// BlockSettings overrides the storage.trace.wal and storage.trace.block config for the blocks of a tenant. Empty and
+// 0 values fall back to the storage config.
+type BlockSettings struct {
+	// Encoding of new wal blocks, applied when the ingester cuts a new head block.
+	WALEncoding string `yaml:"wal_encoding" json:"wal_encoding"`
+	// Encoding, bloom filter false positive rate and index page size of new backend blocks, applied when the
+	// ingester completes a wal block and when the compactor writes a compacted block.
+	Encoding           string  `yaml:"encoding" json:"encoding"`
+	BloomFP            float64 `yaml:"bloom_filter_false_positive" json:"bloom_filter_false_positive"`
+	IndexPageSizeBytes int     `yaml:"index_page_size_bytes" json:"index_page_size_bytes"`
+}
+
+// Validate checks that the encodings are supported and the numbers are in range.
+func (s *BlockSettings) Validate() error {
+	encodings := []struct {
+		name, encoding string
+	}{
+		{"wal_encoding", s.WALEncoding},
+		{"encoding", s.Encoding},
+	}
+	for _, e := range encodings {
+		if e.encoding == "" {
+			continue
+		}
+		if _, err := backend.ParseEncoding(e.encoding); err != nil {
+			return fmt.Errorf("block settings %s: %w", e.name, err)
+		}
+	}
+
+	if s.BloomFP < 0 || s.BloomFP >= 1 {
+		return fmt.Errorf("block settings bloom_filter_false_positive must be in [0, 1)")
+	}
+	if s.IndexPageSizeBytes < 0 {
+		return fmt.Errorf("block settings index_page_size_bytes must not be negative")
+	}
+	return nil
+}
+
 // RegisterFlags adds the flags required to config this to the given FlagSet
 func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	// Distributor Limits
 	f.StringVar(&l.IngestionRateStrategy, "distributor.rate-limit-strategy", "local", "Whether the various ingestion rate limits should be applied individually to each distributor instance (local), or evenly shared across the cluster (global).")
 	f.IntVar(&l.IngestionRateLimitBytes, "distributor.ingestion-rate-limit-bytes", 15e6, "Per-user ingestion rate limit in bytes per second.")
 	f.IntVar(&l.IngestionBurstSizeBytes, "distributor.ingestion-burst-size-bytes", 20e6, "Per-user ingestion burst size in bytes. Should be set to the expected size (in bytes) of a single push request.")
+	f.IntVar(&l.IngestionRateLimitSpans, "distributor.ingestion-rate-limit-spans", 0, "Per-user ingestion rate limit in spans per second. 0 to disable.")
+	f.IntVar(&l.IngestionBurstSizeSpans, "distributor.ingestion-burst-size-spans", 0, "Per-user ingestion burst size in spans. Defaults to the spans rate limit if 0.")
+	f.IntVar(&l.IngestionRateLimitSpansPerService, "distributor.ingestion-rate-limit-spans-per-service", 0, "Per-service ingestion rate limit in spans per second. 0 to disable.")
+	f.IntVar(&l.IngestionBurstSizeSpansPerService, "distributor.ingestion-burst-size-spans-per-service", 0, "Per-service ingestion burst size in spans. Defaults to the per-service spans rate limit if 0.")
+	f.IntVar(&l.MaxServicesPerTenant, "distributor.max-services-per-tenant", 1000, "Maximum number of services per tenant with their own ingestion rate limit. Spans of further services are only subject to the tenant limits. 0 to disable.")
 
 	// Ingester limits
 	f.IntVar(&l.MaxLocalTracesPerUser, "ingester.max-traces-per-user", 10e3, "Maximum number of active traces per user, per ingester. 0 to disable.")
 	f.IntVar(&l.MaxGlobalTracesPerUser, "ingester.max-global-traces-per-user", 0, "Maximum number of active traces per user, across the cluster. 0 to disable.")
 	f.IntVar(&l.MaxBytesPerTrace, "ingester.max-bytes-per-trace", 50e5, "Maximum size of a trace in bytes.  0 to disable.")
 	f.IntVar(&l.MaxBytesPerTrace, "ingester.max-search-bytes-per-trace", 50e3, "Maximum size of search data per trace in bytes.  0 to disable.")
+	f.IntVar(&l.MaxSearchTagValuesPerTag, "ingester.max-search-tag-values-per-tag", 50, "Maximum number of recent values kept per tag for search autocomplete. Tags with more values are reported as high cardinality. 0 to use the default.")
+	_ = l.TraceCompletion.LateSpanWindow.Set("1m")
+	f.Var(&l.TraceCompletion.LateSpanWindow, "ingester.trace-completion.late-span-window", "Spans received within this period after their trace was cut are counted as late. 0 to disable.")
+	f.IntVar(&l.MaxTailSubscribers, "ingester.max-tail-subscribers", 5, "Maximum number of concurrent live tails per user, per ingester. 0 to disable tailing.")
+	f.IntVar(&l.MaxLiveTracesBytes, "ingester.max-live-traces-bytes", 0, "Maximum size of the live traces of a user in bytes, per ingester. Traces are cut into the head block early once exceeded. 0 to disable.")
 
 	f.StringVar(&l.PerTenantOverrideConfig, "limits.per-user-override-config", "", "File name of per-user overrides.")
 	_ = l.PerTenantOverridePeriod.Set("10s")
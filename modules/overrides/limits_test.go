@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -38,12 +40,65 @@ func TestLimitsYamlMatchJson(t *testing.T) {
 ingestion_rate_strategy: global
 ingestion_rate_limit_bytes: 100_000
 ingestion_burst_size_bytes: 100_000
+ingestion_rate_limit_spans: 1000
+ingestion_burst_size_spans: 2000
+ingestion_rate_limit_spans_per_service: 100
+ingestion_burst_size_spans_per_service: 200
+service_ingestion_limits:
+  noisy:
+    ingestion_rate_limit_spans: 10
+    ingestion_burst_size_spans: 20
+max_services_per_tenant: 50
+forwarders:
+  - otlp
+  - kafka
+sampling_strategies:
+  default_strategy:
+    type: probabilistic
+    param: 0.5
+  service_strategies:
+    - service: foo
+      type: ratelimiting
+      param: 10
+      operation_strategies:
+        - operation: bar
+          type: probabilistic
+          param: 0.1
+span_validation:
+  invalid_span_id: reject
+  end_before_start: fix
+  future_timestamp: tag
+  max_future_skew: 1h
+  attribute_value_too_long: fix
+  max_attribute_value_bytes: 1024
 
 max_traces_per_user: 1000
 max_global_traces_per_user: 1000
 max_bytes_per_trace: 100_000
+max_search_tag_values_per_tag: 100
+max_live_traces_bytes: 50_000_000
+max_tail_subscribers: 10
+trace_completion:
+  idle_period: 30s
+  root_span_grace_period: 5s
+  max_trace_duration: 1h
+  late_span_window: 1m
+
+federated_query_tenants:
+  - team-b
+  - team-c
+max_search_duration: 1h
+max_blocks_per_query: 500
+max_bytes_per_query: 1000000000
+max_response_size_bytes: 10000000
+max_concurrent_query_jobs: 50
 
 block_retention: 24h
+block_settings:
+  wal_encoding: snappy
+  encoding: zstd
+  bloom_filter_false_positive: 0.01
+  index_page_size_bytes: 250000
 
 per_tenant_override_config: /etc/overrides.yaml
 per_tenant_override_period: 1m
@@ -53,12 +108,62 @@ per_tenant_override_period: 1m
 	"ingestion_rate_strategy": "global",
 	"ingestion_rate_limit_bytes": 100000,
 	"ingestion_burst_size_bytes": 100000,
+	"ingestion_rate_limit_spans": 1000,
+	"ingestion_burst_size_spans": 2000,
+	"ingestion_rate_limit_spans_per_service": 100,
+	"ingestion_burst_size_spans_per_service": 200,
+	"service_ingestion_limits": {
+		"noisy": {"ingestion_rate_limit_spans": 10, "ingestion_burst_size_spans": 20}
+	},
+	"max_services_per_tenant": 50,
+	"forwarders": ["otlp", "kafka"],
+	"sampling_strategies": {
+		"default_strategy": {"type": "probabilistic", "param": 0.5},
+		"service_strategies": [
+			{
+				"service": "foo",
+				"type": "ratelimiting",
+				"param": 10,
+				"operation_strategies": [{"operation": "bar", "type": "probabilistic", "param": 0.1}]
+			}
+		]
+	},
+	"span_validation": {
+		"invalid_span_id": "reject",
+		"end_before_start": "fix",
+		"future_timestamp": "tag",
+		"max_future_skew": "1h",
+		"attribute_value_too_long": "fix",
+		"max_attribute_value_bytes": 1024
+	},
 
 	"max_traces_per_user": 1000,
 	"max_global_traces_per_user": 1000,
 	"max_bytes_per_trace": 100000,
+	"max_search_tag_values_per_tag": 100,
+	"max_live_traces_bytes": 50000000,
+	"max_tail_subscribers": 10,
+	"trace_completion": {
+		"idle_period": "30s",
+		"root_span_grace_period": "5s",
+		"max_trace_duration": "1h",
+		"late_span_window": "1m"
+	},
+
+	"federated_query_tenants": ["team-b", "team-c"],
+	"max_search_duration": "1h",
+	"max_blocks_per_query": 500,
+	"max_bytes_per_query": 1000000000,
+	"max_response_size_bytes": 10000000,
+	"max_concurrent_query_jobs": 50,
 
 	"block_retention": "24h",
+	"block_settings": {
+		"wal_encoding": "snappy",
+		"encoding": "zstd",
+		"bloom_filter_false_positive": 0.01,
+		"index_page_size_bytes": 250000
+	},
 
 	"per_tenant_override_config": "/etc/overrides.yaml",
 	"per_tenant_override_period": "1m"
@@ -74,3 +179,68 @@ per_tenant_override_period: 1m
 
 	assert.Equal(t, limitsYAML, limitsJSON)
 }
+
+func TestSpanValidationValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      SpanValidation
+		expectedErr bool
+	}{
+		{
+			name:   "empty",
+			policy: SpanValidation{},
+		},
+		{
+			name: "valid",
+			policy: SpanValidation{
+				InvalidSpanID:          SpanValidationActionReject,
+				EndBeforeStart:         SpanValidationActionFix,
+				FutureTimestamp:        SpanValidationActionTag,
+				MaxFutureSkew:          model.Duration(time.Hour),
+				AttributeValueTooLong:  SpanValidationActionFix,
+				MaxAttributeValueBytes: 10,
+			},
+		},
+		{
+			name:        "unknown action",
+			policy:      SpanValidation{EndBeforeStart: "drop"},
+			expectedErr: true,
+		},
+		{
+			name:        "missing skew",
+			policy:      SpanValidation{FutureTimestamp: SpanValidationActionFix},
+			expectedErr: true,
+		},
+		{
+			name:        "missing max bytes",
+			policy:      SpanValidation{AttributeValueTooLong: SpanValidationActionFix},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTraceCompletionValidate(t *testing.T) {
+	assert.NoError(t, (&TraceCompletion{}).Validate())
+	assert.NoError(t, (&TraceCompletion{RootSpanGracePeriod: model.Duration(time.Second), MaxTraceDuration: model.Duration(time.Hour)}).Validate())
+	assert.Error(t, (&TraceCompletion{IdlePeriod: model.Duration(-time.Second)}).Validate())
+}
+
+func TestBlockSettingsValidate(t *testing.T) {
+	assert.NoError(t, (&BlockSettings{}).Validate())
+	assert.NoError(t, (&BlockSettings{WALEncoding: "snappy", Encoding: "zstd", BloomFP: 0.01, IndexPageSizeBytes: 1000}).Validate())
+	assert.Error(t, (&BlockSettings{WALEncoding: "brotli"}).Validate())
+	assert.Error(t, (&BlockSettings{Encoding: "brotli"}).Validate())
+	assert.Error(t, (&BlockSettings{BloomFP: 1}).Validate())
+	assert.Error(t, (&BlockSettings{IndexPageSizeBytes: -1}).Validate())
+}
@@ -41,6 +41,21 @@ func loadPerTenantOverrides(r io.Reader) (interface{}, error) {
 		return nil, err
 	}
 
+	for userID, l := range overrides.TenantLimits {
+		if l == nil {
+			continue
+		}
+		if err := l.SpanValidation.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid overrides for tenant %s: %w", userID, err)
+		}
+		if err := l.TraceCompletion.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid overrides for tenant %s: %w", userID, err)
+		}
+		if err := l.BlockSettings.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid overrides for tenant %s: %w", userID, err)
+		}
+	}
+
 	return overrides, nil
 }
 
@@ -68,6 +83,16 @@ type Overrides struct {
 // are defaulted to those values.  As such, the last call to NewOverrides will
 // become the new global defaults.
 func NewOverrides(defaults Limits) (*Overrides, error) {
+	if err := defaults.SpanValidation.Validate(); err != nil {
+		return nil, err
+	}
+	if err := defaults.TraceCompletion.Validate(); err != nil {
+		return nil, err
+	}
+	if err := defaults.BlockSettings.Validate(); err != nil {
+		return nil, err
+	}
+
 	var manager *runtimeconfig.Manager
 	subservices := []services.Service(nil)
 
@@ -228,11 +253,21 @@ func (o *Overrides) MaxBytesPerTrace(userID string) int {
 	return o.getOverridesForUser(userID).MaxBytesPerTrace
 }
 
+// MaxLiveTracesBytes returns the maximum size of the live traces of a user in bytes, per ingester.
+func (o *Overrides) MaxLiveTracesBytes(userID string) int {
+	return o.getOverridesForUser(userID).MaxLiveTracesBytes
+}
+
 // MaxSearchBytesPerTrace returns the maximum size of search data for trace (in bytes) allowed for a user.
 func (o *Overrides) MaxSearchBytesPerTrace(userID string) int {
 	return o.getOverridesForUser(userID).MaxSearchBytesPerTrace
 }
 
+// MaxSearchTagValuesPerTag returns the maximum number of values kept per tag for search autocomplete for a user.
+func (o *Overrides) MaxSearchTagValuesPerTag(userID string) int {
+	return o.getOverridesForUser(userID).MaxSearchTagValuesPerTag
+}
+
 // IngestionRateLimitBytes is the number of spans per second allowed for this tenant
 func (o *Overrides) IngestionRateLimitBytes(userID string) float64 {
 	return float64(o.getOverridesForUser(userID).IngestionRateLimitBytes)
@@ -243,11 +278,114 @@ func (o *Overrides) IngestionBurstSizeBytes(userID string) int {
 	return o.getOverridesForUser(userID).IngestionBurstSizeBytes
 }
 
+// IngestionRateLimitSpans is the number of spans per second allowed for this tenant. 0 means unlimited.
+func (o *Overrides) IngestionRateLimitSpans(userID string) float64 {
+	return float64(o.getOverridesForUser(userID).IngestionRateLimitSpans)
+}
+
+// IngestionBurstSizeSpans is the burst size in spans allowed for this tenant. Falls back to the rate limit.
+func (o *Overrides) IngestionBurstSizeSpans(userID string) int {
+	l := o.getOverridesForUser(userID)
+	if l.IngestionBurstSizeSpans == 0 {
+		return l.IngestionRateLimitSpans
+	}
+	return l.IngestionBurstSizeSpans
+}
+
+// ServiceIngestionRateLimitSpans is the number of spans per second allowed for a service of this tenant. 0 means unlimited.
+func (o *Overrides) ServiceIngestionRateLimitSpans(userID, service string) float64 {
+	l := o.getOverridesForUser(userID)
+	if svc, ok := l.ServiceIngestionLimits[service]; ok {
+		return float64(svc.IngestionRateLimitSpans)
+	}
+	return float64(l.IngestionRateLimitSpansPerService)
+}
+
+// ServiceIngestionBurstSizeSpans is the burst size in spans allowed for a service of this tenant. Falls back to the rate limit.
+func (o *Overrides) ServiceIngestionBurstSizeSpans(userID, service string) int {
+	l := o.getOverridesForUser(userID)
+
+	rate, burst := l.IngestionRateLimitSpansPerService, l.IngestionBurstSizeSpansPerService
+	if svc, ok := l.ServiceIngestionLimits[service]; ok {
+		rate, burst = svc.IngestionRateLimitSpans, svc.IngestionBurstSizeSpans
+	}
+
+	if burst == 0 {
+		return rate
+	}
+	return burst
+}
+
+// MaxServicesPerTenant is the number of services of this tenant with their own span rate limit. 0 means unlimited.
+func (o *Overrides) MaxServicesPerTenant(userID string) int {
+	return o.getOverridesForUser(userID).MaxServicesPerTenant
+}
+
+// Forwarders are the names of the forwarders that receive a copy of this tenant's spans
+func (o *Overrides) Forwarders(userID string) []string {
+	return o.getOverridesForUser(userID).Forwarders
+}
+
+// FederatedQueryTenants are the tenants that may be queried together with this tenant
+func (o *Overrides) FederatedQueryTenants(userID string) []string {
+	return o.getOverridesForUser(userID).FederatedQueryTenants
+}
+
+// MaxSearchDuration is the longest time range a search of this tenant can cover
+func (o *Overrides) MaxSearchDuration(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).MaxSearchDuration)
+}
+
+// MaxBlocksPerQuery is the most blocks a query of this tenant can inspect
+func (o *Overrides) MaxBlocksPerQuery(userID string) int {
+	return o.getOverridesForUser(userID).MaxBlocksPerQuery
+}
+
+// MaxBytesPerQuery is the most bytes of search data each ingester can inspect for a search of this tenant
+func (o *Overrides) MaxBytesPerQuery(userID string) int {
+	return o.getOverridesForUser(userID).MaxBytesPerQuery
+}
+
+// MaxResponseSizeBytes is the size of the largest response a query of this tenant can return
+func (o *Overrides) MaxResponseSizeBytes(userID string) int {
+	return o.getOverridesForUser(userID).MaxResponseSizeBytes
+}
+
+// MaxConcurrentQueryJobs is the most jobs of this tenant the query frontend runs at once
+func (o *Overrides) MaxConcurrentQueryJobs(userID string) int {
+	return o.getOverridesForUser(userID).MaxConcurrentQueryJobs
+}
+
+// SamplingStrategies are the Jaeger remote sampling strategies configured for this tenant
+func (o *Overrides) SamplingStrategies(userID string) SamplingStrategies {
+	return o.getOverridesForUser(userID).SamplingStrategies
+}
+
+// MaxTailSubscribers returns the maximum number of concurrent live tails of a user, per ingester.
+func (o *Overrides) MaxTailSubscribers(userID string) int {
+	return o.getOverridesForUser(userID).MaxTailSubscribers
+}
+
+// TraceCompletion is the policy for deciding when a live trace of this tenant is complete
+func (o *Overrides) TraceCompletion(userID string) TraceCompletion {
+	return o.getOverridesForUser(userID).TraceCompletion
+}
+
+// SpanValidation is the policy for malformed spans of this tenant
+func (o *Overrides) SpanValidation(userID string) SpanValidation {
+	return o.getOverridesForUser(userID).SpanValidation
+}
+
 // BlockRetention is the duration of the block retention for this tenant
 func (o *Overrides) BlockRetention(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).BlockRetention)
 }
 
+// BlockSettings are the wal and block encoding settings of this tenant
+func (o *Overrides) BlockSettings(userID string) BlockSettings {
+	return o.getOverridesForUser(userID).BlockSettings
+}
+
 func (o *Overrides) getOverridesForUser(userID string) *Limits {
 	if tenantOverrides := o.tenantOverrides(); tenantOverrides != nil {
 		l := tenantOverrides.forUser(userID)
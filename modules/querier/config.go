@@ -11,10 +11,13 @@ import (
 
 // Config for a querier.
 type Config struct {
-	QueryTimeout         time.Duration        `yaml:"query_timeout"`
-	ExtraQueryDelay      time.Duration        `yaml:"extra_query_delay,omitempty"`
-	MaxConcurrentQueries int                  `yaml:"max_concurrent_queries"`
-	Worker               cortex_worker.Config `yaml:"frontend_worker"`
+	QueryTimeout          time.Duration        `yaml:"query_timeout"`
+	ExtraQueryDelay       time.Duration        `yaml:"extra_query_delay,omitempty"`
+	MaxConcurrentQueries  int                  `yaml:"max_concurrent_queries"`
+	MaxTracesPerBatch     int                  `yaml:"max_traces_per_batch"`
+	MaxBatchResponseBytes int                  `yaml:"max_batch_response_bytes"`
+	TraceByIDTimeSlack    time.Duration        `yaml:"trace_by_id_time_slack"`
+	Worker                cortex_worker.Config `yaml:"frontend_worker"`
 }
 
 // RegisterFlagsAndApplyDefaults register flags.
@@ -22,6 +25,9 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 	cfg.QueryTimeout = 10 * time.Second
 	cfg.ExtraQueryDelay = 0
 	cfg.MaxConcurrentQueries = 5
+	cfg.MaxTracesPerBatch = 1000
+	cfg.MaxBatchResponseBytes = 16 << 20 // the max message size the frontend worker sends
+	cfg.TraceByIDTimeSlack = 15 * time.Minute
 	cfg.Worker = cortex_worker.Config{
 		MatchMaxConcurrency:   true,
 		MaxConcurrentRequests: cfg.MaxConcurrentQueries,
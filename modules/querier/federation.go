@@ -0,0 +1,205 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cortexproject/cortex/pkg/tenant"
+	"github.com/opentracing/opentracing-go"
+	ot_log "github.com/opentracing/opentracing-go/log"
+	"github.com/weaveworks/common/user"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+const (
+	// TenantAttribute is the resource attribute added to the batches of traces returned by federated queries
+	TenantAttribute = "tempo.tenant"
+
+	allTenants      = "*"
+	tenantSeparator = "|"
+)
+
+// ErrFederatedQueryNotAllowed is returned for federated queries across tenants that don't allow each other
+var ErrFederatedQueryNotAllowed = errors.New("federated query not allowed")
+
+var tenantResolver = tenant.NewMultiResolver()
+
+// federatedTenants returns the tenants of a federated query, whose org id is several tenants separated by |. It
+// returns nil for queries of a single tenant.
+func (q *Querier) federatedTenants(ctx context.Context) ([]string, error) {
+	// single tenant queries don't go through the stricter validation of the multi tenant resolver
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil || !strings.Contains(orgID, tenantSeparator) {
+		return nil, nil
+	}
+
+	tenantIDs, err := tenantResolver.TenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(tenantIDs) < 2 {
+		return nil, nil
+	}
+
+	for _, t := range tenantIDs {
+		allowed := map[string]struct{}{}
+		for _, a := range q.limits.FederatedQueryTenants(t) {
+			allowed[a] = struct{}{}
+		}
+		if _, ok := allowed[allTenants]; ok {
+			continue
+		}
+
+		for _, other := range tenantIDs {
+			if _, ok := allowed[other]; !ok && other != t {
+				return nil, fmt.Errorf("%w: tenant %s does not allow %s", ErrFederatedQueryNotAllowed, t, other)
+			}
+		}
+	}
+
+	return tenantIDs, nil
+}
+
+// forEachTenant calls f in parallel with a context of each of the tenants
+func forEachTenant(ctx context.Context, tenantIDs []string, f func(ctx context.Context, tenantID string) error) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, tenantID := range tenantIDs {
+		tenantID := tenantID
+		g.Go(func() error {
+			return f(user.InjectOrgID(ctx, tenantID), tenantID)
+		})
+	}
+	return g.Wait()
+}
+
+// annotateTenant adds the tenant to the resource of every batch of the trace
+func annotateTenant(trace *tempopb.Trace, tenantID string) {
+	for _, b := range trace.Batches {
+		if b.Resource == nil {
+			b.Resource = &v1_resource.Resource{}
+		}
+		b.Resource.Attributes = append(b.Resource.Attributes, &v1_common.KeyValue{
+			Key:   TenantAttribute,
+			Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: tenantID}},
+		})
+	}
+}
+
+// findTraceByIDFederated looks the trace up in every tenant and combines the results
+func (q *Querier) findTraceByIDFederated(ctx context.Context, tenantIDs []string, req *tempopb.TraceByIDRequest) (*tempopb.TraceByIDResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.findTraceByIDFederated")
+	defer span.Finish()
+
+	var mtx sync.Mutex
+	var completeTrace *tempopb.Trace
+	metrics := &tempopb.SearchMetrics{}
+	err := forEachTenant(ctx, tenantIDs, func(ctx context.Context, tenantID string) error {
+		resp, err := q.FindTraceByID(ctx, req)
+		if err != nil {
+			return fmt.Errorf("error querying tenant %s: %w", tenantID, err)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		AddMetrics(metrics, resp.Metrics)
+		if resp.Trace == nil || len(resp.Trace.Batches) == 0 {
+			return nil
+		}
+		annotateTenant(resp.Trace, tenantID)
+		completeTrace, _, _, _ = model.CombineTraceProtos(completeTrace, resp.Trace)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.LogFields(ot_log.Int("tenants", len(tenantIDs)), ot_log.Bool("found", completeTrace != nil))
+
+	return &tempopb.TraceByIDResponse{
+		Trace:   completeTrace,
+		Metrics: metrics,
+	}, nil
+}
+
+// findTracesByIDFederated looks the traces up in every tenant and combines the results
+func (q *Querier) findTracesByIDFederated(ctx context.Context, tenantIDs []string, req *tempopb.TracesByIDRequest) (*tempopb.TracesByIDResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.findTracesByIDFederated")
+	defer span.Finish()
+
+	var mtx sync.Mutex
+	traces := map[string]*tempopb.Trace{}
+	err := forEachTenant(ctx, tenantIDs, func(ctx context.Context, tenantID string) error {
+		resp, err := q.FindTracesByID(ctx, req)
+		if err != nil {
+			return fmt.Errorf("error querying tenant %s: %w", tenantID, err)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, found := range resp.Traces {
+			annotateTenant(found.Trace, tenantID)
+			traces[found.TraceID], _, _, _ = model.CombineTraceProtos(traces[found.TraceID], found.Trace)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.LogFields(ot_log.Int("tenants", len(tenantIDs)), ot_log.Int("found", len(traces)))
+
+	// respond in the order of the request
+	resp := &tempopb.TracesByIDResponse{}
+	seen := map[string]struct{}{}
+	for _, id := range req.TraceIDs {
+		key := util.TraceIDToHexString(id)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if trace, ok := traces[key]; ok {
+			resp.Traces = append(resp.Traces, &tempopb.TraceByIDResult{
+				TraceID: key,
+				Trace:   trace,
+			})
+		} else {
+			resp.NotFound = append(resp.NotFound, key)
+		}
+	}
+
+	return resp, nil
+}
+
+// searchFederated searches every tenant and combines the results
+func (q *Querier) searchFederated(ctx context.Context, tenantIDs []string, req *tempopb.SearchRequest) (*tempopb.SearchResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.searchFederated")
+	defer span.Finish()
+
+	var mtx sync.Mutex
+	responses := make([]responseFromIngesters, 0, len(tenantIDs))
+	err := forEachTenant(ctx, tenantIDs, func(ctx context.Context, tenantID string) error {
+		resp, err := q.Search(ctx, req)
+		if err != nil {
+			return fmt.Errorf("error searching tenant %s: %w", tenantID, err)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		responses = append(responses, responseFromIngesters{addr: tenantID, response: resp})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.LogFields(ot_log.Int("tenants", len(tenantIDs)))
+
+	return q.postProcessSearchResults(req, responses)
+}
@@ -0,0 +1,106 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+func TestFederatedTenants(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowed     []string
+		orgID       string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:    "single tenant",
+			allowed: nil,
+			orgID:   "team-a",
+		},
+		{
+			name:     "allowed",
+			allowed:  []string{"team-a", "team-b"},
+			orgID:    "team-a|team-b",
+			expected: []string{"team-a", "team-b"},
+		},
+		{
+			name:     "normalized",
+			allowed:  []string{"team-a", "team-b"},
+			orgID:    "team-b|team-a|team-b",
+			expected: []string{"team-a", "team-b"},
+		},
+		{
+			name:     "all tenants",
+			allowed:  []string{"*"},
+			orgID:    "team-a|team-c",
+			expected: []string{"team-a", "team-c"},
+		},
+		{
+			name:        "not allowed",
+			allowed:     []string{"team-a", "team-b"},
+			orgID:       "team-a|team-c",
+			expectedErr: ErrFederatedQueryNotAllowed,
+		},
+		{
+			name:        "no allow list",
+			allowed:     nil,
+			orgID:       "team-a|team-b",
+			expectedErr: ErrFederatedQueryNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := overrides.NewOverrides(overrides.Limits{FederatedQueryTenants: tt.allowed})
+			require.NoError(t, err)
+			q := &Querier{limits: limits}
+
+			tenantIDs, err := q.federatedTenants(user.InjectOrgID(context.Background(), tt.orgID))
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), err)
+				assert.Equal(t, http.StatusForbidden, queryErrorStatus(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tenantIDs)
+		})
+	}
+}
+
+func TestAnnotateTenant(t *testing.T) {
+	trace := &tempopb.Trace{
+		Batches: []*v1.ResourceSpans{
+			{},
+			{
+				Resource: &v1_resource.Resource{
+					Attributes: []*v1_common.KeyValue{
+						{Key: "service.name", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: "svc"}}},
+					},
+				},
+			},
+		},
+	}
+
+	annotateTenant(trace, "team-a")
+
+	for _, b := range trace.Batches {
+		require.NotNil(t, b.Resource)
+		last := b.Resource.Attributes[len(b.Resource.Attributes)-1]
+		assert.Equal(t, TenantAttribute, last.Key)
+		assert.Equal(t, "team-a", last.Value.GetStringValue())
+	}
+	assert.Len(t, trace.Batches[1].Resource.Attributes, 2)
+}
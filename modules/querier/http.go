@@ -1,20 +1,30 @@
 package querier
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/go-kit/kit/log/level"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/grafana/tempo/pkg/model"
 	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/traceformat"
 	"github.com/grafana/tempo/pkg/util"
 	"github.com/grafana/tempo/tempodb"
+	"github.com/grafana/tempo/tempodb/search"
 	"github.com/opentracing/opentracing-go"
 	ot_log "github.com/opentracing/opentracing-go/log"
 	"github.com/pkg/errors"
@@ -24,6 +34,8 @@ const (
 	BlockStartKey = "blockStart"
 	BlockEndKey   = "blockEnd"
 	QueryModeKey  = "mode"
+	TimeStartKey  = "start"
+	TimeEndKey    = "end"
 
 	QueryModeIngesters = "ingesters"
 	QueryModeBlocks    = "blocks"
@@ -32,8 +44,25 @@ const (
 	urlParamMinDuration = "minDuration"
 	urlParamMaxDuration = "maxDuration"
 	urlParamLimit       = "limit"
+	urlParamSpans       = "spans"
+	urlParamMaxSpans    = "maxSpans"
+	urlParamMaxDepth    = "maxDepth"
+	urlParamAttribute   = "attribute"
+	urlParamCursor      = "cursor"
+	urlParamSort        = "sort"
+	urlParamToken       = "continuationToken"
+	urlParamSince       = "since"
+
+	tailPingPeriod   = 30 * time.Second
+	tailWriteTimeout = 10 * time.Second
+	// control frames are limited to 125 bytes, two of which are the close code
+	maxCloseTextBytes = 123
+
+	ndjsonTypeHeaderValue = "application/x-ndjson"
 )
 
+var tailUpgrader = websocket.Upgrader{}
+
 // TraceByIDHandler is a http.HandlerFunc to retrieve traces
 func (q *Querier) TraceByIDHandler(w http.ResponseWriter, r *http.Request) {
 	// Enforce the query timeout while querying backends
@@ -55,52 +84,264 @@ func (q *Querier) TraceByIDHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	start, end, err := ParseTimeRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	trimOpts, err := ParseTrimOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	span.LogFields(
 		ot_log.String("msg", "validated request"),
 		ot_log.String("blockStart", blockStart),
 		ot_log.String("blockEnd", blockEnd),
-		ot_log.String("queryMode", queryMode))
+		ot_log.String("queryMode", queryMode),
+		ot_log.Uint32("start", start),
+		ot_log.Uint32("end", end))
 
 	resp, err := q.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
 		TraceID:    byteID,
 		BlockStart: blockStart,
 		BlockEnd:   blockEnd,
 		QueryMode:  queryMode,
+		Start:      start,
+		End:        end,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), queryErrorStatus(err))
 		return
 	}
 
+	SetCostHeaders(w.Header(), resp.Metrics)
+
 	if resp.Trace == nil || len(resp.Trace.Batches) == 0 {
 		http.Error(w, fmt.Sprintf("Unable to find %s", hex.EncodeToString(byteID)), http.StatusNotFound)
 		return
 	}
 
-	if r.Header.Get(util.AcceptHeaderKey) == util.ProtobufTypeHeaderValue {
-		span.SetTag("response marshalling format", util.ProtobufTypeHeaderValue)
-		b, err := proto.Marshal(resp.Trace)
+	// the query frontend cuts the requested spans once the parts found by its shards are combined, so only cut the
+	// spans it can't select down to what it needs to rank the others
+	resp.Trace = model.TrimTracePart(resp.Trace, trimOpts)
+
+	marshallingFormat := traceformat.Negotiate(r.Header.Get(util.AcceptHeaderKey))
+	span.SetTag("response marshalling format", marshallingFormat)
+	b, err := traceformat.Marshal(resp.Trace, marshallingFormat)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = q.checkResponseSize(r, len(b))
+	if err != nil {
+		http.Error(w, err.Error(), queryErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", marshallingFormat)
+	_, err = w.Write(b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// ParseTimeRange reads the optional start and end of a trace by id request in unix epoch seconds. A missing side of
+// the range is returned as 0, which is unbounded.
+func ParseTimeRange(r *http.Request) (uint32, uint32, error) {
+	var start, end uint32
+	if s := r.URL.Query().Get(TimeStartKey); s != "" {
+		v, err := strconv.ParseUint(s, 10, 32)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return 0, 0, errors.Wrap(err, "invalid value for start")
 		}
-		_, err = w.Write(b)
+		start = uint32(v)
+	}
+	if s := r.URL.Query().Get(TimeEndKey); s != "" {
+		v, err := strconv.ParseUint(s, 10, 32)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return 0, 0, errors.Wrap(err, "invalid value for end")
+		}
+		end = uint32(v)
+	}
+	if err := validateTimeRange(start, end); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// ParseTrimOptions reads the options of a trace by id request limiting the spans returned. The attribute is given as
+// key=value.
+func ParseTrimOptions(r *http.Request) (model.TrimOptions, error) {
+	opts := model.TrimOptions{}
+	query := r.URL.Query()
+
+	if s := query.Get(urlParamMaxSpans); s != "" {
+		maxSpans, err := strconv.Atoi(s)
+		if err != nil || maxSpans < 0 {
+			return opts, fmt.Errorf("invalid value for %s %s", urlParamMaxSpans, s)
+		}
+		opts.MaxSpans = maxSpans
+	}
+
+	if s := query.Get(urlParamMaxDepth); s != "" {
+		maxDepth, err := strconv.Atoi(s)
+		if err != nil || maxDepth < 0 {
+			return opts, fmt.Errorf("invalid value for %s %s", urlParamMaxDepth, s)
+		}
+		opts.MaxDepth = maxDepth
+	}
+
+	if s := query.Get(urlParamAttribute); s != "" {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return opts, fmt.Errorf("invalid value for %s %s, expected key=value", urlParamAttribute, s)
+		}
+		opts.AttributeKey, opts.AttributeValue = parts[0], parts[1]
+	}
+
+	if s := query.Get(urlParamCursor); s != "" {
+		if opts.MaxSpans == 0 {
+			return opts, fmt.Errorf("%s requires %s", urlParamCursor, urlParamMaxSpans)
+		}
+		offset, err := model.DecodeSpanCursor(s)
+		if err != nil {
+			return opts, err
 		}
+		opts.Offset = offset
+	}
+
+	return opts, nil
+}
+
+func validateTimeRange(start, end uint32) error {
+	if end != 0 && start > end {
+		return fmt.Errorf("invalid time range: start %d is after end %d", start, end)
+	}
+	return nil
+}
+
+// tracesByIDBody is the json body of a batch trace by id request. Start and end are optional unix epoch seconds
+// used to skip blocks outside of the time range.
+type tracesByIDBody struct {
+	TraceIDs []string `json:"traceIDs"`
+	Start    uint32   `json:"start"`
+	End      uint32   `json:"end"`
+}
+
+// TracesByIDHandler is a http.HandlerFunc to retrieve many traces at once. Json responses are newline delimited json,
+// one line per found trace followed by a line listing the trace ids that were not found. The whole response is built
+// before it is written, so its size is limited by max_batch_response_bytes and the tenant's max_response_size_bytes.
+func (q *Querier) TracesByIDHandler(w http.ResponseWriter, r *http.Request) {
+	// Enforce the query timeout while querying backends
+	ctx, cancel := context.WithDeadline(r.Context(), time.Now().Add(q.cfg.QueryTimeout))
+	defer cancel()
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.TracesByIDHandler")
+	defer span.Finish()
+
+	req, err := q.parseTracesByIDRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	span.SetTag("response marshalling format", util.JSONTypeHeaderValue)
-	marshaller := &jsonpb.Marshaler{}
-	err = marshaller.Marshal(w, resp.Trace)
+	resp, err := q.FindTracesByID(ctx, req)
+	if err != nil {
+		http.Error(w, err.Error(), queryErrorStatus(err))
+		return
+	}
+
+	marshallingFormat := ndjsonTypeHeaderValue
+	if r.Header.Get(util.AcceptHeaderKey) == util.ProtobufTypeHeaderValue {
+		marshallingFormat = util.ProtobufTypeHeaderValue
+	}
+	span.SetTag("response marshalling format", marshallingFormat)
+
+	b, err := q.marshalTracesByIDResponse(r, resp, marshallingFormat)
+	if err != nil {
+		http.Error(w, err.Error(), queryErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", marshallingFormat)
+	_, err = w.Write(b)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
+// marshalTracesByIDResponse marshals a batch trace by id response as a TracesByIDResponse proto or as newline
+// delimited json. Json responses are checked against the size limits after every trace, so oversized responses are
+// rejected before they are marshalled in full.
+func (q *Querier) marshalTracesByIDResponse(r *http.Request, resp *tempopb.TracesByIDResponse, marshallingFormat string) ([]byte, error) {
+	if marshallingFormat == util.ProtobufTypeHeaderValue {
+		if err := q.checkBatchResponseSize(r, resp.Size()); err != nil {
+			return nil, err
+		}
+		return proto.Marshal(resp)
+	}
+
+	var buf bytes.Buffer
+	marshaller := &jsonpb.Marshaler{}
+	writeLine := func(m proto.Message) error {
+		if err := marshaller.Marshal(&buf, m); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		return q.checkBatchResponseSize(r, buf.Len())
+	}
+
+	for _, t := range resp.Traces {
+		if err := writeLine(t); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeLine(&tempopb.TracesByIDResponse{NotFound: resp.NotFound}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// parseTracesByIDRequest reads the trace ids and time range of a batch trace by id request
+func (q *Querier) parseTracesByIDRequest(r *http.Request) (*tempopb.TracesByIDRequest, error) {
+	if r.Method != http.MethodPost {
+		return nil, fmt.Errorf("batch trace by id requests must be POSTed")
+	}
+
+	body := tracesByIDBody{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, errors.Wrap(err, "invalid request body")
+	}
+
+	if len(body.TraceIDs) == 0 {
+		return nil, fmt.Errorf("please provide traceIDs")
+	}
+	if q.cfg.MaxTracesPerBatch > 0 && len(body.TraceIDs) > q.cfg.MaxTracesPerBatch {
+		return nil, fmt.Errorf("too many traceIDs: %d > %d", len(body.TraceIDs), q.cfg.MaxTracesPerBatch)
+	}
+	if err := validateTimeRange(body.Start, body.End); err != nil {
+		return nil, err
+	}
+
+	req := &tempopb.TracesByIDRequest{
+		TraceIDs: make([][]byte, 0, len(body.TraceIDs)),
+		Start:    body.Start,
+		End:      body.End,
+	}
+	for _, id := range body.TraceIDs {
+		byteID, err := util.HexStringToTraceID(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid traceID %s", id)
+		}
+		req.TraceIDs = append(req.TraceIDs, byteID)
+	}
+
+	return req, nil
+}
+
 // return values are (blockStart, blockEnd, queryMode, error)
 func validateAndSanitizeRequest(r *http.Request) (string, string, string, error) {
 	q := r.URL.Query().Get(QueryModeKey)
@@ -156,13 +397,60 @@ func (q *Querier) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.SearchHandler")
 	defer span.Finish()
 
+	req, err := parseSearchRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := q.Search(ctx, req)
+	if err != nil {
+		http.Error(w, err.Error(), queryErrorStatus(err))
+		return
+	}
+
+	var buf bytes.Buffer
+	marshaller := &jsonpb.Marshaler{}
+	err = marshaller.Marshal(&buf, resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = q.checkResponseSize(r, buf.Len())
+	if err != nil {
+		http.Error(w, err.Error(), queryErrorStatus(err))
+		return
+	}
+
+	SetCostHeaders(w.Header(), resp.Metrics)
+	_, err = w.Write(buf.Bytes())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// queryErrorStatus returns the status code to respond with for an error of a query
+func queryErrorStatus(err error) int {
+	if errors.Is(err, ErrFederatedQueryNotAllowed) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, ErrQueryLimitExceeded) || errors.Is(err, search.ErrInvalidContinuationToken) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// parseSearchRequest reads the search parameters of the request. Every unknown parameter is a tag to match.
+func parseSearchRequest(r *http.Request) (*tempopb.SearchRequest, error) {
 	req := &tempopb.SearchRequest{
 		Tags: map[string]string{},
 	}
 
 	for k, v := range r.URL.Query() {
 		// Skip known values
-		if k == urlParamMinDuration || k == urlParamMaxDuration || k == urlParamLimit {
+		if k == urlParamMinDuration || k == urlParamMaxDuration || k == urlParamLimit || k == TimeStartKey || k == TimeEndKey ||
+			k == urlParamSort || k == urlParamToken {
 			continue
 		}
 
@@ -174,7 +462,7 @@ func (q *Querier) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	if s := r.URL.Query().Get(urlParamMinDuration); s != "" {
 		dur, err := time.ParseDuration(s)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, err
 		}
 		req.MinDurationMs = uint32(dur.Milliseconds())
 	}
@@ -182,7 +470,7 @@ func (q *Querier) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	if s := r.URL.Query().Get(urlParamMaxDuration); s != "" {
 		dur, err := time.ParseDuration(s)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, err
 		}
 		req.MaxDurationMs = uint32(dur.Milliseconds())
 	}
@@ -190,24 +478,96 @@ func (q *Querier) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	if s := r.URL.Query().Get(urlParamLimit); s != "" {
 		limit, err := strconv.Atoi(s)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+			return nil, err
 		}
 		req.Limit = uint32(limit)
 	}
 
-	resp, err := q.Search(ctx, req)
+	start, end, err := ParseTimeRange(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
+	req.Start = start
+	req.End = end
 
-	marshaller := &jsonpb.Marshaler{}
-	err = marshaller.Marshal(w, resp)
+	if s := r.URL.Query().Get(urlParamSort); s != "" {
+		v, ok := tempopb.SearchSort_value[strings.ToUpper(s)]
+		if !ok {
+			return nil, fmt.Errorf("invalid value for %s %s", urlParamSort, s)
+		}
+		req.Sort = tempopb.SearchSort(v)
+	}
+
+	req.ContinuationToken = r.URL.Query().Get(urlParamToken)
+	err = search.ResolveSort(req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// parseTagValuesFilter reads the filter of a tag values request. The parameters are the same as for search, plus
+// since, the window of recent traces to look at when there is no start. It returns nil if there are no parameters.
+func parseTagValuesFilter(r *http.Request, now time.Time) (*tempopb.SearchRequest, error) {
+	if len(r.URL.Query()) == 0 {
+		return nil, nil
+	}
+
+	filter, err := parseSearchRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	delete(filter.Tags, urlParamSince)
+
+	if s := r.URL.Query().Get(urlParamSince); s != "" {
+		since, err := time.ParseDuration(s)
+		if err != nil || since <= 0 {
+			return nil, fmt.Errorf("invalid value for %s %s", urlParamSince, s)
+		}
+		if filter.Start == 0 {
+			end := now
+			if filter.End != 0 {
+				end = time.Unix(int64(filter.End), 0)
+			}
+			if start := end.Add(-since).Unix(); start > 0 {
+				filter.Start = uint32(start)
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// SearchRequestQuery returns the query parameters of a search http request for the search request, the reverse of
+//  parsing the request
+func SearchRequestQuery(req *tempopb.SearchRequest) url.Values {
+	q := url.Values{}
+	for k, v := range req.Tags {
+		q.Set(k, v)
+	}
+	if req.MinDurationMs != 0 {
+		q.Set(urlParamMinDuration, (time.Duration(req.MinDurationMs) * time.Millisecond).String())
+	}
+	if req.MaxDurationMs != 0 {
+		q.Set(urlParamMaxDuration, (time.Duration(req.MaxDurationMs) * time.Millisecond).String())
+	}
+	if req.Limit != 0 {
+		q.Set(urlParamLimit, strconv.FormatUint(uint64(req.Limit), 10))
+	}
+	if req.Start != 0 {
+		q.Set(TimeStartKey, strconv.FormatUint(uint64(req.Start), 10))
+	}
+	if req.End != 0 {
+		q.Set(TimeEndKey, strconv.FormatUint(uint64(req.End), 10))
+	}
+	if req.Sort != tempopb.SearchSort_FIRST_FOUND {
+		q.Set(urlParamSort, strings.ToLower(req.Sort.String()))
 	}
+	if req.ContinuationToken != "" {
+		q.Set(urlParamToken, req.ContinuationToken)
+	}
+	return q
 }
 
 func (q *Querier) SearchTagsHandler(w http.ResponseWriter, r *http.Request) {
@@ -252,9 +612,16 @@ func (q *Querier) SearchTagValuesHandler(w http.ResponseWriter, r *http.Request)
 		TagName: tagName,
 	}
 
+	filter, err := parseTagValuesFilter(r, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Filter = filter
+
 	resp, err := q.SearchTagValues(ctx, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), queryErrorStatus(err))
 		return
 	}
 
@@ -265,3 +632,82 @@ func (q *Querier) SearchTagValuesHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// TailHandler upgrades the request to a websocket and streams the pushes matching the search parameters as json
+// messages until the client disconnects. Spans are included if the spans parameter is true.
+func (q *Querier) TailHandler(w http.ResponseWriter, r *http.Request) {
+	searchReq, err := parseSearchRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	delete(searchReq.Tags, urlParamSpans)
+
+	req := &tempopb.TailRequest{
+		Search: searchReq,
+	}
+	if s := r.URL.Query().Get(urlParamSpans); s != "" {
+		req.IncludeSpans, err = strconv.ParseBool(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	conn, err := tailUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already replied with an error
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// reading processes control frames and notices the client going away
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// keep idle tails alive through proxies. control frames may be written concurrently with messages
+	go func() {
+		ticker := time.NewTicker(tailPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(tailWriteTimeout)); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	marshaller := &jsonpb.Marshaler{}
+	err = q.Tail(ctx, req, func(resp *tempopb.TailResponse) error {
+		msg, err := marshaller.MarshalToString(resp)
+		if err != nil {
+			return err
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(tailWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, []byte(msg))
+	})
+
+	closeCode, closeText := websocket.CloseNormalClosure, ""
+	if err != nil {
+		level.Error(log.Logger).Log("msg", "live tail failed", "err", err)
+		closeCode, closeText = websocket.CloseInternalServerErr, err.Error()
+		if len(closeText) > maxCloseTextBytes {
+			closeText = closeText[:maxCloseTextBytes]
+		}
+	}
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, closeText), time.Now().Add(tailWriteTimeout))
+}
@@ -0,0 +1,156 @@
+package querier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/tempodb"
+)
+
+// Headers with the cost of a query, set on trace by id and search responses
+const (
+	InspectedTracesHeader = "X-Tempo-Inspected-Traces"
+	InspectedBytesHeader  = "X-Tempo-Inspected-Bytes"
+	InspectedBlocksHeader = "X-Tempo-Inspected-Blocks"
+	SkippedBlocksHeader   = "X-Tempo-Skipped-Blocks"
+)
+
+// ErrQueryLimitExceeded is returned for queries over one of the per tenant query limits
+var ErrQueryLimitExceeded = errors.New("query limit exceeded")
+
+// LimitSearchTimeRange enforces the max search duration on the time range of the request. Searches without a start
+//  are limited to the window before their end, or now if they have no end either.
+func LimitSearchTimeRange(req *tempopb.SearchRequest, max time.Duration, now time.Time) error {
+	if max <= 0 {
+		return nil
+	}
+
+	end := req.End
+	if end == 0 {
+		end = uint32(now.Unix())
+	}
+	window := uint32(max / time.Second)
+
+	if req.Start == 0 {
+		if end > window {
+			req.Start = end - window
+		}
+		return nil
+	}
+
+	if end > req.Start && end-req.Start > window {
+		return fmt.Errorf("%w: search time range %s is longer than max_search_duration %s", ErrQueryLimitExceeded, time.Duration(end-req.Start)*time.Second, max)
+	}
+	return nil
+}
+
+// checkQueryCost returns an error if the query inspected more than the tenant limits allow. max_bytes_per_query is
+// the budget of each ingester, which stops searching once it is exceeded, so it is checked against the most bytes
+// inspected by a single ingester rather than the total of the query.
+func (q *Querier) checkQueryCost(userID string, m *tempopb.SearchMetrics, maxIngesterBytes uint64) error {
+	if m == nil {
+		return nil
+	}
+	if max := q.limits.MaxBlocksPerQuery(userID); max > 0 && int(m.InspectedBlocks) > max {
+		return fmt.Errorf("%w: query inspected %d blocks, max_blocks_per_query is %d", ErrQueryLimitExceeded, m.InspectedBlocks, max)
+	}
+	if max := q.limits.MaxBytesPerQuery(userID); max > 0 && maxIngesterBytes > uint64(max) {
+		return fmt.Errorf("%w: an ingester inspected more than max_bytes_per_query %d bytes", ErrQueryLimitExceeded, max)
+	}
+	return nil
+}
+
+// maxInspectedBytes returns the most bytes inspected by one of the ingesters of a search
+func maxInspectedBytes(rr []responseFromIngesters) uint64 {
+	var max uint64
+	for _, r := range rr {
+		if m := r.response.(*tempopb.SearchResponse).Metrics; m != nil && m.InspectedBytes > max {
+			max = m.InspectedBytes
+		}
+	}
+	return max
+}
+
+// checkBlocksForFind returns an error if the trace by id query would inspect more blocks than the tenant limit. The
+// frontend shards the query by block id, so the blocks of all of the shards are counted: every shard of a query over
+// the limit is rejected before searching.
+func (q *Querier) checkBlocksForFind(userID string, req *tempopb.TraceByIDRequest) error {
+	max := q.limits.MaxBlocksPerQuery(userID)
+	if max <= 0 {
+		return nil
+	}
+
+	blockCount, err := q.store.CountBlocksForFind(userID, req.TraceID, tempodb.BlockIDMin, tempodb.BlockIDMax, int64(req.Start), int64(req.End))
+	if err != nil {
+		return fmt.Errorf("error counting blocks in Querier.FindTraceByID: %w", err)
+	}
+	if blockCount > max {
+		return fmt.Errorf("%w: trace by id would inspect %d blocks, max_blocks_per_query is %d", ErrQueryLimitExceeded, blockCount, max)
+	}
+	return nil
+}
+
+// checkResponseSize returns an error if the response to the request is larger than the tenant limit
+func (q *Querier) checkResponseSize(r *http.Request, size int) error {
+	userID, _ := user.ExtractOrgID(r.Context())
+	if max := q.limits.MaxResponseSizeBytes(userID); max > 0 && size > max {
+		return fmt.Errorf("%w: response of %d bytes is larger than max_response_size_bytes %d", ErrQueryLimitExceeded, size, max)
+	}
+	return nil
+}
+
+// checkBatchResponseSize returns an error if a batch trace by id response is larger than the tenant limit or the
+// querier's max_batch_response_bytes
+func (q *Querier) checkBatchResponseSize(r *http.Request, size int) error {
+	if err := q.checkResponseSize(r, size); err != nil {
+		return err
+	}
+	if max := q.cfg.MaxBatchResponseBytes; max > 0 && size > max {
+		return fmt.Errorf("%w: response is larger than max_batch_response_bytes %d, request fewer traces", ErrQueryLimitExceeded, max)
+	}
+	return nil
+}
+
+// SetCostHeaders sets the cost headers of a response from the metrics of the query
+func SetCostHeaders(h http.Header, m *tempopb.SearchMetrics) {
+	if m == nil {
+		return
+	}
+	h.Set(InspectedTracesHeader, strconv.FormatUint(uint64(m.InspectedTraces), 10))
+	h.Set(InspectedBytesHeader, strconv.FormatUint(m.InspectedBytes, 10))
+	h.Set(InspectedBlocksHeader, strconv.FormatUint(uint64(m.InspectedBlocks), 10))
+	h.Set(SkippedBlocksHeader, strconv.FormatUint(uint64(m.SkippedBlocks), 10))
+}
+
+// CostFromHeaders reads the metrics of a query from the cost headers of its response. Missing or invalid headers
+//  are 0.
+func CostFromHeaders(h http.Header) *tempopb.SearchMetrics {
+	parse := func(key string) uint64 {
+		v, _ := strconv.ParseUint(h.Get(key), 10, 64)
+		return v
+	}
+
+	return &tempopb.SearchMetrics{
+		InspectedTraces: uint32(parse(InspectedTracesHeader)),
+		InspectedBytes:  parse(InspectedBytesHeader),
+		InspectedBlocks: uint32(parse(InspectedBlocksHeader)),
+		SkippedBlocks:   uint32(parse(SkippedBlocksHeader)),
+	}
+}
+
+// AddMetrics adds the metrics of m to total
+func AddMetrics(total, m *tempopb.SearchMetrics) {
+	if m == nil {
+		return
+	}
+	total.InspectedTraces += m.InspectedTraces
+	total.InspectedBytes += m.InspectedBytes
+	total.InspectedBlocks += m.InspectedBlocks
+	total.SkippedBlocks += m.SkippedBlocks
+}
@@ -0,0 +1,159 @@
+package querier
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/modules/storage"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/pkg/util/test"
+	"github.com/grafana/tempo/tempodb"
+	"github.com/grafana/tempo/tempodb/encoding/common"
+)
+
+func TestLimitSearchTimeRange(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	tests := []struct {
+		name                       string
+		start, end                 uint32
+		max                        time.Duration
+		expectedStart, expectedEnd uint32
+		expectedErr                bool
+	}{
+		{name: "no limit", start: 0, end: 0, expectedStart: 0, expectedEnd: 0},
+		{name: "no range", max: time.Hour, expectedStart: 6400, expectedEnd: 0},
+		{name: "no start", end: 5000, max: time.Hour, expectedStart: 1400, expectedEnd: 5000},
+		{name: "no start before epoch", end: 1000, max: time.Hour, expectedStart: 0, expectedEnd: 1000},
+		{name: "within", start: 4000, end: 5000, max: time.Hour, expectedStart: 4000, expectedEnd: 5000},
+		{name: "too long", start: 1000, end: 5000, max: time.Hour, expectedErr: true},
+		{name: "no end too long", start: 1000, max: time.Hour, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &tempopb.SearchRequest{Start: tt.start, End: tt.end}
+			err := LimitSearchTimeRange(req, tt.max, now)
+			if tt.expectedErr {
+				assert.True(t, errors.Is(err, ErrQueryLimitExceeded))
+				assert.Equal(t, http.StatusBadRequest, queryErrorStatus(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStart, req.Start)
+			assert.Equal(t, tt.expectedEnd, req.End)
+		})
+	}
+}
+
+func TestCheckQueryCost(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxBlocksPerQuery: 10, MaxBytesPerQuery: 1000, MaxResponseSizeBytes: 100})
+	require.NoError(t, err)
+	q := &Querier{limits: limits}
+
+	assert.NoError(t, q.checkQueryCost("test", nil, 0))
+	assert.NoError(t, q.checkQueryCost("test", &tempopb.SearchMetrics{InspectedBlocks: 10, InspectedBytes: 1000}, 1000))
+	assert.True(t, errors.Is(q.checkQueryCost("test", &tempopb.SearchMetrics{InspectedBlocks: 11}, 0), ErrQueryLimitExceeded))
+	assert.True(t, errors.Is(q.checkQueryCost("test", &tempopb.SearchMetrics{InspectedBytes: 1001}, 1001), ErrQueryLimitExceeded))
+
+	// the bytes limit is per ingester: every ingester of the search stopped within its budget
+	responses := []responseFromIngesters{
+		{response: &tempopb.SearchResponse{Metrics: &tempopb.SearchMetrics{InspectedBytes: 1000}}},
+		{response: &tempopb.SearchResponse{Metrics: &tempopb.SearchMetrics{InspectedBytes: 900}}},
+		{response: &tempopb.SearchResponse{}},
+	}
+	assert.Equal(t, uint64(1000), maxInspectedBytes(responses))
+	assert.NoError(t, q.checkQueryCost("test", &tempopb.SearchMetrics{InspectedBytes: 1900}, maxInspectedBytes(responses)))
+
+	r := httptest.NewRequest(http.MethodGet, "/api/search", nil)
+	assert.NoError(t, q.checkResponseSize(r, 100))
+	assert.True(t, errors.Is(q.checkResponseSize(r, 101), ErrQueryLimitExceeded))
+}
+
+// blockCountStore has 4 blocks in each of the 4 block id shards
+type blockCountStore struct {
+	storage.Store
+}
+
+func (blockCountStore) CountBlocksForFind(_ string, _ common.ID, blockStart string, blockEnd string, _ int64, _ int64) (int, error) {
+	if blockStart == tempodb.BlockIDMin && blockEnd == tempodb.BlockIDMax {
+		return 16, nil
+	}
+	return 4, nil
+}
+
+func TestCheckBlocksForFind(t *testing.T) {
+	shard := &tempopb.TraceByIDRequest{BlockStart: "40000000-0000-0000-0000-000000000000", BlockEnd: "7fffffff-ffff-ffff-ffff-ffffffffffff"}
+
+	for max, expectedErr := range map[int]bool{0: false, 16: false, 8: true} {
+		limits, err := overrides.NewOverrides(overrides.Limits{MaxBlocksPerQuery: max})
+		require.NoError(t, err)
+		q := &Querier{limits: limits, store: blockCountStore{}}
+
+		// the shard is within the limit but the whole query isn't
+		err = q.checkBlocksForFind("test", shard)
+		if expectedErr {
+			assert.True(t, errors.Is(err, ErrQueryLimitExceeded), max)
+		} else {
+			assert.NoError(t, err, max)
+		}
+	}
+}
+
+func TestMarshalTracesByIDResponseSize(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{})
+	require.NoError(t, err)
+
+	resp := &tempopb.TracesByIDResponse{NotFound: []string{"6b1e3a8f5c3e5b2a"}}
+	for i := 0; i < 10; i++ {
+		resp.Traces = append(resp.Traces, &tempopb.TraceByIDResult{
+			TraceID: "2f3e0cee77ae5dc9c17ade3689eb2e54",
+			Trace:   test.MakeTrace(10, nil),
+		})
+	}
+	r := httptest.NewRequest(http.MethodPost, "/api/traces", nil)
+
+	for _, format := range []string{ndjsonTypeHeaderValue, util.ProtobufTypeHeaderValue} {
+		t.Run(format, func(t *testing.T) {
+			q := &Querier{limits: limits}
+			b, err := q.marshalTracesByIDResponse(r, resp, format)
+			require.NoError(t, err)
+
+			q.cfg.MaxBatchResponseBytes = len(b)
+			_, err = q.marshalTracesByIDResponse(r, resp, format)
+			require.NoError(t, err)
+
+			q.cfg.MaxBatchResponseBytes = len(b) - 1
+			_, err = q.marshalTracesByIDResponse(r, resp, format)
+			assert.True(t, errors.Is(err, ErrQueryLimitExceeded))
+			assert.Equal(t, http.StatusBadRequest, queryErrorStatus(err))
+		})
+	}
+}
+
+func TestCostHeaders(t *testing.T) {
+	m := &tempopb.SearchMetrics{
+		InspectedTraces: 1,
+		InspectedBytes:  2,
+		InspectedBlocks: 3,
+		SkippedBlocks:   4,
+	}
+
+	h := http.Header{}
+	SetCostHeaders(h, m)
+	assert.Equal(t, m, CostFromHeaders(h))
+
+	total := CostFromHeaders(http.Header{})
+	assert.Equal(t, &tempopb.SearchMetrics{}, total)
+	AddMetrics(total, m)
+	AddMetrics(total, m)
+	assert.Equal(t, &tempopb.SearchMetrics{InspectedTraces: 2, InspectedBytes: 4, InspectedBlocks: 6, SkippedBlocks: 8}, total)
+}
@@ -3,8 +3,10 @@ package querier
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"sort"
+	"time"
 
 	cortex_worker "github.com/cortexproject/cortex/pkg/querier/worker"
 	"github.com/cortexproject/cortex/pkg/ring"
@@ -24,7 +26,10 @@ import (
 	"github.com/grafana/tempo/modules/storage"
 	"github.com/grafana/tempo/pkg/model"
 	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
 	"github.com/grafana/tempo/pkg/validation"
+	"github.com/grafana/tempo/tempodb/encoding/common"
+	"github.com/grafana/tempo/tempodb/search"
 )
 
 var (
@@ -151,11 +156,25 @@ func (q *Querier) FindTraceByID(ctx context.Context, req *tempopb.TraceByIDReque
 		return nil, errors.Wrap(err, "error extracting org id in Querier.FindTraceByID")
 	}
 
+	tenantIDs, err := q.federatedTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantIDs != nil {
+		return q.findTraceByIDFederated(ctx, tenantIDs, req)
+	}
+
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.FindTraceByID")
 	defer span.Finish()
 
+	padded := *req
+	padded.Start, padded.End = q.padTimeRange(req.Start, req.End)
+	req = &padded
+	span.LogFields(ot_log.Uint32("start", req.Start), ot_log.Uint32("end", req.End))
+
 	var completeTrace *tempopb.Trace
 	var spanCount, spanCountTotal, traceCountTotal int
+	metrics := &tempopb.SearchMetrics{}
 	if req.QueryMode == QueryModeIngesters || req.QueryMode == QueryModeAll {
 		replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
 		if err != nil {
@@ -186,32 +205,32 @@ func (q *Querier) FindTraceByID(ctx context.Context, req *tempopb.TraceByIDReque
 	}
 
 	if req.QueryMode == QueryModeBlocks || req.QueryMode == QueryModeAll {
-		span.LogFields(ot_log.String("msg", "searching store"))
-		partialTraces, dataEncodings, err := q.store.Find(opentracing.ContextWithSpan(ctx, span), userID, req.TraceID, req.BlockStart, req.BlockEnd)
+		if err := q.checkBlocksForFind(userID, req); err != nil {
+			return nil, err
+		}
+		blockCount, err := q.store.CountBlocksForFind(userID, req.TraceID, req.BlockStart, req.BlockEnd, int64(req.Start), int64(req.End))
+		if err != nil {
+			return nil, errors.Wrap(err, "error querying store in Querier.FindTraceByID")
+		}
+		metrics.InspectedBlocks = uint32(blockCount)
+
+		span.LogFields(ot_log.String("msg", "searching store"), ot_log.Int("blocks", blockCount))
+		partialTraces, dataEncodings, err := q.store.Find(opentracing.ContextWithSpan(ctx, span), userID, req.TraceID, req.BlockStart, req.BlockEnd, int64(req.Start), int64(req.End))
 		if err != nil {
 			return nil, errors.Wrap(err, "error querying store in Querier.FindTraceByID")
 		}
 
 		span.LogFields(ot_log.String("msg", "done searching store"))
+		for _, t := range partialTraces {
+			metrics.InspectedBytes += uint64(len(t))
+		}
 
 		if len(partialTraces) != 0 {
 			traceCountTotal = 0
 			spanCountTotal = 0
-			// combine partialTraces
-			var allBytes []byte
-			baseEncoding := dataEncodings[0] // just arbitrarily choose an encoding. generally they will all be the same
-			for i, partialTrace := range partialTraces {
-				dataEncoding := dataEncodings[i]
-				allBytes, _, err = model.CombineTraceBytes(allBytes, partialTrace, baseEncoding, dataEncoding)
-				if err != nil {
-					return nil, errors.Wrap(err, "error querying store in Querier.FindTraceByID")
-				}
-			}
-
-			// marshal to proto and add to completeTrace
-			storeTrace, err := model.Unmarshal(allBytes, baseEncoding)
+			storeTrace, err := combinePartialTraces(partialTraces, dataEncodings)
 			if err != nil {
-				return nil, errors.Wrap(err, "error unmarshaling combined trace in Querier.FindTraceByID")
+				return nil, errors.Wrap(err, "error querying store in Querier.FindTraceByID")
 			}
 
 			completeTrace, _, _, spanCount = model.CombineTraceProtos(completeTrace, storeTrace)
@@ -226,10 +245,155 @@ func (q *Querier) FindTraceByID(ctx context.Context, req *tempopb.TraceByIDReque
 	}
 
 	return &tempopb.TraceByIDResponse{
-		Trace: completeTrace,
+		Trace:   completeTrace,
+		Metrics: metrics,
 	}, nil
 }
 
+// FindTracesByID looks up many traces at once. The ingesters are asked for all of the traces in a single request
+// and every block of the store is searched once for all of the ids within its id range.
+func (q *Querier) FindTracesByID(ctx context.Context, req *tempopb.TracesByIDRequest) (*tempopb.TracesByIDResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting org id in Querier.FindTracesByID")
+	}
+
+	tenantIDs, err := q.federatedTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantIDs != nil {
+		return q.findTracesByIDFederated(ctx, tenantIDs, req)
+	}
+
+	// dedupe the ids, keeping the order of the request
+	ids := make([]common.ID, 0, len(req.TraceIDs))
+	seen := make(map[string]struct{}, len(req.TraceIDs))
+	for _, id := range req.TraceIDs {
+		if !validation.ValidTraceID(id) {
+			return nil, fmt.Errorf("invalid trace id")
+		}
+		key := util.TraceIDToHexString(id)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no trace ids")
+	}
+	if q.cfg.MaxTracesPerBatch > 0 && len(ids) > q.cfg.MaxTracesPerBatch {
+		return nil, fmt.Errorf("too many trace ids: %d > %d", len(ids), q.cfg.MaxTracesPerBatch)
+	}
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.FindTracesByID")
+	defer span.Finish()
+	start, end := q.padTimeRange(req.Start, req.End)
+	span.LogFields(ot_log.Int("ids", len(ids)), ot_log.Uint32("start", start), ot_log.Uint32("end", end))
+
+	traces := make(map[string]*tempopb.Trace, len(ids))
+
+	replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding ingesters in Querier.FindTracesByID")
+	}
+
+	ingesterReq := &tempopb.TracesByIDRequest{TraceIDs: make([][]byte, 0, len(ids)), Start: start, End: end}
+	for _, id := range ids {
+		ingesterReq.TraceIDs = append(ingesterReq.TraceIDs, id)
+	}
+	responses, err := q.forGivenIngesters(ctx, replicationSet, func(client tempopb.QuerierClient) (interface{}, error) {
+		return client.FindTracesByID(opentracing.ContextWithSpan(ctx, span), ingesterReq)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying ingesters in Querier.FindTracesByID")
+	}
+	for _, r := range responses {
+		for _, found := range r.response.(*tempopb.TracesByIDResponse).Traces {
+			traces[found.TraceID], _, _, _ = model.CombineTraceProtos(traces[found.TraceID], found.Trace)
+		}
+	}
+	span.LogFields(ot_log.String("msg", "done searching ingesters"), ot_log.Int("found", len(traces)))
+
+	results, err := q.store.FindMany(opentracing.ContextWithSpan(ctx, span), userID, ids, int64(start), int64(end))
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying store in Querier.FindTracesByID")
+	}
+	for i, result := range results {
+		if len(result.Objects) == 0 {
+			continue
+		}
+
+		storeTrace, err := combinePartialTraces(result.Objects, result.DataEncodings)
+		if err != nil {
+			return nil, errors.Wrap(err, "error querying store in Querier.FindTracesByID")
+		}
+
+		key := util.TraceIDToHexString(ids[i])
+		traces[key], _, _, _ = model.CombineTraceProtos(traces[key], storeTrace)
+	}
+	span.LogFields(ot_log.String("msg", "done searching store"), ot_log.Int("found", len(traces)))
+
+	resp := &tempopb.TracesByIDResponse{}
+	for _, id := range ids {
+		key := util.TraceIDToHexString(id)
+		trace := traces[key]
+		if trace == nil || len(trace.Batches) == 0 {
+			resp.NotFound = append(resp.NotFound, key)
+			continue
+		}
+		resp.Traces = append(resp.Traces, &tempopb.TraceByIDResult{
+			TraceID: key,
+			Trace:   trace,
+		})
+	}
+
+	return resp, nil
+}
+
+// padTimeRange widens a trace by id time range hint by the configured slack. Blocks are timed by when the spans were
+// written rather than when they happened, and callers usually only know roughly when the trace happened. An unbounded
+// side of the range stays unbounded.
+func (q *Querier) padTimeRange(start, end uint32) (uint32, uint32) {
+	slack := uint32(q.cfg.TraceByIDTimeSlack.Seconds())
+	if start > 0 {
+		if start > slack {
+			start -= slack
+		} else {
+			start = 0
+		}
+	}
+	if end > 0 {
+		if end > math.MaxUint32-slack {
+			end = 0
+		} else {
+			end += slack
+		}
+	}
+	return start, end
+}
+
+// combinePartialTraces combines the partial traces found in the blocks of the store
+func combinePartialTraces(partialTraces [][]byte, dataEncodings []string) (*tempopb.Trace, error) {
+	var allBytes []byte
+	var err error
+	baseEncoding := dataEncodings[0] // just arbitrarily choose an encoding. generally they will all be the same
+	for i, partialTrace := range partialTraces {
+		allBytes, _, err = model.CombineTraceBytes(allBytes, partialTrace, baseEncoding, dataEncodings[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// marshal to proto
+	trace, err := model.Unmarshal(allBytes, baseEncoding)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling combined trace")
+	}
+	return trace, nil
+}
+
 // forGivenIngesters runs f, in parallel, for given ingesters
 func (q *Querier) forGivenIngesters(ctx context.Context, replicationSet ring.ReplicationSet, f func(client tempopb.QuerierClient) (interface{}, error)) ([]responseFromIngesters, error) {
 	results, err := replicationSet.Do(ctx, q.cfg.ExtraQueryDelay, func(ctx context.Context, ingester *ring.InstanceDesc) (interface{}, error) {
@@ -258,24 +422,57 @@ func (q *Querier) forGivenIngesters(ctx context.Context, replicationSet ring.Rep
 }
 
 func (q *Querier) Search(ctx context.Context, req *tempopb.SearchRequest) (*tempopb.SearchResponse, error) {
-	_, err := user.ExtractOrgID(ctx)
+	userID, err := user.ExtractOrgID(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "error extracting org id in Querier.Search")
 	}
 
+	tenantIDs, err := q.federatedTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantIDs != nil {
+		return q.searchFederated(ctx, tenantIDs, req)
+	}
+
+	// the request is shared by the tenants of a federated search, which can have different limits
+	limited := *req
+	err = LimitSearchTimeRange(&limited, q.limits.MaxSearchDuration(userID), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	err = search.ResolveSort(&limited)
+	if err != nil {
+		return nil, err
+	}
+	req = &limited
+
 	replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
 	if err != nil {
 		return nil, errors.Wrap(err, "error finding ingesters in Querier.Search")
 	}
 
+	ingesterReq, err := search.SourceRequest(req)
+	if err != nil {
+		return nil, err
+	}
 	responses, err := q.forGivenIngesters(ctx, replicationSet, func(client tempopb.QuerierClient) (interface{}, error) {
-		return client.Search(ctx, req)
+		return client.Search(ctx, ingesterReq)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "error querying ingesters in Querier.Search")
 	}
 
-	return q.postProcessSearchResults(req, responses), nil
+	resp, err := q.postProcessSearchResults(req, responses)
+	if err != nil {
+		return nil, err
+	}
+	err = q.checkQueryCost(userID, resp.Metrics, maxInspectedBytes(responses))
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
 }
 
 func (q *Querier) SearchTags(ctx context.Context, req *tempopb.SearchTagsRequest) (*tempopb.SearchTagsResponse, error) {
@@ -299,10 +496,14 @@ func (q *Querier) SearchTags(ctx context.Context, req *tempopb.SearchTagsRequest
 
 	// Collect only unique values
 	uniqueMap := map[string]struct{}{}
+	highCardinalityMap := map[string]struct{}{}
 	for _, resp := range lookupResults {
 		for _, res := range resp.response.(*tempopb.SearchTagsResponse).TagNames {
 			uniqueMap[res] = struct{}{}
 		}
+		for _, res := range resp.response.(*tempopb.SearchTagsResponse).HighCardinalityTagNames {
+			highCardinalityMap[res] = struct{}{}
+		}
 	}
 
 	// Final response (sorted)
@@ -313,16 +514,33 @@ func (q *Querier) SearchTags(ctx context.Context, req *tempopb.SearchTagsRequest
 		resp.TagNames = append(resp.TagNames, k)
 	}
 	sort.Strings(resp.TagNames)
+	for k := range highCardinalityMap {
+		resp.HighCardinalityTagNames = append(resp.HighCardinalityTagNames, k)
+	}
+	sort.Strings(resp.HighCardinalityTagNames)
 
 	return resp, nil
 }
 
 func (q *Querier) SearchTagValues(ctx context.Context, req *tempopb.SearchTagValuesRequest) (*tempopb.SearchTagValuesResponse, error) {
-	_, err := user.ExtractOrgID(ctx)
+	userID, err := user.ExtractOrgID(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "error extracting org id in Querier.SearchTagValues")
 	}
 
+	// filtered values are computed by searching, so they are limited like searches
+	if req.Filter != nil {
+		filter := *req.Filter
+		err = LimitSearchTimeRange(&filter, q.limits.MaxSearchDuration(userID), time.Now())
+		if err != nil {
+			return nil, err
+		}
+		req = &tempopb.SearchTagValuesRequest{
+			TagName: req.TagName,
+			Filter:  &filter,
+		}
+	}
+
 	replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
 	if err != nil {
 		return nil, errors.Wrap(err, "error finding ingesters in Querier.SearchTagValues")
@@ -336,17 +554,38 @@ func (q *Querier) SearchTagValues(ctx context.Context, req *tempopb.SearchTagVal
 		return nil, errors.Wrap(err, "error querying ingesters in Querier.SearchTagValues")
 	}
 
-	// Collect only unique values
+	// Collect only unique values. The tag is high cardinality if any ingester dropped values.
 	uniqueMap := map[string]struct{}{}
+	highCardinality := false
+	var counts map[string]uint32
 	for _, resp := range lookupResults {
 		for _, res := range resp.response.(*tempopb.SearchTagValuesResponse).TagValues {
 			uniqueMap[res] = struct{}{}
 		}
+		highCardinality = highCardinality || resp.response.(*tempopb.SearchTagValuesResponse).HighCardinality
+
+		for v, c := range resp.response.(*tempopb.SearchTagValuesResponse).EstimatedCounts {
+			if counts == nil {
+				counts = map[string]uint32{}
+			}
+			counts[v] += c
+		}
+	}
+
+	// every trace is counted by each ingester it is replicated to. the ingesters only return counts, not the traces, so
+	// they can't be deduped and this is an estimate. like the values, it only covers the traces the ingesters still have,
+	// not the backend blocks, whatever the time range of the filter
+	if rf := uint32(q.ring.ReplicationFactor()); rf > 1 {
+		for v, c := range counts {
+			counts[v] = (c + rf - 1) / rf
+		}
 	}
 
 	// Final response (sorted)
 	resp := &tempopb.SearchTagValuesResponse{
-		TagValues: make([]string, 0, len(uniqueMap)),
+		TagValues:       make([]string, 0, len(uniqueMap)),
+		HighCardinality: highCardinality,
+		EstimatedCounts: counts,
 	}
 	for k := range uniqueMap {
 		resp.TagValues = append(resp.TagValues, k)
@@ -356,42 +595,29 @@ func (q *Querier) SearchTagValues(ctx context.Context, req *tempopb.SearchTagVal
 	return resp, nil
 }
 
-func (q *Querier) postProcessSearchResults(req *tempopb.SearchRequest, rr []responseFromIngesters) *tempopb.SearchResponse {
+func (q *Querier) postProcessSearchResults(req *tempopb.SearchRequest, rr []responseFromIngesters) (*tempopb.SearchResponse, error) {
 	response := &tempopb.SearchResponse{
 		Metrics: &tempopb.SearchMetrics{},
 	}
 
-	traces := map[string]*tempopb.TraceSearchMetadata{}
+	// the results of a response with a token are a page of its results
+	more := false
 
 	for _, r := range rr {
 		sr := r.response.(*tempopb.SearchResponse)
-		for _, t := range sr.Traces {
-			// Just simply take first result for each trace
-			if _, ok := traces[t.TraceID]; !ok {
-				traces[t.TraceID] = t
-			}
-		}
-		if sr.Metrics != nil {
-			response.Metrics.InspectedBytes += sr.Metrics.InspectedBytes
-			response.Metrics.InspectedTraces += sr.Metrics.InspectedTraces
-			response.Metrics.InspectedBlocks += sr.Metrics.InspectedBlocks
-			response.Metrics.SkippedBlocks += sr.Metrics.SkippedBlocks
-		}
-	}
-
-	for _, t := range traces {
-		response.Traces = append(response.Traces, t)
+		more = more || sr.ContinuationToken != ""
+		response.Traces = append(response.Traces, sr.Traces...)
+		AddMetrics(response.Metrics, sr.Metrics)
 	}
 
-	// Sort and limit results
-	sort.Slice(response.Traces, func(i, j int) bool {
-		return response.Traces[i].StartTimeUnixNano > response.Traces[j].StartTimeUnixNano
-	})
-	if req.Limit != 0 && int(req.Limit) < len(response.Traces) {
-		response.Traces = response.Traces[:req.Limit]
+	// Dedupe, sort and limit results
+	var err error
+	response.Traces, response.ContinuationToken, err = search.SortAndPage(req, response.Traces, more)
+	if err != nil {
+		return nil, err
 	}
 
-	return response
+	return response, nil
 }
 
 // implements blocklist.JobSharder. Queriers rely on compactors to build the tenant
@@ -3,9 +3,13 @@ package querier
 import (
 	"context"
 	"io/ioutil"
+	"math"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,6 +29,7 @@ import (
 	"github.com/grafana/tempo/tempodb/backend/local"
 	"github.com/grafana/tempo/tempodb/encoding"
 	"github.com/grafana/tempo/tempodb/pool"
+	"github.com/grafana/tempo/tempodb/search"
 	"github.com/grafana/tempo/tempodb/wal"
 )
 
@@ -106,7 +111,7 @@ func TestReturnAllHits(t *testing.T) {
 	time.Sleep(200 * time.Millisecond)
 
 	// find should return both now
-	foundBytes, _, err := r.Find(context.Background(), util.FakeTenantID, testTraceID, tempodb.BlockIDMin, tempodb.BlockIDMax)
+	foundBytes, _, err := r.Find(context.Background(), util.FakeTenantID, testTraceID, tempodb.BlockIDMin, tempodb.BlockIDMax, 0, 0)
 	assert.NoError(t, err)
 	require.Len(t, foundBytes, 2)
 
@@ -124,3 +129,239 @@ func TestReturnAllHits(t *testing.T) {
 	model.SortTrace(actualTrace)
 	assert.Equal(t, expectedTrace, actualTrace)
 }
+
+func TestParseTracesByIDRequest(t *testing.T) {
+	q := &Querier{cfg: Config{MaxTracesPerBatch: 2}}
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		expected *tempopb.TracesByIDRequest
+	}{
+		{
+			name:   "valid",
+			method: http.MethodPost,
+			body:   `{"traceIDs": ["1", "0000000000000000000000000000000a"], "start": 10, "end": 20}`,
+			expected: &tempopb.TracesByIDRequest{
+				TraceIDs: [][]byte{
+					{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+					{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10},
+				},
+				Start: 10,
+				End:   20,
+			},
+		},
+		{
+			name:   "not posted",
+			method: http.MethodGet,
+			body:   `{"traceIDs": ["1"]}`,
+		},
+		{
+			name:   "no trace ids",
+			method: http.MethodPost,
+			body:   `{"traceIDs": []}`,
+		},
+		{
+			name:   "too many trace ids",
+			method: http.MethodPost,
+			body:   `{"traceIDs": ["1", "2", "3"]}`,
+		},
+		{
+			name:   "invalid trace id",
+			method: http.MethodPost,
+			body:   `{"traceIDs": ["xyz"]}`,
+		},
+		{
+			name:   "invalid time range",
+			method: http.MethodPost,
+			body:   `{"traceIDs": ["1"], "start": 20, "end": 10}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/traces", strings.NewReader(tt.body))
+
+			req, err := q.parseTracesByIDRequest(r)
+			if tt.expected == nil {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req)
+		})
+	}
+}
+
+func TestPadTimeRange(t *testing.T) {
+	q := &Querier{cfg: Config{TraceByIDTimeSlack: time.Minute}}
+
+	tests := []struct {
+		start, end                 uint32
+		expectedStart, expectedEnd uint32
+	}{
+		{start: 0, end: 0, expectedStart: 0, expectedEnd: 0},
+		{start: 1000, end: 2000, expectedStart: 940, expectedEnd: 2060},
+		{start: 30, end: 0, expectedStart: 0, expectedEnd: 0},
+		{start: 0, end: math.MaxUint32 - 30, expectedStart: 0, expectedEnd: 0},
+	}
+
+	for _, tt := range tests {
+		start, end := q.padTimeRange(tt.start, tt.end)
+		assert.Equal(t, tt.expectedStart, start)
+		assert.Equal(t, tt.expectedEnd, end)
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	tests := []struct {
+		query       string
+		start, end  uint32
+		expectedErr bool
+	}{
+		{query: ""},
+		{query: "?start=10&end=20", start: 10, end: 20},
+		{query: "?start=10", start: 10},
+		{query: "?end=20", end: 20},
+		{query: "?start=20&end=10", expectedErr: true},
+		{query: "?start=-1", expectedErr: true},
+		{query: "?end=foo", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			start, end, err := ParseTimeRange(httptest.NewRequest(http.MethodGet, "/api/traces/1234"+tt.query, nil))
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestParseTrimOptions(t *testing.T) {
+	tests := []struct {
+		query       string
+		expected    model.TrimOptions
+		expectedErr bool
+	}{
+		{query: ""},
+		{query: "?maxSpans=100&maxDepth=3", expected: model.TrimOptions{MaxSpans: 100, MaxDepth: 3}},
+		{query: "?attribute=http.url%3D%2Ffoo%3Dbar", expected: model.TrimOptions{AttributeKey: "http.url", AttributeValue: "/foo=bar"}},
+		{query: "?maxSpans=10&cursor=" + model.EncodeSpanCursor(20), expected: model.TrimOptions{MaxSpans: 10, Offset: 20}},
+		{query: "?maxSpans=-1", expectedErr: true},
+		{query: "?maxDepth=foo", expectedErr: true},
+		{query: "?attribute=foo", expectedErr: true},
+		{query: "?cursor=" + model.EncodeSpanCursor(20), expectedErr: true},
+		{query: "?maxSpans=10&cursor=foo", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			opts, err := ParseTrimOptions(httptest.NewRequest(http.MethodGet, "/api/traces/1234"+tt.query, nil))
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, opts)
+		})
+	}
+}
+
+func TestSearchRequestQuery(t *testing.T) {
+	req := &tempopb.SearchRequest{
+		Tags:          map[string]string{"service.name": "foo", "http.status_code": "500"},
+		MinDurationMs: 100,
+		MaxDurationMs: 2000,
+		Limit:         10,
+		Start:         1000,
+		End:           2000,
+		Sort:          tempopb.SearchSort_DURATION_ASC,
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/search?"+SearchRequestQuery(req).Encode(), nil)
+	actual, err := parseSearchRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, req, actual)
+}
+
+func TestParseSearchRequestSort(t *testing.T) {
+	_, token, err := search.SortAndPage(&tempopb.SearchRequest{Sort: tempopb.SearchSort_SPAN_COUNT_DESC}, []*tempopb.TraceSearchMetadata{{TraceID: "1"}}, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	tests := []struct {
+		query       string
+		expected    tempopb.SearchSort
+		expectedErr bool
+	}{
+		{query: "", expected: tempopb.SearchSort_FIRST_FOUND},
+		{query: "?sort=start_time_asc", expected: tempopb.SearchSort_START_TIME_ASC},
+		{query: "?sort=SPAN_COUNT_DESC", expected: tempopb.SearchSort_SPAN_COUNT_DESC},
+		{query: "?continuationToken=" + token, expected: tempopb.SearchSort_SPAN_COUNT_DESC},
+		{query: "?sort=span_count_desc&continuationToken=" + token, expected: tempopb.SearchSort_SPAN_COUNT_DESC},
+		{query: "?sort=duration_desc&continuationToken=" + token, expectedErr: true},
+		{query: "?continuationToken=invalid", expectedErr: true},
+		{query: "?sort=invalid", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req, err := parseSearchRequest(httptest.NewRequest(http.MethodGet, "/api/search"+tt.query, nil))
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req.Sort)
+			assert.Empty(t, req.Tags)
+		})
+	}
+}
+
+func TestParseTagValuesFilter(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	tests := []struct {
+		query       string
+		expected    *tempopb.SearchRequest
+		expectedErr bool
+	}{
+		{query: "", expected: nil},
+		{
+			query:    "?service.name=checkout",
+			expected: &tempopb.SearchRequest{Tags: map[string]string{"service.name": "checkout"}},
+		},
+		{
+			query:    "?service.name=checkout&since=1h",
+			expected: &tempopb.SearchRequest{Tags: map[string]string{"service.name": "checkout"}, Start: 6400},
+		},
+		{
+			query:    "?since=1h&end=5000",
+			expected: &tempopb.SearchRequest{Tags: map[string]string{}, Start: 1400, End: 5000},
+		},
+		{
+			query:    "?since=1h&start=8000",
+			expected: &tempopb.SearchRequest{Tags: map[string]string{}, Start: 8000},
+		},
+		{query: "?since=foo", expectedErr: true},
+		{query: "?minDuration=foo", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, err := parseTagValuesFilter(httptest.NewRequest(http.MethodGet, "/api/search/tag/http.route/values"+tt.query, nil), now)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package querier
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/pkg/errors"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+// tailDedupeWindow is the number of recent matches remembered to drop the copies streamed by the other replicas
+const tailDedupeWindow = 1000
+
+// Tail subscribes to every ingester in the ring and calls send with the matches they stream until ctx is done.
+// Copies of a replicated push are only sent once. Ingesters joining the ring after the tail started are not
+// subscribed to, and the tail ends with an error if the stream of any ingester fails so the client can reconnect.
+func (q *Querier) Tail(ctx context.Context, req *tempopb.TailRequest, send func(*tempopb.TailResponse) error) error {
+	_, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error extracting org id in Querier.Tail")
+	}
+
+	replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
+	if err != nil {
+		return errors.Wrap(err, "error finding ingesters in Querier.Tail")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make(chan *tempopb.TailResponse)
+	errs := make(chan error, len(replicationSet.Instances))
+	for _, ingester := range replicationSet.Instances {
+		client, err := q.pool.GetClientFor(ingester.Addr)
+		if err != nil {
+			return errors.Wrap(err, "error getting ingester client in Querier.Tail")
+		}
+
+		stream, err := client.(tempopb.QuerierClient).Tail(ctx, req)
+		if err != nil {
+			return errors.Wrapf(err, "error tailing ingester %s in Querier.Tail", ingester.Addr)
+		}
+
+		addr := ingester.Addr
+		go func() {
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					errs <- errors.Wrapf(err, "error tailing ingester %s in Querier.Tail", addr)
+					return
+				}
+
+				select {
+				case responses <- resp:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	dedupe := newTailDeduper(tailDedupeWindow)
+	var dropped uint32
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		case resp := <-responses:
+			dropped += resp.Dropped
+			if !dedupe.first(resp) {
+				continue
+			}
+
+			resp.Dropped = dropped
+			dropped = 0
+			if err := send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// tailDeduper remembers the most recent matches to recognize the copies of a push streamed by other replicas
+type tailDeduper struct {
+	seen map[uint64]struct{}
+	keys []uint64 // ring buffer of the keys in seen in the order they were added
+	next int
+}
+
+func newTailDeduper(size int) *tailDeduper {
+	return &tailDeduper{
+		seen: make(map[uint64]struct{}, size),
+		keys: make([]uint64, 0, size),
+	}
+}
+
+// first returns true if the match hasn't been seen within the window
+func (d *tailDeduper) first(resp *tempopb.TailResponse) bool {
+	key := tailMatchKey(resp)
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+
+	if len(d.keys) < cap(d.keys) {
+		d.keys = append(d.keys, key)
+	} else {
+		delete(d.seen, d.keys[d.next])
+		d.keys[d.next] = key
+		d.next = (d.next + 1) % len(d.keys)
+	}
+	d.seen[key] = struct{}{}
+
+	return true
+}
+
+// tailMatchKey hashes the match without the dropped count, which differs between replicas
+func tailMatchKey(resp *tempopb.TailResponse) uint64 {
+	match := *resp
+	match.Dropped = 0
+
+	h := fnv.New64a()
+	b, _ := match.Marshal()
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
@@ -0,0 +1,31 @@
+package querier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+func TestTailDeduper(t *testing.T) {
+	d := newTailDeduper(2)
+
+	match := func(traceID string, dropped uint32) *tempopb.TailResponse {
+		return &tempopb.TailResponse{
+			Metadata: &tempopb.TraceSearchMetadata{TraceID: traceID},
+			Dropped:  dropped,
+		}
+	}
+
+	assert.True(t, d.first(match("a", 0)))
+	// copies from other replicas only differ in the dropped count
+	assert.False(t, d.first(match("a", 3)))
+	assert.True(t, d.first(match("b", 0)))
+	assert.False(t, d.first(match("b", 0)))
+
+	// a is pushed out of the window
+	assert.True(t, d.first(match("c", 0)))
+	assert.True(t, d.first(match("a", 0)))
+	assert.False(t, d.first(match("c", 0)))
+}
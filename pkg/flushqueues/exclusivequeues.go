@@ -8,17 +8,19 @@ import (
 )
 
 type ExclusiveQueues struct {
-	queues     []*PriorityQueue
-	index      *atomic.Int32
-	activeKeys sync.Map
-	stopped    atomic.Bool
+	queues        []*PriorityQueue
+	index         *atomic.Int32
+	activeKeysMtx sync.Mutex
+	activeKeys    map[string]Op
+	stopped       atomic.Bool
 }
 
 // New creates a new set of flush queues with a prom gauge to track current depth
 func New(queues int, metric prometheus.Gauge) *ExclusiveQueues {
 	f := &ExclusiveQueues{
-		queues: make([]*PriorityQueue, queues),
-		index:  atomic.NewInt32(0),
+		queues:     make([]*PriorityQueue, queues),
+		index:      atomic.NewInt32(0),
+		activeKeys: map[string]Op{},
 	}
 
 	for j := 0; j < queues; j++ {
@@ -30,12 +32,15 @@ func New(queues int, metric prometheus.Gauge) *ExclusiveQueues {
 
 // Enqueue adds the op to the next queue and prevents any other items to be added with this key
 func (f *ExclusiveQueues) Enqueue(op Op) error {
-	_, ok := f.activeKeys.Load(op.Key())
+	f.activeKeysMtx.Lock()
+	_, ok := f.activeKeys[op.Key()]
 	if ok {
+		f.activeKeysMtx.Unlock()
 		return nil
 	}
+	f.activeKeys[op.Key()] = op
+	f.activeKeysMtx.Unlock()
 
-	f.activeKeys.Store(op.Key(), struct{}{})
 	return f.Requeue(op)
 }
 
@@ -53,19 +58,34 @@ func (f *ExclusiveQueues) Requeue(op Op) error {
 }
 
 // Clear unblocks the requested op.  This should be called only after a flush has been successful
+//  Keys that have been taken over by another op since the op was removed are left alone.
 func (f *ExclusiveQueues) Clear(op Op) {
-	f.activeKeys.Delete(op.Key())
+	f.activeKeysMtx.Lock()
+	defer f.activeKeysMtx.Unlock()
+
+	if f.activeKeys[op.Key()] == op {
+		delete(f.activeKeys, op.Key())
+	}
 }
 
-func (f *ExclusiveQueues) IsEmpty() bool {
-	length := 0
+// Remove takes the op out of the queue it is waiting in and unblocks its key. Returns false if the op is not
+//  waiting in a queue, e.g. because it has been dequeued already.
+func (f *ExclusiveQueues) Remove(op Op) bool {
+	f.Clear(op)
+
+	for _, q := range f.queues {
+		if q.Remove(op) {
+			return true
+		}
+	}
+	return false
+}
 
-	f.activeKeys.Range(func(_, _ interface{}) bool {
-		length++
-		return false
-	})
+func (f *ExclusiveQueues) IsEmpty() bool {
+	f.activeKeysMtx.Lock()
+	defer f.activeKeysMtx.Unlock()
 
-	return length <= 0
+	return len(f.activeKeys) == 0
 }
 
 // Stop closes all queues
@@ -113,3 +113,37 @@ func TestMultipleQueues(t *testing.T) {
 		assert.Equal(t, totalQueues-(i+1), int(length))
 	}
 }
+
+func TestExclusiveQueuesRemove(t *testing.T) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "test",
+		Name:      "testersons",
+	})
+
+	q := New(2, gauge)
+	op := &mockOp{key: "poisoned"}
+
+	err := q.Enqueue(op)
+	assert.NoError(t, err)
+
+	// removing unblocks the key and takes the op out of its queue
+	assert.True(t, q.Remove(op))
+	assert.True(t, q.IsEmpty())
+	length, err := test.GetGaugeValue(gauge)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, int(length))
+
+	// an op that isn't queued anymore only has its key unblocked
+	assert.NoError(t, q.Enqueue(op))
+	assert.Equal(t, op, q.Dequeue(0))
+	assert.False(t, q.Remove(op))
+	assert.True(t, q.IsEmpty())
+
+	// the key is taken over by a new op, clearing the removed op leaves it alone
+	newOp := &mockOp{key: "poisoned"}
+	assert.NoError(t, q.Enqueue(newOp))
+	q.Clear(op)
+	assert.False(t, q.IsEmpty())
+	q.Clear(newOp)
+	assert.True(t, q.IsEmpty())
+}
@@ -106,6 +106,26 @@ func (pq *PriorityQueue) Enqueue(op Op) (bool, error) {
 	return true, nil
 }
 
+// Remove takes the op out of the queue. Returns false if the op is not on the queue.
+func (pq *PriorityQueue) Remove(op Op) bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	for j, queued := range pq.queue {
+		if queued != op {
+			continue
+		}
+
+		heap.Remove(&pq.queue, j)
+		delete(pq.hit, op.Key())
+		if pq.lengthGauge != nil {
+			pq.lengthGauge.Dec()
+		}
+		return true
+	}
+	return false
+}
+
 // Dequeue will return the op with the highest priority; block if queue is
 // empty; returns nil if queue is closed.
 func (pq *PriorityQueue) Dequeue() Op {
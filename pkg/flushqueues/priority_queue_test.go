@@ -84,3 +84,25 @@ func TestPriorityQueueWait(t *testing.T) {
 		t.Fatal("Close didn't unblock Dequeue.")
 	}
 }
+
+func TestPriorityQueueRemove(t *testing.T) {
+	queue := NewPriorityQueue(nil)
+
+	for i := 1; i <= 3; i++ {
+		_, err := queue.Enqueue(simpleItem(i))
+		assert.NoError(t, err)
+	}
+
+	assert.True(t, queue.Remove(simpleItem(3)))
+	assert.False(t, queue.Remove(simpleItem(3)))
+	assert.Equal(t, 2, queue.Length())
+
+	// the key can be enqueued again
+	added, err := queue.Enqueue(simpleItem(3))
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	assert.Equal(t, simpleItem(3), queue.Dequeue())
+	assert.Equal(t, simpleItem(2), queue.Dequeue())
+	assert.Equal(t, simpleItem(1), queue.Dequeue())
+}
@@ -0,0 +1,20 @@
+package model
+
+import "github.com/grafana/tempo/pkg/tempopb"
+
+// ContainsRootSpan returns true if the trace contains a span without a parent
+func ContainsRootSpan(t *tempopb.Trace) bool {
+	for _, b := range t.Batches {
+		if b == nil {
+			continue
+		}
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				if len(s.ParentSpanId) == 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
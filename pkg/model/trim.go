@@ -0,0 +1,360 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	common_v1 "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+// TrimOptions select the spans of a trace to return. The zero value selects every span.
+type TrimOptions struct {
+	// MaxSpans is the number of spans per page. 0 is unlimited
+	MaxSpans int
+	// MaxDepth drops spans nested deeper than this below the roots, which are at depth 1, and spans that aren't below a
+	// root because their parents form a cycle. 0 is unlimited
+	MaxDepth int
+	// AttributeKey and AttributeValue keep only spans with this attribute if AttributeKey is set
+	AttributeKey   string
+	AttributeValue string
+	// Offset is the position of the first span of the page in the ranking, decoded from a cursor
+	Offset int
+}
+
+// Enabled returns true if the options can drop spans
+func (o TrimOptions) Enabled() bool {
+	return o.MaxSpans > 0 || o.MaxDepth > 0 || o.AttributeKey != ""
+}
+
+// EncodeSpanCursor returns the cursor of the page starting at offset
+func EncodeSpanCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// DecodeSpanCursor returns the offset of the page of a cursor returned by EncodeSpanCursor
+func DecodeSpanCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	offset, err := strconv.Atoi(string(b))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %s", cursor)
+	}
+	return offset, nil
+}
+
+type rankedSpan struct {
+	span  *v1.Span
+	depth int
+}
+
+// TrimTrace returns the trace with only the spans selected by the options, and the offset of the next page or 0 if
+//  this is the last one. Spans are ranked with the roots first, then the critical path from the earliest root,
+//  following the child that ends last, then every other span by start time. The ranking is deterministic, so pages
+//  are consistent as long as the trace doesn't change in between. It is destructive, the trace is modified in place.
+func TrimTrace(trace *tempopb.Trace, opts TrimOptions) (*tempopb.Trace, int) {
+	if trace == nil || !opts.Enabled() {
+		return trace, 0
+	}
+
+	ranked := rankSpans(trace)
+
+	selected := ranked[:0]
+	for _, r := range ranked {
+		if opts.MaxDepth > 0 && (r.depth == 0 || r.depth > opts.MaxDepth) {
+			continue
+		}
+		if opts.AttributeKey != "" && !spanHasAttribute(r.span, opts.AttributeKey, opts.AttributeValue) {
+			continue
+		}
+		selected = append(selected, r)
+	}
+
+	next := 0
+	if opts.Offset >= len(selected) {
+		selected = nil
+	} else {
+		selected = selected[opts.Offset:]
+	}
+	if opts.MaxSpans > 0 && len(selected) > opts.MaxSpans {
+		selected = selected[:opts.MaxSpans]
+		next = opts.Offset + opts.MaxSpans
+	}
+
+	keep := make(map[*v1.Span]struct{}, len(selected))
+	for _, r := range selected {
+		keep[r.span] = struct{}{}
+	}
+
+	batches := trace.Batches[:0]
+	for _, b := range trace.Batches {
+		ilss := b.InstrumentationLibrarySpans[:0]
+		for _, ils := range b.InstrumentationLibrarySpans {
+			spans := ils.Spans[:0]
+			for _, s := range ils.Spans {
+				if _, ok := keep[s]; ok {
+					spans = append(spans, s)
+				}
+			}
+			if len(spans) > 0 {
+				ils.Spans = spans
+				ilss = append(ilss, ils)
+			}
+		}
+		if len(ilss) > 0 {
+			b.InstrumentationLibrarySpans = ilss
+			batches = append(batches, b)
+		}
+	}
+	trace.Batches = batches
+
+	return trace, next
+}
+
+// TrimTracePart cuts the spans of part of a trace, such as the part found by a single shard of a query, that TrimTrace
+//  can't select once the parts are combined. The cut spans can still change the roots, depths and ranking of the spans
+//  of the other parts, so they are cut down to what the ranking needs instead of being dropped:
+//  - spans without the attribute
+//  - spans deeper than MaxDepth in the part, they can only be deeper in the whole trace or below a cycle
+//  - without MaxDepth, spans ranked after the page: they aren't roots or on the critical path since a sibling ends
+//    later, and at least the end of the page of spans with the attribute start before them
+//  Server spans and spans ending before a server span are never ranked after the page, the query frontend changes the
+//  parent of server spans that share their id with a client span. It is destructive, the trace is modified in place.
+func TrimTracePart(trace *tempopb.Trace, opts TrimOptions) *tempopb.Trace {
+	if trace == nil || !opts.Enabled() {
+		return trace
+	}
+
+	tree := newSpanTree(trace)
+	matches := func(s *v1.Span) bool {
+		return opts.AttributeKey == "" || spanHasAttribute(s, opts.AttributeKey, opts.AttributeValue)
+	}
+
+	// start times of the spans that can be selected, to count the ones starting before a span
+	pageEnd := opts.Offset + opts.MaxSpans
+	var starts []uint64
+	if opts.MaxSpans > 0 && opts.MaxDepth == 0 {
+		for _, s := range tree.spans {
+			if matches(s) {
+				starts = append(starts, s.StartTimeUnixNano)
+			}
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	}
+	afterPage := func(s *v1.Span) bool {
+		parent := tree.parents[s]
+		if starts == nil || parent == nil || s.Kind == v1.Span_SPAN_KIND_SERVER {
+			return false
+		}
+		if sort.Search(len(starts), func(i int) bool { return starts[i] >= s.StartTimeUnixNano }) < pageEnd {
+			return false
+		}
+		for _, c := range tree.children[parent] {
+			if c != s && c.Kind != v1.Span_SPAN_KIND_SERVER && (c.EndTimeUnixNano > s.EndTimeUnixNano ||
+				(c.EndTimeUnixNano == s.EndTimeUnixNano && compareSpans(c, s))) {
+				return true
+			}
+		}
+		return false
+	}
+
+	cut := make(map[*v1.Span]bool, len(tree.spans))
+	for _, s := range tree.spans {
+		switch {
+		case !matches(s):
+			cut[s] = false
+		case opts.MaxDepth > 0 && tree.depths[s] > opts.MaxDepth:
+			cut[s] = false
+		case afterPage(s):
+			// still counted by TrimTrace for the next page
+			cut[s] = true
+		}
+	}
+
+	for _, b := range trace.Batches {
+		batchCut := true
+		for _, ils := range b.InstrumentationLibrarySpans {
+			ilsCut := true
+			for j, s := range ils.Spans {
+				keepAttribute, ok := cut[s]
+				if !ok {
+					ilsCut = false
+					continue
+				}
+				skeleton := &v1.Span{
+					SpanId:            s.SpanId,
+					ParentSpanId:      s.ParentSpanId,
+					Kind:              s.Kind,
+					StartTimeUnixNano: s.StartTimeUnixNano,
+					EndTimeUnixNano:   s.EndTimeUnixNano,
+				}
+				if keepAttribute && opts.AttributeKey != "" {
+					skeleton.Attributes = []*common_v1.KeyValue{{Key: opts.AttributeKey, Value: &common_v1.AnyValue{Value: &common_v1.AnyValue_StringValue{StringValue: opts.AttributeValue}}}}
+				}
+				ils.Spans[j] = skeleton
+			}
+			if ilsCut {
+				ils.InstrumentationLibrary = nil
+			}
+			batchCut = batchCut && ilsCut
+		}
+		if batchCut {
+			b.Resource = nil
+		}
+	}
+
+	return trace
+}
+
+// spanTree is the parent child structure of the spans of a trace
+type spanTree struct {
+	spans []*v1.Span
+	// parents is nil for roots
+	parents  map[*v1.Span]*v1.Span
+	children map[*v1.Span][]*v1.Span
+	// roots are sorted by start time
+	roots []*v1.Span
+	// depths are 1 for roots. spans in or below a cycle are never reached and are given depth 0
+	depths map[*v1.Span]int
+}
+
+func newSpanTree(trace *tempopb.Trace) spanTree {
+	var spans []*v1.Span
+	byID := map[string]*v1.Span{}
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				spans = append(spans, s)
+				if _, ok := byID[string(s.SpanId)]; !ok {
+					byID[string(s.SpanId)] = s
+				}
+			}
+		}
+	}
+
+	// spans whose parent is not in the trace are roots
+	parents := make(map[*v1.Span]*v1.Span, len(spans))
+	children := map[*v1.Span][]*v1.Span{}
+	var roots []*v1.Span
+	for _, s := range spans {
+		parent, ok := byID[string(s.ParentSpanId)]
+		if len(s.ParentSpanId) == 0 || !ok || parent == s {
+			roots = append(roots, s)
+			continue
+		}
+		parents[s] = parent
+		children[parent] = append(children[parent], s)
+	}
+	sort.Slice(roots, func(i, j int) bool { return compareSpans(roots[i], roots[j]) })
+
+	// depths, walking down from the roots
+	depths := make(map[*v1.Span]int, len(spans))
+	queue := make([]*v1.Span, 0, len(spans))
+	for _, r := range roots {
+		depths[r] = 1
+		queue = append(queue, r)
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, c := range children[s] {
+			if _, ok := depths[c]; !ok {
+				depths[c] = depths[s] + 1
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	return spanTree{
+		spans:    spans,
+		parents:  parents,
+		children: children,
+		roots:    roots,
+		depths:   depths,
+	}
+}
+
+// rankSpans returns every span of the trace in the order they are kept by TrimTrace, along with their depth
+func rankSpans(trace *tempopb.Trace) []rankedSpan {
+	tree := newSpanTree(trace)
+	spans, children, roots, depths := tree.spans, tree.children, tree.roots, tree.depths
+
+	ranked := make([]rankedSpan, 0, len(spans))
+	added := make(map[*v1.Span]struct{}, len(spans))
+	add := func(s *v1.Span) {
+		if _, ok := added[s]; ok {
+			return
+		}
+		added[s] = struct{}{}
+		ranked = append(ranked, rankedSpan{span: s, depth: depths[s]})
+	}
+
+	for _, r := range roots {
+		add(r)
+	}
+
+	// critical path
+	if len(roots) > 0 {
+		for s := roots[0]; ; {
+			var last *v1.Span
+			for _, c := range children[s] {
+				if last == nil || c.EndTimeUnixNano > last.EndTimeUnixNano ||
+					(c.EndTimeUnixNano == last.EndTimeUnixNano && compareSpans(c, last)) {
+					last = c
+				}
+			}
+			if last == nil {
+				break
+			}
+			if _, ok := added[last]; ok {
+				break
+			}
+			add(last)
+			s = last
+		}
+	}
+
+	rest := make([]*v1.Span, 0, len(spans)-len(ranked))
+	for _, s := range spans {
+		if _, ok := added[s]; !ok {
+			rest = append(rest, s)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool { return compareSpans(rest[i], rest[j]) })
+	for _, s := range rest {
+		add(s)
+	}
+
+	return ranked
+}
+
+func spanHasAttribute(s *v1.Span, key, value string) bool {
+	for _, a := range s.Attributes {
+		if a.Key != key {
+			continue
+		}
+		if v, ok := attributeValueAsString(a.Value); ok && v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func attributeValueAsString(v *common_v1.AnyValue) (string, bool) {
+	switch vv := v.GetValue().(type) {
+	case *common_v1.AnyValue_StringValue:
+		return vv.StringValue, true
+	case *common_v1.AnyValue_BoolValue:
+		return strconv.FormatBool(vv.BoolValue), true
+	case *common_v1.AnyValue_IntValue:
+		return strconv.FormatInt(vv.IntValue, 10), true
+	case *common_v1.AnyValue_DoubleValue:
+		return strconv.FormatFloat(vv.DoubleValue, 'g', -1, 64), true
+	}
+	return "", false
+}
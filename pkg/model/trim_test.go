@@ -0,0 +1,234 @@
+package model
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/grafana/tempo/pkg/tempopb"
+	common_v1 "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeTrimTrace returns a trace shaped like
+//  a (0-100)
+//  ├── b (10-50)
+//  │   └── f (15-45) http.status_code=500
+//  └── c (20-90)
+//      └── d (30-80)
+//          └── e (40-60)
+// split over two batches
+func makeTrimTrace() *tempopb.Trace {
+	span := func(id, parent string, start, end uint64) *v1.Span {
+		s := &v1.Span{
+			SpanId:            []byte(id),
+			StartTimeUnixNano: start,
+			EndTimeUnixNano:   end,
+		}
+		if parent != "" {
+			s.ParentSpanId = []byte(parent)
+		}
+		return s
+	}
+
+	f := span("f", "b", 15, 45)
+	f.Attributes = []*common_v1.KeyValue{
+		{Key: "http.status_code", Value: &common_v1.AnyValue{Value: &common_v1.AnyValue_IntValue{IntValue: 500}}},
+	}
+
+	return &tempopb.Trace{
+		Batches: []*v1.ResourceSpans{
+			{
+				InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+					{Spans: []*v1.Span{span("a", "", 0, 100), span("b", "a", 10, 50), f}},
+				},
+			},
+			{
+				InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+					{Spans: []*v1.Span{span("c", "a", 20, 90)}},
+					{Spans: []*v1.Span{span("d", "c", 30, 80), span("e", "d", 40, 60)}},
+				},
+			},
+		},
+	}
+}
+
+func trimmedSpanIDs(trace *tempopb.Trace) []string {
+	var ids []string
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				ids = append(ids, string(s.SpanId))
+			}
+		}
+	}
+	return ids
+}
+
+func TestTrimTrace(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         TrimOptions
+		expectedIDs  []string
+		expectedNext int
+	}{
+		{
+			name:        "disabled",
+			opts:        TrimOptions{},
+			expectedIDs: []string{"a", "b", "f", "c", "d", "e"},
+		},
+		{
+			name:         "root and critical path first",
+			opts:         TrimOptions{MaxSpans: 3},
+			expectedIDs:  []string{"a", "c", "d"},
+			expectedNext: 3,
+		},
+		{
+			name:        "last page",
+			opts:        TrimOptions{MaxSpans: 3, Offset: 3},
+			expectedIDs: []string{"b", "f", "e"},
+		},
+		{
+			name: "past the last page",
+			opts: TrimOptions{MaxSpans: 3, Offset: 6},
+		},
+		{
+			name:        "depth",
+			opts:        TrimOptions{MaxDepth: 2},
+			expectedIDs: []string{"a", "b", "c"},
+		},
+		{
+			name:        "attribute",
+			opts:        TrimOptions{AttributeKey: "http.status_code", AttributeValue: "500"},
+			expectedIDs: []string{"f"},
+		},
+		{
+			name:         "depth and max spans",
+			opts:         TrimOptions{MaxDepth: 3, MaxSpans: 2, Offset: 2},
+			expectedIDs:  []string{"b", "d"},
+			expectedNext: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace, next := TrimTrace(makeTrimTrace(), tt.opts)
+			assert.Equal(t, tt.expectedIDs, trimmedSpanIDs(trace))
+			assert.Equal(t, tt.expectedNext, next)
+		})
+	}
+}
+
+func TestTrimTracePart(t *testing.T) {
+	// the part of the trace found by a shard, with b and e found by another one
+	part := makeTrimTrace()
+	part.Batches[0].InstrumentationLibrarySpans[0].Spans = part.Batches[0].InstrumentationLibrarySpans[0].Spans[:1]
+	part.Batches[1].InstrumentationLibrarySpans[1].Spans = part.Batches[1].InstrumentationLibrarySpans[1].Spans[:1]
+	part.Batches[1].Resource = &v1_resource.Resource{Attributes: []*common_v1.KeyValue{{Key: "service.name", Value: &common_v1.AnyValue{Value: &common_v1.AnyValue_StringValue{StringValue: "c"}}}}}
+	for _, s := range trimmedSpansOf(part) {
+		s.Name = "name"
+	}
+
+	// c and d are deeper than the page can reach once b is added
+	part = TrimTracePart(part, TrimOptions{MaxDepth: 1})
+	names := map[string]string{}
+	for _, s := range trimmedSpansOf(part) {
+		names[string(s.SpanId)] = s.Name
+	}
+	assert.Equal(t, map[string]string{"a": "name", "c": "", "d": ""}, names)
+	assert.Nil(t, part.Batches[1].Resource)
+}
+
+func TestTrimTracePartKeepsTheResultOfTrimTrace(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	kinds := []v1.Span_SpanKind{v1.Span_SPAN_KIND_INTERNAL, v1.Span_SPAN_KIND_SERVER, v1.Span_SPAN_KIND_CLIENT}
+
+	for i := 0; i < 2000; i++ {
+		// a trace with ties in start and end times, missing parents and spans found by more than one part
+		spans := make([]*v1.Span, 1+r.Intn(40))
+		for j := range spans {
+			spans[j] = &v1.Span{
+				SpanId:            []byte{byte(j + 1)},
+				Name:              fmt.Sprintf("span %d", j),
+				Kind:              kinds[r.Intn(len(kinds))],
+				StartTimeUnixNano: uint64(r.Intn(10)),
+				EndTimeUnixNano:   uint64(10 + r.Intn(10)),
+				Attributes:        []*common_v1.KeyValue{{Key: "k", Value: &common_v1.AnyValue{Value: &common_v1.AnyValue_IntValue{IntValue: int64(r.Intn(2))}}}},
+			}
+			if j > 0 && r.Intn(8) > 0 {
+				spans[j].ParentSpanId = []byte{byte(r.Intn(j+2) + 1)}
+			}
+		}
+		opts := TrimOptions{MaxSpans: r.Intn(6), Offset: r.Intn(6)}
+		if r.Intn(2) == 0 {
+			opts.MaxDepth = r.Intn(4)
+		}
+		if r.Intn(2) == 0 {
+			opts.AttributeKey, opts.AttributeValue = "k", "1"
+		}
+
+		parts := make([]*tempopb.Trace, 1+r.Intn(3))
+		for j := range parts {
+			parts[j] = &tempopb.Trace{}
+		}
+		for _, s := range spans {
+			for j, found := range r.Perm(len(parts))[:1+r.Intn(len(parts))] {
+				if j > 0 && r.Intn(2) == 0 {
+					continue
+				}
+				parts[found].Batches = append(parts[found].Batches, &v1.ResourceSpans{
+					InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: []*v1.Span{proto.Clone(s).(*v1.Span)}}},
+				})
+			}
+		}
+
+		whole := &tempopb.Trace{Batches: []*v1.ResourceSpans{{InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{Spans: spans}}}}}
+		expected, expectedNext := TrimTrace(whole, opts)
+
+		var combined *tempopb.Trace
+		for _, p := range parts {
+			combined, _, _, _ = CombineTraceProtos(combined, TrimTracePart(p, opts))
+		}
+		actual, next := TrimTrace(combined, opts)
+
+		require.Equal(t, expectedNext, next, "trace %d", i)
+		require.Equal(t, len(trimmedSpansOf(expected)), len(trimmedSpansOf(actual)), "trace %d", i)
+		actualByID := map[string]*v1.Span{}
+		for _, s := range trimmedSpansOf(actual) {
+			actualByID[string(s.SpanId)] = s
+		}
+		for _, s := range trimmedSpansOf(expected) {
+			require.True(t, proto.Equal(s, actualByID[string(s.SpanId)]), "trace %d", i)
+		}
+	}
+}
+
+func trimmedSpansOf(trace *tempopb.Trace) []*v1.Span {
+	var spans []*v1.Span
+	if trace == nil {
+		return nil
+	}
+	for _, b := range trace.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			spans = append(spans, ils.Spans...)
+		}
+	}
+	return spans
+}
+
+func TestSpanCursor(t *testing.T) {
+	for _, offset := range []int{0, 1, 1000} {
+		decoded, err := DecodeSpanCursor(EncodeSpanCursor(offset))
+		require.NoError(t, err)
+		assert.Equal(t, offset, decoded)
+	}
+
+	_, err := DecodeSpanCursor("!!")
+	assert.Error(t, err)
+	_, err = DecodeSpanCursor(EncodeSpanCursor(-1))
+	assert.Error(t, err)
+}
@@ -85,8 +85,20 @@ func (rcv *SearchEntry) MutateEndTimeUnixNano(n uint64) bool {
 	return rcv._tab.MutateUint64Slot(10, n)
 }
 
+func (rcv *SearchEntry) SpanCount() uint32 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(12))
+	if o != 0 {
+		return rcv._tab.GetUint32(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SearchEntry) MutateSpanCount(n uint32) bool {
+	return rcv._tab.MutateUint32Slot(12, n)
+}
+
 func SearchEntryStart(builder *flatbuffers.Builder) {
-	builder.StartObject(4)
+	builder.StartObject(5)
 }
 func SearchEntryAddId(builder *flatbuffers.Builder, id flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(id), 0)
@@ -103,6 +115,9 @@ func SearchEntryAddStartTimeUnixNano(builder *flatbuffers.Builder, startTimeUnix
 func SearchEntryAddEndTimeUnixNano(builder *flatbuffers.Builder, endTimeUnixNano uint64) {
 	builder.PrependUint64Slot(3, endTimeUnixNano, 0)
 }
+func SearchEntryAddSpanCount(builder *flatbuffers.Builder, spanCount uint32) {
+	builder.PrependUint32Slot(4, spanCount, 0)
+}
 func SearchEntryEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
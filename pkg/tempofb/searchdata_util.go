@@ -9,8 +9,8 @@ import (
 	"github.com/grafana/tempo/tempodb/encoding/common"
 )
 
-// TagContainer is anything with KeyValues (tags). This is implemented by both
-// SearchPage and SearchEntry.
+// TagContainer is anything with KeyValues (tags). This is implemented by
+// SearchPage, SearchEntry and SearchBlockHeader.
 type TagContainer interface {
 	Tags(obj *KeyValues, j int) bool
 	TagsLength() int
@@ -18,6 +18,7 @@ type TagContainer interface {
 
 var _ TagContainer = (*SearchPage)(nil)
 var _ TagContainer = (*SearchEntry)(nil)
+var _ TagContainer = (*SearchBlockHeader)(nil)
 
 type SearchDataMap map[string][]string
 
@@ -94,6 +95,7 @@ type SearchEntryMutable struct {
 	Tags              SearchDataMap
 	StartTimeUnixNano uint64
 	EndTimeUnixNano   uint64
+	SpanCount         uint32
 }
 
 // AddTag adds the unique tag name and value to the search data. No effect if the pair is already present.
@@ -135,6 +137,7 @@ func (s *SearchEntryMutable) WriteToBuilder(b *flatbuffers.Builder) flatbuffers.
 	SearchEntryAddId(b, idOffset)
 	SearchEntryAddStartTimeUnixNano(b, s.StartTimeUnixNano)
 	SearchEntryAddEndTimeUnixNano(b, s.EndTimeUnixNano)
+	SearchEntryAddSpanCount(b, s.SpanCount)
 	SearchEntryAddTags(b, tagOffset)
 	return SearchEntryEnd(b)
 }
@@ -215,6 +218,25 @@ func (s *SearchEntry) Get(k string) string {
 	return ""
 }
 
+// GetAll searches the entry and returns every value of the given key.
+func (s *SearchEntry) GetAll(k string) []string {
+	kv := &KeyValues{}
+	kb := bytes.ToLower([]byte(k))
+
+	for i := 0; i < s.TagsLength(); i++ {
+		s.Tags(kv, i)
+		if bytes.Equal(kv.Key(), kb) {
+			values := make([]string, 0, kv.ValueLength())
+			for j := 0; j < kv.ValueLength(); j++ {
+				values = append(values, string(kv.Value(j)))
+			}
+			return values
+		}
+	}
+
+	return nil
+}
+
 // Contains returns true if the key and value are found in the search data.
 // Buffer KeyValue object can be passed to reduce allocations. Key and value must be
 // already converted to byte slices which match the nature of the flatbuffer data
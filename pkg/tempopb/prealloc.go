@@ -4,9 +4,15 @@ import (
 	"github.com/prometheus/prometheus/pkg/pool"
 )
 
+const (
+	bytePoolMinSize = 500
+	bytePoolMaxSize = 16_000
+	bytePoolFactor  = 2
+)
+
 var (
 	// buckets: [0.5KiB, 1KiB, 2KiB, 4KiB, 8KiB, 16KiB]
-	bytePool = pool.New(500, 16_000, 2, func(size int) interface{} { return make([]byte, 0, size) })
+	bytePool = pool.New(bytePoolMinSize, bytePoolMaxSize, bytePoolFactor, func(size int) interface{} { return make([]byte, 0, size) })
 )
 
 // PreallocBytes is a (repeated bytes slices) which preallocs slices on Unmarshal.
@@ -37,11 +43,24 @@ func (r *PreallocBytes) Size() (n int) {
 	return len(r.Slice)
 }
 
-// ReuseTraceBytes puts the byte slice back into bytePool for reuse.
+// ReuseTraceBytes puts the byte slice back into bytePool for reuse. The pool files a slice in the smallest bucket
+// that fits its capacity and hands it out for any size up to the bucket size, so only slices with the capacity of a
+// bucket, i.e. that were allocated by the pool, are put back.
 func ReuseTraceBytes(trace *TraceBytes) {
 	for _, t := range trace.Traces {
-		bytePool.Put(t[:0])
+		if isBytePoolBucket(cap(t)) {
+			bytePool.Put(t[:0])
+		}
+	}
+}
+
+func isBytePoolBucket(size int) bool {
+	for s := bytePoolMinSize; s <= bytePoolMaxSize; s *= bytePoolFactor {
+		if size == s {
+			return true
+		}
 	}
+	return false
 }
 
 // SliceFromBytePool gets a slice from the byte pool
@@ -38,6 +38,17 @@ func TestSize(t *testing.T) {
 	assert.Equal(t, 10, preallocReq.Size())
 }
 
+func TestReuseTraceBytesIgnoresForeignSlices(t *testing.T) {
+	// slices that weren't allocated by the pool would be handed out for larger sizes
+	for i := 0; i < 100; i++ {
+		ReuseTraceBytes(&TraceBytes{Traces: [][]byte{make([]byte, 541)}})
+	}
+
+	for i := 0; i < 100; i++ {
+		assert.Len(t, SliceFromBytePool(815), 815)
+	}
+}
+
 /* The prometheus pool pkg is a wrapper around sync.Pool
 
 From the comments on sync.Pool pkg:
@@ -0,0 +1,98 @@
+package tempopb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogo/status"
+	"google.golang.org/grpc/codes"
+)
+
+// NewPushPartialSuccess builds a partial success from rejected span counts keyed by reason.
+// Returns nil if no spans were rejected.
+func NewPushPartialSuccess(acceptedSpans int, rejectedByReason map[string]int) *PushPartialSuccess {
+	ps := &PushPartialSuccess{
+		AcceptedSpans: int64(acceptedSpans),
+	}
+	for reason, spans := range rejectedByReason {
+		if spans == 0 {
+			continue
+		}
+		ps.RejectedByReason = append(ps.RejectedByReason, RejectedSpans{Reason: reason, Spans: int64(spans)})
+		ps.RejectedSpans += int64(spans)
+	}
+
+	if ps.RejectedSpans == 0 {
+		return nil
+	}
+
+	ps.normalize()
+	return ps
+}
+
+// Merge adds the counts of other to m.
+func (m *PushPartialSuccess) Merge(other *PushPartialSuccess) {
+	if other == nil {
+		return
+	}
+
+	m.AcceptedSpans += other.AcceptedSpans
+	m.RejectedSpans += other.RejectedSpans
+
+outer:
+	for _, o := range other.RejectedByReason {
+		for i := range m.RejectedByReason {
+			if m.RejectedByReason[i].Reason == o.Reason {
+				m.RejectedByReason[i].Spans += o.Spans
+				continue outer
+			}
+		}
+		m.RejectedByReason = append(m.RejectedByReason, o)
+	}
+
+	m.normalize()
+}
+
+// normalize sorts the reasons and regenerates the error message from the counts.
+func (m *PushPartialSuccess) normalize() {
+	sort.Slice(m.RejectedByReason, func(i, j int) bool {
+		return m.RejectedByReason[i].Reason < m.RejectedByReason[j].Reason
+	})
+
+	reasons := make([]string, 0, len(m.RejectedByReason))
+	for _, r := range m.RejectedByReason {
+		reasons = append(reasons, fmt.Sprintf("%s: %d", r.Reason, r.Spans))
+	}
+	m.ErrorMessage = fmt.Sprintf("%d of %d spans rejected (%s)", m.RejectedSpans, m.RejectedSpans+m.AcceptedSpans, strings.Join(reasons, ", "))
+}
+
+// PushPartialSuccessError returns a gRPC status error with the partial success attached to its details.
+// The message is prefixed with prefix.
+func PushPartialSuccessError(code codes.Code, prefix string, ps *PushPartialSuccess) error {
+	msg := ps.ErrorMessage
+	if prefix != "" {
+		msg = prefix + " " + msg
+	}
+
+	s, err := status.New(code, msg).WithDetails(ps)
+	if err != nil {
+		return status.Error(code, msg)
+	}
+	return s.Err()
+}
+
+// PushPartialSuccessFromError returns the partial success attached to a gRPC status error, if any.
+func PushPartialSuccessFromError(err error) (*PushPartialSuccess, bool) {
+	s, ok := status.FromError(err)
+	if !ok || s == nil {
+		return nil, false
+	}
+
+	for _, d := range s.Details() {
+		if ps, ok := d.(*PushPartialSuccess); ok {
+			return ps, true
+		}
+	}
+	return nil, false
+}
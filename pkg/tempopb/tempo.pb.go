@@ -28,12 +28,114 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+// SearchSort is the order of search results. Sorted searches inspect every match to return the first results in the
+// order, and can be paged with continuation tokens.
+type SearchSort int32
+
+const (
+	// newest first out of the first matches found. faster, but not exact and can't be paged
+	SearchSort_FIRST_FOUND     SearchSort = 0
+	SearchSort_START_TIME_DESC SearchSort = 1
+	SearchSort_START_TIME_ASC  SearchSort = 2
+	SearchSort_DURATION_DESC   SearchSort = 3
+	SearchSort_DURATION_ASC    SearchSort = 4
+	SearchSort_SPAN_COUNT_DESC SearchSort = 5
+	SearchSort_SPAN_COUNT_ASC  SearchSort = 6
+)
+
+var SearchSort_name = map[int32]string{
+	0: "FIRST_FOUND",
+	1: "START_TIME_DESC",
+	2: "START_TIME_ASC",
+	3: "DURATION_DESC",
+	4: "DURATION_ASC",
+	5: "SPAN_COUNT_DESC",
+	6: "SPAN_COUNT_ASC",
+}
+
+var SearchSort_value = map[string]int32{
+	"FIRST_FOUND":     0,
+	"START_TIME_DESC": 1,
+	"START_TIME_ASC":  2,
+	"DURATION_DESC":   3,
+	"DURATION_ASC":    4,
+	"SPAN_COUNT_DESC": 5,
+	"SPAN_COUNT_ASC":  6,
+}
+
+func (x SearchSort) String() string {
+	return proto.EnumName(SearchSort_name, int32(x))
+}
+
+func (SearchSort) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{0}
+}
+
+type PushErrorReason int32
+
+const (
+	PushErrorReason_NO_ERROR         PushErrorReason = 0
+	PushErrorReason_MAX_LIVE_TRACES  PushErrorReason = 1
+	PushErrorReason_TRACE_TOO_LARGE  PushErrorReason = 2
+	PushErrorReason_INVALID_TRACE_ID PushErrorReason = 3
+)
+
+var PushErrorReason_name = map[int32]string{
+	0: "NO_ERROR",
+	1: "MAX_LIVE_TRACES",
+	2: "TRACE_TOO_LARGE",
+	3: "INVALID_TRACE_ID",
+}
+
+var PushErrorReason_value = map[string]int32{
+	"NO_ERROR":         0,
+	"MAX_LIVE_TRACES":  1,
+	"TRACE_TOO_LARGE":  2,
+	"INVALID_TRACE_ID": 3,
+}
+
+func (x PushErrorReason) String() string {
+	return proto.EnumName(PushErrorReason_name, int32(x))
+}
+
+func (PushErrorReason) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{1}
+}
+
+type TransferFileKind int32
+
+const (
+	TransferFileKind_WAL_BLOCK   TransferFileKind = 0
+	TransferFileKind_LOCAL_BLOCK TransferFileKind = 1
+)
+
+var TransferFileKind_name = map[int32]string{
+	0: "WAL_BLOCK",
+	1: "LOCAL_BLOCK",
+}
+
+var TransferFileKind_value = map[string]int32{
+	"WAL_BLOCK":   0,
+	"LOCAL_BLOCK": 1,
+}
+
+func (x TransferFileKind) String() string {
+	return proto.EnumName(TransferFileKind_name, int32(x))
+}
+
+func (TransferFileKind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{2}
+}
+
 // Read
 type TraceByIDRequest struct {
 	TraceID    []byte `protobuf:"bytes,1,opt,name=traceID,proto3" json:"traceID,omitempty"`
 	BlockStart string `protobuf:"bytes,2,opt,name=blockStart,proto3" json:"blockStart,omitempty"`
 	BlockEnd   string `protobuf:"bytes,3,opt,name=blockEnd,proto3" json:"blockEnd,omitempty"`
 	QueryMode  string `protobuf:"bytes,5,opt,name=queryMode,proto3" json:"queryMode,omitempty"`
+	// optional time range hint in unix epoch seconds. blocks that don't overlap it are not searched. 0 is unbounded
+	Start uint32 `protobuf:"varint,6,opt,name=start,proto3" json:"start,omitempty"`
+	End   uint32 `protobuf:"varint,7,opt,name=end,proto3" json:"end,omitempty"`
 }
 
 func (m *TraceByIDRequest) Reset()         { *m = TraceByIDRequest{} }
@@ -97,8 +199,24 @@ func (m *TraceByIDRequest) GetQueryMode() string {
 	return ""
 }
 
+func (m *TraceByIDRequest) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *TraceByIDRequest) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
 type TraceByIDResponse struct {
 	Trace *Trace `protobuf:"bytes,1,opt,name=trace,proto3" json:"trace,omitempty"`
+	// blocks and bytes of the store inspected by the querier
+	Metrics *SearchMetrics `protobuf:"bytes,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
 }
 
 func (m *TraceByIDResponse) Reset()         { *m = TraceByIDResponse{} }
@@ -141,19 +259,200 @@ func (m *TraceByIDResponse) GetTrace() *Trace {
 	return nil
 }
 
+func (m *TraceByIDResponse) GetMetrics() *SearchMetrics {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+// batch lookup of many trace ids at once
+type TracesByIDRequest struct {
+	TraceIDs [][]byte `protobuf:"bytes,1,rep,name=traceIDs,proto3" json:"traceIDs,omitempty"`
+	// optional time range hint in unix epoch seconds, like in TraceByIDRequest
+	Start uint32 `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End   uint32 `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (m *TracesByIDRequest) Reset()         { *m = TracesByIDRequest{} }
+func (m *TracesByIDRequest) String() string { return proto.CompactTextString(m) }
+func (*TracesByIDRequest) ProtoMessage()    {}
+func (*TracesByIDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{2}
+}
+func (m *TracesByIDRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TracesByIDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TracesByIDRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TracesByIDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TracesByIDRequest.Merge(m, src)
+}
+func (m *TracesByIDRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TracesByIDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TracesByIDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TracesByIDRequest proto.InternalMessageInfo
+
+func (m *TracesByIDRequest) GetTraceIDs() [][]byte {
+	if m != nil {
+		return m.TraceIDs
+	}
+	return nil
+}
+
+func (m *TracesByIDRequest) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *TracesByIDRequest) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+type TracesByIDResponse struct {
+	Traces []*TraceByIDResult `protobuf:"bytes,1,rep,name=traces,proto3" json:"traces,omitempty"`
+	// hex encoded ids of the requested traces that were not found
+	NotFound []string `protobuf:"bytes,2,rep,name=notFound,proto3" json:"notFound,omitempty"`
+}
+
+func (m *TracesByIDResponse) Reset()         { *m = TracesByIDResponse{} }
+func (m *TracesByIDResponse) String() string { return proto.CompactTextString(m) }
+func (*TracesByIDResponse) ProtoMessage()    {}
+func (*TracesByIDResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{3}
+}
+func (m *TracesByIDResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TracesByIDResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TracesByIDResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TracesByIDResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TracesByIDResponse.Merge(m, src)
+}
+func (m *TracesByIDResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TracesByIDResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TracesByIDResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TracesByIDResponse proto.InternalMessageInfo
+
+func (m *TracesByIDResponse) GetTraces() []*TraceByIDResult {
+	if m != nil {
+		return m.Traces
+	}
+	return nil
+}
+
+func (m *TracesByIDResponse) GetNotFound() []string {
+	if m != nil {
+		return m.NotFound
+	}
+	return nil
+}
+
+type TraceByIDResult struct {
+	// hex encoded
+	TraceID string `protobuf:"bytes,1,opt,name=traceID,proto3" json:"traceID,omitempty"`
+	Trace   *Trace `protobuf:"bytes,2,opt,name=trace,proto3" json:"trace,omitempty"`
+}
+
+func (m *TraceByIDResult) Reset()         { *m = TraceByIDResult{} }
+func (m *TraceByIDResult) String() string { return proto.CompactTextString(m) }
+func (*TraceByIDResult) ProtoMessage()    {}
+func (*TraceByIDResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{4}
+}
+func (m *TraceByIDResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TraceByIDResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TraceByIDResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TraceByIDResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TraceByIDResult.Merge(m, src)
+}
+func (m *TraceByIDResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *TraceByIDResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_TraceByIDResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TraceByIDResult proto.InternalMessageInfo
+
+func (m *TraceByIDResult) GetTraceID() string {
+	if m != nil {
+		return m.TraceID
+	}
+	return ""
+}
+
+func (m *TraceByIDResult) GetTrace() *Trace {
+	if m != nil {
+		return m.Trace
+	}
+	return nil
+}
+
 type SearchRequest struct {
 	// case insensitive partial match
 	Tags          map[string]string `protobuf:"bytes,1,rep,name=Tags,proto3" json:"Tags" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	MinDurationMs uint32            `protobuf:"varint,2,opt,name=MinDurationMs,proto3" json:"MinDurationMs,omitempty"`
 	MaxDurationMs uint32            `protobuf:"varint,3,opt,name=MaxDurationMs,proto3" json:"MaxDurationMs,omitempty"`
 	Limit         uint32            `protobuf:"varint,4,opt,name=Limit,proto3" json:"Limit,omitempty"`
+	// optional time range in unix epoch seconds. only traces that overlap it are returned. 0 is unbounded
+	Start uint32     `protobuf:"varint,5,opt,name=start,proto3" json:"start,omitempty"`
+	End   uint32     `protobuf:"varint,6,opt,name=end,proto3" json:"end,omitempty"`
+	Sort  SearchSort `protobuf:"varint,7,opt,name=sort,proto3,enum=tempopb.SearchSort" json:"sort,omitempty"`
+	// continuation token of the previous page of a sorted search. the rest of the request must be the same
+	ContinuationToken string `protobuf:"bytes,8,opt,name=continuationToken,proto3" json:"continuationToken,omitempty"`
 }
 
 func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
 func (m *SearchRequest) String() string { return proto.CompactTextString(m) }
 func (*SearchRequest) ProtoMessage()    {}
 func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{2}
+	return fileDescriptor_f22805646f4f62b6, []int{5}
 }
 func (m *SearchRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -210,16 +509,46 @@ func (m *SearchRequest) GetLimit() uint32 {
 	return 0
 }
 
+func (m *SearchRequest) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetSort() SearchSort {
+	if m != nil {
+		return m.Sort
+	}
+	return SearchSort_FIRST_FOUND
+}
+
+func (m *SearchRequest) GetContinuationToken() string {
+	if m != nil {
+		return m.ContinuationToken
+	}
+	return ""
+}
+
 type SearchResponse struct {
 	Traces  []*TraceSearchMetadata `protobuf:"bytes,1,rep,name=traces,proto3" json:"traces,omitempty"`
 	Metrics *SearchMetrics         `protobuf:"bytes,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	// set if a sorted search has more results. pass it in the request of the next page
+	ContinuationToken string `protobuf:"bytes,3,opt,name=continuationToken,proto3" json:"continuationToken,omitempty"`
 }
 
 func (m *SearchResponse) Reset()         { *m = SearchResponse{} }
 func (m *SearchResponse) String() string { return proto.CompactTextString(m) }
 func (*SearchResponse) ProtoMessage()    {}
 func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{3}
+	return fileDescriptor_f22805646f4f62b6, []int{6}
 }
 func (m *SearchResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -262,19 +591,27 @@ func (m *SearchResponse) GetMetrics() *SearchMetrics {
 	return nil
 }
 
+func (m *SearchResponse) GetContinuationToken() string {
+	if m != nil {
+		return m.ContinuationToken
+	}
+	return ""
+}
+
 type TraceSearchMetadata struct {
 	TraceID           string `protobuf:"bytes,1,opt,name=traceID,proto3" json:"traceID,omitempty"`
 	RootServiceName   string `protobuf:"bytes,2,opt,name=rootServiceName,proto3" json:"rootServiceName,omitempty"`
 	RootTraceName     string `protobuf:"bytes,3,opt,name=rootTraceName,proto3" json:"rootTraceName,omitempty"`
 	StartTimeUnixNano uint64 `protobuf:"varint,4,opt,name=startTimeUnixNano,proto3" json:"startTimeUnixNano,omitempty"`
 	DurationMs        uint32 `protobuf:"varint,5,opt,name=durationMs,proto3" json:"durationMs,omitempty"`
+	SpanCount         uint32 `protobuf:"varint,6,opt,name=spanCount,proto3" json:"spanCount,omitempty"`
 }
 
 func (m *TraceSearchMetadata) Reset()         { *m = TraceSearchMetadata{} }
 func (m *TraceSearchMetadata) String() string { return proto.CompactTextString(m) }
 func (*TraceSearchMetadata) ProtoMessage()    {}
 func (*TraceSearchMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{4}
+	return fileDescriptor_f22805646f4f62b6, []int{7}
 }
 func (m *TraceSearchMetadata) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -338,6 +675,13 @@ func (m *TraceSearchMetadata) GetDurationMs() uint32 {
 	return 0
 }
 
+func (m *TraceSearchMetadata) GetSpanCount() uint32 {
+	if m != nil {
+		return m.SpanCount
+	}
+	return 0
+}
+
 type SearchMetrics struct {
 	InspectedTraces uint32 `protobuf:"varint,1,opt,name=inspectedTraces,proto3" json:"inspectedTraces,omitempty"`
 	InspectedBytes  uint64 `protobuf:"varint,2,opt,name=inspectedBytes,proto3" json:"inspectedBytes,omitempty"`
@@ -349,7 +693,7 @@ func (m *SearchMetrics) Reset()         { *m = SearchMetrics{} }
 func (m *SearchMetrics) String() string { return proto.CompactTextString(m) }
 func (*SearchMetrics) ProtoMessage()    {}
 func (*SearchMetrics) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{5}
+	return fileDescriptor_f22805646f4f62b6, []int{8}
 }
 func (m *SearchMetrics) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -406,21 +750,25 @@ func (m *SearchMetrics) GetSkippedBlocks() uint32 {
 	return 0
 }
 
-type SearchTagsRequest struct {
+type TailRequest struct {
+	// filter evaluated against the search data of every pushed segment. limit is ignored
+	Search *SearchRequest `protobuf:"bytes,1,opt,name=search,proto3" json:"search,omitempty"`
+	// include the spans of the matching segment in the response
+	IncludeSpans bool `protobuf:"varint,2,opt,name=includeSpans,proto3" json:"includeSpans,omitempty"`
 }
 
-func (m *SearchTagsRequest) Reset()         { *m = SearchTagsRequest{} }
-func (m *SearchTagsRequest) String() string { return proto.CompactTextString(m) }
-func (*SearchTagsRequest) ProtoMessage()    {}
-func (*SearchTagsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{6}
+func (m *TailRequest) Reset()         { *m = TailRequest{} }
+func (m *TailRequest) String() string { return proto.CompactTextString(m) }
+func (*TailRequest) ProtoMessage()    {}
+func (*TailRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{9}
 }
-func (m *SearchTagsRequest) XXX_Unmarshal(b []byte) error {
+func (m *TailRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *SearchTagsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *TailRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_SearchTagsRequest.Marshal(b, m, deterministic)
+		return xxx_messageInfo_TailRequest.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalToSizedBuffer(b)
@@ -430,34 +778,52 @@ func (m *SearchTagsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, e
 		return b[:n], nil
 	}
 }
-func (m *SearchTagsRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SearchTagsRequest.Merge(m, src)
+func (m *TailRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TailRequest.Merge(m, src)
 }
-func (m *SearchTagsRequest) XXX_Size() int {
+func (m *TailRequest) XXX_Size() int {
 	return m.Size()
 }
-func (m *SearchTagsRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_SearchTagsRequest.DiscardUnknown(m)
+func (m *TailRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TailRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_SearchTagsRequest proto.InternalMessageInfo
+var xxx_messageInfo_TailRequest proto.InternalMessageInfo
 
-type SearchTagsResponse struct {
-	TagNames []string `protobuf:"bytes,1,rep,name=tagNames,proto3" json:"tagNames,omitempty"`
+func (m *TailRequest) GetSearch() *SearchRequest {
+	if m != nil {
+		return m.Search
+	}
+	return nil
 }
 
-func (m *SearchTagsResponse) Reset()         { *m = SearchTagsResponse{} }
-func (m *SearchTagsResponse) String() string { return proto.CompactTextString(m) }
-func (*SearchTagsResponse) ProtoMessage()    {}
-func (*SearchTagsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{7}
+func (m *TailRequest) GetIncludeSpans() bool {
+	if m != nil {
+		return m.IncludeSpans
+	}
+	return false
 }
-func (m *SearchTagsResponse) XXX_Unmarshal(b []byte) error {
+
+type TailResponse struct {
+	Metadata *TraceSearchMetadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// spans of the matching segment, only set if requested
+	Trace *Trace `protobuf:"bytes,2,opt,name=trace,proto3" json:"trace,omitempty"`
+	// matches dropped since the previous response because the subscriber fell behind
+	Dropped uint32 `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+}
+
+func (m *TailResponse) Reset()         { *m = TailResponse{} }
+func (m *TailResponse) String() string { return proto.CompactTextString(m) }
+func (*TailResponse) ProtoMessage()    {}
+func (*TailResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{10}
+}
+func (m *TailResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *SearchTagsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *TailResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_SearchTagsResponse.Marshal(b, m, deterministic)
+		return xxx_messageInfo_TailResponse.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalToSizedBuffer(b)
@@ -467,40 +833,146 @@ func (m *SearchTagsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte,
 		return b[:n], nil
 	}
 }
-func (m *SearchTagsResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SearchTagsResponse.Merge(m, src)
+func (m *TailResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TailResponse.Merge(m, src)
 }
-func (m *SearchTagsResponse) XXX_Size() int {
+func (m *TailResponse) XXX_Size() int {
 	return m.Size()
 }
-func (m *SearchTagsResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_SearchTagsResponse.DiscardUnknown(m)
+func (m *TailResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TailResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_SearchTagsResponse proto.InternalMessageInfo
+var xxx_messageInfo_TailResponse proto.InternalMessageInfo
 
-func (m *SearchTagsResponse) GetTagNames() []string {
+func (m *TailResponse) GetMetadata() *TraceSearchMetadata {
 	if m != nil {
-		return m.TagNames
+		return m.Metadata
 	}
 	return nil
 }
 
-type SearchTagValuesRequest struct {
-	TagName string `protobuf:"bytes,1,opt,name=tagName,proto3" json:"tagName,omitempty"`
+func (m *TailResponse) GetTrace() *Trace {
+	if m != nil {
+		return m.Trace
+	}
+	return nil
 }
 
-func (m *SearchTagValuesRequest) Reset()         { *m = SearchTagValuesRequest{} }
-func (m *SearchTagValuesRequest) String() string { return proto.CompactTextString(m) }
-func (*SearchTagValuesRequest) ProtoMessage()    {}
-func (*SearchTagValuesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{8}
+func (m *TailResponse) GetDropped() uint32 {
+	if m != nil {
+		return m.Dropped
+	}
+	return 0
 }
-func (m *SearchTagValuesRequest) XXX_Unmarshal(b []byte) error {
-	return m.Unmarshal(b)
+
+type SearchTagsRequest struct {
 }
-func (m *SearchTagValuesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	if deterministic {
+
+func (m *SearchTagsRequest) Reset()         { *m = SearchTagsRequest{} }
+func (m *SearchTagsRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchTagsRequest) ProtoMessage()    {}
+func (*SearchTagsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{11}
+}
+func (m *SearchTagsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SearchTagsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SearchTagsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SearchTagsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchTagsRequest.Merge(m, src)
+}
+func (m *SearchTagsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SearchTagsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchTagsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchTagsRequest proto.InternalMessageInfo
+
+type SearchTagsResponse struct {
+	TagNames []string `protobuf:"bytes,1,rep,name=tagNames,proto3" json:"tagNames,omitempty"`
+	// tags with more values than are kept for autocomplete
+	HighCardinalityTagNames []string `protobuf:"bytes,2,rep,name=highCardinalityTagNames,proto3" json:"highCardinalityTagNames,omitempty"`
+}
+
+func (m *SearchTagsResponse) Reset()         { *m = SearchTagsResponse{} }
+func (m *SearchTagsResponse) String() string { return proto.CompactTextString(m) }
+func (*SearchTagsResponse) ProtoMessage()    {}
+func (*SearchTagsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{12}
+}
+func (m *SearchTagsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SearchTagsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SearchTagsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SearchTagsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchTagsResponse.Merge(m, src)
+}
+func (m *SearchTagsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *SearchTagsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchTagsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchTagsResponse proto.InternalMessageInfo
+
+func (m *SearchTagsResponse) GetTagNames() []string {
+	if m != nil {
+		return m.TagNames
+	}
+	return nil
+}
+
+func (m *SearchTagsResponse) GetHighCardinalityTagNames() []string {
+	if m != nil {
+		return m.HighCardinalityTagNames
+	}
+	return nil
+}
+
+type SearchTagValuesRequest struct {
+	TagName string `protobuf:"bytes,1,opt,name=tagName,proto3" json:"tagName,omitempty"`
+	// optional. only the values in the traces matching the filter are returned, counted by trace. limit and sort are
+	// ignored
+	Filter *SearchRequest `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (m *SearchTagValuesRequest) Reset()         { *m = SearchTagValuesRequest{} }
+func (m *SearchTagValuesRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchTagValuesRequest) ProtoMessage()    {}
+func (*SearchTagValuesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{13}
+}
+func (m *SearchTagValuesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SearchTagValuesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
 		return xxx_messageInfo_SearchTagValuesRequest.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
@@ -530,15 +1002,29 @@ func (m *SearchTagValuesRequest) GetTagName() string {
 	return ""
 }
 
+func (m *SearchTagValuesRequest) GetFilter() *SearchRequest {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
 type SearchTagValuesResponse struct {
 	TagValues []string `protobuf:"bytes,1,rep,name=tagValues,proto3" json:"tagValues,omitempty"`
+	// true if the tag has more values than are kept for autocomplete, only the most recent ones are returned. for
+	// filtered requests, only the values in the most traces are returned
+	HighCardinality bool `protobuf:"varint,2,opt,name=highCardinality,proto3" json:"highCardinality,omitempty"`
+	// estimated number of traces matching the filter with each value. only set for filtered requests. the querier sums
+	// the counts of the ingesters and divides them by the replication factor, so traces that didn't reach every replica
+	// or are only in some of the searched blocks skew the estimate
+	EstimatedCounts map[string]uint32 `protobuf:"bytes,3,rep,name=estimatedCounts,proto3" json:"estimatedCounts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 }
 
 func (m *SearchTagValuesResponse) Reset()         { *m = SearchTagValuesResponse{} }
 func (m *SearchTagValuesResponse) String() string { return proto.CompactTextString(m) }
 func (*SearchTagValuesResponse) ProtoMessage()    {}
 func (*SearchTagValuesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{9}
+	return fileDescriptor_f22805646f4f62b6, []int{14}
 }
 func (m *SearchTagValuesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -574,6 +1060,20 @@ func (m *SearchTagValuesResponse) GetTagValues() []string {
 	return nil
 }
 
+func (m *SearchTagValuesResponse) GetHighCardinality() bool {
+	if m != nil {
+		return m.HighCardinality
+	}
+	return false
+}
+
+func (m *SearchTagValuesResponse) GetEstimatedCounts() map[string]uint32 {
+	if m != nil {
+		return m.EstimatedCounts
+	}
+	return nil
+}
+
 type Trace struct {
 	Batches []*v1.ResourceSpans `protobuf:"bytes,1,rep,name=batches,proto3" json:"batches,omitempty"`
 }
@@ -582,7 +1082,7 @@ func (m *Trace) Reset()         { *m = Trace{} }
 func (m *Trace) String() string { return proto.CompactTextString(m) }
 func (*Trace) ProtoMessage()    {}
 func (*Trace) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{10}
+	return fileDescriptor_f22805646f4f62b6, []int{15}
 }
 func (m *Trace) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -627,7 +1127,7 @@ func (m *PushRequest) Reset()         { *m = PushRequest{} }
 func (m *PushRequest) String() string { return proto.CompactTextString(m) }
 func (*PushRequest) ProtoMessage()    {}
 func (*PushRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{11}
+	return fileDescriptor_f22805646f4f62b6, []int{16}
 }
 func (m *PushRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -664,13 +1164,18 @@ func (m *PushRequest) GetBatch() *v1.ResourceSpans {
 }
 
 type PushResponse struct {
+	// Compatible with the OTLP ExportTraceServiceResponse partial_success field. Set when some
+	// spans of the request were rejected.
+	PartialSuccess *PushPartialSuccess `protobuf:"bytes,1,opt,name=partialSuccess,proto3" json:"partialSuccess,omitempty"`
+	// Set by ingesters in response to PushBytes. One entry per rejected trace.
+	TraceErrors []PushTraceError `protobuf:"bytes,2,rep,name=traceErrors,proto3" json:"traceErrors"`
 }
 
 func (m *PushResponse) Reset()         { *m = PushResponse{} }
 func (m *PushResponse) String() string { return proto.CompactTextString(m) }
 func (*PushResponse) ProtoMessage()    {}
 func (*PushResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{12}
+	return fileDescriptor_f22805646f4f62b6, []int{17}
 }
 func (m *PushResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -699,6 +1204,196 @@ func (m *PushResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PushResponse proto.InternalMessageInfo
 
+func (m *PushResponse) GetPartialSuccess() *PushPartialSuccess {
+	if m != nil {
+		return m.PartialSuccess
+	}
+	return nil
+}
+
+func (m *PushResponse) GetTraceErrors() []PushTraceError {
+	if m != nil {
+		return m.TraceErrors
+	}
+	return nil
+}
+
+// Compatible with the OTLP ExportTracePartialSuccess message. Also attached to gRPC status
+// details when a push is rejected.
+type PushPartialSuccess struct {
+	RejectedSpans int64  `protobuf:"varint,1,opt,name=rejectedSpans,proto3" json:"rejectedSpans,omitempty"`
+	ErrorMessage  string `protobuf:"bytes,2,opt,name=errorMessage,proto3" json:"errorMessage,omitempty"`
+	// Tempo extensions, ignored by OTLP clients
+	AcceptedSpans    int64           `protobuf:"varint,3,opt,name=acceptedSpans,proto3" json:"acceptedSpans,omitempty"`
+	RejectedByReason []RejectedSpans `protobuf:"bytes,4,rep,name=rejectedByReason,proto3" json:"rejectedByReason"`
+}
+
+func (m *PushPartialSuccess) Reset()         { *m = PushPartialSuccess{} }
+func (m *PushPartialSuccess) String() string { return proto.CompactTextString(m) }
+func (*PushPartialSuccess) ProtoMessage()    {}
+func (*PushPartialSuccess) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{18}
+}
+func (m *PushPartialSuccess) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PushPartialSuccess) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushPartialSuccess.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PushPartialSuccess) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushPartialSuccess.Merge(m, src)
+}
+func (m *PushPartialSuccess) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushPartialSuccess) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushPartialSuccess.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushPartialSuccess proto.InternalMessageInfo
+
+func (m *PushPartialSuccess) GetRejectedSpans() int64 {
+	if m != nil {
+		return m.RejectedSpans
+	}
+	return 0
+}
+
+func (m *PushPartialSuccess) GetErrorMessage() string {
+	if m != nil {
+		return m.ErrorMessage
+	}
+	return ""
+}
+
+func (m *PushPartialSuccess) GetAcceptedSpans() int64 {
+	if m != nil {
+		return m.AcceptedSpans
+	}
+	return 0
+}
+
+func (m *PushPartialSuccess) GetRejectedByReason() []RejectedSpans {
+	if m != nil {
+		return m.RejectedByReason
+	}
+	return nil
+}
+
+type RejectedSpans struct {
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Spans  int64  `protobuf:"varint,2,opt,name=spans,proto3" json:"spans,omitempty"`
+}
+
+func (m *RejectedSpans) Reset()         { *m = RejectedSpans{} }
+func (m *RejectedSpans) String() string { return proto.CompactTextString(m) }
+func (*RejectedSpans) ProtoMessage()    {}
+func (*RejectedSpans) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{19}
+}
+func (m *RejectedSpans) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RejectedSpans) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RejectedSpans.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RejectedSpans) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RejectedSpans.Merge(m, src)
+}
+func (m *RejectedSpans) XXX_Size() int {
+	return m.Size()
+}
+func (m *RejectedSpans) XXX_DiscardUnknown() {
+	xxx_messageInfo_RejectedSpans.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RejectedSpans proto.InternalMessageInfo
+
+func (m *RejectedSpans) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *RejectedSpans) GetSpans() int64 {
+	if m != nil {
+		return m.Spans
+	}
+	return 0
+}
+
+type PushTraceError struct {
+	// index of the trace in the PushBytesRequest
+	Index  uint32          `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Reason PushErrorReason `protobuf:"varint,2,opt,name=reason,proto3,enum=tempopb.PushErrorReason" json:"reason,omitempty"`
+}
+
+func (m *PushTraceError) Reset()         { *m = PushTraceError{} }
+func (m *PushTraceError) String() string { return proto.CompactTextString(m) }
+func (*PushTraceError) ProtoMessage()    {}
+func (*PushTraceError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{20}
+}
+func (m *PushTraceError) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PushTraceError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushTraceError.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PushTraceError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushTraceError.Merge(m, src)
+}
+func (m *PushTraceError) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushTraceError) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushTraceError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushTraceError proto.InternalMessageInfo
+
+func (m *PushTraceError) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *PushTraceError) GetReason() PushErrorReason {
+	if m != nil {
+		return m.Reason
+	}
+	return PushErrorReason_NO_ERROR
+}
+
 type PushBytesRequest struct {
 	// pre-marshalled PushRequests
 	Requests []PreallocBytes `protobuf:"bytes,1,rep,name=requests,proto3,customtype=PreallocBytes" json:"requests"` // Deprecated: Do not use.
@@ -708,13 +1403,15 @@ type PushBytesRequest struct {
 	Ids []PreallocBytes `protobuf:"bytes,3,rep,name=ids,proto3,customtype=PreallocBytes" json:"ids"`
 	// search data, length must match traces
 	SearchData []PreallocBytes `protobuf:"bytes,4,rep,name=searchData,proto3,customtype=PreallocBytes" json:"searchData"`
+	// whether the trace of the same index contains a span without a parent. optional, length must match traces if set
+	RootSpans []bool `protobuf:"varint,5,rep,packed,name=rootSpans,proto3" json:"rootSpans,omitempty"`
 }
 
 func (m *PushBytesRequest) Reset()         { *m = PushBytesRequest{} }
 func (m *PushBytesRequest) String() string { return proto.CompactTextString(m) }
 func (*PushBytesRequest) ProtoMessage()    {}
 func (*PushBytesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{13}
+	return fileDescriptor_f22805646f4f62b6, []int{21}
 }
 func (m *PushBytesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -743,6 +1440,13 @@ func (m *PushBytesRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PushBytesRequest proto.InternalMessageInfo
 
+func (m *PushBytesRequest) GetRootSpans() []bool {
+	if m != nil {
+		return m.RootSpans
+	}
+	return nil
+}
+
 type TraceBytes struct {
 	// pre-marshalled Traces
 	Traces [][]byte `protobuf:"bytes,1,rep,name=traces,proto3" json:"traces,omitempty"`
@@ -752,7 +1456,7 @@ func (m *TraceBytes) Reset()         { *m = TraceBytes{} }
 func (m *TraceBytes) String() string { return proto.CompactTextString(m) }
 func (*TraceBytes) ProtoMessage()    {}
 func (*TraceBytes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{14}
+	return fileDescriptor_f22805646f4f62b6, []int{22}
 }
 func (m *TraceBytes) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -788,85 +1492,417 @@ func (m *TraceBytes) GetTraces() [][]byte {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*TraceByIDRequest)(nil), "tempopb.TraceByIDRequest")
-	proto.RegisterType((*TraceByIDResponse)(nil), "tempopb.TraceByIDResponse")
-	proto.RegisterType((*SearchRequest)(nil), "tempopb.SearchRequest")
-	proto.RegisterMapType((map[string]string)(nil), "tempopb.SearchRequest.TagsEntry")
-	proto.RegisterType((*SearchResponse)(nil), "tempopb.SearchResponse")
-	proto.RegisterType((*TraceSearchMetadata)(nil), "tempopb.TraceSearchMetadata")
-	proto.RegisterType((*SearchMetrics)(nil), "tempopb.SearchMetrics")
-	proto.RegisterType((*SearchTagsRequest)(nil), "tempopb.SearchTagsRequest")
-	proto.RegisterType((*SearchTagsResponse)(nil), "tempopb.SearchTagsResponse")
-	proto.RegisterType((*SearchTagValuesRequest)(nil), "tempopb.SearchTagValuesRequest")
-	proto.RegisterType((*SearchTagValuesResponse)(nil), "tempopb.SearchTagValuesResponse")
-	proto.RegisterType((*Trace)(nil), "tempopb.Trace")
-	proto.RegisterType((*PushRequest)(nil), "tempopb.PushRequest")
-	proto.RegisterType((*PushResponse)(nil), "tempopb.PushResponse")
-	proto.RegisterType((*PushBytesRequest)(nil), "tempopb.PushBytesRequest")
-	proto.RegisterType((*TraceBytes)(nil), "tempopb.TraceBytes")
+// TransferRequest is a single message of an ingester hand-off. The leaving ingester streams its live traces
+// followed by its wal and local block files. Only one of trace or file is set.
+type TransferRequest struct {
+	FromIngesterID string         `protobuf:"bytes,1,opt,name=fromIngesterID,proto3" json:"fromIngesterID,omitempty"`
+	TenantID       string         `protobuf:"bytes,2,opt,name=tenantID,proto3" json:"tenantID,omitempty"`
+	Trace          *TransferTrace `protobuf:"bytes,3,opt,name=trace,proto3" json:"trace,omitempty"`
+	File           *TransferFile  `protobuf:"bytes,4,opt,name=file,proto3" json:"file,omitempty"`
+	// identifies the hand-off across retries, so the receiver can drop a retry of a transfer it already received
+	TransferID string `protobuf:"bytes,5,opt,name=transferID,proto3" json:"transferID,omitempty"`
 }
 
-func init() { proto.RegisterFile("pkg/tempopb/tempo.proto", fileDescriptor_f22805646f4f62b6) }
-
-var fileDescriptor_f22805646f4f62b6 = []byte{
-	// 890 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0x41, 0x8f, 0xda, 0x46,
-	0x14, 0xc6, 0x0b, 0x2c, 0xeb, 0xb7, 0xcb, 0x66, 0x77, 0xb2, 0x01, 0xd7, 0x5d, 0x01, 0xb2, 0x56,
-	0x2d, 0x87, 0x06, 0x12, 0xd2, 0x55, 0x9a, 0xf4, 0x50, 0x09, 0x91, 0xb6, 0x91, 0x4a, 0x94, 0x1a,
-	0x9a, 0xfb, 0x60, 0xa6, 0xac, 0x05, 0xd8, 0xce, 0x78, 0x8c, 0x96, 0x5b, 0x4f, 0x3d, 0xf7, 0xaf,
-	0xe4, 0x5f, 0xe4, 0x52, 0x29, 0xa7, 0xaa, 0xea, 0x21, 0xaa, 0x76, 0xa5, 0xfe, 0x8e, 0x6a, 0x66,
-	0xec, 0xc1, 0x36, 0x24, 0x39, 0xe1, 0xf7, 0xbd, 0xef, 0x3d, 0xcf, 0xfb, 0xfc, 0xbd, 0x01, 0xea,
-	0xc1, 0x7c, 0xd6, 0x65, 0x64, 0x19, 0xf8, 0xc1, 0x44, 0xfe, 0x76, 0x02, 0xea, 0x33, 0x1f, 0x55,
-	0x62, 0xd0, 0x3c, 0x63, 0x14, 0x3b, 0xa4, 0xbb, 0x7a, 0xd8, 0x15, 0x0f, 0x32, 0x6d, 0xde, 0x9f,
-	0xb9, 0xec, 0x2a, 0x9a, 0x74, 0x1c, 0x7f, 0xd9, 0x9d, 0xf9, 0x33, 0xbf, 0x2b, 0xe0, 0x49, 0xf4,
-	0xab, 0x88, 0x44, 0x20, 0x9e, 0x24, 0xdd, 0xfa, 0x5d, 0x83, 0x93, 0x31, 0x2f, 0xef, 0xaf, 0x9f,
-	0x0f, 0x6c, 0xf2, 0x3a, 0x22, 0x21, 0x43, 0x06, 0x54, 0x44, 0xcb, 0xe7, 0x03, 0x43, 0x6b, 0x69,
-	0xed, 0x23, 0x3b, 0x09, 0x51, 0x03, 0x60, 0xb2, 0xf0, 0x9d, 0xf9, 0x88, 0x61, 0xca, 0x8c, 0xbd,
-	0x96, 0xd6, 0xd6, 0xed, 0x14, 0x82, 0x4c, 0x38, 0x10, 0xd1, 0x33, 0x6f, 0x6a, 0x14, 0x45, 0x56,
-	0xc5, 0xe8, 0x1c, 0xf4, 0xd7, 0x11, 0xa1, 0xeb, 0xa1, 0x3f, 0x25, 0x46, 0x59, 0x24, 0x37, 0x80,
-	0xf5, 0x04, 0x4e, 0x53, 0xe7, 0x08, 0x03, 0xdf, 0x0b, 0x09, 0xba, 0x80, 0xb2, 0x78, 0xb3, 0x38,
-	0xc6, 0x61, 0xef, 0xb8, 0x13, 0xcf, 0xde, 0x11, 0x54, 0x5b, 0x26, 0xad, 0xff, 0x34, 0xa8, 0x8e,
-	0x08, 0xa6, 0xce, 0x55, 0x32, 0xc0, 0x53, 0x28, 0x8d, 0xf1, 0x2c, 0x34, 0xb4, 0x56, 0xb1, 0x7d,
-	0xd8, 0x6b, 0xa9, 0xb2, 0x0c, 0xab, 0xc3, 0x29, 0xcf, 0x3c, 0x46, 0xd7, 0xfd, 0xd2, 0xdb, 0xf7,
-	0xcd, 0x82, 0x2d, 0x6a, 0xd0, 0x05, 0x54, 0x87, 0xae, 0x37, 0x88, 0x28, 0x66, 0xae, 0xef, 0x0d,
-	0x43, 0x31, 0x65, 0xd5, 0xce, 0x82, 0x82, 0x85, 0xaf, 0x53, 0xac, 0x62, 0xcc, 0x4a, 0x83, 0xe8,
-	0x0c, 0xca, 0x3f, 0xb9, 0x4b, 0x97, 0x19, 0x25, 0x91, 0x95, 0x81, 0xf9, 0x18, 0x74, 0xf5, 0x6a,
-	0x74, 0x02, 0xc5, 0x39, 0x59, 0x8b, 0x01, 0x75, 0x9b, 0x3f, 0xf2, 0xa2, 0x15, 0x5e, 0x44, 0x24,
-	0x96, 0x57, 0x06, 0x4f, 0xf7, 0xbe, 0xd1, 0xac, 0x6b, 0x38, 0x4e, 0x26, 0x88, 0x05, 0xfa, 0x1a,
-	0xf6, 0x85, 0x06, 0xc9, 0xa8, 0xe7, 0x59, 0x85, 0x24, 0x7b, 0x48, 0x18, 0x9e, 0x62, 0x86, 0xed,
-	0x98, 0x8b, 0x1e, 0x40, 0x65, 0x49, 0x18, 0x75, 0x1d, 0x39, 0xdc, 0x61, 0xaf, 0x96, 0x53, 0x68,
-	0x28, 0xb3, 0x76, 0x42, 0xb3, 0xfe, 0xd4, 0xe0, 0xee, 0x8e, 0x8e, 0x79, 0xa7, 0xe8, 0x1b, 0xa7,
-	0xb4, 0xe1, 0x0e, 0xf5, 0x7d, 0x36, 0x22, 0x74, 0xe5, 0x3a, 0xe4, 0x05, 0x5e, 0x26, 0xf3, 0xe4,
-	0x61, 0x2e, 0x25, 0x87, 0x44, 0x7b, 0xc1, 0x93, 0xc6, 0xc9, 0x82, 0xe8, 0x2b, 0x38, 0x0d, 0xb9,
-	0xc5, 0xc6, 0xee, 0x92, 0xfc, 0xe2, 0xb9, 0xd7, 0x2f, 0xb0, 0xe7, 0x0b, 0x59, 0x4b, 0xf6, 0x76,
-	0x82, 0xfb, 0x74, 0xba, 0xf9, 0x36, 0x65, 0xa1, 0x7e, 0x0a, 0xb1, 0xde, 0x28, 0xcb, 0xc4, 0xa3,
-	0xf2, 0xf3, 0xba, 0x5e, 0x18, 0x10, 0x87, 0x91, 0xe9, 0x38, 0x91, 0x94, 0x97, 0xe5, 0x61, 0xf4,
-	0x05, 0x1c, 0x2b, 0xa8, 0xbf, 0x66, 0x44, 0x8a, 0x58, 0xb2, 0x73, 0x68, 0xa6, 0x63, 0x9f, 0x2f,
-	0x41, 0x62, 0x92, 0x3c, 0xcc, 0x15, 0x08, 0xe7, 0x6e, 0x10, 0x28, 0x9e, 0xb4, 0x4b, 0x16, 0xb4,
-	0xee, 0xc2, 0xa9, 0x3c, 0x32, 0x37, 0x4f, 0xec, 0x61, 0xeb, 0x01, 0xa0, 0x34, 0x18, 0xdb, 0xc2,
-	0x84, 0x03, 0x86, 0x67, 0x5c, 0x37, 0x69, 0x0c, 0xdd, 0x56, 0xb1, 0xd5, 0x83, 0x9a, 0xaa, 0x78,
-	0xc5, 0xad, 0x15, 0xa6, 0xd7, 0x5e, 0xb2, 0xd4, 0xc7, 0x94, 0xa1, 0xf5, 0x18, 0xea, 0x5b, 0x35,
-	0xf1, 0xab, 0xce, 0x41, 0x67, 0x09, 0x18, 0xbf, 0x6b, 0x03, 0x58, 0x7d, 0x28, 0x0b, 0xd5, 0xd0,
-	0x13, 0xa8, 0x4c, 0x30, 0x73, 0xae, 0x94, 0x53, 0x9b, 0xca, 0x72, 0xf2, 0xf6, 0x5a, 0x3d, 0xec,
-	0xd8, 0x24, 0xf4, 0x23, 0xea, 0x90, 0x51, 0x80, 0xbd, 0xd0, 0x4e, 0xf8, 0xd6, 0x00, 0x0e, 0x5f,
-	0x46, 0xa1, 0xda, 0xed, 0x4b, 0x28, 0x8b, 0x4c, 0x7c, 0x27, 0x7c, 0xb2, 0x8f, 0x64, 0x5b, 0xc7,
-	0x70, 0x24, 0xbb, 0xc8, 0x73, 0x5b, 0x7f, 0x69, 0x70, 0xc2, 0x01, 0xf1, 0xad, 0x92, 0xde, 0x8f,
-	0xe0, 0x80, 0xca, 0x47, 0x79, 0xcc, 0xa3, 0x7e, 0x9d, 0xdf, 0x0c, 0xff, 0xbc, 0x6f, 0x56, 0x5f,
-	0x52, 0x82, 0x17, 0x0b, 0xdf, 0x91, 0x5f, 0x5c, 0xb3, 0x15, 0x11, 0xdd, 0x57, 0x3b, 0xb8, 0x27,
-	0x4a, 0xee, 0xed, 0x2c, 0x51, 0xcb, 0xf7, 0x25, 0x14, 0xdd, 0x29, 0xb7, 0xc2, 0x47, 0xb8, 0x9c,
-	0x81, 0x2e, 0x01, 0x42, 0x21, 0xfa, 0x00, 0x33, 0x6c, 0x94, 0x3e, 0xc6, 0x4f, 0x11, 0xad, 0x0b,
-	0x80, 0xf8, 0x22, 0xe5, 0x26, 0xac, 0x65, 0x2e, 0x88, 0xa3, 0xe4, 0x14, 0xbd, 0xdf, 0x34, 0xd8,
-	0xe7, 0xe3, 0x13, 0x8a, 0x2e, 0xa1, 0xc4, 0x9f, 0xd0, 0x99, 0x52, 0x32, 0x25, 0xb7, 0x79, 0x2f,
-	0x87, 0xc6, 0xf2, 0x15, 0xd0, 0x77, 0xa0, 0x2b, 0xfd, 0xd0, 0x67, 0x19, 0x56, 0x5a, 0xd3, 0x0f,
-	0x36, 0xe8, 0xbd, 0xd9, 0x83, 0xca, 0xcf, 0x11, 0xa1, 0x2e, 0xa1, 0xe8, 0x47, 0xa8, 0x7e, 0xef,
-	0x7a, 0x53, 0xf5, 0x0f, 0x90, 0x6a, 0x98, 0xff, 0x77, 0x32, 0xcd, 0x5d, 0x29, 0x75, 0xac, 0x6f,
-	0x61, 0x5f, 0x5a, 0x15, 0xd5, 0x76, 0x5f, 0xfb, 0x66, 0x7d, 0x0b, 0x57, 0xc5, 0x3f, 0x00, 0x6c,
-	0xb6, 0x09, 0x99, 0x39, 0x62, 0x6a, 0xef, 0xcc, 0xcf, 0x77, 0xe6, 0x54, 0xa3, 0x57, 0x70, 0x27,
-	0xb7, 0x30, 0xa8, 0xb9, 0x5d, 0x91, 0x59, 0x3f, 0xb3, 0xf5, 0x61, 0x42, 0xd2, 0xb7, 0x6f, 0xbc,
-	0xbd, 0x69, 0x68, 0xef, 0x6e, 0x1a, 0xda, 0xbf, 0x37, 0x0d, 0xed, 0x8f, 0xdb, 0x46, 0xe1, 0xdd,
-	0x6d, 0xa3, 0xf0, 0xf7, 0x6d, 0xa3, 0x30, 0xd9, 0x17, 0xff, 0xe7, 0x8f, 0xfe, 0x0f, 0x00, 0x00,
-	0xff, 0xff, 0xcf, 0x63, 0xe2, 0x49, 0x38, 0x08, 0x00, 0x00,
+func (m *TransferRequest) Reset()         { *m = TransferRequest{} }
+func (m *TransferRequest) String() string { return proto.CompactTextString(m) }
+func (*TransferRequest) ProtoMessage()    {}
+func (*TransferRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{23}
+}
+func (m *TransferRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferRequest.Merge(m, src)
+}
+func (m *TransferRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferRequest proto.InternalMessageInfo
+
+func (m *TransferRequest) GetFromIngesterID() string {
+	if m != nil {
+		return m.FromIngesterID
+	}
+	return ""
+}
+
+func (m *TransferRequest) GetTenantID() string {
+	if m != nil {
+		return m.TenantID
+	}
+	return ""
+}
+
+func (m *TransferRequest) GetTrace() *TransferTrace {
+	if m != nil {
+		return m.Trace
+	}
+	return nil
+}
+
+func (m *TransferRequest) GetFile() *TransferFile {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+func (m *TransferRequest) GetTransferID() string {
+	if m != nil {
+		return m.TransferID
+	}
+	return ""
+}
+
+// TransferTrace is part of a live trace. A trace may be split across several messages.
+type TransferTrace struct {
+	TraceID []byte `protobuf:"bytes,1,opt,name=traceID,proto3" json:"traceID,omitempty"`
+	// pre-marshalled Traces
+	Traces     [][]byte `protobuf:"bytes,2,rep,name=traces,proto3" json:"traces,omitempty"`
+	SearchData [][]byte `protobuf:"bytes,3,rep,name=searchData,proto3" json:"searchData,omitempty"`
+}
+
+func (m *TransferTrace) Reset()         { *m = TransferTrace{} }
+func (m *TransferTrace) String() string { return proto.CompactTextString(m) }
+func (*TransferTrace) ProtoMessage()    {}
+func (*TransferTrace) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{24}
+}
+func (m *TransferTrace) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferTrace) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferTrace.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferTrace) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferTrace.Merge(m, src)
+}
+func (m *TransferTrace) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferTrace) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferTrace.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferTrace proto.InternalMessageInfo
+
+func (m *TransferTrace) GetTraceID() []byte {
+	if m != nil {
+		return m.TraceID
+	}
+	return nil
+}
+
+func (m *TransferTrace) GetTraces() [][]byte {
+	if m != nil {
+		return m.Traces
+	}
+	return nil
+}
+
+func (m *TransferTrace) GetSearchData() [][]byte {
+	if m != nil {
+		return m.SearchData
+	}
+	return nil
+}
+
+// TransferFile is a chunk of a block file. Files are sent one after the other and eof is set on the last
+// chunk of each file.
+type TransferFile struct {
+	Kind TransferFileKind `protobuf:"varint,1,opt,name=kind,proto3,enum=tempopb.TransferFileKind" json:"kind,omitempty"`
+	// id of the block the file belongs to
+	BlockID string `protobuf:"bytes,2,opt,name=blockID,proto3" json:"blockID,omitempty"`
+	Name    string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Data    []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	Eof     bool   `protobuf:"varint,5,opt,name=eof,proto3" json:"eof,omitempty"`
+}
+
+func (m *TransferFile) Reset()         { *m = TransferFile{} }
+func (m *TransferFile) String() string { return proto.CompactTextString(m) }
+func (*TransferFile) ProtoMessage()    {}
+func (*TransferFile) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{25}
+}
+func (m *TransferFile) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferFile) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferFile.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferFile) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferFile.Merge(m, src)
+}
+func (m *TransferFile) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferFile) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferFile.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferFile proto.InternalMessageInfo
+
+func (m *TransferFile) GetKind() TransferFileKind {
+	if m != nil {
+		return m.Kind
+	}
+	return TransferFileKind_WAL_BLOCK
+}
+
+func (m *TransferFile) GetBlockID() string {
+	if m != nil {
+		return m.BlockID
+	}
+	return ""
+}
+
+func (m *TransferFile) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TransferFile) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *TransferFile) GetEof() bool {
+	if m != nil {
+		return m.Eof
+	}
+	return false
+}
+
+type TransferResponse struct {
+}
+
+func (m *TransferResponse) Reset()         { *m = TransferResponse{} }
+func (m *TransferResponse) String() string { return proto.CompactTextString(m) }
+func (*TransferResponse) ProtoMessage()    {}
+func (*TransferResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{26}
+}
+func (m *TransferResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferResponse.Merge(m, src)
+}
+func (m *TransferResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterEnum("tempopb.SearchSort", SearchSort_name, SearchSort_value)
+	proto.RegisterEnum("tempopb.PushErrorReason", PushErrorReason_name, PushErrorReason_value)
+	proto.RegisterEnum("tempopb.TransferFileKind", TransferFileKind_name, TransferFileKind_value)
+	proto.RegisterType((*TraceByIDRequest)(nil), "tempopb.TraceByIDRequest")
+	proto.RegisterType((*TraceByIDResponse)(nil), "tempopb.TraceByIDResponse")
+	proto.RegisterType((*TracesByIDRequest)(nil), "tempopb.TracesByIDRequest")
+	proto.RegisterType((*TracesByIDResponse)(nil), "tempopb.TracesByIDResponse")
+	proto.RegisterType((*TraceByIDResult)(nil), "tempopb.TraceByIDResult")
+	proto.RegisterType((*SearchRequest)(nil), "tempopb.SearchRequest")
+	proto.RegisterMapType((map[string]string)(nil), "tempopb.SearchRequest.TagsEntry")
+	proto.RegisterType((*SearchResponse)(nil), "tempopb.SearchResponse")
+	proto.RegisterType((*TraceSearchMetadata)(nil), "tempopb.TraceSearchMetadata")
+	proto.RegisterType((*SearchMetrics)(nil), "tempopb.SearchMetrics")
+	proto.RegisterType((*TailRequest)(nil), "tempopb.TailRequest")
+	proto.RegisterType((*TailResponse)(nil), "tempopb.TailResponse")
+	proto.RegisterType((*SearchTagsRequest)(nil), "tempopb.SearchTagsRequest")
+	proto.RegisterType((*SearchTagsResponse)(nil), "tempopb.SearchTagsResponse")
+	proto.RegisterType((*SearchTagValuesRequest)(nil), "tempopb.SearchTagValuesRequest")
+	proto.RegisterType((*SearchTagValuesResponse)(nil), "tempopb.SearchTagValuesResponse")
+	proto.RegisterMapType((map[string]uint32)(nil), "tempopb.SearchTagValuesResponse.EstimatedCountsEntry")
+	proto.RegisterType((*Trace)(nil), "tempopb.Trace")
+	proto.RegisterType((*PushRequest)(nil), "tempopb.PushRequest")
+	proto.RegisterType((*PushResponse)(nil), "tempopb.PushResponse")
+	proto.RegisterType((*PushPartialSuccess)(nil), "tempopb.PushPartialSuccess")
+	proto.RegisterType((*RejectedSpans)(nil), "tempopb.RejectedSpans")
+	proto.RegisterType((*PushTraceError)(nil), "tempopb.PushTraceError")
+	proto.RegisterType((*PushBytesRequest)(nil), "tempopb.PushBytesRequest")
+	proto.RegisterType((*TraceBytes)(nil), "tempopb.TraceBytes")
+	proto.RegisterType((*TransferRequest)(nil), "tempopb.TransferRequest")
+	proto.RegisterType((*TransferTrace)(nil), "tempopb.TransferTrace")
+	proto.RegisterType((*TransferFile)(nil), "tempopb.TransferFile")
+	proto.RegisterType((*TransferResponse)(nil), "tempopb.TransferResponse")
+}
+
+func init() { proto.RegisterFile("pkg/tempopb/tempo.proto", fileDescriptor_f22805646f4f62b6) }
+
+var fileDescriptor_f22805646f4f62b6 = []byte{
+	// 1841 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0xcd, 0x6f, 0x1b, 0xc7,
+	0x15, 0xd7, 0x72, 0x29, 0x8a, 0x7a, 0x14, 0xa9, 0xf5, 0x58, 0x96, 0x68, 0xda, 0x90, 0x89, 0x85,
+	0x91, 0xa8, 0x46, 0x2c, 0x29, 0x4c, 0x8d, 0xb8, 0x29, 0x82, 0x80, 0x5f, 0x4a, 0x58, 0x4b, 0xa2,
+	0x33, 0xa4, 0xed, 0xdc, 0x88, 0xe5, 0xee, 0x88, 0xda, 0x88, 0xdc, 0x65, 0x76, 0x97, 0x82, 0x75,
+	0xeb, 0xa9, 0xe8, 0xb1, 0x05, 0x72, 0xed, 0xb5, 0xc7, 0x02, 0x3d, 0xf5, 0x5f, 0xc8, 0xa9, 0xc8,
+	0xb1, 0xe8, 0xc1, 0x28, 0x6c, 0xf4, 0xd4, 0x6b, 0xff, 0x80, 0x62, 0x3e, 0xb9, 0xbb, 0xa4, 0xe4,
+	0x16, 0xcd, 0x89, 0x33, 0xbf, 0xf9, 0xcd, 0x9b, 0xf7, 0x35, 0x6f, 0xde, 0x12, 0x76, 0xa6, 0x17,
+	0xa3, 0x83, 0x88, 0x4c, 0xa6, 0xfe, 0x74, 0xc8, 0x7f, 0xf7, 0xa7, 0x81, 0x1f, 0xf9, 0x68, 0x4d,
+	0x80, 0x95, 0xad, 0x28, 0xb0, 0x6c, 0x72, 0x70, 0xf9, 0xf1, 0x01, 0x1b, 0xf0, 0xe5, 0xca, 0xe3,
+	0x91, 0x1b, 0x9d, 0xcf, 0x86, 0xfb, 0xb6, 0x3f, 0x39, 0x18, 0xf9, 0x23, 0xff, 0x80, 0xc1, 0xc3,
+	0xd9, 0x19, 0x9b, 0xb1, 0x09, 0x1b, 0x71, 0xba, 0xf9, 0x27, 0x0d, 0x8c, 0x3e, 0xdd, 0xde, 0xb8,
+	0xea, 0xb4, 0x30, 0xf9, 0x6e, 0x46, 0xc2, 0x08, 0x95, 0x61, 0x8d, 0x89, 0xec, 0xb4, 0xca, 0x5a,
+	0x55, 0xdb, 0xdb, 0xc0, 0x72, 0x8a, 0x76, 0x01, 0x86, 0x63, 0xdf, 0xbe, 0xe8, 0x45, 0x56, 0x10,
+	0x95, 0x33, 0x55, 0x6d, 0x6f, 0x1d, 0xc7, 0x10, 0x54, 0x81, 0x3c, 0x9b, 0xb5, 0x3d, 0xa7, 0xac,
+	0xb3, 0x55, 0x35, 0x47, 0xf7, 0x61, 0xfd, 0xbb, 0x19, 0x09, 0xae, 0x4e, 0x7c, 0x87, 0x94, 0x57,
+	0xd9, 0xe2, 0x1c, 0x40, 0x5b, 0xb0, 0x1a, 0x32, 0xa1, 0xb9, 0xaa, 0xb6, 0x57, 0xc4, 0x7c, 0x82,
+	0x0c, 0xd0, 0x89, 0xe7, 0x94, 0xd7, 0x18, 0x46, 0x87, 0xe6, 0x05, 0xdc, 0x8a, 0xe9, 0x1b, 0x4e,
+	0x7d, 0x2f, 0x24, 0xe8, 0x21, 0xac, 0x32, 0x0d, 0x99, 0xba, 0x85, 0x5a, 0x69, 0x5f, 0xf8, 0x68,
+	0x9f, 0x51, 0x31, 0x5f, 0x44, 0x87, 0xb0, 0x36, 0x21, 0x51, 0xe0, 0xda, 0x21, 0xd3, 0xbc, 0x50,
+	0xdb, 0x56, 0xbc, 0x1e, 0xb1, 0x02, 0xfb, 0xfc, 0x84, 0xaf, 0x62, 0x49, 0x33, 0x5f, 0x89, 0xc3,
+	0xc2, 0xb8, 0x77, 0x2a, 0x90, 0x17, 0xee, 0x08, 0xcb, 0x5a, 0x55, 0xdf, 0xdb, 0xc0, 0x6a, 0x3e,
+	0xb7, 0x22, 0xb3, 0xc4, 0x0a, 0x7d, 0x6e, 0xc5, 0x10, 0x50, 0x5c, 0xb0, 0x30, 0xe3, 0x10, 0x72,
+	0x4c, 0x12, 0x97, 0x5b, 0xa8, 0x95, 0x93, 0x76, 0x08, 0xee, 0x6c, 0x1c, 0x61, 0xc1, 0xa3, 0xba,
+	0x78, 0x7e, 0x74, 0xe4, 0xcf, 0x3c, 0xa7, 0x9c, 0xa9, 0xea, 0xd4, 0xdf, 0x72, 0x6e, 0x7e, 0x0d,
+	0x9b, 0xa9, 0x6d, 0xe9, 0xc0, 0xae, 0xcf, 0x03, 0xab, 0x3c, 0x98, 0xb9, 0xc1, 0x83, 0xe6, 0x3f,
+	0x33, 0x50, 0xe4, 0xae, 0x92, 0xce, 0xf8, 0x0c, 0xb2, 0x7d, 0x6b, 0x24, 0x15, 0xae, 0xa6, 0x1c,
+	0x2a, 0x58, 0xfb, 0x94, 0xd2, 0xf6, 0xa2, 0xe0, 0xaa, 0x91, 0xfd, 0xe1, 0xcd, 0x83, 0x15, 0xcc,
+	0xf6, 0xa0, 0x87, 0x50, 0x3c, 0x71, 0xbd, 0xd6, 0x2c, 0xb0, 0x22, 0xd7, 0xf7, 0x4e, 0x42, 0xe1,
+	0xb4, 0x24, 0xc8, 0x58, 0xd6, 0xeb, 0x18, 0x4b, 0x17, 0xac, 0x38, 0x48, 0x1d, 0x7f, 0xec, 0x4e,
+	0xdc, 0xa8, 0x9c, 0xe5, 0x8e, 0x67, 0x93, 0x79, 0x38, 0x56, 0x97, 0x84, 0x23, 0xa7, 0xc2, 0x81,
+	0x3e, 0x84, 0x6c, 0xe8, 0x07, 0x11, 0xcb, 0xb3, 0x52, 0xed, 0x76, 0xca, 0x8a, 0x9e, 0x1f, 0x44,
+	0x98, 0x11, 0xd0, 0x47, 0x70, 0xcb, 0xf6, 0xbd, 0xc8, 0xf5, 0x66, 0xec, 0xe0, 0xbe, 0x7f, 0x41,
+	0xbc, 0x72, 0x9e, 0xb9, 0x72, 0x71, 0xa1, 0xf2, 0x29, 0xac, 0x2b, 0xcb, 0xe9, 0xa9, 0x17, 0xe4,
+	0x4a, 0xf8, 0x9d, 0x0e, 0xa9, 0x76, 0x97, 0xd6, 0x78, 0x46, 0xc4, 0x3d, 0xe2, 0x93, 0xcf, 0x32,
+	0x4f, 0x35, 0xf3, 0x8f, 0x1a, 0x94, 0xa4, 0x07, 0x45, 0x6e, 0xfc, 0x3c, 0x95, 0x1b, 0xf7, 0x93,
+	0x11, 0x52, 0x09, 0x6c, 0x39, 0x56, 0x64, 0xa9, 0xfc, 0xf8, 0x9f, 0x53, 0x7e, 0xb9, 0x85, 0xfa,
+	0x35, 0x16, 0x9a, 0xff, 0xd2, 0xe0, 0xf6, 0x92, 0xf3, 0x6f, 0x48, 0xb4, 0x3d, 0xd8, 0x0c, 0x7c,
+	0x3f, 0xea, 0x91, 0xe0, 0xd2, 0xb5, 0xc9, 0xa9, 0x35, 0x91, 0xe6, 0xa7, 0x61, 0x1a, 0x78, 0x0a,
+	0x31, 0xf1, 0x8c, 0xc7, 0xb5, 0x48, 0x82, 0x54, 0x5f, 0x16, 0xd5, 0xbe, 0x3b, 0x21, 0x2f, 0x3c,
+	0xf7, 0xf5, 0xa9, 0xe5, 0xf9, 0x2c, 0x09, 0xb2, 0x78, 0x71, 0x81, 0xd6, 0x2f, 0x67, 0x9e, 0x49,
+	0x3c, 0x2b, 0x62, 0x08, 0xad, 0x51, 0xe1, 0xd4, 0xf2, 0x9a, 0xfe, 0xcc, 0x93, 0x95, 0x68, 0x0e,
+	0x98, 0x7f, 0xd6, 0xa0, 0x98, 0x70, 0x1b, 0xb5, 0xc6, 0xf5, 0xc2, 0x29, 0xb1, 0x23, 0xe2, 0xf4,
+	0x65, 0x78, 0xe8, 0xae, 0x34, 0x8c, 0x3e, 0x80, 0x92, 0x82, 0x1a, 0x57, 0x11, 0xe1, 0x01, 0xc9,
+	0xe2, 0x14, 0x9a, 0x90, 0xd8, 0xa0, 0xa5, 0x53, 0x26, 0x7c, 0x1a, 0xa6, 0xfe, 0x09, 0x2f, 0xdc,
+	0xe9, 0x54, 0xf1, 0x78, 0xea, 0x27, 0x41, 0xd3, 0x82, 0x42, 0xdf, 0x72, 0xc7, 0xf2, 0xbe, 0xee,
+	0x43, 0x2e, 0x64, 0x16, 0x94, 0xb5, 0xa5, 0xf9, 0x20, 0x78, 0x58, 0xb0, 0x90, 0x09, 0x1b, 0xae,
+	0x67, 0x8f, 0x67, 0x0e, 0xe9, 0x4d, 0x2d, 0x8f, 0x2b, 0x9d, 0xc7, 0x09, 0xcc, 0xfc, 0xad, 0x06,
+	0x1b, 0xfc, 0x0c, 0x91, 0xab, 0x4f, 0x21, 0x3f, 0x11, 0x99, 0x20, 0x8e, 0xb9, 0x39, 0x5b, 0x15,
+	0xfb, 0xbf, 0x2b, 0x43, 0x34, 0xbb, 0x9c, 0xc0, 0xa7, 0x46, 0x0a, 0xdf, 0xc8, 0xa9, 0x79, 0x1b,
+	0x6e, 0x71, 0xd9, 0xf4, 0xde, 0x09, 0x5b, 0xcc, 0x6f, 0x01, 0xc5, 0x41, 0xa1, 0x24, 0x2d, 0xe3,
+	0xd6, 0x88, 0xe6, 0x10, 0xbf, 0x52, 0xeb, 0x58, 0xcd, 0xd1, 0x53, 0xd8, 0x39, 0x77, 0x47, 0xe7,
+	0x4d, 0x2b, 0x70, 0x5c, 0xcf, 0x1a, 0xbb, 0xd1, 0x55, 0x5f, 0x52, 0x79, 0x95, 0xbd, 0x6e, 0xd9,
+	0x1c, 0xc2, 0xb6, 0x3a, 0xeb, 0x25, 0xbd, 0xcf, 0x61, 0xfc, 0x51, 0xe5, 0x2c, 0x75, 0x25, 0xf8,
+	0x94, 0xc6, 0xe4, 0xcc, 0x1d, 0x47, 0x24, 0xb8, 0xe6, 0x8e, 0xaa, 0x98, 0x70, 0x96, 0xf9, 0x9b,
+	0x0c, 0xec, 0x2c, 0x1c, 0x22, 0xac, 0xba, 0x0f, 0xeb, 0x91, 0x04, 0x85, 0x59, 0x73, 0x80, 0x26,
+	0x57, 0x4a, 0x71, 0x11, 0xd0, 0x34, 0x8c, 0x06, 0xb0, 0x49, 0xc2, 0xc8, 0x9d, 0x58, 0x11, 0x71,
+	0x58, 0xf2, 0xd3, 0x34, 0xa4, 0x75, 0xe7, 0x49, 0x4a, 0xb9, 0x05, 0x15, 0xf6, 0xdb, 0xc9, 0x7d,
+	0xac, 0xfa, 0xe1, 0xb4, 0xb4, 0x4a, 0x03, 0xb6, 0x96, 0x11, 0xdf, 0x57, 0x26, 0x8b, 0xf1, 0x32,
+	0xd9, 0x80, 0x55, 0x96, 0x17, 0xe8, 0x17, 0xb0, 0x36, 0xb4, 0x22, 0xfb, 0x5c, 0x55, 0xc7, 0x07,
+	0x4a, 0x4b, 0xde, 0x1b, 0x5d, 0x7e, 0xbc, 0x8f, 0x49, 0xe8, 0xcf, 0x02, 0x9b, 0xe7, 0x2c, 0x96,
+	0x7c, 0xb3, 0x05, 0x85, 0xe7, 0xb3, 0x50, 0xbd, 0x67, 0x4f, 0x60, 0x95, 0xad, 0x88, 0xbc, 0x7d,
+	0xaf, 0x1c, 0xce, 0x36, 0xbf, 0xd7, 0x60, 0x83, 0x8b, 0x11, 0x71, 0x68, 0x42, 0x69, 0x6a, 0x05,
+	0x91, 0x6b, 0x8d, 0x7b, 0x33, 0xdb, 0x26, 0x61, 0x28, 0x04, 0xde, 0x53, 0x02, 0x29, 0xfd, 0x79,
+	0x82, 0x82, 0x53, 0x5b, 0xd0, 0x17, 0x50, 0x60, 0xc7, 0xb6, 0x83, 0xc0, 0x0f, 0x78, 0xea, 0x15,
+	0x6a, 0x3b, 0x09, 0x09, 0x7d, 0xb5, 0x2e, 0x9e, 0xd6, 0xf8, 0x0e, 0xf3, 0xaf, 0x1a, 0xa0, 0xc5,
+	0x73, 0x58, 0x65, 0x25, 0xdf, 0xb2, 0x5a, 0xc2, 0x6f, 0x35, 0xd5, 0x4d, 0xc7, 0x49, 0x90, 0x5e,
+	0x7d, 0x42, 0xc5, 0x9c, 0x90, 0x30, 0xb4, 0x46, 0xb2, 0x4c, 0x27, 0x30, 0x2a, 0xc9, 0xb2, 0x6d,
+	0x32, 0x55, 0x92, 0x74, 0x2e, 0x29, 0x01, 0xa2, 0xaf, 0xc0, 0x90, 0xa2, 0x1b, 0x57, 0x98, 0x58,
+	0xa1, 0xef, 0x95, 0xb3, 0x55, 0x3d, 0x91, 0xea, 0x38, 0x7e, 0xb6, 0xb0, 0x65, 0x61, 0x97, 0xf9,
+	0x39, 0x14, 0x13, 0x44, 0xb4, 0x0d, 0xb9, 0x80, 0x0b, 0xe4, 0x19, 0x23, 0x66, 0xec, 0xe5, 0x57,
+	0x05, 0x4b, 0xc7, 0x7c, 0x62, 0x7e, 0x03, 0xa5, 0xa4, 0xd3, 0x28, 0xcf, 0xf5, 0x1c, 0xf2, 0x5a,
+	0x94, 0x6d, 0x3e, 0xa1, 0x8d, 0x98, 0x90, 0x9a, 0x61, 0x1d, 0x41, 0x39, 0xe1, 0x73, 0xb6, 0x93,
+	0x2b, 0x24, 0xcf, 0x33, 0xff, 0xad, 0x81, 0x41, 0xd7, 0x58, 0x11, 0x97, 0xc9, 0xf4, 0x09, 0xe4,
+	0x03, 0x3e, 0x14, 0x9d, 0x62, 0x63, 0x87, 0xda, 0xf5, 0xf7, 0x37, 0x0f, 0x8a, 0xcf, 0x03, 0x62,
+	0x8d, 0xc7, 0xbe, 0xcd, 0x9f, 0x02, 0x0d, 0x2b, 0x22, 0x7a, 0xac, 0x1e, 0xfa, 0x0c, 0xdb, 0x72,
+	0x67, 0xe9, 0x16, 0xf5, 0xc2, 0x7f, 0x08, 0xba, 0xeb, 0xf0, 0xcb, 0x79, 0x2d, 0x97, 0x32, 0xd0,
+	0x13, 0x00, 0x5e, 0xd3, 0x5b, 0xb4, 0x2c, 0x67, 0x6f, 0xe2, 0xc7, 0x88, 0xb4, 0xa0, 0xb0, 0x87,
+	0x99, 0x39, 0x73, 0xb5, 0xaa, 0xef, 0xe5, 0xf1, 0x1c, 0x30, 0x1f, 0x02, 0x88, 0x1e, 0x93, 0xbe,
+	0x5d, 0xdb, 0x89, 0x1e, 0x65, 0x43, 0xea, 0x48, 0xd3, 0x90, 0xb6, 0xa2, 0x5e, 0x78, 0x46, 0x02,
+	0xe9, 0x9b, 0x0f, 0xa0, 0x74, 0x16, 0xf8, 0x93, 0x8e, 0x37, 0x22, 0x61, 0x44, 0x02, 0xd5, 0x28,
+	0xa4, 0x50, 0x56, 0xa6, 0x89, 0x67, 0x79, 0x51, 0xa7, 0x25, 0x32, 0x50, 0xcd, 0xd1, 0x47, 0xf2,
+	0xb5, 0xd0, 0x53, 0x75, 0x53, 0x1e, 0x96, 0x78, 0x35, 0x7e, 0x06, 0xd9, 0x33, 0x77, 0x4c, 0xd8,
+	0x33, 0x59, 0xa8, 0xdd, 0x59, 0x20, 0x1f, 0xb9, 0x63, 0x82, 0x19, 0x85, 0xb6, 0x09, 0x91, 0x40,
+	0x3b, 0x2d, 0xf1, 0xad, 0x12, 0x43, 0x4c, 0x0b, 0x8a, 0x89, 0x23, 0x6e, 0xf8, 0x62, 0xda, 0x4e,
+	0x86, 0x53, 0xc5, 0x6d, 0x37, 0x11, 0x0e, 0x16, 0xbe, 0xb8, 0xdf, 0xcd, 0xdf, 0xd3, 0x47, 0x35,
+	0xa6, 0x19, 0x7a, 0x0c, 0xd9, 0x0b, 0xd7, 0x73, 0x98, 0xfc, 0x52, 0xed, 0xee, 0x52, 0xf5, 0x9f,
+	0xb9, 0x9e, 0x83, 0x19, 0x8d, 0x6a, 0xc4, 0xbe, 0xbc, 0x94, 0xdb, 0xe4, 0x14, 0x21, 0xc8, 0x7a,
+	0xf3, 0x76, 0x8a, 0x8d, 0x29, 0xe6, 0xf0, 0xb4, 0xa0, 0xca, 0xb3, 0x31, 0x6b, 0x93, 0xfd, 0x33,
+	0x66, 0x7d, 0x1e, 0xd3, 0xa1, 0x89, 0xc0, 0x90, 0xa7, 0xc9, 0x42, 0xf7, 0xe8, 0x7b, 0x0d, 0x60,
+	0xde, 0x26, 0xa3, 0x4d, 0x28, 0x1c, 0x75, 0x70, 0xaf, 0x3f, 0x38, 0xea, 0xbe, 0x38, 0x6d, 0x19,
+	0x2b, 0xe8, 0x36, 0x6c, 0xf6, 0xfa, 0x75, 0xdc, 0x1f, 0xf4, 0x3b, 0x27, 0xed, 0x41, 0xab, 0xdd,
+	0x6b, 0x1a, 0x1a, 0x42, 0x50, 0x8a, 0x81, 0xf5, 0x5e, 0xd3, 0xc8, 0xa0, 0x5b, 0x50, 0x6c, 0xbd,
+	0xc0, 0xf5, 0x7e, 0xa7, 0x7b, 0xca, 0x69, 0x3a, 0x32, 0x60, 0x43, 0x41, 0x94, 0x94, 0x65, 0xd2,
+	0x9e, 0xd7, 0x4f, 0x07, 0xcd, 0xee, 0x8b, 0xd3, 0x3e, 0xa7, 0xad, 0x32, 0x69, 0x73, 0x90, 0x12,
+	0x73, 0x8f, 0x06, 0xb0, 0x99, 0xba, 0xaa, 0x68, 0x03, 0xf2, 0xa7, 0xdd, 0x41, 0x1b, 0xe3, 0x2e,
+	0xe6, 0x7a, 0x9d, 0xd4, 0xbf, 0x19, 0x1c, 0x77, 0x5e, 0xb6, 0x07, 0x7d, 0x5c, 0x6f, 0xb6, 0x7b,
+	0x86, 0x46, 0x41, 0x36, 0x1e, 0xf4, 0xbb, 0xdd, 0xc1, 0x71, 0x1d, 0x7f, 0xd9, 0x36, 0x32, 0x68,
+	0x0b, 0x8c, 0xce, 0xe9, 0xcb, 0xfa, 0x71, 0xa7, 0xc5, 0x89, 0x83, 0x4e, 0xcb, 0xd0, 0x1f, 0xd5,
+	0xc0, 0x48, 0x7b, 0x1e, 0x15, 0x61, 0xfd, 0x55, 0xfd, 0x78, 0xd0, 0x38, 0xee, 0x36, 0x9f, 0x19,
+	0x2b, 0xd4, 0x17, 0xc7, 0xdd, 0xa6, 0x02, 0xb4, 0xda, 0xaf, 0x35, 0xc8, 0x51, 0xad, 0x48, 0x80,
+	0x9e, 0x40, 0x96, 0x8e, 0xd0, 0x56, 0xa2, 0xb2, 0x88, 0xcb, 0x51, 0xb9, 0x93, 0x42, 0xb9, 0xaf,
+	0xcd, 0x15, 0xf4, 0x05, 0xac, 0xab, 0x2a, 0x83, 0xee, 0x26, 0x58, 0xf1, 0xca, 0x73, 0xad, 0x80,
+	0xda, 0x5f, 0x74, 0x58, 0xfb, 0x7a, 0x46, 0x02, 0x97, 0x04, 0xe8, 0x2b, 0x28, 0x1e, 0xb9, 0x9e,
+	0xa3, 0x3e, 0x12, 0xd1, 0xdd, 0x65, 0xdf, 0x9b, 0x5c, 0x60, 0x65, 0xd9, 0x92, 0x52, 0xeb, 0x19,
+	0x94, 0x94, 0x24, 0xf6, 0x49, 0x8b, 0x52, 0xfc, 0xf8, 0x07, 0x74, 0xe5, 0xde, 0xd2, 0x35, 0x25,
+	0xec, 0x97, 0x90, 0xe3, 0x09, 0x85, 0xae, 0x69, 0x84, 0x2a, 0x3b, 0x0b, 0xb8, 0xda, 0xfc, 0x25,
+	0xc0, 0xbc, 0xd7, 0x8b, 0x69, 0xb1, 0xd0, 0x15, 0x56, 0xee, 0x2d, 0x5d, 0x53, 0x82, 0x5e, 0xc2,
+	0x66, 0xaa, 0xc1, 0x41, 0x0f, 0xae, 0x6f, 0x7d, 0xb8, 0xc8, 0xea, 0xfb, 0x7a, 0x23, 0x73, 0x05,
+	0x7d, 0x4a, 0x3f, 0x98, 0xdd, 0x71, 0x2c, 0xf0, 0xb1, 0xf6, 0xbc, 0x72, 0x27, 0x85, 0xca, 0x6d,
+	0x87, 0x5a, 0xed, 0x0f, 0x1a, 0x18, 0xbd, 0x28, 0x20, 0xd6, 0xc4, 0xf5, 0x46, 0x32, 0x84, 0x9f,
+	0xff, 0x1f, 0xbe, 0x3a, 0xd4, 0xd0, 0xaf, 0x7e, 0xaa, 0x0c, 0x38, 0xd4, 0x6a, 0xaf, 0xc0, 0x90,
+	0x65, 0x5b, 0x5e, 0x0c, 0xd4, 0x84, 0xbc, 0x1a, 0x97, 0x17, 0x2a, 0x96, 0x94, 0x7c, 0x77, 0xc9,
+	0x8a, 0x14, 0xbc, 0xa7, 0x35, 0xca, 0x3f, 0xbc, 0xdd, 0xd5, 0x7e, 0x7c, 0xbb, 0xab, 0xfd, 0xe3,
+	0xed, 0xae, 0xf6, 0xbb, 0x77, 0xbb, 0x2b, 0x3f, 0xbe, 0xdb, 0x5d, 0xf9, 0xdb, 0xbb, 0xdd, 0x95,
+	0x61, 0x8e, 0xfd, 0x87, 0xf5, 0xc9, 0x7f, 0x06, 0x00, 0x46, 0x80, 0xe3, 0x0e, 0x2c, 0x13, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -990,9 +2026,11 @@ var _Pusher_serviceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type QuerierClient interface {
 	FindTraceByID(ctx context.Context, in *TraceByIDRequest, opts ...grpc.CallOption) (*TraceByIDResponse, error)
+	FindTracesByID(ctx context.Context, in *TracesByIDRequest, opts ...grpc.CallOption) (*TracesByIDResponse, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	SearchTags(ctx context.Context, in *SearchTagsRequest, opts ...grpc.CallOption) (*SearchTagsResponse, error)
 	SearchTagValues(ctx context.Context, in *SearchTagValuesRequest, opts ...grpc.CallOption) (*SearchTagValuesResponse, error)
+	Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (Querier_TailClient, error)
 }
 
 type querierClient struct {
@@ -1012,6 +2050,15 @@ func (c *querierClient) FindTraceByID(ctx context.Context, in *TraceByIDRequest,
 	return out, nil
 }
 
+func (c *querierClient) FindTracesByID(ctx context.Context, in *TracesByIDRequest, opts ...grpc.CallOption) (*TracesByIDResponse, error) {
+	out := new(TracesByIDResponse)
+	err := c.cc.Invoke(ctx, "/tempopb.Querier/FindTracesByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *querierClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
 	out := new(SearchResponse)
 	err := c.cc.Invoke(ctx, "/tempopb.Querier/Search", in, out, opts...)
@@ -1039,12 +2086,46 @@ func (c *querierClient) SearchTagValues(ctx context.Context, in *SearchTagValues
 	return out, nil
 }
 
+func (c *querierClient) Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (Querier_TailClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Querier_serviceDesc.Streams[0], "/tempopb.Querier/Tail", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &querierTailClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Querier_TailClient interface {
+	Recv() (*TailResponse, error)
+	grpc.ClientStream
+}
+
+type querierTailClient struct {
+	grpc.ClientStream
+}
+
+func (x *querierTailClient) Recv() (*TailResponse, error) {
+	m := new(TailResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // QuerierServer is the server API for Querier service.
 type QuerierServer interface {
 	FindTraceByID(context.Context, *TraceByIDRequest) (*TraceByIDResponse, error)
+	FindTracesByID(context.Context, *TracesByIDRequest) (*TracesByIDResponse, error)
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	SearchTags(context.Context, *SearchTagsRequest) (*SearchTagsResponse, error)
 	SearchTagValues(context.Context, *SearchTagValuesRequest) (*SearchTagValuesResponse, error)
+	Tail(*TailRequest, Querier_TailServer) error
 }
 
 // UnimplementedQuerierServer can be embedded to have forward compatible implementations.
@@ -1054,6 +2135,9 @@ type UnimplementedQuerierServer struct {
 func (*UnimplementedQuerierServer) FindTraceByID(ctx context.Context, req *TraceByIDRequest) (*TraceByIDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindTraceByID not implemented")
 }
+func (*UnimplementedQuerierServer) FindTracesByID(ctx context.Context, req *TracesByIDRequest) (*TracesByIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindTracesByID not implemented")
+}
 func (*UnimplementedQuerierServer) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
 }
@@ -1063,6 +2147,9 @@ func (*UnimplementedQuerierServer) SearchTags(ctx context.Context, req *SearchTa
 func (*UnimplementedQuerierServer) SearchTagValues(ctx context.Context, req *SearchTagValuesRequest) (*SearchTagValuesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchTagValues not implemented")
 }
+func (*UnimplementedQuerierServer) Tail(req *TailRequest, srv Querier_TailServer) error {
+	return status.Errorf(codes.Unimplemented, "method Tail not implemented")
+}
 
 func RegisterQuerierServer(s *grpc.Server, srv QuerierServer) {
 	s.RegisterService(&_Querier_serviceDesc, srv)
@@ -1086,6 +2173,24 @@ func _Querier_FindTraceByID_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Querier_FindTracesByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TracesByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuerierServer).FindTracesByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tempopb.Querier/FindTracesByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuerierServer).FindTracesByID(ctx, req.(*TracesByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Querier_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchRequest)
 	if err := dec(in); err != nil {
@@ -1140,6 +2245,27 @@ func _Querier_SearchTagValues_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Querier_Tail_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuerierServer).Tail(m, &querierTailServer{stream})
+}
+
+type Querier_TailServer interface {
+	Send(*TailResponse) error
+	grpc.ServerStream
+}
+
+type querierTailServer struct {
+	grpc.ServerStream
+}
+
+func (x *querierTailServer) Send(m *TailResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Querier_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "tempopb.Querier",
 	HandlerType: (*QuerierServer)(nil),
@@ -1148,6 +2274,10 @@ var _Querier_serviceDesc = grpc.ServiceDesc{
 			MethodName: "FindTraceByID",
 			Handler:    _Querier_FindTraceByID_Handler,
 		},
+		{
+			MethodName: "FindTracesByID",
+			Handler:    _Querier_FindTracesByID_Handler,
+		},
 		{
 			MethodName: "Search",
 			Handler:    _Querier_Search_Handler,
@@ -1161,36 +2291,324 @@ var _Querier_serviceDesc = grpc.ServiceDesc{
 			Handler:    _Querier_SearchTagValues_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Tail",
+			Handler:       _Querier_Tail_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "pkg/tempopb/tempo.proto",
 }
 
-func (m *TraceByIDRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+// StreamingQuerierClient is the client API for StreamingQuerier service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type StreamingQuerierClient interface {
+	// the search is split in jobs by time range. a trace can be in the responses of several jobs
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (StreamingQuerier_SearchClient, error)
+	// every response is the part of the trace found by one job. combine them for the whole trace
+	FindTraceByID(ctx context.Context, in *TraceByIDRequest, opts ...grpc.CallOption) (StreamingQuerier_FindTraceByIDClient, error)
+}
+
+type streamingQuerierClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStreamingQuerierClient(cc *grpc.ClientConn) StreamingQuerierClient {
+	return &streamingQuerierClient{cc}
+}
+
+func (c *streamingQuerierClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (StreamingQuerier_SearchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_StreamingQuerier_serviceDesc.Streams[0], "/tempopb.StreamingQuerier/Search", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	x := &streamingQuerierSearchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (m *TraceByIDRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+type StreamingQuerier_SearchClient interface {
+	Recv() (*SearchResponse, error)
+	grpc.ClientStream
 }
 
-func (m *TraceByIDRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if len(m.QueryMode) > 0 {
-		i -= len(m.QueryMode)
-		copy(dAtA[i:], m.QueryMode)
-		i = encodeVarintTempo(dAtA, i, uint64(len(m.QueryMode)))
-		i--
-		dAtA[i] = 0x2a
+type streamingQuerierSearchClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamingQuerierSearchClient) Recv() (*SearchResponse, error) {
+	m := new(SearchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *streamingQuerierClient) FindTraceByID(ctx context.Context, in *TraceByIDRequest, opts ...grpc.CallOption) (StreamingQuerier_FindTraceByIDClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_StreamingQuerier_serviceDesc.Streams[1], "/tempopb.StreamingQuerier/FindTraceByID", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamingQuerierFindTraceByIDClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StreamingQuerier_FindTraceByIDClient interface {
+	Recv() (*TraceByIDResponse, error)
+	grpc.ClientStream
+}
+
+type streamingQuerierFindTraceByIDClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamingQuerierFindTraceByIDClient) Recv() (*TraceByIDResponse, error) {
+	m := new(TraceByIDResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StreamingQuerierServer is the server API for StreamingQuerier service.
+type StreamingQuerierServer interface {
+	// the search is split in jobs by time range. a trace can be in the responses of several jobs
+	Search(*SearchRequest, StreamingQuerier_SearchServer) error
+	// every response is the part of the trace found by one job. combine them for the whole trace
+	FindTraceByID(*TraceByIDRequest, StreamingQuerier_FindTraceByIDServer) error
+}
+
+// UnimplementedStreamingQuerierServer can be embedded to have forward compatible implementations.
+type UnimplementedStreamingQuerierServer struct {
+}
+
+func (*UnimplementedStreamingQuerierServer) Search(req *SearchRequest, srv StreamingQuerier_SearchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (*UnimplementedStreamingQuerierServer) FindTraceByID(req *TraceByIDRequest, srv StreamingQuerier_FindTraceByIDServer) error {
+	return status.Errorf(codes.Unimplemented, "method FindTraceByID not implemented")
+}
+
+func RegisterStreamingQuerierServer(s *grpc.Server, srv StreamingQuerierServer) {
+	s.RegisterService(&_StreamingQuerier_serviceDesc, srv)
+}
+
+func _StreamingQuerier_Search_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamingQuerierServer).Search(m, &streamingQuerierSearchServer{stream})
+}
+
+type StreamingQuerier_SearchServer interface {
+	Send(*SearchResponse) error
+	grpc.ServerStream
+}
+
+type streamingQuerierSearchServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamingQuerierSearchServer) Send(m *SearchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StreamingQuerier_FindTraceByID_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TraceByIDRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamingQuerierServer).FindTraceByID(m, &streamingQuerierFindTraceByIDServer{stream})
+}
+
+type StreamingQuerier_FindTraceByIDServer interface {
+	Send(*TraceByIDResponse) error
+	grpc.ServerStream
+}
+
+type streamingQuerierFindTraceByIDServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamingQuerierFindTraceByIDServer) Send(m *TraceByIDResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _StreamingQuerier_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tempopb.StreamingQuerier",
+	HandlerType: (*StreamingQuerierServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Search",
+			Handler:       _StreamingQuerier_Search_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "FindTraceByID",
+			Handler:       _StreamingQuerier_FindTraceByID_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/tempopb/tempo.proto",
+}
+
+// IngesterTransferClient is the client API for IngesterTransfer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type IngesterTransferClient interface {
+	Transfer(ctx context.Context, opts ...grpc.CallOption) (IngesterTransfer_TransferClient, error)
+}
+
+type ingesterTransferClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIngesterTransferClient(cc *grpc.ClientConn) IngesterTransferClient {
+	return &ingesterTransferClient{cc}
+}
+
+func (c *ingesterTransferClient) Transfer(ctx context.Context, opts ...grpc.CallOption) (IngesterTransfer_TransferClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IngesterTransfer_serviceDesc.Streams[0], "/tempopb.IngesterTransfer/Transfer", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterTransferTransferClient{stream}
+	return x, nil
+}
+
+type IngesterTransfer_TransferClient interface {
+	Send(*TransferRequest) error
+	CloseAndRecv() (*TransferResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterTransferTransferClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterTransferTransferClient) Send(m *TransferRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *ingesterTransferTransferClient) CloseAndRecv() (*TransferResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TransferResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IngesterTransferServer is the server API for IngesterTransfer service.
+type IngesterTransferServer interface {
+	Transfer(IngesterTransfer_TransferServer) error
+}
+
+// UnimplementedIngesterTransferServer can be embedded to have forward compatible implementations.
+type UnimplementedIngesterTransferServer struct {
+}
+
+func (*UnimplementedIngesterTransferServer) Transfer(srv IngesterTransfer_TransferServer) error {
+	return status.Errorf(codes.Unimplemented, "method Transfer not implemented")
+}
+
+func RegisterIngesterTransferServer(s *grpc.Server, srv IngesterTransferServer) {
+	s.RegisterService(&_IngesterTransfer_serviceDesc, srv)
+}
+
+func _IngesterTransfer_Transfer_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngesterTransferServer).Transfer(&ingesterTransferTransferServer{stream})
+}
+
+type IngesterTransfer_TransferServer interface {
+	SendAndClose(*TransferResponse) error
+	Recv() (*TransferRequest, error)
+	grpc.ServerStream
+}
+
+type ingesterTransferTransferServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterTransferTransferServer) SendAndClose(m *TransferResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingesterTransferTransferServer) Recv() (*TransferRequest, error) {
+	m := new(TransferRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _IngesterTransfer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tempopb.IngesterTransfer",
+	HandlerType: (*IngesterTransferServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Transfer",
+			Handler:       _IngesterTransfer_Transfer_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/tempopb/tempo.proto",
+}
+
+func (m *TraceByIDRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TraceByIDRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TraceByIDRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.End != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.End))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Start != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Start))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.QueryMode) > 0 {
+		i -= len(m.QueryMode)
+		copy(dAtA[i:], m.QueryMode)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.QueryMode)))
+		i--
+		dAtA[i] = 0x2a
 	}
 	if len(m.BlockEnd) > 0 {
 		i -= len(m.BlockEnd)
@@ -1236,6 +2654,18 @@ func (m *TraceByIDResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Metrics != nil {
+		{
+			size, err := m.Metrics.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
 	if m.Trace != nil {
 		{
 			size, err := m.Trace.MarshalToSizedBuffer(dAtA[:i])
@@ -1251,7 +2681,7 @@ func (m *TraceByIDResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchRequest) Marshal() (dAtA []byte, err error) {
+func (m *TracesByIDRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1261,46 +2691,31 @@ func (m *SearchRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *TracesByIDRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TracesByIDRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.Limit != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.Limit))
-		i--
-		dAtA[i] = 0x20
-	}
-	if m.MaxDurationMs != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.MaxDurationMs))
+	if m.End != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.End))
 		i--
 		dAtA[i] = 0x18
 	}
-	if m.MinDurationMs != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.MinDurationMs))
+	if m.Start != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Start))
 		i--
 		dAtA[i] = 0x10
 	}
-	if len(m.Tags) > 0 {
-		for k := range m.Tags {
-			v := m.Tags[k]
-			baseI := i
-			i -= len(v)
-			copy(dAtA[i:], v)
-			i = encodeVarintTempo(dAtA, i, uint64(len(v)))
-			i--
-			dAtA[i] = 0x12
-			i -= len(k)
-			copy(dAtA[i:], k)
-			i = encodeVarintTempo(dAtA, i, uint64(len(k)))
-			i--
-			dAtA[i] = 0xa
-			i = encodeVarintTempo(dAtA, i, uint64(baseI-i))
+	if len(m.TraceIDs) > 0 {
+		for iNdEx := len(m.TraceIDs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TraceIDs[iNdEx])
+			copy(dAtA[i:], m.TraceIDs[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.TraceIDs[iNdEx])))
 			i--
 			dAtA[i] = 0xa
 		}
@@ -1308,7 +2723,7 @@ func (m *SearchRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchResponse) Marshal() (dAtA []byte, err error) {
+func (m *TracesByIDResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1318,27 +2733,24 @@ func (m *SearchResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *TracesByIDResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TracesByIDResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.Metrics != nil {
-		{
-			size, err := m.Metrics.MarshalToSizedBuffer(dAtA[:i])
-			if err != nil {
-				return 0, err
-			}
-			i -= size
-			i = encodeVarintTempo(dAtA, i, uint64(size))
+	if len(m.NotFound) > 0 {
+		for iNdEx := len(m.NotFound) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.NotFound[iNdEx])
+			copy(dAtA[i:], m.NotFound[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.NotFound[iNdEx])))
+			i--
+			dAtA[i] = 0x12
 		}
-		i--
-		dAtA[i] = 0x12
 	}
 	if len(m.Traces) > 0 {
 		for iNdEx := len(m.Traces) - 1; iNdEx >= 0; iNdEx-- {
@@ -1357,7 +2769,7 @@ func (m *SearchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *TraceSearchMetadata) Marshal() (dAtA []byte, err error) {
+func (m *TraceByIDResult) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1367,37 +2779,25 @@ func (m *TraceSearchMetadata) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *TraceSearchMetadata) MarshalTo(dAtA []byte) (int, error) {
+func (m *TraceByIDResult) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *TraceSearchMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TraceByIDResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.DurationMs != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.DurationMs))
-		i--
-		dAtA[i] = 0x28
-	}
-	if m.StartTimeUnixNano != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.StartTimeUnixNano))
-		i--
-		dAtA[i] = 0x20
-	}
-	if len(m.RootTraceName) > 0 {
-		i -= len(m.RootTraceName)
-		copy(dAtA[i:], m.RootTraceName)
-		i = encodeVarintTempo(dAtA, i, uint64(len(m.RootTraceName)))
-		i--
-		dAtA[i] = 0x1a
-	}
-	if len(m.RootServiceName) > 0 {
-		i -= len(m.RootServiceName)
-		copy(dAtA[i:], m.RootServiceName)
-		i = encodeVarintTempo(dAtA, i, uint64(len(m.RootServiceName)))
+	if m.Trace != nil {
+		{
+			size, err := m.Trace.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
 		i--
 		dAtA[i] = 0x12
 	}
@@ -1411,7 +2811,7 @@ func (m *TraceSearchMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchMetrics) Marshal() (dAtA []byte, err error) {
+func (m *SearchRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1421,40 +2821,76 @@ func (m *SearchMetrics) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchMetrics) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchMetrics) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.SkippedBlocks != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.SkippedBlocks))
+	if len(m.ContinuationToken) > 0 {
+		i -= len(m.ContinuationToken)
+		copy(dAtA[i:], m.ContinuationToken)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.ContinuationToken)))
+		i--
+		dAtA[i] = 0x42
+	}
+	if m.Sort != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Sort))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.End != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.End))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Start != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Start))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Limit != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Limit))
 		i--
 		dAtA[i] = 0x20
 	}
-	if m.InspectedBlocks != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.InspectedBlocks))
+	if m.MaxDurationMs != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.MaxDurationMs))
 		i--
 		dAtA[i] = 0x18
 	}
-	if m.InspectedBytes != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.InspectedBytes))
+	if m.MinDurationMs != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.MinDurationMs))
 		i--
 		dAtA[i] = 0x10
 	}
-	if m.InspectedTraces != 0 {
-		i = encodeVarintTempo(dAtA, i, uint64(m.InspectedTraces))
-		i--
-		dAtA[i] = 0x8
+	if len(m.Tags) > 0 {
+		for k := range m.Tags {
+			v := m.Tags[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintTempo(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintTempo(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintTempo(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0xa
+		}
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchTagsRequest) Marshal() (dAtA []byte, err error) {
+func (m *SearchResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1464,20 +2900,53 @@ func (m *SearchTagsRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchTagsRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchTagsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
+	if len(m.ContinuationToken) > 0 {
+		i -= len(m.ContinuationToken)
+		copy(dAtA[i:], m.ContinuationToken)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.ContinuationToken)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Metrics != nil {
+		{
+			size, err := m.Metrics.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Traces) > 0 {
+		for iNdEx := len(m.Traces) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Traces[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchTagsResponse) Marshal() (dAtA []byte, err error) {
+func (m *TraceSearchMetadata) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1487,29 +2956,56 @@ func (m *SearchTagsResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchTagsResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *TraceSearchMetadata) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchTagsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TraceSearchMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.TagNames) > 0 {
-		for iNdEx := len(m.TagNames) - 1; iNdEx >= 0; iNdEx-- {
-			i -= len(m.TagNames[iNdEx])
-			copy(dAtA[i:], m.TagNames[iNdEx])
-			i = encodeVarintTempo(dAtA, i, uint64(len(m.TagNames[iNdEx])))
-			i--
-			dAtA[i] = 0xa
-		}
+	if m.SpanCount != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.SpanCount))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.DurationMs != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.DurationMs))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.StartTimeUnixNano != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.StartTimeUnixNano))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.RootTraceName) > 0 {
+		i -= len(m.RootTraceName)
+		copy(dAtA[i:], m.RootTraceName)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.RootTraceName)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.RootServiceName) > 0 {
+		i -= len(m.RootServiceName)
+		copy(dAtA[i:], m.RootServiceName)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.RootServiceName)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.TraceID) > 0 {
+		i -= len(m.TraceID)
+		copy(dAtA[i:], m.TraceID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TraceID)))
+		i--
+		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchTagValuesRequest) Marshal() (dAtA []byte, err error) {
+func (m *SearchMetrics) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1519,27 +3015,40 @@ func (m *SearchTagValuesRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchTagValuesRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchMetrics) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchTagValuesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchMetrics) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.TagName) > 0 {
-		i -= len(m.TagName)
-		copy(dAtA[i:], m.TagName)
-		i = encodeVarintTempo(dAtA, i, uint64(len(m.TagName)))
+	if m.SkippedBlocks != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.SkippedBlocks))
 		i--
-		dAtA[i] = 0xa
+		dAtA[i] = 0x20
+	}
+	if m.InspectedBlocks != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.InspectedBlocks))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.InspectedBytes != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.InspectedBytes))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.InspectedTraces != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.InspectedTraces))
+		i--
+		dAtA[i] = 0x8
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchTagValuesResponse) Marshal() (dAtA []byte, err error) {
+func (m *TailRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1549,29 +3058,42 @@ func (m *SearchTagValuesResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchTagValuesResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *TailRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchTagValuesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TailRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.TagValues) > 0 {
-		for iNdEx := len(m.TagValues) - 1; iNdEx >= 0; iNdEx-- {
-			i -= len(m.TagValues[iNdEx])
-			copy(dAtA[i:], m.TagValues[iNdEx])
-			i = encodeVarintTempo(dAtA, i, uint64(len(m.TagValues[iNdEx])))
-			i--
-			dAtA[i] = 0xa
+	if m.IncludeSpans {
+		i--
+		if m.IncludeSpans {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Search != nil {
+		{
+			size, err := m.Search.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
 		}
+		i--
+		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *Trace) Marshal() (dAtA []byte, err error) {
+func (m *TailResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1581,34 +3103,49 @@ func (m *Trace) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *Trace) MarshalTo(dAtA []byte) (int, error) {
+func (m *TailResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *Trace) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TailResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.Batches) > 0 {
-		for iNdEx := len(m.Batches) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size, err := m.Batches[iNdEx].MarshalToSizedBuffer(dAtA[:i])
-				if err != nil {
-					return 0, err
-				}
-				i -= size
-				i = encodeVarintTempo(dAtA, i, uint64(size))
+	if m.Dropped != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Dropped))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Trace != nil {
+		{
+			size, err := m.Trace.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
 			}
-			i--
-			dAtA[i] = 0xa
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Metadata != nil {
+		{
+			size, err := m.Metadata.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
 		}
+		i--
+		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *PushRequest) Marshal() (dAtA []byte, err error) {
+func (m *SearchTagsRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1618,32 +3155,20 @@ func (m *PushRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTagsRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *PushRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTagsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.Batch != nil {
-		{
-			size, err := m.Batch.MarshalToSizedBuffer(dAtA[:i])
-			if err != nil {
-				return 0, err
-			}
-			i -= size
-			i = encodeVarintTempo(dAtA, i, uint64(size))
-		}
-		i--
-		dAtA[i] = 0xa
-	}
 	return len(dAtA) - i, nil
 }
 
-func (m *PushResponse) Marshal() (dAtA []byte, err error) {
+func (m *SearchTagsResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1653,20 +3178,38 @@ func (m *PushResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTagsResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *PushResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTagsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
+	if len(m.HighCardinalityTagNames) > 0 {
+		for iNdEx := len(m.HighCardinalityTagNames) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.HighCardinalityTagNames[iNdEx])
+			copy(dAtA[i:], m.HighCardinalityTagNames[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.HighCardinalityTagNames[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.TagNames) > 0 {
+		for iNdEx := len(m.TagNames) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TagNames[iNdEx])
+			copy(dAtA[i:], m.TagNames[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.TagNames[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
 	return len(dAtA) - i, nil
 }
 
-func (m *PushBytesRequest) Marshal() (dAtA []byte, err error) {
+func (m *SearchTagValuesRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1676,66 +3219,125 @@ func (m *PushBytesRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushBytesRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTagValuesRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *PushBytesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTagValuesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.SearchData) > 0 {
-		for iNdEx := len(m.SearchData) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size := m.SearchData[iNdEx].Size()
-				i -= size
-				if _, err := m.SearchData[iNdEx].MarshalTo(dAtA[i:]); err != nil {
-					return 0, err
-				}
-				i = encodeVarintTempo(dAtA, i, uint64(size))
+	if m.Filter != nil {
+		{
+			size, err := m.Filter.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
 			}
-			i--
-			dAtA[i] = 0x22
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
 		}
+		i--
+		dAtA[i] = 0x12
 	}
-	if len(m.Ids) > 0 {
-		for iNdEx := len(m.Ids) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size := m.Ids[iNdEx].Size()
-				i -= size
-				if _, err := m.Ids[iNdEx].MarshalTo(dAtA[i:]); err != nil {
-					return 0, err
-				}
-				i = encodeVarintTempo(dAtA, i, uint64(size))
-			}
+	if len(m.TagName) > 0 {
+		i -= len(m.TagName)
+		copy(dAtA[i:], m.TagName)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TagName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SearchTagValuesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SearchTagValuesResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SearchTagValuesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.EstimatedCounts) > 0 {
+		for k := range m.EstimatedCounts {
+			v := m.EstimatedCounts[k]
+			baseI := i
+			i = encodeVarintTempo(dAtA, i, uint64(v))
+			i--
+			dAtA[i] = 0x10
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintTempo(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintTempo(dAtA, i, uint64(baseI-i))
 			i--
 			dAtA[i] = 0x1a
 		}
 	}
-	if len(m.Traces) > 0 {
-		for iNdEx := len(m.Traces) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size := m.Traces[iNdEx].Size()
-				i -= size
-				if _, err := m.Traces[iNdEx].MarshalTo(dAtA[i:]); err != nil {
-					return 0, err
-				}
-				i = encodeVarintTempo(dAtA, i, uint64(size))
-			}
+	if m.HighCardinality {
+		i--
+		if m.HighCardinality {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.TagValues) > 0 {
+		for iNdEx := len(m.TagValues) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TagValues[iNdEx])
+			copy(dAtA[i:], m.TagValues[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.TagValues[iNdEx])))
 			i--
-			dAtA[i] = 0x12
+			dAtA[i] = 0xa
 		}
 	}
-	if len(m.Requests) > 0 {
-		for iNdEx := len(m.Requests) - 1; iNdEx >= 0; iNdEx-- {
+	return len(dAtA) - i, nil
+}
+
+func (m *Trace) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Trace) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Trace) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Batches) > 0 {
+		for iNdEx := len(m.Batches) - 1; iNdEx >= 0; iNdEx-- {
 			{
-				size := m.Requests[iNdEx].Size()
-				i -= size
-				if _, err := m.Requests[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+				size, err := m.Batches[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
 					return 0, err
 				}
+				i -= size
 				i = encodeVarintTempo(dAtA, i, uint64(size))
 			}
 			i--
@@ -1745,7 +3347,7 @@ func (m *PushBytesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *TraceBytes) Marshal() (dAtA []byte, err error) {
+func (m *PushRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1755,314 +3357,3024 @@ func (m *TraceBytes) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *TraceBytes) MarshalTo(dAtA []byte) (int, error) {
+func (m *PushRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *TraceBytes) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *PushRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.Traces) > 0 {
-		for iNdEx := len(m.Traces) - 1; iNdEx >= 0; iNdEx-- {
-			i -= len(m.Traces[iNdEx])
-			copy(dAtA[i:], m.Traces[iNdEx])
-			i = encodeVarintTempo(dAtA, i, uint64(len(m.Traces[iNdEx])))
-			i--
-			dAtA[i] = 0xa
+	if m.Batch != nil {
+		{
+			size, err := m.Batch.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
 		}
+		i--
+		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintTempo(dAtA []byte, offset int, v uint64) int {
-	offset -= sovTempo(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *PushResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *TraceByIDRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *PushResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PushResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	l = len(m.TraceID)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
-	}
-	l = len(m.BlockStart)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
-	}
-	l = len(m.BlockEnd)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
+	if len(m.TraceErrors) > 0 {
+		for iNdEx := len(m.TraceErrors) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.TraceErrors[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
 	}
-	l = len(m.QueryMode)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
+	if m.PartialSuccess != nil {
+		{
+			size, err := m.PartialSuccess.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *TraceByIDResponse) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Trace != nil {
-		l = m.Trace.Size()
-		n += 1 + l + sovTempo(uint64(l))
+func (m *PushPartialSuccess) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *SearchRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *PushPartialSuccess) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PushPartialSuccess) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.Tags) > 0 {
-		for k, v := range m.Tags {
-			_ = k
-			_ = v
-			mapEntrySize := 1 + len(k) + sovTempo(uint64(len(k))) + 1 + len(v) + sovTempo(uint64(len(v)))
-			n += mapEntrySize + 1 + sovTempo(uint64(mapEntrySize))
+	if len(m.RejectedByReason) > 0 {
+		for iNdEx := len(m.RejectedByReason) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.RejectedByReason[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
 		}
 	}
-	if m.MinDurationMs != 0 {
-		n += 1 + sovTempo(uint64(m.MinDurationMs))
+	if m.AcceptedSpans != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.AcceptedSpans))
+		i--
+		dAtA[i] = 0x18
 	}
-	if m.MaxDurationMs != 0 {
-		n += 1 + sovTempo(uint64(m.MaxDurationMs))
+	if len(m.ErrorMessage) > 0 {
+		i -= len(m.ErrorMessage)
+		copy(dAtA[i:], m.ErrorMessage)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.ErrorMessage)))
+		i--
+		dAtA[i] = 0x12
 	}
-	if m.Limit != 0 {
-		n += 1 + sovTempo(uint64(m.Limit))
+	if m.RejectedSpans != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.RejectedSpans))
+		i--
+		dAtA[i] = 0x8
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *SearchResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *RejectedSpans) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *RejectedSpans) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RejectedSpans) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.Traces) > 0 {
-		for _, e := range m.Traces {
-			l = e.Size()
-			n += 1 + l + sovTempo(uint64(l))
-		}
+	if m.Spans != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Spans))
+		i--
+		dAtA[i] = 0x10
 	}
-	if m.Metrics != nil {
-		l = m.Metrics.Size()
-		n += 1 + l + sovTempo(uint64(l))
+	if len(m.Reason) > 0 {
+		i -= len(m.Reason)
+		copy(dAtA[i:], m.Reason)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.Reason)))
+		i--
+		dAtA[i] = 0xa
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *TraceSearchMetadata) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.TraceID)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
-	}
-	l = len(m.RootServiceName)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
-	}
-	l = len(m.RootTraceName)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
-	}
-	if m.StartTimeUnixNano != 0 {
-		n += 1 + sovTempo(uint64(m.StartTimeUnixNano))
-	}
-	if m.DurationMs != 0 {
-		n += 1 + sovTempo(uint64(m.DurationMs))
+func (m *PushTraceError) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *SearchMetrics) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *PushTraceError) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PushTraceError) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if m.InspectedTraces != 0 {
-		n += 1 + sovTempo(uint64(m.InspectedTraces))
-	}
-	if m.InspectedBytes != 0 {
-		n += 1 + sovTempo(uint64(m.InspectedBytes))
-	}
-	if m.InspectedBlocks != 0 {
-		n += 1 + sovTempo(uint64(m.InspectedBlocks))
+	if m.Reason != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Reason))
+		i--
+		dAtA[i] = 0x10
 	}
-	if m.SkippedBlocks != 0 {
-		n += 1 + sovTempo(uint64(m.SkippedBlocks))
+	if m.Index != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Index))
+		i--
+		dAtA[i] = 0x8
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *SearchTagsRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *PushBytesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	var l int
-	_ = l
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *SearchTagsResponse) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *PushBytesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PushBytesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.TagNames) > 0 {
-		for _, s := range m.TagNames {
-			l = len(s)
-			n += 1 + l + sovTempo(uint64(l))
+	if len(m.RootSpans) > 0 {
+		for iNdEx := len(m.RootSpans) - 1; iNdEx >= 0; iNdEx-- {
+			i--
+			if m.RootSpans[iNdEx] {
+				dAtA[i] = 1
+			} else {
+				dAtA[i] = 0
+			}
 		}
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.RootSpans)))
+		i--
+		dAtA[i] = 0x2a
 	}
-	return n
-}
-
-func (m *SearchTagValuesRequest) Size() (n int) {
-	if m == nil {
-		return 0
+	if len(m.SearchData) > 0 {
+		for iNdEx := len(m.SearchData) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.SearchData[iNdEx].Size()
+				i -= size
+				if _, err := m.SearchData[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
 	}
-	var l int
-	_ = l
-	l = len(m.TagName)
-	if l > 0 {
-		n += 1 + l + sovTempo(uint64(l))
+	if len(m.Ids) > 0 {
+		for iNdEx := len(m.Ids) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Ids[iNdEx].Size()
+				i -= size
+				if _, err := m.Ids[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
 	}
-	return n
-}
-
-func (m *SearchTagValuesResponse) Size() (n int) {
-	if m == nil {
-		return 0
+	if len(m.Traces) > 0 {
+		for iNdEx := len(m.Traces) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Traces[iNdEx].Size()
+				i -= size
+				if _, err := m.Traces[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
 	}
-	var l int
-	_ = l
-	if len(m.TagValues) > 0 {
-		for _, s := range m.TagValues {
-			l = len(s)
-			n += 1 + l + sovTempo(uint64(l))
+	if len(m.Requests) > 0 {
+		for iNdEx := len(m.Requests) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Requests[iNdEx].Size()
+				i -= size
+				if _, err := m.Requests[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
 		}
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *Trace) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *TraceBytes) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *TraceBytes) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TraceBytes) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.Batches) > 0 {
-		for _, e := range m.Batches {
-			l = e.Size()
-			n += 1 + l + sovTempo(uint64(l))
+	if len(m.Traces) > 0 {
+		for iNdEx := len(m.Traces) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Traces[iNdEx])
+			copy(dAtA[i:], m.Traces[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.Traces[iNdEx])))
+			i--
+			dAtA[i] = 0xa
 		}
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *PushRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Batch != nil {
-		l = m.Batch.Size()
-		n += 1 + l + sovTempo(uint64(l))
+func (m *TransferRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *PushResponse) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	return n
+func (m *TransferRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *PushBytesRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *TransferRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.Requests) > 0 {
-		for _, e := range m.Requests {
-			l = e.Size()
-			n += 1 + l + sovTempo(uint64(l))
-		}
-	}
-	if len(m.Traces) > 0 {
-		for _, e := range m.Traces {
-			l = e.Size()
-			n += 1 + l + sovTempo(uint64(l))
-		}
+	if len(m.TransferID) > 0 {
+		i -= len(m.TransferID)
+		copy(dAtA[i:], m.TransferID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TransferID)))
+		i--
+		dAtA[i] = 0x2a
 	}
-	if len(m.Ids) > 0 {
-		for _, e := range m.Ids {
-			l = e.Size()
-			n += 1 + l + sovTempo(uint64(l))
+	if m.File != nil {
+		{
+			size, err := m.File.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Trace != nil {
+		{
+			size, err := m.Trace.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
 		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.TenantID) > 0 {
+		i -= len(m.TenantID)
+		copy(dAtA[i:], m.TenantID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TenantID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.FromIngesterID) > 0 {
+		i -= len(m.FromIngesterID)
+		copy(dAtA[i:], m.FromIngesterID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.FromIngesterID)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferTrace) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferTrace) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferTrace) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
 	if len(m.SearchData) > 0 {
-		for _, e := range m.SearchData {
-			l = e.Size()
+		for iNdEx := len(m.SearchData) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.SearchData[iNdEx])
+			copy(dAtA[i:], m.SearchData[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.SearchData[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Traces) > 0 {
+		for iNdEx := len(m.Traces) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Traces[iNdEx])
+			copy(dAtA[i:], m.Traces[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.Traces[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.TraceID) > 0 {
+		i -= len(m.TraceID)
+		copy(dAtA[i:], m.TraceID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TraceID)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferFile) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferFile) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferFile) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Eof {
+		i--
+		if m.Eof {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.BlockID) > 0 {
+		i -= len(m.BlockID)
+		copy(dAtA[i:], m.BlockID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.BlockID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Kind != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Kind))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintTempo(dAtA []byte, offset int, v uint64) int {
+	offset -= sovTempo(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *TraceByIDRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TraceID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.BlockStart)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.BlockEnd)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.QueryMode)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Start != 0 {
+		n += 1 + sovTempo(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovTempo(uint64(m.End))
+	}
+	return n
+}
+
+func (m *TraceByIDResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Trace != nil {
+		l = m.Trace.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Metrics != nil {
+		l = m.Metrics.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *TracesByIDRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TraceIDs) > 0 {
+		for _, b := range m.TraceIDs {
+			l = len(b)
 			n += 1 + l + sovTempo(uint64(l))
 		}
 	}
+	if m.Start != 0 {
+		n += 1 + sovTempo(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovTempo(uint64(m.End))
+	}
 	return n
 }
 
-func (m *TraceBytes) Size() (n int) {
+func (m *TracesByIDResponse) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
 	if len(m.Traces) > 0 {
-		for _, b := range m.Traces {
-			l = len(b)
+		for _, e := range m.Traces {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.NotFound) > 0 {
+		for _, s := range m.NotFound {
+			l = len(s)
 			n += 1 + l + sovTempo(uint64(l))
 		}
 	}
 	return n
 }
 
-func sovTempo(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozTempo(x uint64) (n int) {
-	return sovTempo(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+func (m *TraceByIDResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TraceID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Trace != nil {
+		l = m.Trace.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *SearchRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Tags) > 0 {
+		for k, v := range m.Tags {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovTempo(uint64(len(k))) + 1 + len(v) + sovTempo(uint64(len(v)))
+			n += mapEntrySize + 1 + sovTempo(uint64(mapEntrySize))
+		}
+	}
+	if m.MinDurationMs != 0 {
+		n += 1 + sovTempo(uint64(m.MinDurationMs))
+	}
+	if m.MaxDurationMs != 0 {
+		n += 1 + sovTempo(uint64(m.MaxDurationMs))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovTempo(uint64(m.Limit))
+	}
+	if m.Start != 0 {
+		n += 1 + sovTempo(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovTempo(uint64(m.End))
+	}
+	if m.Sort != 0 {
+		n += 1 + sovTempo(uint64(m.Sort))
+	}
+	l = len(m.ContinuationToken)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *SearchResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Traces) > 0 {
+		for _, e := range m.Traces {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if m.Metrics != nil {
+		l = m.Metrics.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.ContinuationToken)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *TraceSearchMetadata) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TraceID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.RootServiceName)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.RootTraceName)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.StartTimeUnixNano != 0 {
+		n += 1 + sovTempo(uint64(m.StartTimeUnixNano))
+	}
+	if m.DurationMs != 0 {
+		n += 1 + sovTempo(uint64(m.DurationMs))
+	}
+	if m.SpanCount != 0 {
+		n += 1 + sovTempo(uint64(m.SpanCount))
+	}
+	return n
+}
+
+func (m *SearchMetrics) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.InspectedTraces != 0 {
+		n += 1 + sovTempo(uint64(m.InspectedTraces))
+	}
+	if m.InspectedBytes != 0 {
+		n += 1 + sovTempo(uint64(m.InspectedBytes))
+	}
+	if m.InspectedBlocks != 0 {
+		n += 1 + sovTempo(uint64(m.InspectedBlocks))
+	}
+	if m.SkippedBlocks != 0 {
+		n += 1 + sovTempo(uint64(m.SkippedBlocks))
+	}
+	return n
+}
+
+func (m *TailRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Search != nil {
+		l = m.Search.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.IncludeSpans {
+		n += 2
+	}
+	return n
+}
+
+func (m *TailResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Metadata != nil {
+		l = m.Metadata.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Trace != nil {
+		l = m.Trace.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Dropped != 0 {
+		n += 1 + sovTempo(uint64(m.Dropped))
+	}
+	return n
+}
+
+func (m *SearchTagsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *SearchTagsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TagNames) > 0 {
+		for _, s := range m.TagNames {
+			l = len(s)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.HighCardinalityTagNames) > 0 {
+		for _, s := range m.HighCardinalityTagNames {
+			l = len(s)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *SearchTagValuesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TagName)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Filter != nil {
+		l = m.Filter.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *SearchTagValuesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TagValues) > 0 {
+		for _, s := range m.TagValues {
+			l = len(s)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if m.HighCardinality {
+		n += 2
+	}
+	if len(m.EstimatedCounts) > 0 {
+		for k, v := range m.EstimatedCounts {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovTempo(uint64(len(k))) + 1 + sovTempo(uint64(v))
+			n += mapEntrySize + 1 + sovTempo(uint64(mapEntrySize))
+		}
+	}
+	return n
+}
+
+func (m *Trace) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Batches) > 0 {
+		for _, e := range m.Batches {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *PushRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Batch != nil {
+		l = m.Batch.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *PushResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.PartialSuccess != nil {
+		l = m.PartialSuccess.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if len(m.TraceErrors) > 0 {
+		for _, e := range m.TraceErrors {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *PushPartialSuccess) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.RejectedSpans != 0 {
+		n += 1 + sovTempo(uint64(m.RejectedSpans))
+	}
+	l = len(m.ErrorMessage)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.AcceptedSpans != 0 {
+		n += 1 + sovTempo(uint64(m.AcceptedSpans))
+	}
+	if len(m.RejectedByReason) > 0 {
+		for _, e := range m.RejectedByReason {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *RejectedSpans) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Spans != 0 {
+		n += 1 + sovTempo(uint64(m.Spans))
+	}
+	return n
+}
+
+func (m *PushTraceError) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Index != 0 {
+		n += 1 + sovTempo(uint64(m.Index))
+	}
+	if m.Reason != 0 {
+		n += 1 + sovTempo(uint64(m.Reason))
+	}
+	return n
+}
+
+func (m *PushBytesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Requests) > 0 {
+		for _, e := range m.Requests {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.Traces) > 0 {
+		for _, e := range m.Traces {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.Ids) > 0 {
+		for _, e := range m.Ids {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.SearchData) > 0 {
+		for _, e := range m.SearchData {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.RootSpans) > 0 {
+		n += 1 + sovTempo(uint64(len(m.RootSpans))) + len(m.RootSpans)*1
+	}
+	return n
+}
+
+func (m *TraceBytes) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Traces) > 0 {
+		for _, b := range m.Traces {
+			l = len(b)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *TransferRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.FromIngesterID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.TenantID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Trace != nil {
+		l = m.Trace.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.File != nil {
+		l = m.File.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.TransferID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *TransferTrace) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.TraceID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if len(m.Traces) > 0 {
+		for _, b := range m.Traces {
+			l = len(b)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.SearchData) > 0 {
+		for _, b := range m.SearchData {
+			l = len(b)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *TransferFile) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Kind != 0 {
+		n += 1 + sovTempo(uint64(m.Kind))
+	}
+	l = len(m.BlockID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Eof {
+		n += 2
+	}
+	return n
+}
+
+func (m *TransferResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func sovTempo(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozTempo(x uint64) (n int) {
+	return sovTempo(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TraceByIDRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TraceByIDRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TraceID = append(m.TraceID[:0], dAtA[iNdEx:postIndex]...)
+			if m.TraceID == nil {
+				m.TraceID = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockStart", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlockStart = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockEnd", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlockEnd = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueryMode", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.QueryMode = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TraceByIDResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TraceByIDResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TraceByIDResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Trace", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Trace == nil {
+				m.Trace = &Trace{}
+			}
+			if err := m.Trace.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metrics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metrics == nil {
+				m.Metrics = &SearchMetrics{}
+			}
+			if err := m.Metrics.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TracesByIDRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TracesByIDRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TracesByIDRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceIDs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TraceIDs = append(m.TraceIDs, make([]byte, postIndex-iNdEx))
+			copy(m.TraceIDs[len(m.TraceIDs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TracesByIDResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TracesByIDResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TracesByIDResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Traces = append(m.Traces, &TraceByIDResult{})
+			if err := m.Traces[len(m.Traces)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NotFound", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NotFound = append(m.NotFound, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TraceByIDResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TraceByIDResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TraceByIDResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TraceID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Trace", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Trace == nil {
+				m.Trace = &Trace{}
+			}
+			if err := m.Trace.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Tags == nil {
+				m.Tags = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowTempo
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTempo
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthTempo
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthTempo
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTempo
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthTempo
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthTempo
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipTempo(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthTempo
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Tags[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinDurationMs", wireType)
+			}
+			m.MinDurationMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinDurationMs |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxDurationMs", wireType)
+			}
+			m.MaxDurationMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxDurationMs |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sort", wireType)
+			}
+			m.Sort = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Sort |= SearchSort(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ContinuationToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ContinuationToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Traces = append(m.Traces, &TraceSearchMetadata{})
+			if err := m.Traces[len(m.Traces)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metrics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metrics == nil {
+				m.Metrics = &SearchMetrics{}
+			}
+			if err := m.Metrics.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ContinuationToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ContinuationToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TraceSearchMetadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TraceSearchMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TraceID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RootServiceName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RootServiceName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RootTraceName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RootTraceName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeUnixNano", wireType)
+			}
+			m.StartTimeUnixNano = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StartTimeUnixNano |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DurationMs", wireType)
+			}
+			m.DurationMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DurationMs |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SpanCount", wireType)
+			}
+			m.SpanCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SpanCount |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchMetrics) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchMetrics: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchMetrics: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InspectedTraces", wireType)
+			}
+			m.InspectedTraces = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InspectedTraces |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InspectedBytes", wireType)
+			}
+			m.InspectedBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InspectedBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InspectedBlocks", wireType)
+			}
+			m.InspectedBlocks = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InspectedBlocks |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SkippedBlocks", wireType)
+			}
+			m.SkippedBlocks = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SkippedBlocks |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TailRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TailRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TailRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Search", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Search == nil {
+				m.Search = &SearchRequest{}
+			}
+			if err := m.Search.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncludeSpans", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IncludeSpans = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TailResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TailResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TailResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metadata == nil {
+				m.Metadata = &TraceSearchMetadata{}
+			}
+			if err := m.Metadata.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Trace", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Trace == nil {
+				m.Trace = &Trace{}
+			}
+			if err := m.Trace.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dropped", wireType)
+			}
+			m.Dropped = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Dropped |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TagNames", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TagNames = append(m.TagNames, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HighCardinalityTagNames", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HighCardinalityTagNames = append(m.HighCardinalityTagNames, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
 }
-func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
+func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2085,17 +6397,17 @@ func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TraceByIDRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: SearchTagValuesRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TraceByIDRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SearchTagValuesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TagName", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2105,29 +6417,113 @@ func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TraceID = append(m.TraceID[:0], dAtA[iNdEx:postIndex]...)
-			if m.TraceID == nil {
-				m.TraceID = []byte{}
-			}
+			m.TagName = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field BlockStart", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Filter", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Filter == nil {
+				m.Filter = &SearchRequest{}
+			}
+			if err := m.Filter.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagValuesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagValuesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TagValues", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2155,13 +6551,33 @@ func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.BlockStart = string(dAtA[iNdEx:postIndex])
+			m.TagValues = append(m.TagValues, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HighCardinality", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.HighCardinality = bool(v != 0)
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field BlockEnd", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field EstimatedCounts", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2171,29 +6587,160 @@ func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.BlockEnd = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 5:
+			if m.EstimatedCounts == nil {
+				m.EstimatedCounts = make(map[string]uint32)
+			}
+			var mapkey string
+			var mapvalue uint32
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowTempo
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTempo
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthTempo
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthTempo
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTempo
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapvalue |= uint32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipTempo(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthTempo
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.EstimatedCounts[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Trace) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Trace: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Trace: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field QueryMode", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Batches", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2203,23 +6750,25 @@ func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.QueryMode = string(dAtA[iNdEx:postIndex])
+			m.Batches = append(m.Batches, &v1.ResourceSpans{})
+			if err := m.Batches[len(m.Batches)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2242,7 +6791,7 @@ func (m *TraceByIDRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TraceByIDResponse) Unmarshal(dAtA []byte) error {
+func (m *PushRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2265,15 +6814,15 @@ func (m *TraceByIDResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TraceByIDResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TraceByIDResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Trace", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Batch", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2300,10 +6849,10 @@ func (m *TraceByIDResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Trace == nil {
-				m.Trace = &Trace{}
+			if m.Batch == nil {
+				m.Batch = &v1.ResourceSpans{}
 			}
-			if err := m.Trace.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Batch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -2328,7 +6877,7 @@ func (m *TraceByIDResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchRequest) Unmarshal(dAtA []byte) error {
+func (m *PushResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2351,15 +6900,15 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field PartialSuccess", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2386,109 +6935,18 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Tags == nil {
-				m.Tags = make(map[string]string)
+			if m.PartialSuccess == nil {
+				m.PartialSuccess = &PushPartialSuccess{}
 			}
-			var mapkey string
-			var mapvalue string
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowTempo
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					var stringLenmapkey uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowTempo
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapkey |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapkey := int(stringLenmapkey)
-					if intStringLenmapkey < 0 {
-						return ErrInvalidLengthTempo
-					}
-					postStringIndexmapkey := iNdEx + intStringLenmapkey
-					if postStringIndexmapkey < 0 {
-						return ErrInvalidLengthTempo
-					}
-					if postStringIndexmapkey > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
-					iNdEx = postStringIndexmapkey
-				} else if fieldNum == 2 {
-					var stringLenmapvalue uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowTempo
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapvalue |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapvalue := int(stringLenmapvalue)
-					if intStringLenmapvalue < 0 {
-						return ErrInvalidLengthTempo
-					}
-					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
-					if postStringIndexmapvalue < 0 {
-						return ErrInvalidLengthTempo
-					}
-					if postStringIndexmapvalue > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
-					iNdEx = postStringIndexmapvalue
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipTempo(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if (skippy < 0) || (iNdEx+skippy) < 0 {
-						return ErrInvalidLengthTempo
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
-				}
+			if err := m.PartialSuccess.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			m.Tags[mapkey] = mapvalue
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MinDurationMs", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceErrors", wireType)
 			}
-			m.MinDurationMs = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2498,49 +6956,26 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.MinDurationMs |= uint32(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MaxDurationMs", wireType)
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
 			}
-			m.MaxDurationMs = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowTempo
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.MaxDurationMs |= uint32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
 			}
-		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
-			m.Limit = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowTempo
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Limit |= uint32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			m.TraceErrors = append(m.TraceErrors, PushTraceError{})
+			if err := m.TraceErrors[len(m.TraceErrors)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
@@ -2562,7 +6997,7 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchResponse) Unmarshal(dAtA []byte) error {
+func (m *PushPartialSuccess) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2585,17 +7020,36 @@ func (m *SearchResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushPartialSuccess: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushPartialSuccess: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectedSpans", wireType)
+			}
+			m.RejectedSpans = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RejectedSpans |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrorMessage", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2605,29 +7059,46 @@ func (m *SearchResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ErrorMessage = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AcceptedSpans", wireType)
+			}
+			m.AcceptedSpans = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AcceptedSpans |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthTempo
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthTempo
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Traces = append(m.Traces, &TraceSearchMetadata{})
-			if err := m.Traces[len(m.Traces)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 2:
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Metrics", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectedByReason", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2654,10 +7125,8 @@ func (m *SearchResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Metrics == nil {
-				m.Metrics = &SearchMetrics{}
-			}
-			if err := m.Metrics.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.RejectedByReason = append(m.RejectedByReason, RejectedSpans{})
+			if err := m.RejectedByReason[len(m.RejectedByReason)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -2682,7 +7151,7 @@ func (m *SearchResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
+func (m *RejectedSpans) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2705,15 +7174,15 @@ func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TraceSearchMetadata: wiretype end group for non-group")
+			return fmt.Errorf("proto: RejectedSpans: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TraceSearchMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RejectedSpans: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2741,13 +7210,13 @@ func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TraceID = string(dAtA[iNdEx:postIndex])
+			m.Reason = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RootServiceName", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Spans", wireType)
 			}
-			var stringLen uint64
+			m.Spans = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2757,61 +7226,66 @@ func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.Spans |= int64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthTempo
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthTempo
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.RootServiceName = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RootTraceName", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowTempo
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthTempo
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthTempo
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushTraceError) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
 			}
-			if postIndex > l {
+			if iNdEx >= l {
 				return io.ErrUnexpectedEOF
 			}
-			m.RootTraceName = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 4:
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PushTraceError: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PushTraceError: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeUnixNano", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
 			}
-			m.StartTimeUnixNano = 0
+			m.Index = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2821,16 +7295,16 @@ func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StartTimeUnixNano |= uint64(b&0x7F) << shift
+				m.Index |= uint32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 5:
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DurationMs", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
 			}
-			m.DurationMs = 0
+			m.Reason = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2840,7 +7314,7 @@ func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.DurationMs |= uint32(b&0x7F) << shift
+				m.Reason |= PushErrorReason(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -2866,7 +7340,7 @@ func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchMetrics) Unmarshal(dAtA []byte) error {
+func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2889,17 +7363,17 @@ func (m *SearchMetrics) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchMetrics: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushBytesRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchMetrics: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushBytesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field InspectedTraces", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Requests", wireType)
 			}
-			m.InspectedTraces = 0
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2909,16 +7383,32 @@ func (m *SearchMetrics) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.InspectedTraces |= uint32(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v PreallocBytes
+			m.Requests = append(m.Requests, v)
+			if err := m.Requests[len(m.Requests)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field InspectedBytes", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
 			}
-			m.InspectedBytes = 0
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2928,16 +7418,67 @@ func (m *SearchMetrics) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.InspectedBytes |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v PreallocBytes
+			m.Traces = append(m.Traces, v)
+			if err := m.Traces[len(m.Traces)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field InspectedBlocks", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ids", wireType)
 			}
-			m.InspectedBlocks = 0
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v PreallocBytes
+			m.Ids = append(m.Ids, v)
+			if err := m.Ids[len(m.Ids)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchData", wireType)
+			}
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -2945,31 +7486,98 @@ func (m *SearchMetrics) Unmarshal(dAtA []byte) error {
 				if iNdEx >= l {
 					return io.ErrUnexpectedEOF
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.InspectedBlocks |= uint32(b&0x7F) << shift
-				if b < 0x80 {
-					break
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v PreallocBytes
+			m.SearchData = append(m.SearchData, v)
+			if err := m.SearchData[len(m.SearchData)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType == 0 {
+				var v int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowTempo
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.RootSpans = append(m.RootSpans, bool(v != 0))
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowTempo
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthTempo
 				}
-			}
-		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SkippedBlocks", wireType)
-			}
-			m.SkippedBlocks = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowTempo
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthTempo
 				}
-				if iNdEx >= l {
+				if postIndex > l {
 					return io.ErrUnexpectedEOF
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.SkippedBlocks |= uint32(b&0x7F) << shift
-				if b < 0x80 {
-					break
+				var elementCount int
+				elementCount = packedLen
+				if elementCount != 0 && len(m.RootSpans) == 0 {
+					m.RootSpans = make([]bool, 0, elementCount)
 				}
+				for iNdEx < postIndex {
+					var v int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTempo
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.RootSpans = append(m.RootSpans, bool(v != 0))
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field RootSpans", wireType)
 			}
 		default:
 			iNdEx = preIndex
@@ -2992,57 +7600,7 @@ func (m *SearchMetrics) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchTagsRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowTempo
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: SearchTagsRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchTagsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTempo(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
-				return ErrInvalidLengthTempo
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *SearchTagsResponse) Unmarshal(dAtA []byte) error {
+func (m *TraceBytes) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3065,17 +7623,17 @@ func (m *SearchTagsResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchTagsResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: TraceBytes: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchTagsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TraceBytes: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TagNames", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -3085,23 +7643,23 @@ func (m *SearchTagsResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TagNames = append(m.TagNames, string(dAtA[iNdEx:postIndex]))
+			m.Traces = append(m.Traces, make([]byte, postIndex-iNdEx))
+			copy(m.Traces[len(m.Traces)-1], dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3124,7 +7682,7 @@ func (m *SearchTagsResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
+func (m *TransferRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3147,15 +7705,15 @@ func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchTagValuesRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: TransferRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchTagValuesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TransferRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TagName", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field FromIngesterID", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3183,61 +7741,11 @@ func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TagName = string(dAtA[iNdEx:postIndex])
+			m.FromIngesterID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTempo(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
-				return ErrInvalidLengthTempo
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowTempo
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: SearchTagValuesResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchTagValuesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TagValues", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TenantID", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3265,63 +7773,85 @@ func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TagValues = append(m.TagValues, string(dAtA[iNdEx:postIndex]))
+			m.TenantID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTempo(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Trace", wireType)
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *Trace) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowTempo
+			if m.Trace == nil {
+				m.Trace = &TransferTrace{}
 			}
-			if iNdEx >= l {
+			if err := m.Trace.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field File", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			if m.File == nil {
+				m.File = &TransferFile{}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: Trace: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Trace: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			if err := m.File.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Batches", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TransferID", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -3331,25 +7861,23 @@ func (m *Trace) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Batches = append(m.Batches, &v1.ResourceSpans{})
-			if err := m.Batches[len(m.Batches)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.TransferID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3372,7 +7900,7 @@ func (m *Trace) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushRequest) Unmarshal(dAtA []byte) error {
+func (m *TransferTrace) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3395,17 +7923,17 @@ func (m *PushRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: TransferTrace: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TransferTrace: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Batch", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -3415,78 +7943,90 @@ func (m *PushRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Batch == nil {
-				m.Batch = &v1.ResourceSpans{}
-			}
-			if err := m.Batch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.TraceID = append(m.TraceID[:0], dAtA[iNdEx:postIndex]...)
+			if m.TraceID == nil {
+				m.TraceID = []byte{}
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTempo(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *PushResponse) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowTempo
+			m.Traces = append(m.Traces, make([]byte, postIndex-iNdEx))
+			copy(m.Traces[len(m.Traces)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchData", wireType)
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: PushResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SearchData = append(m.SearchData, make([]byte, postIndex-iNdEx))
+			copy(m.SearchData[len(m.SearchData)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
@@ -3508,7 +8048,7 @@ func (m *PushResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
+func (m *TransferFile) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3531,17 +8071,17 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushBytesRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: TransferFile: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushBytesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TransferFile: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Requests", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Kind", wireType)
 			}
-			var byteLen int
+			m.Kind = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -3551,32 +8091,16 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				m.Kind |= TransferFileKind(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthTempo
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthTempo
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			var v PreallocBytes
-			m.Requests = append(m.Requests, v)
-			if err := m.Requests[len(m.Requests)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockID", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -3586,32 +8110,29 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v PreallocBytes
-			m.Traces = append(m.Traces, v)
-			if err := m.Traces[len(m.Traces)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.BlockID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Ids", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -3621,30 +8142,27 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v PreallocBytes
-			m.Ids = append(m.Ids, v)
-			if err := m.Ids[len(m.Ids)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SearchData", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -3671,12 +8189,31 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v PreallocBytes
-			m.SearchData = append(m.SearchData, v)
-			if err := m.SearchData[len(m.SearchData)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Eof", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Eof = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
@@ -3698,7 +8235,7 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TraceBytes) Unmarshal(dAtA []byte) error {
+func (m *TransferResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3721,44 +8258,12 @@ func (m *TraceBytes) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TraceBytes: wiretype end group for non-group")
+			return fmt.Errorf("proto: TransferResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TraceBytes: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TransferResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowTempo
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthTempo
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthTempo
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Traces = append(m.Traces, make([]byte, postIndex-iNdEx))
-			copy(m.Traces[len(m.Traces)-1], dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
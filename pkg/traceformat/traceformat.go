@@ -0,0 +1,213 @@
+// Package traceformat marshals traces to the formats that can be requested from the trace by id endpoints.
+package traceformat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	jaeger "github.com/jaegertracing/jaeger/model"
+	jaeger_json "github.com/jaegertracing/jaeger/model/converter/json"
+	jaeger_ui "github.com/jaegertracing/jaeger/model/json"
+	ot_pdata "go.opentelemetry.io/collector/consumer/pdata"
+	ot_jaeger "go.opentelemetry.io/collector/translator/trace/jaeger"
+	ot_zipkin "go.opentelemetry.io/collector/translator/trace/zipkin"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+const (
+	// OTLPJSONTypeHeaderValue is the OTLP/JSON encoding of an ExportTraceServiceRequest, with hex ids
+	OTLPJSONTypeHeaderValue = "application/otlp+json"
+	// JaegerJSONTypeHeaderValue is the json of the Jaeger query API
+	JaegerJSONTypeHeaderValue = "application/jaeger+json"
+	// ZipkinJSONTypeHeaderValue is a Zipkin v2 json list of spans
+	ZipkinJSONTypeHeaderValue = "application/zipkin+json"
+)
+
+// otlpIDKeys are the bytes fields of spans and links that OTLP/JSON encodes as hex instead of base64
+var otlpIDKeys = map[string]struct{}{
+	"traceId":      {},
+	"spanId":       {},
+	"parentSpanId": {},
+}
+
+// Negotiate returns the content type to respond with given the Accept header of a request. Types are preferred in
+// the order they are listed. It defaults to the json of tempopb.Trace.
+func Negotiate(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case util.ProtobufTypeHeaderValue, util.JSONTypeHeaderValue, OTLPJSONTypeHeaderValue, JaegerJSONTypeHeaderValue, ZipkinJSONTypeHeaderValue:
+			return mediaType
+		}
+	}
+
+	return util.JSONTypeHeaderValue
+}
+
+// Marshal marshals the trace to the content type returned by Negotiate
+func Marshal(trace *tempopb.Trace, contentType string) ([]byte, error) {
+	switch contentType {
+	case util.ProtobufTypeHeaderValue:
+		return proto.Marshal(trace)
+	case util.JSONTypeHeaderValue:
+		var buf bytes.Buffer
+		err := (&jsonpb.Marshaler{}).Marshal(&buf, trace)
+		return buf.Bytes(), err
+	case OTLPJSONTypeHeaderValue:
+		return marshalOTLPJSON(trace)
+	case JaegerJSONTypeHeaderValue:
+		return marshalJaegerJSON(trace)
+	case ZipkinJSONTypeHeaderValue:
+		return marshalZipkinJSON(trace)
+	default:
+		return nil, fmt.Errorf("unsupported content type %s", contentType)
+	}
+}
+
+// jaegerTraceFromOTLPBytes translates a proto marshalled tempopb.Trace to a Jaeger trace
+func jaegerTraceFromOTLPBytes(b []byte) (*jaeger.Trace, error) {
+	otTrace := ot_pdata.NewTraces()
+	err := otTrace.FromOtlpProtoBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("error converting tempo response to Otlp: %w", err)
+	}
+
+	jaegerBatches, err := ot_jaeger.InternalTracesToJaegerProto(otTrace)
+	if err != nil {
+		return nil, fmt.Errorf("error translating to jaegerBatches: %w", err)
+	}
+
+	jaegerTrace := &jaeger.Trace{
+		Spans:      []*jaeger.Span{},
+		ProcessMap: []jaeger.Trace_ProcessMapping{},
+	}
+
+	// otel proto conversion doesn't set jaeger processes
+	for _, batch := range jaegerBatches {
+		for _, s := range batch.Spans {
+			s.Process = batch.Process
+		}
+
+		jaegerTrace.Spans = append(jaegerTrace.Spans, batch.Spans...)
+		jaegerTrace.ProcessMap = append(jaegerTrace.ProcessMap, jaeger.Trace_ProcessMapping{
+			Process:   *batch.Process,
+			ProcessID: batch.Process.ServiceName,
+		})
+	}
+
+	return jaegerTrace, nil
+}
+
+// marshalOTLPJSON marshals the trace like an OTLP ExportTraceServiceRequest in the OTLP/JSON encoding, which
+// differs from jsonpb in its hex ids and integer enums
+func marshalOTLPJSON(trace *tempopb.Trace) ([]byte, error) {
+	var buf bytes.Buffer
+	err := (&jsonpb.Marshaler{EnumsAsInts: true}).Marshal(&buf, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]interface{}
+	decoder := json.NewDecoder(&buf)
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	if err := hexIDs(obj); err != nil {
+		return nil, err
+	}
+
+	resourceSpans, ok := obj["batches"]
+	if !ok {
+		resourceSpans = []interface{}{}
+	}
+	return json.Marshal(map[string]interface{}{
+		"resourceSpans": resourceSpans,
+	})
+}
+
+// hexIDs reencodes the base64 ids of the jsonpb object to hex in place
+func hexIDs(v interface{}) error {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, child := range vv {
+			if _, ok := otlpIDKeys[k]; ok {
+				if s, ok := child.(string); ok {
+					id, err := base64.StdEncoding.DecodeString(s)
+					if err != nil {
+						return fmt.Errorf("invalid %s %s: %w", k, s, err)
+					}
+					vv[k] = hex.EncodeToString(id)
+					continue
+				}
+			}
+			if err := hexIDs(child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range vv {
+			if err := hexIDs(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// marshalJaegerJSON marshals the trace like the response of the Jaeger query API
+func marshalJaegerJSON(trace *tempopb.Trace) ([]byte, error) {
+	b, err := proto.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+
+	jaegerTrace, err := jaegerTraceFromOTLPBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		Data []*jaeger_ui.Trace `json:"data"`
+	}{
+		Data: []*jaeger_ui.Trace{jaeger_json.FromDomain(jaegerTrace)},
+	})
+}
+
+// marshalZipkinJSON marshals the trace as a list of Zipkin v2 spans
+func marshalZipkinJSON(trace *tempopb.Trace) ([]byte, error) {
+	b, err := proto.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+
+	otTrace := ot_pdata.NewTraces()
+	err = otTrace.FromOtlpProtoBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("error converting trace to Otlp: %w", err)
+	}
+
+	spans, err := ot_zipkin.InternalTracesToZipkinSpans(otTrace)
+	if err != nil {
+		return nil, fmt.Errorf("error translating to zipkin spans: %w", err)
+	}
+	if spans == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(spans)
+}
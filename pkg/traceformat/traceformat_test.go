@@ -0,0 +1,184 @@
+package traceformat
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1_trace "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+var (
+	testTraceID  = []byte{0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x10}
+	testRootID   = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	testChildID  = []byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}
+	testTraceHex = hex.EncodeToString(testTraceID)
+)
+
+func makeTestTrace() *tempopb.Trace {
+	return &tempopb.Trace{
+		Batches: []*v1_trace.ResourceSpans{
+			{
+				Resource: &v1_resource.Resource{
+					Attributes: []*v1_common.KeyValue{
+						{Key: "service.name", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: "test-service"}}},
+					},
+				},
+				InstrumentationLibrarySpans: []*v1_trace.InstrumentationLibrarySpans{
+					{
+						Spans: []*v1_trace.Span{
+							{
+								TraceId:           testTraceID,
+								SpanId:            testRootID,
+								Name:              "root",
+								Kind:              v1_trace.Span_SPAN_KIND_SERVER,
+								StartTimeUnixNano: 1000000000,
+								EndTimeUnixNano:   2000000000,
+							},
+							{
+								TraceId:           testTraceID,
+								SpanId:            testChildID,
+								ParentSpanId:      testRootID,
+								Name:              "child",
+								Kind:              v1_trace.Span_SPAN_KIND_CLIENT,
+								StartTimeUnixNano: 1100000000,
+								EndTimeUnixNano:   1900000000,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "", expected: util.JSONTypeHeaderValue},
+		{accept: "*/*", expected: util.JSONTypeHeaderValue},
+		{accept: "text/html", expected: util.JSONTypeHeaderValue},
+		{accept: util.ProtobufTypeHeaderValue, expected: util.ProtobufTypeHeaderValue},
+		{accept: "application/otlp+json; charset=utf-8", expected: OTLPJSONTypeHeaderValue},
+		{accept: "text/html, application/zipkin+json;q=0.9, application/jaeger+json", expected: ZipkinJSONTypeHeaderValue},
+		{accept: "application/jaeger+json", expected: JaegerJSONTypeHeaderValue},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Negotiate(tt.accept), tt.accept)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	b, err := Marshal(makeTestTrace(), util.JSONTypeHeaderValue)
+	require.NoError(t, err)
+
+	actual := &tempopb.Trace{}
+	require.NoError(t, jsonpb.UnmarshalString(string(b), actual))
+	assert.Equal(t, makeTestTrace(), actual)
+}
+
+func TestMarshalOTLPJSON(t *testing.T) {
+	b, err := Marshal(makeTestTrace(), OTLPJSONTypeHeaderValue)
+	require.NoError(t, err)
+
+	var actual struct {
+		ResourceSpans []struct {
+			InstrumentationLibrarySpans []struct {
+				Spans []struct {
+					TraceID      string `json:"traceId"`
+					SpanID       string `json:"spanId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Kind         int    `json:"kind"`
+				} `json:"spans"`
+			} `json:"instrumentationLibrarySpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(b, &actual))
+
+	require.Len(t, actual.ResourceSpans, 1)
+	spans := actual.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Equal(t, testTraceHex, spans[0].TraceID)
+	assert.Equal(t, hex.EncodeToString(testRootID), spans[0].SpanID)
+	assert.Equal(t, "", spans[0].ParentSpanID)
+	assert.Equal(t, int(v1_trace.Span_SPAN_KIND_SERVER), spans[0].Kind)
+	assert.Equal(t, hex.EncodeToString(testChildID), spans[1].SpanID)
+	assert.Equal(t, hex.EncodeToString(testRootID), spans[1].ParentSpanID)
+
+	b, err = Marshal(&tempopb.Trace{}, OTLPJSONTypeHeaderValue)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resourceSpans": []}`, string(b))
+}
+
+func TestMarshalJaegerJSON(t *testing.T) {
+	b, err := Marshal(makeTestTrace(), JaegerJSONTypeHeaderValue)
+	require.NoError(t, err)
+
+	var actual struct {
+		Data []struct {
+			TraceID string `json:"traceID"`
+			Spans   []struct {
+				SpanID        string `json:"spanID"`
+				OperationName string `json:"operationName"`
+				ProcessID     string `json:"processID"`
+			} `json:"spans"`
+			Processes map[string]struct {
+				ServiceName string `json:"serviceName"`
+			} `json:"processes"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(b, &actual))
+
+	require.Len(t, actual.Data, 1)
+	assert.Equal(t, testTraceHex, actual.Data[0].TraceID)
+	require.Len(t, actual.Data[0].Spans, 2)
+	for _, s := range actual.Data[0].Spans {
+		assert.Equal(t, "test-service", actual.Data[0].Processes[s.ProcessID].ServiceName)
+	}
+}
+
+func TestMarshalZipkinJSON(t *testing.T) {
+	b, err := Marshal(makeTestTrace(), ZipkinJSONTypeHeaderValue)
+	require.NoError(t, err)
+
+	var actual []struct {
+		TraceID       string `json:"traceId"`
+		ID            string `json:"id"`
+		ParentID      string `json:"parentId"`
+		Name          string `json:"name"`
+		Kind          string `json:"kind"`
+		LocalEndpoint struct {
+			ServiceName string `json:"serviceName"`
+		} `json:"localEndpoint"`
+	}
+	require.NoError(t, json.Unmarshal(b, &actual))
+
+	require.Len(t, actual, 2)
+	for _, s := range actual {
+		assert.Equal(t, testTraceHex, s.TraceID)
+		assert.Equal(t, "test-service", s.LocalEndpoint.ServiceName)
+	}
+	assert.Equal(t, hex.EncodeToString(testRootID), actual[0].ID)
+	assert.Equal(t, "SERVER", actual[0].Kind)
+	assert.Equal(t, hex.EncodeToString(testRootID), actual[1].ParentID)
+
+	b, err = Marshal(&tempopb.Trace{}, ZipkinJSONTypeHeaderValue)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(b))
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	_, err := Marshal(makeTestTrace(), "text/html")
+	assert.Error(t, err)
+}
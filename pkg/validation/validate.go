@@ -4,3 +4,16 @@ package validation
 func ValidTraceID(id []byte) bool {
 	return len(id) == 16
 }
+
+// ValidSpanID confirms that span ids are 64 bits and not all zeros
+func ValidSpanID(id []byte) bool {
+	if len(id) != 8 {
+		return false
+	}
+	for _, b := range id {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
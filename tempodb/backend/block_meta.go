@@ -61,3 +61,15 @@ func (b *BlockMeta) ObjectAdded(id []byte) {
 
 	b.TotalObjects++
 }
+
+// OverlapsTimeRange returns true if the time the objects were written to the block overlaps the range given in
+// unix epoch seconds. A start or end of 0 leaves that side of the range unbounded.
+func (b *BlockMeta) OverlapsTimeRange(start, end int64) bool {
+	if start > 0 && b.EndTime.Unix() < start {
+		return false
+	}
+	if end > 0 && b.StartTime.Unix() > end {
+		return false
+	}
+	return true
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -43,6 +44,34 @@ func TestBlockMeta(t *testing.T) {
 	assert.Equal(t, 2, b.TotalObjects)
 }
 
+func TestBlockMetaOverlapsTimeRange(t *testing.T) {
+	b := &BlockMeta{
+		StartTime: time.Unix(100, 0),
+		EndTime:   time.Unix(200, 0),
+	}
+
+	tests := []struct {
+		start, end int64
+		expected   bool
+	}{
+		{0, 0, true},
+		{50, 150, true},
+		{150, 250, true},
+		{120, 180, true},
+		{50, 250, true},
+		{200, 0, true},
+		{0, 100, true},
+		{201, 0, false},
+		{0, 99, false},
+		{10, 50, false},
+		{250, 300, false},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, b.OverlapsTimeRange(tc.start, tc.end), "start %d end %d", tc.start, tc.end)
+	}
+}
+
 func TestBlockMetaParsing(t *testing.T) {
 	inputJSON := `
 {
@@ -15,7 +15,8 @@ type Config struct {
 	TTL time.Duration `yaml:"ttl"`
 }
 
-func NewClient(cfg *Config, cfgBackground *cortex_cache.BackgroundConfig, logger log.Logger) cortex_cache.Cache {
+// NewClient creates a memcached cache. The name tells the metrics of different caches apart.
+func NewClient(name string, cfg *Config, cfgBackground *cortex_cache.BackgroundConfig, logger log.Logger) cortex_cache.Cache {
 	if cfg.ClientConfig.MaxIdleConns == 0 {
 		cfg.ClientConfig.MaxIdleConns = 16
 	}
@@ -26,13 +27,13 @@ func NewClient(cfg *Config, cfgBackground *cortex_cache.BackgroundConfig, logger
 		cfg.ClientConfig.UpdateInterval = time.Minute
 	}
 
-	client := cortex_cache.NewMemcachedClient(cfg.ClientConfig, "tempo", prometheus.DefaultRegisterer, logger)
+	client := cortex_cache.NewMemcachedClient(cfg.ClientConfig, name, prometheus.DefaultRegisterer, logger)
 	memcachedCfg := cortex_cache.MemcachedConfig{
 		Expiration:  cfg.TTL,
 		BatchSize:   0, // we are currently only requesting one key at a time, which is bad.  we could restructure Find() to batch request all blooms at once
 		Parallelism: 0,
 	}
-	cache := cortex_cache.NewMemcached(memcachedCfg, client, "tempo", prometheus.DefaultRegisterer, logger)
+	cache := cortex_cache.NewMemcached(memcachedCfg, client, name, prometheus.DefaultRegisterer, logger)
 
-	return cortex_cache.NewBackground("tempo", *cfgBackground, cache, prometheus.DefaultRegisterer)
+	return cortex_cache.NewBackground(name, *cfgBackground, cache, prometheus.DefaultRegisterer)
 }
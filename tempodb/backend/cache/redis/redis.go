@@ -15,7 +15,8 @@ type Config struct {
 	TTL time.Duration `yaml:"ttl"`
 }
 
-func NewClient(cfg *Config, cfgBackground *cortex_cache.BackgroundConfig, logger log.Logger) cortex_cache.Cache {
+// NewClient creates a redis cache. The name tells the metrics of different caches apart.
+func NewClient(name string, cfg *Config, cfgBackground *cortex_cache.BackgroundConfig, logger log.Logger) cortex_cache.Cache {
 	if cfg.ClientConfig.Timeout == 0 {
 		cfg.ClientConfig.Timeout = 100 * time.Millisecond
 	}
@@ -24,7 +25,7 @@ func NewClient(cfg *Config, cfgBackground *cortex_cache.BackgroundConfig, logger
 	}
 
 	client := cortex_cache.NewRedisClient(&cfg.ClientConfig)
-	cache := cortex_cache.NewRedisCache("tempo", client, prometheus.DefaultRegisterer, logger)
+	cache := cortex_cache.NewRedisCache(name, client, prometheus.DefaultRegisterer, logger)
 
-	return cortex_cache.NewBackground("tempo", *cfgBackground, cache, prometheus.DefaultRegisterer)
+	return cortex_cache.NewBackground(name, *cfgBackground, cache, prometheus.DefaultRegisterer)
 }
@@ -115,6 +115,24 @@ func (rw *Backend) List(ctx context.Context, keypath backend.KeyPath) ([]string,
 	return objects, nil
 }
 
+// Objects returns the names of the objects stored at the keypath, e.g. the files of a block
+func (rw *Backend) Objects(ctx context.Context, keypath backend.KeyPath) ([]string, error) {
+	files, err := ioutil.ReadDir(rw.rootPath(keypath))
+	if err != nil {
+		return nil, readError(err)
+	}
+
+	objects := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		objects = append(objects, f.Name())
+	}
+
+	return objects, nil
+}
+
 // Read implements backend.Reader
 func (rw *Backend) Read(ctx context.Context, name string, keypath backend.KeyPath, _ bool) (io.ReadCloser, int64, error) {
 	filename := rw.objectFileName(keypath, name)
@@ -55,6 +55,7 @@ const (
 	compactionCycle = 30 * time.Second
 
 	DefaultFlushSizeBytes uint32 = 30 * 1024 * 1024 // 30 MiB
+	DefaultChunkSizeBytes uint32 = 5 * 1024 * 1024  // 5 MiB
 
 	DefaultIteratorBufferSize = 1000
 )
@@ -171,6 +172,11 @@ func (rw *readerWriter) compact(blockMetas []*backend.BlockMeta, tenantID string
 		iters = append(iters, iter)
 	}
 
+	blockCfg, err := rw.blockConfigForTenant(tenantID)
+	if err != nil {
+		return err
+	}
+
 	recordsPerBlock := (totalRecords / outputBlocks)
 	var newCompactedBlocks []*backend.BlockMeta
 	var currentBlock *encoding.StreamingBlock
@@ -197,7 +203,7 @@ func (rw *readerWriter) compact(blockMetas []*backend.BlockMeta, tenantID string
 
 		// make a new block if necessary
 		if currentBlock == nil {
-			currentBlock, err = encoding.NewStreamingBlock(rw.cfg.Block, uuid.New(), tenantID, blockMetas, recordsPerBlock)
+			currentBlock, err = encoding.NewStreamingBlock(blockCfg, uuid.New(), tenantID, blockMetas, recordsPerBlock)
 			if err != nil {
 				return errors.Wrap(err, "error making new compacted block")
 			}
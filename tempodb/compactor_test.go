@@ -49,13 +49,28 @@ type mockJobSharder struct{}
 func (m *mockJobSharder) Owns(_ string) bool { return true }
 
 type mockOverrides struct {
-	blockRetention time.Duration
+	blockRetention     time.Duration
+	blockEncoding      string
+	bloomFP            float64
+	indexPageSizeBytes int
 }
 
 func (m *mockOverrides) BlockRetentionForTenant(_ string) time.Duration {
 	return m.blockRetention
 }
 
+func (m *mockOverrides) BlockEncodingForTenant(_ string) string {
+	return m.blockEncoding
+}
+
+func (m *mockOverrides) BlockBloomFPForTenant(_ string) float64 {
+	return m.bloomFP
+}
+
+func (m *mockOverrides) BlockIndexPageSizeBytesForTenant(_ string) int {
+	return m.indexPageSizeBytes
+}
+
 func TestCompaction(t *testing.T) {
 	tempDir, err := ioutil.TempDir("/tmp", "")
 	defer os.RemoveAll(tempDir)
@@ -175,7 +190,7 @@ func TestCompaction(t *testing.T) {
 
 	// now see if we can find our ids
 	for i, id := range allIds {
-		b, _, err := rw.Find(context.Background(), testTenantID, id, BlockIDMin, BlockIDMax)
+		b, _, err := rw.Find(context.Background(), testTenantID, id, BlockIDMin, BlockIDMax, 0, 0)
 		assert.NoError(t, err)
 
 		out := &tempopb.PushRequest{}
@@ -337,7 +352,7 @@ func TestCompactionUpdatesBlocklist(t *testing.T) {
 	// Make sure all expected traces are found.
 	for i := 0; i < blockCount; i++ {
 		for j := 0; j < recordCount; j++ {
-			trace, _, err := rw.Find(context.TODO(), testTenantID, makeTraceID(i, j), BlockIDMin, BlockIDMax)
+			trace, _, err := rw.Find(context.TODO(), testTenantID, makeTraceID(i, j), BlockIDMin, BlockIDMax, 0, 0)
 			assert.NotNil(t, trace)
 			assert.Greater(t, len(trace), 0)
 			assert.NoError(t, err)
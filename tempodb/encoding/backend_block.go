@@ -49,11 +49,22 @@ func (b *BackendBlock) Find(ctx context.Context, id common.ID) ([]byte, error) {
 	span.SetTag("block", b.meta.BlockID.String())
 
 	shardKey := common.ShardKeyForTraceID(id, int(b.meta.BloomShardCount))
-	blockID := b.meta.BlockID
-	tenantID := b.meta.TenantID
+	filter, err := b.Bloom(ctx, shardKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.Test(id) {
+		return nil, nil
+	}
+
+	objectBytes, err := b.FindWithoutBloom(ctx, id)
+	return objectBytes, err
+}
 
-	nameBloom := bloomName(shardKey)
-	bloomBytes, err := b.reader.Read(ctx, nameBloom, blockID, tenantID, true)
+// Bloom reads the bloom filter shard with the given key from the backend.
+func (b *BackendBlock) Bloom(ctx context.Context, shardKey int) (*willf_bloom.BloomFilter, error) {
+	bloomBytes, err := b.reader.Read(ctx, bloomName(shardKey), b.meta.BlockID, b.meta.TenantID, true)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving bloom (%s, %s): %w", b.meta.TenantID, b.meta.BlockID, err)
 	}
@@ -64,10 +75,12 @@ func (b *BackendBlock) Find(ctx context.Context, id common.ID) ([]byte, error) {
 		return nil, fmt.Errorf("error parsing bloom (%s, %s): %w", b.meta.TenantID, b.meta.BlockID, err)
 	}
 
-	if !filter.Test(id) {
-		return nil, nil
-	}
+	return filter, nil
+}
 
+// FindWithoutBloom searches the index and objects of the block for the ID without testing the bloom filter first.
+//  Callers are expected to have tested the bloom filter themselves.
+func (b *BackendBlock) FindWithoutBloom(ctx context.Context, id common.ID) ([]byte, error) {
 	indexReaderAt := backend.NewContextReader(b.meta, nameIndex, b.reader, false)
 	indexReader, err := b.encoding.NewIndexReader(indexReaderAt, int(b.meta.IndexPageSize), int(b.meta.TotalRecords))
 	if err != nil {
@@ -92,6 +105,68 @@ func (b *BackendBlock) Find(ctx context.Context, id common.ID) ([]byte, error) {
 	return objectBytes, nil
 }
 
+// FindMany searches the block for all of the IDs and returns the objects in the order of the IDs, nil for
+//  the ones that weren't found. Each bloom shard and index page is read at most once.
+func (b *BackendBlock) FindMany(ctx context.Context, ids []common.ID) ([][]byte, error) {
+	var err error
+	span, ctx := opentracing.StartSpanFromContext(ctx, "BackendBlock.FindMany")
+	defer func() {
+		if err != nil {
+			span.SetTag("error", true)
+		}
+		span.Finish()
+	}()
+
+	span.SetTag("block", b.meta.BlockID.String())
+	span.SetTag("ids", len(ids))
+
+	filters := map[int]*willf_bloom.BloomFilter{}
+	candidates := make([]int, 0, len(ids))
+	for i, id := range ids {
+		shardKey := common.ShardKeyForTraceID(id, int(b.meta.BloomShardCount))
+		filter, ok := filters[shardKey]
+		if !ok {
+			filter, err = b.Bloom(ctx, shardKey)
+			if err != nil {
+				return nil, err
+			}
+			filters[shardKey] = filter
+		}
+
+		if filter.Test(id) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	objects := make([][]byte, len(ids))
+	if len(candidates) == 0 {
+		return objects, nil
+	}
+
+	// the index reader caches the pages it read, sharing it between the ids reads every page once
+	indexReader, err := b.NewIndexReader()
+	if err != nil {
+		return nil, err
+	}
+
+	ra := backend.NewContextReader(b.meta, nameObjects, b.reader, false)
+	dataReader, err := b.encoding.NewDataReader(ra, b.meta.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("error building page reader (%s, %s): %w", b.meta.TenantID, b.meta.BlockID, err)
+	}
+	defer dataReader.Close()
+
+	finder := NewPagedFinder(indexReader, dataReader, nil, b.encoding.NewObjectReaderWriter(), b.meta.DataEncoding)
+	for _, i := range candidates {
+		objects[i], err = finder.Find(ctx, ids[i])
+		if err != nil {
+			return nil, fmt.Errorf("error using pageFinder (%s, %s): %w", b.meta.TenantID, b.meta.BlockID, err)
+		}
+	}
+
+	return objects, nil
+}
+
 // Iterator returns an Iterator that iterates over the objects in the block from the backend
 func (b *BackendBlock) Iterator(chunkSizeBytes uint32) (Iterator, error) {
 	// read index
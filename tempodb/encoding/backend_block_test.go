@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/grafana/tempo/tempodb/backend"
 	"github.com/grafana/tempo/tempodb/backend/local"
+	"github.com/grafana/tempo/tempodb/encoding/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -66,6 +67,18 @@ func testLegacyBlock(t *testing.T, ids [][]byte, objs [][]byte, meta *backend.Bl
 		assert.Equal(t, objs[i], foundBytes)
 	}
 
+	// test FindMany, with an id that isn't in the block
+	missingID := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	findIDs := []common.ID{missingID}
+	for _, id := range ids {
+		findIDs = append(findIDs, id)
+	}
+	foundObjs, err := backendBlock.FindMany(context.Background(), findIDs)
+	require.NoError(t, err)
+	require.Len(t, foundObjs, len(ids)+1)
+	assert.Nil(t, foundObjs[0])
+	assert.Equal(t, objs, foundObjs[1:])
+
 	// test Iterator
 	iterator, err := backendBlock.Iterator(10)
 	require.NoError(t, err, "error getting iterator")
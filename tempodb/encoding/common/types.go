@@ -22,6 +22,9 @@ type Record struct {
 	Length uint32
 }
 
+// ObjectFilter returns true if the object with the given id should be kept
+type ObjectFilter func(id ID) bool
+
 // ObjectCombiner is used to combine two objects in the backend
 type ObjectCombiner interface {
 	// Combine objects encoded using dataEncoding. The returned object must
@@ -82,6 +82,7 @@ func NewBackendSearchBlock(input *StreamingSearchBlock, l *local.Backend, blockI
 			TraceID:           id,
 			StartTimeUnixNano: s.StartTimeUnixNano(),
 			EndTimeUnixNano:   s.EndTimeUnixNano(),
+			SpanCount:         s.SpanCount(),
 		}
 
 		for i, l := 0, s.TagsLength(); i < l; i++ {
@@ -140,6 +141,27 @@ func OpenBackendSearchBlock(l *local.Backend, blockID uuid.UUID, tenantID string
 	}
 }
 
+// Header returns the header of the block, which contains every tag and value in the block.
+func (s *BackendSearchBlock) Header(ctx context.Context) (*tempofb.SearchBlockHeader, error) {
+	header, _, err := s.readHeader(ctx)
+	return header, err
+}
+
+func (s *BackendSearchBlock) readHeader(ctx context.Context) (*tempofb.SearchBlockHeader, int64, error) {
+	hbr, hbrlen, err := s.l.Read(ctx, "search-header", backend.KeyPathForBlock(s.id, s.tenantID), true)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer hbr.Close()
+
+	hb, err := tempo_io.ReadAllWithEstimate(hbr, hbrlen)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tempofb.GetRootAsSearchBlockHeader(hb, 0), hbrlen, nil
+}
+
 // Search iterates through the block looking for matches.
 func (s *BackendSearchBlock) Search(ctx context.Context, p Pipeline, sr *Results) error {
 	var pageBuf []byte
@@ -160,19 +182,13 @@ func (s *BackendSearchBlock) Search(ctx context.Context, p Pipeline, sr *Results
 
 	// Read header
 	// Verify something in the block matches by checking the header
-	hbr, hbrlen, err := s.l.Read(ctx, "search-header", backend.KeyPathForBlock(s.id, s.tenantID), true)
+	header, hbrlen, err := s.readHeader(ctx)
 	if err != nil {
 		return err
 	}
 
 	sr.bytesInspected.Add(uint64(hbrlen))
 
-	hb, err := tempo_io.ReadAllWithEstimate(hbr, hbrlen)
-	if err != nil {
-		return err
-	}
-
-	header := tempofb.GetRootAsSearchBlockHeader(hb, 0)
 	if !p.MatchesBlock(header) {
 		// Block filtered out
 		sr.AddBlockSkipped()
@@ -242,6 +258,7 @@ func (s *BackendSearchBlock) Search(ctx context.Context, p Pipeline, sr *Results
 			}
 
 			// If we got here then it's a match.
+			sr.Match(entry)
 			match := GetSearchResultFromData(entry)
 
 			if quit := sr.AddResult(ctx, match); quit {
@@ -35,6 +35,8 @@ func (*DataCombiner) Combine(_ string, searchData ...[]byte) ([]byte, bool) {
 
 		data.SetStartTimeUnixNano(sd.StartTimeUnixNano())
 		data.SetEndTimeUnixNano(sd.EndTimeUnixNano())
+		// every segment has different spans of the trace
+		data.SpanCount += sd.SpanCount()
 		data.TraceID = sd.Id()
 	}
 
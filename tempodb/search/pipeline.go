@@ -53,6 +53,15 @@ func NewSearchPipeline(req *tempopb.SearchRequest) Pipeline {
 		})
 	}
 
+	if req.Start > 0 || req.End > 0 {
+		startNanos := uint64(time.Duration(req.Start) * time.Second)
+		endNanos := uint64(time.Duration(req.End) * time.Second)
+
+		p.tracefilters = append(p.tracefilters, func(s *tempofb.SearchEntry) bool {
+			return s.EndTimeUnixNano() >= startNanos && (endNanos == 0 || s.StartTimeUnixNano() <= endNanos)
+		})
+	}
+
 	if len(req.Tags) > 0 {
 		// Convert all search params to bytes once
 		kb := make([][]byte, 0, len(req.Tags))
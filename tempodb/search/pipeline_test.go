@@ -133,6 +133,36 @@ func TestPipelineMatchesTraceDuration(t *testing.T) {
 	}
 }
 
+func TestPipelineMatchesTimeRange(t *testing.T) {
+	testCases := []struct {
+		name        string
+		start, end  uint32
+		shouldMatch bool
+	}{
+		{name: "no filtering", shouldMatch: true},
+		{name: "within", start: 90, end: 200, shouldMatch: true},
+		{name: "overlaps start", start: 105, end: 200, shouldMatch: true},
+		{name: "overlaps end", start: 50, end: 105, shouldMatch: true},
+		{name: "before", start: 50, end: 90, shouldMatch: false},
+		{name: "after", start: 120, end: 200, shouldMatch: false},
+		{name: "open end", start: 105, shouldMatch: true},
+		{name: "open start", end: 90, shouldMatch: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewSearchPipeline(&tempopb.SearchRequest{Start: tc.start, End: tc.end})
+			data := tempofb.SearchEntryMutable{
+				StartTimeUnixNano: uint64(100 * time.Second),
+				EndTimeUnixNano:   uint64(110 * time.Second),
+			}
+			sd := tempofb.SearchEntryFromBytes(data.ToBytes())
+
+			require.Equal(t, tc.shouldMatch, p.Matches(sd))
+		})
+	}
+}
+
 func TestPipelineMatchesBlock(t *testing.T) {
 
 	// Run all tests against this header
@@ -3,6 +3,7 @@ package search
 import (
 	"context"
 
+	"github.com/grafana/tempo/pkg/tempofb"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"go.uber.org/atomic"
 )
@@ -21,6 +22,11 @@ type Results struct {
 	bytesInspected  atomic.Uint64
 	blocksInspected atomic.Uint32
 	blocksSkipped   atomic.Uint32
+
+	maxBytesInspected uint64
+	bytesLimitReached atomic.Bool
+
+	matchFunc func(entry *tempofb.SearchEntry)
 }
 
 func NewResults() *Results {
@@ -116,7 +122,36 @@ func (sr *Results) BytesInspected() uint64 {
 }
 
 func (sr *Results) AddBytesInspected(c uint64) {
-	sr.bytesInspected.Add(c)
+	total := sr.bytesInspected.Add(c)
+	if sr.maxBytesInspected > 0 && total > sr.maxBytesInspected {
+		// stop the workers but keep the results found so far flowing to the receiver
+		sr.bytesLimitReached.Store(true)
+		sr.quit.Store(true)
+	}
+}
+
+// SetMaxBytesInspected makes the workers quit once more than max bytes have been inspected. 0 is unlimited.
+// Must be called before any worker is started.
+func (sr *Results) SetMaxBytesInspected(max uint64) {
+	sr.maxBytesInspected = max
+}
+
+// SetMatchFunc sets a function the workers call with the search data of every match before sending it as a result.
+// It is called concurrently, and the entry is only valid during the call. Must be called before any worker is started.
+func (sr *Results) SetMatchFunc(f func(entry *tempofb.SearchEntry)) {
+	sr.matchFunc = f
+}
+
+// Match passes the search data of a match to the match func, if any
+func (sr *Results) Match(entry *tempofb.SearchEntry) {
+	if sr.matchFunc != nil {
+		sr.matchFunc(entry)
+	}
+}
+
+// BytesLimitReached returns true if the workers quit because of the max bytes inspected
+func (sr *Results) BytesLimitReached() bool {
+	return sr.bytesLimitReached.Load()
 }
 
 func (sr *Results) AddBlockInspected() {
@@ -1,4 +1,4 @@
-package distributor
+package search
 
 import (
 	"testing"
@@ -10,7 +10,6 @@ import (
 	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
 	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
 	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
-	"github.com/grafana/tempo/tempodb/search"
 )
 
 func TestExtractSearchData(t *testing.T) {
@@ -63,22 +62,23 @@ func TestExtractSearchData(t *testing.T) {
 			searchData: &tempofb.SearchEntryMutable{
 				TraceID: traceIDA,
 				Tags: tempofb.SearchDataMap{
-					"foo":                         []string{"bar"},
-					search.RootSpanPrefix + "foo": []string{"bar"},
-					search.RootSpanNameTag:        []string{"firstSpan"},
-					search.SpanNameTag:            []string{"firstSpan"},
-					search.RootServiceNameTag:     []string{"baz"},
-					search.ServiceNameTag:         []string{"baz"},
+					"foo":                  []string{"bar"},
+					RootSpanPrefix + "foo": []string{"bar"},
+					RootSpanNameTag:        []string{"firstSpan"},
+					SpanNameTag:            []string{"firstSpan"},
+					RootServiceNameTag:     []string{"baz"},
+					ServiceNameTag:         []string{"baz"},
 				},
 				StartTimeUnixNano: 0,
 				EndTimeUnixNano:   0,
+				SpanCount:         1,
 			},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			assert.Equal(t, tc.searchData.ToBytes(), extractSearchData(tc.trace, tc.id))
+			assert.Equal(t, tc.searchData.ToBytes(), ExtractSearchData(tc.trace, tc.id))
 		})
 	}
 }
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+// DefaultLimit is the number of results of a search without a limit
+const DefaultLimit = 20
+
+// ErrInvalidContinuationToken is returned for continuation tokens that weren't returned by a search with the same sort
+var ErrInvalidContinuationToken = errors.New("invalid continuation token")
+
+// sortKey is the position of a result in a sorted search. Results with the same value are sorted by trace ID, so
+// the order is the same on every page. In a continuation token, Offset is the number of results of the pages before.
+type sortKey struct {
+	Sort    tempopb.SearchSort `json:"s"`
+	Value   uint64             `json:"v"`
+	TraceID string             `json:"t"`
+	Offset  int                `json:"o,omitempty"`
+}
+
+func keyOf(s tempopb.SearchSort, r *tempopb.TraceSearchMetadata) sortKey {
+	key := sortKey{Sort: s, TraceID: r.TraceID}
+	switch s {
+	case tempopb.SearchSort_DURATION_DESC, tempopb.SearchSort_DURATION_ASC:
+		key.Value = uint64(r.DurationMs)
+	case tempopb.SearchSort_SPAN_COUNT_DESC, tempopb.SearchSort_SPAN_COUNT_ASC:
+		key.Value = uint64(r.SpanCount)
+	default:
+		key.Value = r.StartTimeUnixNano
+	}
+	return key
+}
+
+// before returns true if a is sorted before b
+func (a sortKey) before(b sortKey) bool {
+	if a.Value != b.Value {
+		switch a.Sort {
+		case tempopb.SearchSort_START_TIME_ASC, tempopb.SearchSort_DURATION_ASC, tempopb.SearchSort_SPAN_COUNT_ASC:
+			return a.Value < b.Value
+		default:
+			return a.Value > b.Value
+		}
+	}
+	return a.TraceID < b.TraceID
+}
+
+func (a sortKey) token() string {
+	b, _ := json.Marshal(a)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeToken(token string) (sortKey, error) {
+	key := sortKey{}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return key, fmt.Errorf("%w: %v", ErrInvalidContinuationToken, err)
+	}
+	err = json.Unmarshal(b, &key)
+	if err != nil || key.Sort == tempopb.SearchSort_FIRST_FOUND {
+		return key, fmt.Errorf("%w %s", ErrInvalidContinuationToken, token)
+	}
+	return key, nil
+}
+
+// ResolveSort sets the sort of a request with a continuation token and no sort to the sort of the token. It returns
+// an error if the token is invalid or was returned by a search with a different sort.
+func ResolveSort(req *tempopb.SearchRequest) error {
+	if req.ContinuationToken == "" {
+		return nil
+	}
+
+	key, err := decodeToken(req.ContinuationToken)
+	if err != nil {
+		return err
+	}
+	if req.Sort == tempopb.SearchSort_FIRST_FOUND {
+		req.Sort = key.Sort
+	}
+	if req.Sort != key.Sort {
+		return fmt.Errorf("%w: token of a search sorted by %s", ErrInvalidContinuationToken, key.Sort)
+	}
+	return nil
+}
+
+// SourceRequest returns the request to send to each source of a search, e.g. the ingesters. The same trace can have
+// different values in different sources, like replicas that didn't receive every span, so a source can't tell which
+// of its results were on the pages before. Sorted searches ask the sources for their first results up to the end of
+// the page instead, and the continuation token is applied once they are combined by SortAndPage.
+func SourceRequest(req *tempopb.SearchRequest) (*tempopb.SearchRequest, error) {
+	if req.Sort == tempopb.SearchSort_FIRST_FOUND && req.ContinuationToken == "" {
+		return req, nil
+	}
+
+	source := *req
+	err := ResolveSort(&source)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if source.ContinuationToken != "" {
+		after, err := decodeToken(source.ContinuationToken)
+		if err != nil {
+			return nil, err
+		}
+		offset = after.Offset
+	}
+	if source.Limit == 0 {
+		source.Limit = DefaultLimit
+	}
+	source.Limit += uint32(offset)
+	source.ContinuationToken = ""
+
+	return &source, nil
+}
+
+// SortAndPage sorts the results in the order of the request and returns the page after its continuation token, along
+// with the token of the next page. Pages of sorted searches have the request limit, or DefaultLimit if it has none.
+// more is true if the results are themselves a page with more after them, e.g. the results of the ingesters of a
+// search. The token is empty for the last page and for unsorted searches, which are only limited if the request has
+// a limit. Results of the same trace from different sources are deduped, sorted searches keep the one that is sorted
+// first: it is within the results its source returned for the SourceRequest.
+func SortAndPage(req *tempopb.SearchRequest, results []*tempopb.TraceSearchMetadata, more bool) ([]*tempopb.TraceSearchMetadata, string, error) {
+	limit := int(req.Limit)
+
+	if req.Sort != tempopb.SearchSort_FIRST_FOUND || req.ContinuationToken != "" {
+		sorted := *req
+		err := ResolveSort(&sorted)
+		if err != nil {
+			return nil, "", err
+		}
+		req = &sorted
+
+		if limit == 0 {
+			limit = DefaultLimit
+		}
+	}
+
+	results = dedupeResults(req.Sort, results)
+
+	offset := 0
+	if req.ContinuationToken != "" {
+		after, err := decodeToken(req.ContinuationToken)
+		if err != nil {
+			return nil, "", err
+		}
+		offset = after.Offset
+
+		page := results[:0:0]
+		for _, r := range results {
+			if after.before(keyOf(req.Sort, r)) {
+				page = append(page, r)
+			}
+		}
+		results = page
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return keyOf(req.Sort, results[i]).before(keyOf(req.Sort, results[j]))
+	})
+
+	if limit != 0 && len(results) > limit {
+		results = results[:limit]
+		more = true
+	}
+
+	if req.Sort == tempopb.SearchSort_FIRST_FOUND || !more || len(results) == 0 {
+		return results, "", nil
+	}
+	next := keyOf(req.Sort, results[len(results)-1])
+	next.Offset = offset + len(results)
+	return results, next.token(), nil
+}
+
+// dedupeResults keeps the first result of each trace, or the one sorted first if the search is sorted
+func dedupeResults(s tempopb.SearchSort, results []*tempopb.TraceSearchMetadata) []*tempopb.TraceSearchMetadata {
+	deduped := results[:0:0]
+	positions := make(map[string]int, len(results))
+	for _, r := range results {
+		i, ok := positions[r.TraceID]
+		if !ok {
+			positions[r.TraceID] = len(deduped)
+			deduped = append(deduped, r)
+			continue
+		}
+		if s != tempopb.SearchSort_FIRST_FOUND && keyOf(s, r).before(keyOf(s, deduped[i])) {
+			deduped[i] = r
+		}
+	}
+	return deduped
+}
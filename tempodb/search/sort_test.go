@@ -0,0 +1,196 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+func TestSortAndPage(t *testing.T) {
+	// values repeat so pages have to break ties by trace id
+	var results []*tempopb.TraceSearchMetadata
+	for i := 0; i < 10; i++ {
+		results = append(results, &tempopb.TraceSearchMetadata{
+			TraceID:           fmt.Sprintf("%02d", i),
+			StartTimeUnixNano: uint64(i % 4),
+			DurationMs:        uint32(i % 3),
+			SpanCount:         uint32(9 - i),
+		})
+	}
+
+	tests := []struct {
+		sort     tempopb.SearchSort
+		expected []string
+	}{
+		{sort: tempopb.SearchSort_START_TIME_DESC, expected: []string{"03", "07", "02", "06", "01", "05", "09", "00", "04", "08"}},
+		{sort: tempopb.SearchSort_START_TIME_ASC, expected: []string{"00", "04", "08", "01", "05", "09", "02", "06", "03", "07"}},
+		{sort: tempopb.SearchSort_DURATION_DESC, expected: []string{"02", "05", "08", "01", "04", "07", "00", "03", "06", "09"}},
+		{sort: tempopb.SearchSort_DURATION_ASC, expected: []string{"00", "03", "06", "09", "01", "04", "07", "02", "05", "08"}},
+		{sort: tempopb.SearchSort_SPAN_COUNT_DESC, expected: []string{"00", "01", "02", "03", "04", "05", "06", "07", "08", "09"}},
+		{sort: tempopb.SearchSort_SPAN_COUNT_ASC, expected: []string{"09", "08", "07", "06", "05", "04", "03", "02", "01", "00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			req := &tempopb.SearchRequest{Sort: tt.sort, Limit: 3}
+
+			var actual []string
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 4)
+
+				// the results are shuffled by every search
+				shuffled := append([]*tempopb.TraceSearchMetadata{}, results[pages:]...)
+				shuffled = append(shuffled, results[:pages]...)
+
+				page, token, err := SortAndPage(req, shuffled, false)
+				require.NoError(t, err)
+				for _, r := range page {
+					actual = append(actual, r.TraceID)
+				}
+				if token == "" {
+					break
+				}
+
+				// the sort is taken from the token
+				req = &tempopb.SearchRequest{ContinuationToken: token, Limit: 3}
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSortAndPageReplicas(t *testing.T) {
+	// two replicas of the same traces, with trace 05 missing spans in the first one and traces only one of them has
+	replica := func(spanCounts map[string]uint32) []*tempopb.TraceSearchMetadata {
+		var results []*tempopb.TraceSearchMetadata
+		for id, spanCount := range spanCounts {
+			results = append(results, &tempopb.TraceSearchMetadata{TraceID: id, SpanCount: spanCount})
+		}
+		return results
+	}
+	replicas := [][]*tempopb.TraceSearchMetadata{
+		replica(map[string]uint32{"00": 9, "01": 8, "02": 7, "03": 6, "04": 5, "05": 2, "06": 3}),
+		replica(map[string]uint32{"00": 9, "01": 8, "02": 7, "03": 6, "04": 5, "05": 8, "07": 1}),
+	}
+
+	tests := []struct {
+		sort     tempopb.SearchSort
+		expected []string
+	}{
+		{sort: tempopb.SearchSort_SPAN_COUNT_DESC, expected: []string{"00", "01", "05", "02", "03", "04", "06", "07"}},
+		{sort: tempopb.SearchSort_SPAN_COUNT_ASC, expected: []string{"07", "05", "06", "04", "03", "02", "01", "00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			req := &tempopb.SearchRequest{Sort: tt.sort, Limit: 2}
+
+			var actual []string
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 5)
+
+				// like the querier, combine the results of every replica for the request
+				source, err := SourceRequest(req)
+				require.NoError(t, err)
+				var combined []*tempopb.TraceSearchMetadata
+				more := false
+				for _, results := range replicas {
+					page, token, err := SortAndPage(source, append([]*tempopb.TraceSearchMetadata{}, results...), false)
+					require.NoError(t, err)
+					combined = append(combined, page...)
+					more = more || token != ""
+				}
+
+				page, token, err := SortAndPage(req, combined, more)
+				require.NoError(t, err)
+				for _, r := range page {
+					actual = append(actual, r.TraceID)
+				}
+				if token == "" {
+					break
+				}
+				req = &tempopb.SearchRequest{ContinuationToken: token, Limit: 2}
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSourceRequest(t *testing.T) {
+	req := &tempopb.SearchRequest{Tags: map[string]string{"foo": "bar"}, Limit: 5}
+	source, err := SourceRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, req, source)
+
+	token := sortKey{Sort: tempopb.SearchSort_DURATION_ASC, Value: 10, TraceID: "1", Offset: 10}.token()
+	source, err = SourceRequest(&tempopb.SearchRequest{Tags: map[string]string{"foo": "bar"}, ContinuationToken: token})
+	require.NoError(t, err)
+	assert.Equal(t, &tempopb.SearchRequest{Tags: map[string]string{"foo": "bar"}, Sort: tempopb.SearchSort_DURATION_ASC, Limit: DefaultLimit + 10}, source)
+
+	_, err = SourceRequest(&tempopb.SearchRequest{ContinuationToken: "!!"})
+	assert.True(t, errors.Is(err, ErrInvalidContinuationToken))
+}
+
+func TestSortAndPageUnsorted(t *testing.T) {
+	results := []*tempopb.TraceSearchMetadata{
+		{TraceID: "1", StartTimeUnixNano: 1},
+		{TraceID: "2", StartTimeUnixNano: 2},
+		{TraceID: "3", StartTimeUnixNano: 3},
+	}
+
+	page, token, err := SortAndPage(&tempopb.SearchRequest{}, results, true)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+	assert.Len(t, page, 3)
+	assert.Equal(t, "3", page[0].TraceID)
+
+	page, token, err = SortAndPage(&tempopb.SearchRequest{Limit: 2}, results, false)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+	assert.Len(t, page, 2)
+}
+
+func TestSortAndPageMore(t *testing.T) {
+	results := []*tempopb.TraceSearchMetadata{
+		{TraceID: "1", StartTimeUnixNano: 1},
+		{TraceID: "2", StartTimeUnixNano: 2},
+	}
+	req := &tempopb.SearchRequest{Sort: tempopb.SearchSort_START_TIME_ASC, Limit: 5}
+
+	_, token, err := SortAndPage(req, results, false)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+
+	// a page of the results of an ingester can have less than the limit after combining, but there is more
+	_, token, err = SortAndPage(req, results, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	page, _, err := SortAndPage(&tempopb.SearchRequest{ContinuationToken: token}, append(results, &tempopb.TraceSearchMetadata{TraceID: "3", StartTimeUnixNano: 3}), false)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "3", page[0].TraceID)
+}
+
+func TestResolveSort(t *testing.T) {
+	token := sortKey{Sort: tempopb.SearchSort_DURATION_ASC, Value: 10, TraceID: "1"}.token()
+
+	req := &tempopb.SearchRequest{ContinuationToken: token}
+	require.NoError(t, ResolveSort(req))
+	assert.Equal(t, tempopb.SearchSort_DURATION_ASC, req.Sort)
+
+	req = &tempopb.SearchRequest{Sort: tempopb.SearchSort_DURATION_DESC, ContinuationToken: token}
+	assert.True(t, errors.Is(ResolveSort(req), ErrInvalidContinuationToken))
+
+	for _, invalid := range []string{"!!", "e30", sortKey{TraceID: "1"}.token()} {
+		req = &tempopb.SearchRequest{ContinuationToken: invalid}
+		assert.True(t, errors.Is(ResolveSort(req), ErrInvalidContinuationToken), invalid)
+	}
+
+	assert.NoError(t, ResolveSort(&tempopb.SearchRequest{}))
+}
@@ -113,6 +113,7 @@ func (s *StreamingSearchBlock) Search(ctx context.Context, p Pipeline, sr *Resul
 		}
 
 		// If we got here then it's a match.
+		sr.Match(entry)
 		match := GetSearchResultFromData(entry)
 
 		if quit := sr.AddResult(ctx, match); quit {
@@ -10,22 +10,38 @@ import (
 )
 
 type CacheEntry struct {
-	values map[string]int64 // value -> unix timestamp
+	values  map[string]int64 // value -> unix timestamp
+	evicted int              // number of values pruned to stay within maxValuesPerTag
 }
 
-const maxValuesPerTag = 50
+// DefaultMaxValuesPerTag is the number of values kept per tag if no other maximum is set.
+const DefaultMaxValuesPerTag = 50
 
 type TagCache struct {
-	lookups map[string]*CacheEntry
-	mtx     sync.RWMutex
+	lookups         map[string]*CacheEntry
+	maxValuesPerTag int
+	mtx             sync.RWMutex
 }
 
 func NewTagCache() *TagCache {
 	return &TagCache{
-		lookups: map[string]*CacheEntry{},
+		lookups:         map[string]*CacheEntry{},
+		maxValuesPerTag: DefaultMaxValuesPerTag,
 	}
 }
 
+// SetMaxValuesPerTag sets the number of values kept per tag. The oldest values of a tag are pruned once exceeded and
+// the tag is reported as high cardinality. Values <= 0 use DefaultMaxValuesPerTag.
+func (s *TagCache) SetMaxValuesPerTag(max int) {
+	if max <= 0 {
+		max = DefaultMaxValuesPerTag
+	}
+
+	s.mtx.Lock()
+	s.maxValuesPerTag = max
+	s.mtx.Unlock()
+}
+
 func (s *TagCache) GetNames() []string {
 	s.mtx.RLock()
 	tags := make([]string, 0, len(s.lookups))
@@ -54,7 +70,54 @@ func (s *TagCache) GetValues(tagName string) []string {
 	return vals
 }
 
+// Cardinality returns the number of values seen for the tag since it was added to the cache. Values pruned to stay
+// within the maximum are counted once per time they were pruned.
+func (s *TagCache) Cardinality(tagName string) int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	if e := s.lookups[tagName]; e != nil {
+		return len(e.values) + e.evicted
+	}
+	return 0
+}
+
+// IsHighCardinality returns true if the tag has more values than are kept, i.e. GetValues only returns the most
+// recent ones.
+func (s *TagCache) IsHighCardinality(tagName string) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	e := s.lookups[tagName]
+	return e != nil && e.evicted > 0
+}
+
+// GetHighCardinalityNames returns the sorted names of all high cardinality tags.
+func (s *TagCache) GetHighCardinalityNames() []string {
+	var tags []string
+
+	s.mtx.RLock()
+	for k, e := range s.lookups {
+		if e.evicted > 0 {
+			tags = append(tags, k)
+		}
+	}
+	s.mtx.RUnlock()
+
+	sort.Strings(tags)
+	return tags
+}
+
 func (s *TagCache) SetData(ts time.Time, data *tempofb.SearchEntry) {
+	s.setTags(ts, data)
+}
+
+// SetHeader adds the tags of a search block, e.g. to rebuild the cache from the blocks on disk.
+func (s *TagCache) SetHeader(ts time.Time, header *tempofb.SearchBlockHeader) {
+	s.setTags(ts, header)
+}
+
+func (s *TagCache) setTags(ts time.Time, data tempofb.TagContainer) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
@@ -81,8 +144,13 @@ func (s *TagCache) setEntry(ts int64, k, v string) {
 		return
 	}
 
+	if _, ok := e.values[v]; ok {
+		e.values[v] = ts
+		return
+	}
+
 	// Prune oldest as needed
-	for len(e.values) >= maxValuesPerTag {
+	for len(e.values) >= s.maxValuesPerTag {
 		earliestv := ""
 		earliestts := int64(math.MaxInt64)
 
@@ -94,6 +162,7 @@ func (s *TagCache) setEntry(ts int64, k, v string) {
 		}
 
 		delete(e.values, earliestv)
+		e.evicted++
 	}
 
 	e.values[v] = ts
@@ -115,6 +184,12 @@ func (s *TagCache) PurgeExpired(before time.Time) {
 		// Remove tags when all values deleted
 		if len(e.values) <= 0 {
 			delete(s.lookups, k)
+			continue
+		}
+
+		// Tags are not high cardinality anymore once the remaining values fit
+		if len(e.values) < s.maxValuesPerTag {
+			e.evicted = 0
 		}
 	}
 }
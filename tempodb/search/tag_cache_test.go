@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/pkg/tempofb"
 )
 
 func TestSearchTagCacheGetNames(t *testing.T) {
@@ -19,15 +21,59 @@ func TestSearchTagCacheGetNames(t *testing.T) {
 func TestSearchTagCacheMaxValuesPerTag(t *testing.T) {
 	c := NewTagCache()
 
-	for i := 0; i < maxValuesPerTag+1; i++ {
+	for i := 0; i < DefaultMaxValuesPerTag+1; i++ {
 		c.setEntry(int64(i), "k", fmt.Sprintf("v%02d", i))
 	}
 
 	vals := c.GetValues("k")
 
-	require.Len(t, vals, maxValuesPerTag)
+	require.Len(t, vals, DefaultMaxValuesPerTag)
 	require.Equal(t, "v01", vals[0]) // oldest v0 was evicted
-	require.Equal(t, fmt.Sprintf("v%02d", maxValuesPerTag), vals[maxValuesPerTag-1])
+	require.Equal(t, fmt.Sprintf("v%02d", DefaultMaxValuesPerTag), vals[DefaultMaxValuesPerTag-1])
+}
+
+func TestSearchTagCacheHighCardinality(t *testing.T) {
+	c := NewTagCache()
+	c.SetMaxValuesPerTag(2)
+
+	c.setEntry(0, "k", "v0")
+	c.setEntry(1, "k", "v1")
+	c.setEntry(2, "k", "v1") // existing values don't evict others
+	c.setEntry(3, "j", "v0")
+	require.False(t, c.IsHighCardinality("k"))
+	require.Equal(t, 2, c.Cardinality("k"))
+
+	c.setEntry(4, "k", "v2")
+	require.Equal(t, []string{"v1", "v2"}, c.GetValues("k"))
+	require.True(t, c.IsHighCardinality("k"))
+	require.False(t, c.IsHighCardinality("j"))
+	require.Equal(t, 3, c.Cardinality("k"))
+	require.Equal(t, []string{"k"}, c.GetHighCardinalityNames())
+
+	// not high cardinality anymore once the remaining values fit
+	c.PurgeExpired(time.Unix(4, 0))
+	require.Equal(t, []string{"v2"}, c.GetValues("k"))
+	require.False(t, c.IsHighCardinality("k"))
+	require.Empty(t, c.GetHighCardinalityNames())
+
+	// the default is used for values <= 0
+	c.SetMaxValuesPerTag(0)
+	require.Equal(t, DefaultMaxValuesPerTag, c.maxValuesPerTag)
+}
+
+func TestSearchTagCacheSetHeader(t *testing.T) {
+	b := tempofb.NewSearchBlockHeaderBuilder()
+	b.AddTag("k1", "v1")
+	b.AddTag("k1", "v2")
+	b.AddTag("k2", "v3")
+	header := tempofb.GetRootAsSearchBlockHeader(b.ToBytes(), 0)
+
+	c := NewTagCache()
+	c.SetHeader(time.Now(), header)
+
+	require.Equal(t, []string{"k1", "k2"}, c.GetNames())
+	require.Equal(t, []string{"v1", "v2"}, c.GetValues("k1"))
+	require.Equal(t, []string{"v3"}, c.GetValues("k2"))
 }
 
 func TestSearchTagCachePurge(t *testing.T) {
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/grafana/tempo/pkg/tempofb"
+)
+
+// TagValues collects the values of a tag in the search data of the matches of a search, and the traces they were
+// found in. A trace whose search data is split in several entries is only counted once per value.
+type TagValues struct {
+	tagName string
+
+	mtx    sync.Mutex
+	traces map[string]map[string]struct{} // trace ids by value
+}
+
+// NewTagValues returns a TagValues collecting the values of the tag
+func NewTagValues(tagName string) *TagValues {
+	return &TagValues{
+		tagName: tagName,
+		traces:  map[string]map[string]struct{}{},
+	}
+}
+
+// Collect adds the values of the tag in the entry. It can be used as the match func of Results.
+func (t *TagValues) Collect(entry *tempofb.SearchEntry) {
+	values := entry.GetAll(t.tagName)
+	if len(values) == 0 {
+		return
+	}
+	traceID := string(entry.Id())
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, v := range values {
+		traces, ok := t.traces[v]
+		if !ok {
+			traces = map[string]struct{}{}
+			t.traces[v] = traces
+		}
+		traces[traceID] = struct{}{}
+	}
+}
+
+// Counts returns the number of traces with each value, keeping only the max values in the most traces. Values <= 0
+// use DefaultMaxValuesPerTag. dropped is true if there were more values.
+func (t *TagValues) Counts(max int) (counts map[string]uint32, dropped bool) {
+	if max <= 0 {
+		max = DefaultMaxValuesPerTag
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	values := make([]string, 0, len(t.traces))
+	for v := range t.traces {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		ci, cj := len(t.traces[values[i]]), len(t.traces[values[j]])
+		if ci != cj {
+			return ci > cj
+		}
+		return values[i] < values[j]
+	})
+
+	if len(values) > max {
+		values = values[:max]
+		dropped = true
+	}
+
+	counts = make(map[string]uint32, len(values))
+	for _, v := range values {
+		counts[v] = uint32(len(t.traces[v]))
+	}
+	return counts, dropped
+}
@@ -0,0 +1,36 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/tempo/pkg/tempofb"
+)
+
+func TestTagValues(t *testing.T) {
+	entry := func(id string, values ...string) *tempofb.SearchEntry {
+		data := &tempofb.SearchEntryMutable{TraceID: []byte(id)}
+		data.AddTag("other", "x")
+		for _, v := range values {
+			data.AddTag("http.route", v)
+		}
+		return tempofb.SearchEntryFromBytes(data.ToBytes())
+	}
+
+	values := NewTagValues("http.route")
+	values.Collect(entry("1", "/a", "/b"))
+	// another entry of the same trace
+	values.Collect(entry("1", "/a"))
+	values.Collect(entry("2", "/a", "/c"))
+	values.Collect(entry("3"))
+
+	counts, dropped := values.Counts(0)
+	assert.False(t, dropped)
+	assert.Equal(t, map[string]uint32{"/a": 2, "/b": 1, "/c": 1}, counts)
+
+	// the values in the most traces are kept, ties by value
+	counts, dropped = values.Counts(2)
+	assert.True(t, dropped)
+	assert.Equal(t, map[string]uint32{"/a": 2, "/b": 1}, counts)
+}
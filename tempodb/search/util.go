@@ -21,6 +21,7 @@ func GetSearchResultFromData(s *tempofb.SearchEntry) *tempopb.TraceSearchMetadat
 		RootTraceName:     s.Get(RootSpanNameTag),
 		StartTimeUnixNano: s.StartTimeUnixNano(),
 		DurationMs:        uint32((s.EndTimeUnixNano() - s.StartTimeUnixNano()) / 1_000_000),
+		SpanCount:         s.SpanCount(),
 	}
 }
 
@@ -49,4 +50,7 @@ func CombineSearchResults(existing *tempopb.TraceSearchMetadata, incoming *tempo
 	if existing.DurationMs < incoming.DurationMs {
 		existing.DurationMs = incoming.DurationMs
 	}
+
+	// Spans of all segments
+	existing.SpanCount += incoming.SpanCount
 }
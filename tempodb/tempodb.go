@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -69,16 +70,26 @@ type Writer interface {
 	WriteBlock(ctx context.Context, block WriteableBlock) error
 	CompleteBlock(block *wal.AppendBlock, combiner common.ObjectCombiner) (*encoding.BackendBlock, error)
 	CompleteBlockWithBackend(ctx context.Context, block *wal.AppendBlock, combiner common.ObjectCombiner, r backend.Reader, w backend.Writer) (*encoding.BackendBlock, error)
+	WriteBlockWithFilter(ctx context.Context, block *wal.LocalBlock, filter common.ObjectFilter) (bool, error)
+	EnableBlockOverrides(overrides BlockOverrides)
 	WAL() *wal.WAL
 }
 
 type Reader interface {
-	Find(ctx context.Context, tenantID string, id common.ID, blockStart string, blockEnd string) ([][]byte, []string, error)
+	Find(ctx context.Context, tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) ([][]byte, []string, error)
+	CountBlocksForFind(tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) (int, error)
+	FindMany(ctx context.Context, tenantID string, ids []common.ID, timeStart int64, timeEnd int64) ([]FindManyResult, error)
 	EnablePolling(sharder blocklist.JobSharder)
 
 	Shutdown()
 }
 
+// FindManyResult holds the partial objects found for one of the IDs passed to FindMany and their data encodings
+type FindManyResult struct {
+	Objects       [][]byte
+	DataEncodings []string
+}
+
 type Compactor interface {
 	EnableCompaction(cfg *CompactorConfig, sharder CompactorSharder, overrides CompactorOverrides)
 }
@@ -89,9 +100,17 @@ type CompactorSharder interface {
 }
 
 type CompactorOverrides interface {
+	BlockOverrides
 	BlockRetentionForTenant(tenantID string) time.Duration
 }
 
+// BlockOverrides are the per-tenant settings of new backend blocks. Empty and 0 values fall back to the block config.
+type BlockOverrides interface {
+	BlockEncodingForTenant(tenantID string) string
+	BlockBloomFPForTenant(tenantID string) float64
+	BlockIndexPageSizeBytesForTenant(tenantID string) int
+}
+
 type WriteableBlock interface {
 	BlockMeta() *backend.BlockMeta
 	Write(ctx context.Context, w backend.Writer) error
@@ -114,6 +133,8 @@ type readerWriter struct {
 	blocklistPoller *blocklist.Poller
 	blocklist       *blocklist.List
 
+	blockOverrides BlockOverrides
+
 	compactorCfg          *CompactorConfig
 	compactorSharder      CompactorSharder
 	compactorOverrides    CompactorOverrides
@@ -155,9 +176,9 @@ func New(cfg *Config, logger log.Logger) (Reader, Writer, Compactor, error) {
 
 	switch cfg.Cache {
 	case "redis":
-		cacheBackend = redis.NewClient(cfg.Redis, cfg.BackgroundCache, logger)
+		cacheBackend = redis.NewClient("tempo", cfg.Redis, cfg.BackgroundCache, logger)
 	case "memcached":
-		cacheBackend = memcached.NewClient(cfg.Memcached, cfg.BackgroundCache, logger)
+		cacheBackend = memcached.NewClient("tempo", cfg.Memcached, cfg.BackgroundCache, logger)
 	}
 
 	if cacheBackend != nil {
@@ -202,28 +223,71 @@ func (rw *readerWriter) CompleteBlock(block *wal.AppendBlock, combiner common.Ob
 // CompleteBlock iterates the given WAL block but flushes it to the given backend instead of the default TempoDB backend. The
 // new block will have the same ID as the input block.
 func (rw *readerWriter) CompleteBlockWithBackend(ctx context.Context, block *wal.AppendBlock, combiner common.ObjectCombiner, r backend.Reader, w backend.Writer) (*encoding.BackendBlock, error) {
-	meta := block.Meta()
-	blockID := meta.BlockID
-	tenantID := meta.TenantID
+	iter, err := block.GetIterator(combiner)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting completing block iterator")
+	}
+	defer iter.Close()
+
+	newBlock, err := rw.writeStreamingBlock(ctx, block.Meta(), iter, nil, w)
+	if err != nil {
+		return nil, err
+	}
+
+	backendBlock, err := encoding.NewBackendBlock(newBlock.BlockMeta(), r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating creating backend block")
+	}
 
+	return backendBlock, nil
+}
+
+// WriteBlockWithFilter writes the objects of the local block accepted by the filter to the TempoDB backend as a
+// block with the same ID. The local block itself is left as is and marked as flushed. Returns false if the filter
+// rejected every object and nothing was written.
+func (rw *readerWriter) WriteBlockWithFilter(ctx context.Context, block *wal.LocalBlock, filter common.ObjectFilter) (bool, error) {
+	chunkSize := DefaultChunkSizeBytes
+	if rw.compactorCfg != nil && rw.compactorCfg.ChunkSizeBytes > 0 {
+		chunkSize = rw.compactorCfg.ChunkSizeBytes
+	}
+
+	iter, err := block.Iterator(chunkSize)
+	if err != nil {
+		return false, errors.Wrap(err, "error getting local block iterator")
+	}
+	defer iter.Close()
+
+	meta := block.BlockMeta()
+	newBlock, err := rw.writeStreamingBlock(ctx, meta, iter, filter, rw.getWriterForBlock(meta, time.Now()))
+	if err != nil {
+		return false, err
+	}
+
+	return newBlock != nil, block.SetFlushed(ctx)
+}
+
+// writeStreamingBlock writes the objects of the iterator accepted by the filter to a new block with the same ID as
+// the given meta. A nil filter keeps all objects. If the filter rejects every object no block is written and nil is
+// returned.
+func (rw *readerWriter) writeStreamingBlock(ctx context.Context, meta *backend.BlockMeta, iter encoding.Iterator, filter common.ObjectFilter, w backend.Writer) (*encoding.StreamingBlock, error) {
 	// Default and nil check is primarily to make testing easier.
 	flushSize := DefaultFlushSizeBytes
 	if rw.compactorCfg != nil && rw.compactorCfg.FlushSizeBytes > 0 {
 		flushSize = rw.compactorCfg.FlushSizeBytes
 	}
 
-	iter, err := block.GetIterator(combiner)
+	blockCfg, err := rw.blockConfigForTenant(meta.TenantID)
 	if err != nil {
-		return nil, errors.Wrap(err, "error getting completing block iterator")
+		return nil, err
 	}
-	defer iter.Close()
 
-	newBlock, err := encoding.NewStreamingBlock(rw.cfg.Block, blockID, tenantID, []*backend.BlockMeta{meta}, meta.TotalObjects)
+	newBlock, err := encoding.NewStreamingBlock(blockCfg, meta.BlockID, meta.TenantID, []*backend.BlockMeta{meta}, meta.TotalObjects)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating compactor block")
 	}
 
 	var tracker backend.AppendTracker
+	objects := 0
 	for {
 		id, data, err := iter.Next(ctx)
 		if err != nil && err != io.EOF {
@@ -234,10 +298,15 @@ func (rw *readerWriter) CompleteBlockWithBackend(ctx context.Context, block *wal
 			break
 		}
 
+		if filter != nil && !filter(id) {
+			continue
+		}
+
 		err = newBlock.AddObject(id, data)
 		if err != nil {
 			return nil, errors.Wrap(err, "error adding object to compactor block")
 		}
+		objects++
 
 		if newBlock.CurrentBufferLength() > int(flushSize) {
 			tracker, _, err = newBlock.FlushBuffer(ctx, tracker, w)
@@ -247,99 +316,217 @@ func (rw *readerWriter) CompleteBlockWithBackend(ctx context.Context, block *wal
 		}
 	}
 
-	_, err = newBlock.Complete(ctx, tracker, w)
-	if err != nil {
-		return nil, errors.Wrap(err, "error completing compactor block")
+	if objects == 0 && filter != nil {
+		return nil, nil
 	}
 
-	backendBlock, err := encoding.NewBackendBlock(newBlock.BlockMeta(), r)
+	_, err = newBlock.Complete(ctx, tracker, w)
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating creating backend block")
+		return nil, errors.Wrap(err, "error completing compactor block")
 	}
 
-	return backendBlock, nil
+	return newBlock, nil
 }
 
 func (rw *readerWriter) WAL() *wal.WAL {
 	return rw.wal
 }
 
-func (rw *readerWriter) Find(ctx context.Context, tenantID string, id common.ID, blockStart string, blockEnd string) ([][]byte, []string, error) {
+func (rw *readerWriter) Find(ctx context.Context, tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) ([][]byte, []string, error) {
 	// tracing instrumentation
 	logger := log_util.WithContext(ctx, log_util.Logger)
 	span, ctx := opentracing.StartSpanFromContext(ctx, "store.Find")
 	defer span.Finish()
 
-	blockStartUUID, err := uuid.Parse(blockStart)
+	blocks, err := rw.blocksForFind(tenantID, id, blockStart, blockEnd, timeStart, timeEnd)
 	if err != nil {
 		return nil, nil, err
 	}
+	if len(blocks.metas) == 0 {
+		return nil, nil, nil
+	}
+
+	curTime := time.Now()
+	partialTraces, dataEncodings, err := rw.pool.RunJobs(ctx, blocks.metas, func(ctx context.Context, payload interface{}) ([]byte, string, error) {
+		meta := payload.(*backend.BlockMeta)
+		r := rw.getReaderForBlock(meta, curTime)
+		block, err := encoding.NewBackendBlock(meta, r)
+		if err != nil {
+			return nil, "", err
+		}
+
+		foundObject, err := block.Find(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+
+		level.Info(logger).Log("msg", "searching for trace in block", "findTraceID", hex.EncodeToString(id), "block", meta.BlockID, "found", foundObject != nil)
+		span.LogFields(
+			ot_log.String("msg", "searching for trace in block"),
+			ot_log.String("blockID", meta.BlockID.String()),
+			ot_log.Bool("found", foundObject != nil),
+			ot_log.Int("bytes", len(foundObject)),
+			ot_log.Int("live blocks", blocks.live),
+			ot_log.Int("live blocks searched", blocks.liveSearched),
+			ot_log.Int("compacted blocks", blocks.compacted),
+			ot_log.Int("compacted blocks searched", blocks.compactedSearched),
+		)
+
+		return foundObject, meta.DataEncoding, nil
+	})
+
+	return partialTraces, dataEncodings, err
+}
+
+// CountBlocksForFind returns the number of blocks Find would search for the id
+func (rw *readerWriter) CountBlocksForFind(tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) (int, error) {
+	blocks, err := rw.blocksForFind(tenantID, id, blockStart, blockEnd, timeStart, timeEnd)
+	if err != nil {
+		return 0, err
+	}
+	return len(blocks.metas), nil
+}
+
+type findBlocks struct {
+	metas                        []interface{}
+	live, liveSearched           int
+	compacted, compactedSearched int
+}
+
+// blocksForFind gathers the live and compacted blocks that can contain the id
+func (rw *readerWriter) blocksForFind(tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) (findBlocks, error) {
+	blockStartUUID, err := uuid.Parse(blockStart)
+	if err != nil {
+		return findBlocks{}, err
+	}
 	blockStartBytes, err := blockStartUUID.MarshalBinary()
 	if err != nil {
-		return nil, nil, err
+		return findBlocks{}, err
 	}
 	blockEndUUID, err := uuid.Parse(blockEnd)
 	if err != nil {
-		return nil, nil, err
+		return findBlocks{}, err
 	}
 	blockEndBytes, err := blockEndUUID.MarshalBinary()
 	if err != nil {
-		return nil, nil, err
+		return findBlocks{}, err
 	}
 
-	// gather appropriate blocks
 	blocklist := rw.blocklist.Metas(tenantID)
 	compactedBlocklist := rw.blocklist.CompactedMetas(tenantID)
-	copiedBlocklist := make([]interface{}, 0, len(blocklist))
-	blocksSearched := 0
-	compactedBlocksSearched := 0
+	blocks := findBlocks{
+		metas:     make([]interface{}, 0, len(blocklist)),
+		live:      len(blocklist),
+		compacted: len(compactedBlocklist),
+	}
 
 	for _, b := range blocklist {
-		if includeBlock(b, id, blockStartBytes, blockEndBytes) {
-			copiedBlocklist = append(copiedBlocklist, b)
-			blocksSearched++
+		if includeBlock(b, id, blockStartBytes, blockEndBytes) && b.OverlapsTimeRange(timeStart, timeEnd) {
+			blocks.metas = append(blocks.metas, b)
+			blocks.liveSearched++
 		}
 	}
 	for _, c := range compactedBlocklist {
-		if includeCompactedBlock(c, id, blockStartBytes, blockEndBytes, rw.cfg.BlocklistPoll) {
-			copiedBlocklist = append(copiedBlocklist, &c.BlockMeta)
-			compactedBlocksSearched++
+		if includeCompactedBlock(c, id, blockStartBytes, blockEndBytes, rw.cfg.BlocklistPoll) && c.OverlapsTimeRange(timeStart, timeEnd) {
+			blocks.metas = append(blocks.metas, &c.BlockMeta)
+			blocks.compactedSearched++
 		}
 	}
-	if len(copiedBlocklist) == 0 {
-		return nil, nil, nil
+
+	return blocks, nil
+}
+
+// FindMany looks up many IDs at once. Every block is searched once for all of the IDs within its MinID and MaxID,
+// testing each bloom shard and reading each index page at most once. The results are in the order of the IDs.
+func (rw *readerWriter) FindMany(ctx context.Context, tenantID string, ids []common.ID, timeStart int64, timeEnd int64) ([]FindManyResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "store.FindMany")
+	defer span.Finish()
+
+	blockStartBytes, _ := uuid.MustParse(BlockIDMin).MarshalBinary()
+	blockEndBytes, _ := uuid.MustParse(BlockIDMax).MarshalBinary()
+
+	type blockJob struct {
+		meta *backend.BlockMeta
+		idxs []int // of the ids within the MinID and MaxID of the block
+	}
+
+	jobFor := func(meta *backend.BlockMeta, include func(id common.ID) bool) *blockJob {
+		if !meta.OverlapsTimeRange(timeStart, timeEnd) {
+			return nil
+		}
+		var idxs []int
+		for i, id := range ids {
+			if include(id) {
+				idxs = append(idxs, i)
+			}
+		}
+		if len(idxs) == 0 {
+			return nil
+		}
+		return &blockJob{meta: meta, idxs: idxs}
+	}
+
+	var jobs []interface{}
+	for _, b := range rw.blocklist.Metas(tenantID) {
+		b := b
+		if j := jobFor(b, func(id common.ID) bool { return includeBlock(b, id, blockStartBytes, blockEndBytes) }); j != nil {
+			jobs = append(jobs, j)
+		}
+	}
+	for _, c := range rw.blocklist.CompactedMetas(tenantID) {
+		c := c
+		if j := jobFor(&c.BlockMeta, func(id common.ID) bool {
+			return includeCompactedBlock(c, id, blockStartBytes, blockEndBytes, rw.cfg.BlocklistPoll)
+		}); j != nil {
+			jobs = append(jobs, j)
+		}
+	}
+
+	span.LogFields(ot_log.Int("ids", len(ids)), ot_log.Int("blocks searched", len(jobs)))
+
+	results := make([]FindManyResult, len(ids))
+	if len(jobs) == 0 {
+		return results, nil
 	}
 
+	var resultsMtx sync.Mutex
 	curTime := time.Now()
-	partialTraces, dataEncodings, err := rw.pool.RunJobs(ctx, copiedBlocklist, func(ctx context.Context, payload interface{}) ([]byte, string, error) {
-		meta := payload.(*backend.BlockMeta)
-		r := rw.getReaderForBlock(meta, curTime)
-		block, err := encoding.NewBackendBlock(meta, r)
+	_, _, err := rw.pool.RunJobs(ctx, jobs, func(ctx context.Context, payload interface{}) ([]byte, string, error) {
+		j := payload.(*blockJob)
+		block, err := encoding.NewBackendBlock(j.meta, rw.getReaderForBlock(j.meta, curTime))
 		if err != nil {
 			return nil, "", err
 		}
 
-		foundObject, err := block.Find(ctx, id)
+		blockIDs := make([]common.ID, 0, len(j.idxs))
+		for _, i := range j.idxs {
+			blockIDs = append(blockIDs, ids[i])
+		}
+
+		objects, err := block.FindMany(ctx, blockIDs)
 		if err != nil {
 			return nil, "", err
 		}
 
-		level.Info(logger).Log("msg", "searching for trace in block", "findTraceID", hex.EncodeToString(id), "block", meta.BlockID, "found", foundObject != nil)
-		span.LogFields(
-			ot_log.String("msg", "searching for trace in block"),
-			ot_log.String("blockID", meta.BlockID.String()),
-			ot_log.Bool("found", foundObject != nil),
-			ot_log.Int("bytes", len(foundObject)),
-			ot_log.Int("live blocks", len(blocklist)),
-			ot_log.Int("live blocks searched", blocksSearched),
-			ot_log.Int("compacted blocks", len(compactedBlocklist)),
-			ot_log.Int("compacted blocks searched", compactedBlocksSearched),
-		)
+		resultsMtx.Lock()
+		defer resultsMtx.Unlock()
+		for k, obj := range objects {
+			if obj == nil {
+				continue
+			}
+			r := &results[j.idxs[k]]
+			r.Objects = append(r.Objects, obj)
+			r.DataEncodings = append(r.DataEncodings, j.meta.DataEncoding)
+		}
 
-		return foundObject, meta.DataEncoding, nil
+		// results are collected above, nothing is returned through the pool
+		return nil, "", nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return partialTraces, dataEncodings, err
+	return results, nil
 }
 
 func (rw *readerWriter) Shutdown() {
@@ -348,6 +535,34 @@ func (rw *readerWriter) Shutdown() {
 	rw.r.Shutdown()
 }
 
+// EnableBlockOverrides applies the per-tenant block settings to the blocks created by CompleteBlock and compaction.
+func (rw *readerWriter) EnableBlockOverrides(overrides BlockOverrides) {
+	rw.blockOverrides = overrides
+}
+
+// blockConfigForTenant returns the block config with the overrides of the tenant applied
+func (rw *readerWriter) blockConfigForTenant(tenantID string) (*encoding.BlockConfig, error) {
+	if rw.blockOverrides == nil {
+		return rw.cfg.Block, nil
+	}
+
+	cfg := *rw.cfg.Block
+	if enc := rw.blockOverrides.BlockEncodingForTenant(tenantID); enc != "" {
+		e, err := backend.ParseEncoding(enc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid block encoding for tenant %s", tenantID)
+		}
+		cfg.Encoding = e
+	}
+	if fp := rw.blockOverrides.BlockBloomFPForTenant(tenantID); fp > 0 {
+		cfg.BloomFP = fp
+	}
+	if size := rw.blockOverrides.BlockIndexPageSizeBytesForTenant(tenantID); size > 0 {
+		cfg.IndexPageSizeBytes = size
+	}
+	return &cfg, nil
+}
+
 // EnableCompaction activates the compaction/retention loops
 func (rw *readerWriter) EnableCompaction(cfg *CompactorConfig, c CompactorSharder, overrides CompactorOverrides) {
 	// Set default if needed. This is mainly for tests.
@@ -358,6 +573,7 @@ func (rw *readerWriter) EnableCompaction(cfg *CompactorConfig, c CompactorSharde
 	rw.compactorCfg = cfg
 	rw.compactorSharder = c
 	rw.compactorOverrides = overrides
+	rw.blockOverrides = overrides
 
 	if rw.cfg.BlocklistPoll == 0 {
 		level.Info(rw.logger).Log("msg", "polling cycle unset. compaction and retention disabled")
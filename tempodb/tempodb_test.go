@@ -101,7 +101,7 @@ func TestDB(t *testing.T) {
 
 	// read
 	for i, id := range ids {
-		bFound, actualDataEncoding, err := r.Find(context.Background(), testTenantID, id, BlockIDMin, BlockIDMax)
+		bFound, actualDataEncoding, err := r.Find(context.Background(), testTenantID, id, BlockIDMin, BlockIDMax, 0, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, []string{testDataEncoding}, actualDataEncoding)
 
@@ -153,7 +153,7 @@ func TestBlockSharding(t *testing.T) {
 	// check if it respects the blockstart/blockend params - case1: hit
 	blockStart := uuid.MustParse(BlockIDMin).String()
 	blockEnd := uuid.MustParse(BlockIDMax).String()
-	bFound, _, err := r.Find(context.Background(), testTenantID, id, blockStart, blockEnd)
+	bFound, _, err := r.Find(context.Background(), testTenantID, id, blockStart, blockEnd, 0, 0)
 	assert.NoError(t, err)
 	assert.Greater(t, len(bFound), 0)
 
@@ -165,16 +165,124 @@ func TestBlockSharding(t *testing.T) {
 	// check if it respects the blockstart/blockend params - case2: miss
 	blockStart = uuid.MustParse(BlockIDMin).String()
 	blockEnd = uuid.MustParse(BlockIDMin).String()
-	bFound, _, err = r.Find(context.Background(), testTenantID, id, blockStart, blockEnd)
+	bFound, _, err = r.Find(context.Background(), testTenantID, id, blockStart, blockEnd, 0, 0)
 	assert.NoError(t, err)
 	assert.Len(t, bFound, 0)
 }
 
+func TestFindTimeRange(t *testing.T) {
+	r, w, _, tempDir := testConfig(t, backend.EncLZ4_256k, 0)
+	defer os.RemoveAll(tempDir)
+
+	r.EnablePolling(&mockJobSharder{})
+
+	wal := w.WAL()
+	head, err := wal.NewBlock(uuid.New(), testTenantID, "")
+	assert.NoError(t, err)
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	req := test.MakeRequest(rand.Int()%1000, id)
+	bReq, err := proto.Marshal(req)
+	assert.NoError(t, err)
+	err = head.Write(id, bReq)
+	assert.NoError(t, err, "unexpected error writing req")
+
+	_, err = w.CompleteBlock(head, &mockSharder{})
+	assert.NoError(t, err)
+
+	r.(*readerWriter).pollBlocklist()
+	blocks := r.(*readerWriter).blocklist.Metas(testTenantID)
+	require.Len(t, blocks, 1)
+	start := blocks[0].StartTime.Unix()
+	end := blocks[0].EndTime.Unix()
+
+	tests := []struct {
+		name               string
+		timeStart, timeEnd int64
+		expectedFound      bool
+	}{
+		{"unbounded", 0, 0, true},
+		{"overlapping", start - 60, end + 60, true},
+		{"before block", 0, start - 1, false},
+		{"after block", end + 1, 0, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bFound, _, err := r.Find(context.Background(), testTenantID, id, BlockIDMin, BlockIDMax, tc.timeStart, tc.timeEnd)
+			assert.NoError(t, err)
+			if tc.expectedFound {
+				assert.Len(t, bFound, 1)
+			} else {
+				assert.Len(t, bFound, 0)
+			}
+		})
+	}
+}
+
+func TestFindMany(t *testing.T) {
+	r, w, _, tempDir := testConfig(t, backend.EncLZ4_256k, 0)
+	defer os.RemoveAll(tempDir)
+
+	r.EnablePolling(&mockJobSharder{})
+
+	newID := func() common.ID {
+		id := make([]byte, 16)
+		rand.Read(id)
+		return id
+	}
+	writeBlock := func(ids ...common.ID) {
+		head, err := w.WAL().NewBlock(uuid.New(), testTenantID, "")
+		require.NoError(t, err)
+		for _, id := range ids {
+			bReq, err := proto.Marshal(test.MakeRequest(1, id))
+			require.NoError(t, err)
+			require.NoError(t, head.Write(id, bReq))
+		}
+		_, err = w.CompleteBlock(head, &mockSharder{})
+		require.NoError(t, err)
+	}
+
+	// shared is split over both blocks
+	first, second, shared, missing := newID(), newID(), newID(), newID()
+	writeBlock(first, shared)
+	writeBlock(second, shared)
+	r.(*readerWriter).pollBlocklist()
+
+	results, err := r.FindMany(context.Background(), testTenantID, []common.ID{first, second, shared, missing}, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Len(t, results[0].Objects, 1)
+	assert.Len(t, results[1].Objects, 1)
+	assert.Len(t, results[2].Objects, 2)
+	assert.Len(t, results[2].DataEncodings, 2)
+	assert.Len(t, results[3].Objects, 0)
+
+	out := &tempopb.PushRequest{}
+	require.NoError(t, proto.Unmarshal(results[0].Objects[0], out))
+	assert.Equal(t, []byte(first), out.Batch.InstrumentationLibrarySpans[0].Spans[0].TraceId)
+
+	// blocks outside of the time range are skipped
+	results, err = r.FindMany(context.Background(), testTenantID, []common.ID{first, second}, 0, 1)
+	require.NoError(t, err)
+	assert.Len(t, results[0].Objects, 0)
+	assert.Len(t, results[1].Objects, 0)
+
+	// Find searches both blocks for the shared id
+	count, err := r.CountBlocksForFind(testTenantID, shared, BlockIDMin, BlockIDMax, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = r.CountBlocksForFind(testTenantID, shared, BlockIDMin, BlockIDMax, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
 func TestNilOnUnknownTenantID(t *testing.T) {
 	r, _, _, tempDir := testConfig(t, backend.EncLZ4_256k, 0)
 	defer os.RemoveAll(tempDir)
 
-	buff, _, err := r.Find(context.Background(), "unknown", []byte{0x01}, BlockIDMin, BlockIDMax)
+	buff, _, err := r.Find(context.Background(), "unknown", []byte{0x01}, BlockIDMin, BlockIDMax, 0, 0)
 	assert.Nil(t, buff)
 	assert.Nil(t, err)
 }
@@ -516,7 +624,7 @@ func TestSearchCompactedBlocks(t *testing.T) {
 
 	// read
 	for i, id := range ids {
-		bFound, _, err := r.Find(context.Background(), testTenantID, id, blockID, blockID)
+		bFound, _, err := r.Find(context.Background(), testTenantID, id, blockID, blockID, 0, 0)
 		assert.NoError(t, err)
 
 		out := &tempopb.PushRequest{}
@@ -544,7 +652,7 @@ func TestSearchCompactedBlocks(t *testing.T) {
 
 	// find should succeed with old block range
 	for i, id := range ids {
-		bFound, _, err := r.Find(context.Background(), testTenantID, id, blockID, blockID)
+		bFound, _, err := r.Find(context.Background(), testTenantID, id, blockID, blockID, 0, 0)
 		assert.NoError(t, err)
 
 		out := &tempopb.PushRequest{}
@@ -596,6 +704,75 @@ func TestCompleteBlock(t *testing.T) {
 	}
 }
 
+func TestWriteBlockWithFilter(t *testing.T) {
+	_, w, _, tempDir := testConfig(t, backend.EncLZ4_256k, time.Minute)
+	defer os.RemoveAll(tempDir)
+
+	l, err := local.NewBackend(&local.Config{Path: path.Join(tempDir, "local")})
+	require.NoError(t, err)
+
+	numMsgs := 10
+	ids := make([][]byte, 0, numMsgs)
+	newLocalBlock := func() *wal.LocalBlock {
+		block, err := w.WAL().NewBlock(uuid.New(), testTenantID, "")
+		require.NoError(t, err, "unexpected error creating block")
+
+		for _, id := range ids {
+			bReq, err := proto.Marshal(test.MakeRequest(rand.Int()%10+1, id))
+			require.NoError(t, err)
+			err = block.Write(id, bReq)
+			require.NoError(t, err, "unexpected error writing req")
+		}
+
+		complete, err := w.CompleteBlockWithBackend(context.Background(), block, &mockSharder{}, backend.NewReader(l), backend.NewWriter(l))
+		require.NoError(t, err, "unexpected error completing block")
+		localBlock, err := wal.NewLocalBlock(context.Background(), complete, l)
+		require.NoError(t, err)
+		return localBlock
+	}
+	for i := 0; i < numMsgs; i++ {
+		id := make([]byte, 16)
+		rand.Read(id)
+		id[0] = byte(i)
+		ids = append(ids, id)
+	}
+
+	rw := w.(*readerWriter)
+	even := func(id common.ID) bool { return id[0]%2 == 0 }
+	localBlock := newLocalBlock()
+	written, err := w.WriteBlockWithFilter(context.Background(), localBlock, even)
+	require.NoError(t, err)
+	assert.True(t, written)
+	assert.False(t, localBlock.FlushedTime().IsZero())
+
+	meta, err := rw.r.BlockMeta(context.Background(), localBlock.BlockMeta().BlockID, testTenantID)
+	require.NoError(t, err)
+	assert.Equal(t, numMsgs/2, meta.TotalObjects)
+	flushed, err := encoding.NewBackendBlock(meta, rw.r)
+	require.NoError(t, err)
+
+	for _, id := range ids {
+		// the local block keeps every object
+		foundBytes, err := localBlock.Find(context.TODO(), id)
+		assert.NoError(t, err)
+		assert.NotNil(t, foundBytes)
+
+		foundBytes, err = flushed.Find(context.TODO(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, even(id), foundBytes != nil)
+	}
+
+	none := func(id common.ID) bool { return false }
+	localBlock = newLocalBlock()
+	written, err = w.WriteBlockWithFilter(context.Background(), localBlock, none)
+	require.NoError(t, err)
+	assert.False(t, written)
+	assert.False(t, localBlock.FlushedTime().IsZero())
+
+	_, err = rw.r.BlockMeta(context.Background(), localBlock.BlockMeta().BlockID, testTenantID)
+	assert.Equal(t, backend.ErrDoesNotExist, err)
+}
+
 func TestShouldCache(t *testing.T) {
 	tempDir, err := ioutil.TempDir(tmpdir, "")
 	defer os.RemoveAll(tempDir)
@@ -662,3 +839,40 @@ func TestShouldCache(t *testing.T) {
 		})
 	}
 }
+
+func TestCompleteBlockWithBlockOverrides(t *testing.T) {
+	_, w, _, tempDir := testConfig(t, backend.EncLZ4_256k, time.Minute)
+	defer os.RemoveAll(tempDir)
+
+	w.EnableBlockOverrides(&mockOverrides{blockEncoding: "zstd", bloomFP: 0.05})
+
+	block, err := w.WAL().NewBlock(uuid.New(), testTenantID, "")
+	require.NoError(t, err)
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	req := test.MakeRequest(10, id)
+	bReq, err := proto.Marshal(req)
+	require.NoError(t, err)
+	require.NoError(t, block.Write(id, bReq))
+
+	complete, err := w.CompleteBlock(block, &mockSharder{})
+	require.NoError(t, err)
+	assert.Equal(t, backend.EncZstd, complete.BlockMeta().Encoding)
+
+	foundBytes, err := complete.Find(context.TODO(), id)
+	require.NoError(t, err)
+	out := &tempopb.PushRequest{}
+	require.NoError(t, proto.Unmarshal(foundBytes, out))
+	assert.True(t, proto.Equal(out, req))
+
+	// unset overrides fall back to the block config
+	w.EnableBlockOverrides(&mockOverrides{})
+	cfg, err := w.(*readerWriter).blockConfigForTenant(testTenantID)
+	require.NoError(t, err)
+	assert.Equal(t, backend.EncLZ4_256k, cfg.Encoding)
+
+	w.EnableBlockOverrides(&mockOverrides{blockEncoding: "brotli"})
+	_, err = w.CompleteBlock(block, &mockSharder{})
+	assert.Error(t, err)
+}
@@ -153,6 +153,14 @@ func (a *AppendBlock) Write(id common.ID, b []byte) error {
 	return nil
 }
 
+// Sync flushes the appended objects to disk. It is a no-op once the block is no longer appended to.
+func (a *AppendBlock) Sync() error {
+	if a.appendFile == nil {
+		return nil
+	}
+	return a.appendFile.Sync()
+}
+
 func (a *AppendBlock) BlockID() uuid.UUID {
 	return a.meta.BlockID
 }
@@ -229,6 +237,16 @@ func (a *AppendBlock) Clear() error {
 	return os.Remove(name)
 }
 
+// Filename returns the name of the wal file of the block.
+func (a *AppendBlock) Filename() string {
+	return filepath.Base(a.fullFilename())
+}
+
+// Reader returns a reader of the wal file of the block, e.g. to transfer it to another ingester.
+func (a *AppendBlock) Reader() (io.ReadCloser, error) {
+	return os.Open(a.fullFilename())
+}
+
 func (a *AppendBlock) fullFilename() string {
 	if a.meta.Version == "v0" {
 		return filepath.Join(a.filepath, fmt.Sprintf("%v:%v", a.meta.BlockID, a.meta.TenantID))
@@ -2,8 +2,10 @@ package wal
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	willf_bloom "github.com/willf/bloom"
 	"go.uber.org/atomic"
 
 	"github.com/grafana/tempo/tempodb/backend"
@@ -23,6 +25,11 @@ type LocalBlock struct {
 	writer backend.Writer
 
 	flushedTime atomic.Int64 // protecting flushedTime b/c it's accessed from the store on flush and from the ingester instance checking flush time
+
+	// bloom shards are read once on the first Find and kept in memory so lookups of traces that aren't in the
+	// block don't touch the disk
+	bloomMtx sync.Mutex
+	blooms   []*willf_bloom.BloomFilter
 }
 
 func NewLocalBlock(ctx context.Context, existingBlock *encoding.BackendBlock, l *local.Backend) (*LocalBlock, error) {
@@ -46,7 +53,38 @@ func NewLocalBlock(ctx context.Context, existingBlock *encoding.BackendBlock, l
 }
 
 func (c *LocalBlock) Find(ctx context.Context, id common.ID) ([]byte, error) {
-	return c.BackendBlock.Find(ctx, id)
+	blooms, err := c.loadBlooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !blooms[common.ShardKeyForTraceID(id, len(blooms))].Test(id) {
+		return nil, nil
+	}
+
+	return c.BackendBlock.FindWithoutBloom(ctx, id)
+}
+
+func (c *LocalBlock) loadBlooms(ctx context.Context) ([]*willf_bloom.BloomFilter, error) {
+	c.bloomMtx.Lock()
+	defer c.bloomMtx.Unlock()
+
+	if c.blooms != nil {
+		return c.blooms, nil
+	}
+
+	shardCount := common.ValidateShardCount(int(c.BlockMeta().BloomShardCount))
+	blooms := make([]*willf_bloom.BloomFilter, 0, shardCount)
+	for shardKey := 0; shardKey < shardCount; shardKey++ {
+		bloom, err := c.BackendBlock.Bloom(ctx, shardKey)
+		if err != nil {
+			return nil, err
+		}
+		blooms = append(blooms, bloom)
+	}
+
+	c.blooms = blooms
+	return c.blooms, nil
 }
 
 // FlushedTime returns the time the block was flushed.  Will return 0
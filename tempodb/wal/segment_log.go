@@ -0,0 +1,344 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+const (
+	liveTracesDir = "live"
+
+	// each record is prefixed with the payload length and the crc32 of the payload
+	segmentRecordHeaderLength = 8
+	segmentNameFormat         = "%020d"
+)
+
+var (
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+	errCorruptRecord = errors.New("corrupt segment record")
+)
+
+// TruncateAllSegments can be passed to SegmentLog.Truncate to remove every segment.
+const TruncateAllSegments = ^uint64(0)
+
+// SegmentLog is an append-only log of trace payloads for a single tenant. It is split into numbered segment
+// files so that data can be removed from the front of the log once it is durable elsewhere.
+type SegmentLog struct {
+	mtx sync.Mutex
+
+	dir             string
+	maxSegmentBytes int64
+
+	segments    []uint64 // ascending, includes the active segment
+	nextSeq     uint64
+	active      *os.File
+	activeSeq   uint64
+	activeBytes int64
+	dirty       bool
+
+	buffer []byte
+}
+
+// NewSegmentLog opens the segment log of a tenant. Existing segments are kept for Replay and new records are
+// always appended to a new segment.
+func (w *WAL) NewSegmentLog(tenantID string, maxSegmentBytes int64) (*SegmentLog, error) {
+	dir := filepath.Join(w.c.Filepath, liveTracesDir, tenantID)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &SegmentLog{
+		dir:             dir,
+		maxSegmentBytes: maxSegmentBytes,
+	}
+	for _, f := range files {
+		seq, err := strconv.ParseUint(f.Name(), 10, 64)
+		if err != nil || f.IsDir() {
+			continue
+		}
+		l.segments = append(l.segments, seq)
+	}
+	sort.Slice(l.segments, func(i, j int) bool { return l.segments[i] < l.segments[j] })
+
+	l.nextSeq = 1
+	if len(l.segments) > 0 {
+		l.nextSeq = l.segments[len(l.segments)-1] + 1
+	}
+
+	return l, nil
+}
+
+// SegmentLogTenants returns the tenants that have a segment log on disk.
+func (w *WAL) SegmentLogTenants() ([]string, error) {
+	files, err := ioutil.ReadDir(filepath.Join(w.c.Filepath, liveTracesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() {
+			tenants = append(tenants, f.Name())
+		}
+	}
+	return tenants, nil
+}
+
+// Append writes a record to the active segment and returns the sequence number of the segment it was written to.
+// The record is not durable until Sync is called. The slices are not retained.
+func (l *SegmentLog) Append(id []byte, object []byte, searchData []byte) (uint64, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.active != nil && l.maxSegmentBytes > 0 && l.activeBytes >= l.maxSegmentBytes {
+		err := l.seal()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if l.active == nil {
+		err := l.openNext()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	l.buffer = encodeSegmentRecord(l.buffer[:0], id, object, searchData)
+	n, err := l.active.Write(l.buffer)
+	l.activeBytes += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	l.dirty = true
+
+	return l.activeSeq, nil
+}
+
+// Sync flushes the active segment to disk. Sealed segments are synced when they are sealed.
+func (l *SegmentLog) Sync() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.active == nil || !l.dirty {
+		return nil
+	}
+	l.dirty = false
+	return l.active.Sync()
+}
+
+// Truncate removes every segment with a sequence number lower than keepFrom. If the active segment is removed
+// the next Append starts a new segment.
+func (l *SegmentLog) Truncate(keepFrom uint64) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.active != nil && l.activeSeq < keepFrom {
+		err := l.seal()
+		if err != nil {
+			return err
+		}
+	}
+
+	remaining := l.segments[:0]
+	for _, seq := range l.segments {
+		if seq >= keepFrom {
+			remaining = append(remaining, seq)
+			continue
+		}
+		err := os.Remove(l.segmentPath(seq))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	l.segments = remaining
+
+	return nil
+}
+
+// Replay calls fn for every record of the segments that existed when the log was opened, in the order they
+// were written. A segment is read up to the first corrupt or partially written record. The slices passed to
+// fn are not reused.
+func (l *SegmentLog) Replay(logger log.Logger, fn func(seq uint64, id []byte, object []byte, searchData []byte) error) error {
+	l.mtx.Lock()
+	segments := make([]uint64, 0, len(l.segments))
+	for _, seq := range l.segments {
+		if l.active == nil || seq != l.activeSeq {
+			segments = append(segments, seq)
+		}
+	}
+	l.mtx.Unlock()
+
+	for _, seq := range segments {
+		records, err := l.replaySegment(seq, fn)
+		if errors.Is(err, errCorruptRecord) || errors.Is(err, io.ErrUnexpectedEOF) {
+			level.Warn(logger).Log("msg", "segment ends with a corrupt or partial record. partial replay likely.", "segment", l.segmentPath(seq), "records", records, "err", err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error replaying segment %s: %w", l.segmentPath(seq), err)
+		}
+	}
+
+	return nil
+}
+
+// Close syncs and closes the active segment.
+func (l *SegmentLog) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.active == nil {
+		return nil
+	}
+	return l.seal()
+}
+
+func (l *SegmentLog) replaySegment(seq uint64, fn func(seq uint64, id []byte, object []byte, searchData []byte) error) (int, error) {
+	f, err := os.Open(l.segmentPath(seq))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	remaining := info.Size()
+
+	r := bufio.NewReader(f)
+	header := make([]byte, segmentRecordHeaderLength)
+	records := 0
+	for {
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+
+		remaining -= segmentRecordHeaderLength
+
+		// don't trust a corrupt length to allocate
+		length := int64(binary.LittleEndian.Uint32(header[0:]))
+		if length > remaining {
+			return records, io.ErrUnexpectedEOF
+		}
+		remaining -= length
+
+		payload := make([]byte, length)
+		_, err = io.ReadFull(r, payload)
+		if err != nil {
+			return records, err
+		}
+		if crc32.Checksum(payload, castagnoli) != binary.LittleEndian.Uint32(header[4:]) {
+			return records, errCorruptRecord
+		}
+
+		id, object, searchData, err := decodeSegmentPayload(payload)
+		if err != nil {
+			return records, err
+		}
+
+		err = fn(seq, id, object, searchData)
+		if err != nil {
+			return records, err
+		}
+		records++
+	}
+}
+
+// openNext should be called under lock
+func (l *SegmentLog) openNext() error {
+	seq := l.nextSeq
+
+	f, err := os.OpenFile(l.segmentPath(seq), os.O_APPEND|os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	l.nextSeq++
+	l.active = f
+	l.activeSeq = seq
+	l.activeBytes = 0
+	l.segments = append(l.segments, seq)
+	return nil
+}
+
+// seal syncs and closes the active segment. should be called under lock
+func (l *SegmentLog) seal() error {
+	f := l.active
+	l.active = nil
+	l.dirty = false
+
+	err := f.Sync()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (l *SegmentLog) segmentPath(seq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf(segmentNameFormat, seq))
+}
+
+func encodeSegmentRecord(buffer []byte, id []byte, object []byte, searchData []byte) []byte {
+	buffer = append(buffer, make([]byte, segmentRecordHeaderLength)...)
+	for _, b := range [][]byte{id, object, searchData} {
+		buffer = appendUvarint(buffer, uint64(len(b)))
+		buffer = append(buffer, b...)
+	}
+
+	payload := buffer[segmentRecordHeaderLength:]
+	binary.LittleEndian.PutUint32(buffer[0:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buffer[4:], crc32.Checksum(payload, castagnoli))
+	return buffer
+}
+
+func decodeSegmentPayload(payload []byte) ([]byte, []byte, []byte, error) {
+	fields := make([][]byte, 3)
+	for i := range fields {
+		length, n := binary.Uvarint(payload)
+		if n <= 0 || uint64(len(payload)-n) < length {
+			return nil, nil, nil, errCorruptRecord
+		}
+		payload = payload[n:]
+		if length > 0 {
+			fields[i] = payload[:length]
+		}
+		payload = payload[length:]
+	}
+	return fields[0], fields[1], fields[2], nil
+}
+
+func appendUvarint(buffer []byte, v uint64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], v)
+	return append(buffer, scratch[:n]...)
+}
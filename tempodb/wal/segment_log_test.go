@@ -0,0 +1,128 @@
+package wal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type segmentRecord struct {
+	seq        uint64
+	id         []byte
+	object     []byte
+	searchData []byte
+}
+
+func replayAll(t *testing.T, l *SegmentLog) []segmentRecord {
+	var records []segmentRecord
+	err := l.Replay(log.NewNopLogger(), func(seq uint64, id []byte, object []byte, searchData []byte) error {
+		records = append(records, segmentRecord{seq: seq, id: id, object: object, searchData: searchData})
+		return nil
+	})
+	require.NoError(t, err)
+	return records
+}
+
+func TestSegmentLogReplay(t *testing.T) {
+	tempDir := t.TempDir()
+	w, err := New(&Config{Filepath: tempDir})
+	require.NoError(t, err)
+
+	// tiny segments so every record gets its own
+	l, err := w.NewSegmentLog(testTenantID, 1)
+	require.NoError(t, err)
+
+	expected := []segmentRecord{
+		{seq: 1, id: []byte{0x01}, object: []byte("foo"), searchData: []byte("search")},
+		{seq: 2, id: []byte{0x02}, object: []byte("bar")},
+		{seq: 3, id: []byte{0x01}, object: []byte("baz")},
+	}
+	for _, r := range expected {
+		seq, err := l.Append(r.id, r.object, r.searchData)
+		require.NoError(t, err)
+		assert.Equal(t, r.seq, seq)
+	}
+	require.NoError(t, l.Close())
+
+	tenants, err := w.SegmentLogTenants()
+	require.NoError(t, err)
+	assert.Equal(t, []string{testTenantID}, tenants)
+
+	l, err = w.NewSegmentLog(testTenantID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, expected, replayAll(t, l))
+
+	// new records go to a new segment and are not replayed
+	seq, err := l.Append([]byte{0x03}, []byte("qux"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), seq)
+	assert.Equal(t, expected, replayAll(t, l))
+}
+
+func TestSegmentLogTruncate(t *testing.T) {
+	tempDir := t.TempDir()
+	w, err := New(&Config{Filepath: tempDir})
+	require.NoError(t, err)
+
+	l, err := w.NewSegmentLog(testTenantID, 1)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = l.Append([]byte{byte(i)}, []byte("foo"), nil)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, l.Truncate(3))
+	files, err := ioutil.ReadDir(l.dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "00000000000000000003", files[0].Name())
+
+	// truncating the active segment starts a new one
+	require.NoError(t, l.Truncate(TruncateAllSegments))
+	files, err = ioutil.ReadDir(l.dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	seq, err := l.Append([]byte{0x01}, []byte("foo"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), seq)
+	require.NoError(t, l.Close())
+}
+
+func TestSegmentLogPartialRecord(t *testing.T) {
+	tempDir := t.TempDir()
+	w, err := New(&Config{Filepath: tempDir})
+	require.NoError(t, err)
+
+	l, err := w.NewSegmentLog(testTenantID, 0)
+	require.NoError(t, err)
+	_, err = l.Append([]byte{0x01}, []byte("foo"), nil)
+	require.NoError(t, err)
+	_, err = l.Append([]byte{0x02}, []byte("bar"), nil)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	// cut the last record in half
+	path := filepath.Join(l.dir, "00000000000000000001")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-2))
+
+	l, err = w.NewSegmentLog(testTenantID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []segmentRecord{{seq: 1, id: []byte{0x01}, object: []byte("foo")}}, replayAll(t, l))
+
+	// corrupt the remaining record
+	buff, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	buff[segmentRecordHeaderLength] ^= 0xFF
+	require.NoError(t, ioutil.WriteFile(path, buff, 0644))
+
+	assert.Empty(t, replayAll(t, l))
+}
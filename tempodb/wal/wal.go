@@ -125,10 +125,40 @@ func (w *WAL) RescanBlocks(log log.Logger) ([]*AppendBlock, error) {
 	return blocks, nil
 }
 
+// CreateBlockFile creates an empty wal file with the name of a wal file of another ingester, e.g. for a transfer.
+func (w *WAL) CreateBlockFile(name string) (*os.File, error) {
+	if err := validBlockFilename(name); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(filepath.Join(w.c.Filepath, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+}
+
+// OpenBlockFile replays a single wal file. Like RescanBlocks the block can be completed but not appended to.
+// It can return a warning or a fatal error
+func (w *WAL) OpenBlockFile(name string) (*AppendBlock, error, error) {
+	if err := validBlockFilename(name); err != nil {
+		return nil, nil, err
+	}
+	return newAppendBlockFromFile(name, w.c.Filepath)
+}
+
+func validBlockFilename(name string) error {
+	if name == "" || filepath.Base(name) != name {
+		return fmt.Errorf("invalid wal file name %q", name)
+	}
+	_, _, _, _, _, err := parseFilename(name)
+	return err
+}
+
 func (w *WAL) NewBlock(id uuid.UUID, tenantID string, dataEncoding string) (*AppendBlock, error) {
 	return newAppendBlock(id, tenantID, w.c.Filepath, w.c.Encoding, dataEncoding)
 }
 
+// NewBlockWithEncoding is like NewBlock but overrides the configured encoding of the wal, e.g. for a single tenant.
+func (w *WAL) NewBlockWithEncoding(id uuid.UUID, tenantID string, dataEncoding string, e backend.Encoding) (*AppendBlock, error) {
+	return newAppendBlock(id, tenantID, w.c.Filepath, e, dataEncoding)
+}
+
 func (w *WAL) NewFile(blockid uuid.UUID, tenantid string, dir string, name string) (*os.File, error) {
 	p := filepath.Join(w.c.Filepath, dir)
 	err := os.MkdirAll(p, os.ModePerm)
@@ -117,6 +117,32 @@ func TestCompletedDirIsRemoved(t *testing.T) {
 	assert.Error(t, err, "completedDir should not exist")
 }
 
+func TestNewBlockWithEncoding(t *testing.T) {
+	wal, err := New(&Config{
+		Filepath: t.TempDir(),
+		Encoding: backend.EncNone,
+	})
+	require.NoError(t, err)
+
+	block, err := wal.NewBlockWithEncoding(uuid.New(), testTenantID, "", backend.EncSnappy)
+	require.NoError(t, err)
+	assert.Equal(t, backend.EncSnappy, block.Meta().Encoding)
+
+	bReq, err := proto.Marshal(test.MakeRequest(10, []byte{0x01}))
+	require.NoError(t, err)
+	require.NoError(t, block.Write([]byte{0x01}, bReq))
+
+	// the encoding is kept in the file name and used on replay
+	blocks, err := wal.RescanBlocks(log.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, backend.EncSnappy, blocks[0].Meta().Encoding)
+
+	found, err := blocks[0].Find([]byte{0x01}, &mockCombiner{})
+	require.NoError(t, err)
+	assert.Equal(t, bReq, found)
+}
+
 func TestErrorConditions(t *testing.T) {
 	tempDir, err := ioutil.TempDir("/tmp", "")
 	defer os.RemoveAll(tempDir)